@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/export"
+	adminRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/admin"
+	auditRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/audit"
+	exportRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/export"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/security"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
+)
+
+// runExport ユーザー・クラス・学校データをCSV・XLSX・JSONで書き出す
+// 保護者の連絡先の列はENCRYPTION_KEYが設定されている場合のみ指定できる
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	dataset := flags.String("dataset", "", "Dataset to export: users, classes or schools (required)")
+	format := flags.String("format", entities.ExportFormatCSV, "Output format: csv, xlsx or json")
+	columns := flags.String("columns", "", "Comma-separated columns (default: all non-sensitive columns)")
+	schoolID := flags.Int64("school-id", 0, "Limit to a school (optional)")
+	out := flags.String("out", "", "Output file (default: stdout)")
+	flags.Parse(args)
+
+	if *dataset == "" {
+		log.Fatal("--dataset is required")
+	}
+
+	req := entities.ExportRequest{
+		Dataset: *dataset,
+		Format:  strings.ToLower(*format),
+	}
+	for _, column := range strings.Split(*columns, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			req.Columns = append(req.Columns, column)
+		}
+	}
+	if *schoolID > 0 {
+		id := strconv.FormatInt(*schoolID, 10)
+		req.SchoolID = &id
+	}
+
+	cfg := config.Load()
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("DB connect error: %v", err)
+	}
+	defer db.Close()
+
+	// 管理用コマンドのためテナントトランザクションは使用しない
+	appDB := database.NewDB(db)
+	exportUsecase := usecase.NewExportUsecase(exportRepo.NewExportRepository(appDB), cfg)
+	exportUsecase.SetAuditRecorder(usecase.NewAuditUsecase(auditRepo.NewAuditRepository(appDB), adminRepo.NewAdminRepository(appDB), cfg))
+	if cipher, err := security.NewCipher(cfg.EncryptionKey); err == nil {
+		exportUsecase.SetCipher(cipher)
+	}
+
+	ctx := context.Background()
+	prepared, err := exportUsecase.PrepareExport(ctx, req, policy.RoleAdmin, "")
+	if err != nil {
+		log.Fatalf("export error: %v", err)
+	}
+
+	output := os.Stdout
+	if *out != "" {
+		output, err = os.Create(*out)
+		if err != nil {
+			log.Fatalf("create output error: %v", err)
+		}
+		defer output.Close()
+	}
+
+	writer, err := export.NewWriter(prepared.Format, output, prepared.Columns)
+	if err != nil {
+		log.Fatalf("export error: %v", err)
+	}
+	count, err := exportUsecase.Export(ctx, prepared, writer)
+	if err != nil {
+		log.Fatalf("export error after %d rows: %v", count, err)
+	}
+	if err := writer.Close(); err != nil {
+		log.Fatalf("export error: %v", err)
+	}
+
+	log.Printf("dataset=%s format=%s rows=%d", prepared.Dataset, prepared.Format, count)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/mail"
+	adminRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/admin"
+	auditRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/audit"
+	schoolRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/school"
+	userRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/user"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/roster"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
+)
+
+// runImportStudents CSV・XLSXの名簿から生徒を一括登録し、新規の生徒へ招待メールをキューに積む
+// 学籍番号で照合するため、同じ名簿を再実行しても重複登録されない
+func runImportStudents(args []string) {
+	flags := flag.NewFlagSet("import-students", flag.ExitOnError)
+	schoolID := flags.Int64("school-id", 0, "Target school id (required)")
+	path := flags.String("file", "", "Roster file (.csv or .xlsx) (required)")
+	dryRun := flags.Bool("dry-run", false, "Validate the roster without creating users")
+	invite := flags.Bool("invite", true, "Queue invitations for newly created students")
+	flags.Parse(args)
+
+	if *schoolID <= 0 || *path == "" {
+		log.Fatal("--school-id and --file are required")
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		log.Fatalf("open roster error: %v", err)
+	}
+	defer file.Close()
+
+	rows, err := roster.Parse(*path, file)
+	if err != nil {
+		log.Fatalf("parse roster error: %v", err)
+	}
+
+	cfg := config.Load()
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("DB connect error: %v", err)
+	}
+	defer db.Close()
+
+	// 管理用コマンドのためテナントトランザクションは使用しない
+	appDB := database.NewDB(db)
+	adminRepository := adminRepo.NewAdminRepository(appDB)
+	adminUsecase := usecase.NewAdminUsecase(adminRepository, userRepo.NewUserRepository(appDB), cfg)
+	adminUsecase.SetSchoolRepository(schoolRepo.NewSchoolRepository(appDB))
+	adminUsecase.SetUserImportRepository(userRepo.NewUserImportRepository(appDB))
+	adminUsecase.SetAuditRecorder(usecase.NewAuditUsecase(auditRepo.NewAuditRepository(appDB), adminRepository, cfg))
+
+	// 招待メールはサーバーの送信キューで配信する
+	if *invite && !*dryRun {
+		mailer, err := mail.NewMailer(cfg)
+		if err != nil {
+			log.Fatalf("mailer init error: %v", err)
+		}
+		adminUsecase.SetMailer(mail.NewQueue(database.ConnectRedis(cfg.RedisURL), mailer))
+	}
+
+	result, err := adminUsecase.ImportStudents(context.Background(), strconv.FormatInt(*schoolID, 10), rows, *dryRun, *invite, policy.RoleAdmin, "")
+	if err != nil {
+		log.Fatalf("import error: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		log.Fatalf("output error: %v", err)
+	}
+
+	log.Printf("rows=%d created=%d updated=%d invited=%d errors=%d dry_run=%t", result.TotalRows, result.Created, result.Updated, result.Invited, len(result.Errors), result.DryRun)
+	if len(result.Errors) > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,24 +1,48 @@
 package main
 
 import (
+    "context"
     "crypto/rand"
     "database/sql"
     "encoding/hex"
     "flag"
     "fmt"
     "log"
+    "os"
+    "strconv"
     "strings"
     "time"
 
+    "github.com/rikut0904/bloomia/backend/internal/domain/entities"
+    "github.com/rikut0904/bloomia/backend/internal/domain/schoolcode"
     "github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
     dbpkg "github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+    adminRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/admin"
+    auditRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/audit"
+    schoolRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/school"
+    userRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/user"
+    "github.com/rikut0904/bloomia/backend/internal/usecase"
 )
 
+// 引数なし（フラグのみ）の場合は管理者アカウントを作成する
+// サブコマンド: import-students 名簿ファイルから生徒を一括登録
+//               export          ユーザー・クラス・学校データを書き出し
 func main() {
+    if len(os.Args) > 1 && os.Args[1] == "import-students" {
+        runImportStudents(os.Args[2:])
+        return
+    }
+    if len(os.Args) > 1 && os.Args[1] == "export" {
+        runExport(os.Args[2:])
+        return
+    }
+
     name := flag.String("name", "", "Admin user's name (required)")
     email := flag.String("email", "", "Admin user's email (required)")
     schoolID := flag.Int64("school-id", 0, "Target school id (optional)")
-    schoolCode := flag.String("school-code", "", "Target school code (optional)")
+    schoolCode := flag.String("school-code", "", "Target school code (optional; former codes also resolve)")
+    createSchoolName := flag.String("create-school", "", "Create a school with this name if the target school is not specified or not found (optional)")
+    prefecture := flag.String("prefecture", "", "Prefecture of the created school, used for its generated code (optional)")
     firebaseUID := flag.String("firebase-uid", "", "Firebase UID (optional; auto-generated if empty)")
     approve := flag.Bool("approve", true, "Mark admin as approved and active")
     flag.Parse()
@@ -34,7 +58,7 @@ func main() {
     }
     defer db.Close()
 
-    sid, err := resolveOrCreateSchool(db, *schoolID, *schoolCode)
+    sid, err := resolveOrCreateSchool(db, cfg, *schoolID, *schoolCode, *createSchoolName, *prefecture)
     if err != nil {
         log.Fatalf("resolve school error: %v", err)
     }
@@ -60,7 +84,8 @@ func genUID() string {
     return "admin_" + hex.EncodeToString(b)
 }
 
-func resolveOrCreateSchool(db *sql.DB, id int64, code string) (int64, error) {
+// resolveOrCreateSchool 対象の学校を解決し、見つからない場合は--create-schoolが指定されたときのみ作成する
+func resolveOrCreateSchool(db *sql.DB, cfg *config.Config, id int64, code, createName, prefecture string) (int64, error) {
     if id > 0 {
         var exists bool
         if err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM schools WHERE id=$1)`, id).Scan(&exists); err != nil {
@@ -69,30 +94,41 @@ func resolveOrCreateSchool(db *sql.DB, id int64, code string) (int64, error) {
         if exists { return id, nil }
         return 0, fmt.Errorf("school not found: id=%d", id)
     }
+    code = schoolcode.Normalize(code)
     if code != "" {
         var sid int64
-        err := db.QueryRow(`SELECT id FROM schools WHERE code=$1`, code).Scan(&sid)
+        err := db.QueryRow(`
+            SELECT id FROM schools
+            WHERE code=$1 OR id = (SELECT school_id FROM school_code_aliases WHERE code=$1)
+        `, code).Scan(&sid)
         if err == nil { return sid, nil }
         if err != sql.ErrNoRows { return 0, err }
-        // create with given code
-        return createSchool(db, "System Administration", code)
+        if createName == "" {
+            return 0, fmt.Errorf("school not found: code=%s (pass --create-school to create it)", code)
+        }
+    } else if createName == "" {
+        return 0, fmt.Errorf("--school-id, --school-code or --create-school is required")
     }
-    // Try to find or create default code 'system'
-    var sid int64
-    err := db.QueryRow(`SELECT id FROM schools WHERE code='system'`).Scan(&sid)
-    if err == nil { return sid, nil }
-    if err != sql.ErrNoRows { return 0, err }
-    return createSchool(db, "System Administration", "system")
+    return createSchool(db, cfg, createName, code, prefecture)
 }
 
-func createSchool(db *sql.DB, name, code string) (int64, error) {
-    var sid int64
-    err := db.QueryRow(`
-        INSERT INTO schools (name, code, is_active, created_at, updated_at)
-        VALUES ($1, $2, true, NOW(), NOW())
-        RETURNING id
-    `, name, code).Scan(&sid)
+// createSchool 学校を作成（コードが空の場合はSCHOOL_CODE_FORMATの形式で採番する）
+func createSchool(db *sql.DB, cfg *config.Config, name, code, prefecture string) (int64, error) {
+    // 管理用コマンドのためテナントトランザクションは使用しない
+    appDB := dbpkg.NewDB(db)
+    schoolUsecase := usecase.NewSchoolUsecase(schoolRepo.NewSchoolRepository(appDB), userRepo.NewUserRepository(appDB), cfg)
+    schoolUsecase.SetAuditRecorder(usecase.NewAuditUsecase(auditRepo.NewAuditRepository(appDB), adminRepo.NewAdminRepository(appDB), cfg))
+
+    created, err := schoolUsecase.CreateSchool(context.Background(), &entities.School{
+        SchoolID:   code,
+        SchoolName: name,
+        Prefecture: &prefecture,
+    })
+    if err != nil { return 0, err }
+
+    sid, err := strconv.ParseInt(created.ID, 10, 64)
     if err != nil { return 0, err }
+    fmt.Printf("School created:\n  id=%s\n  name=%s\n  code=%s\n", created.ID, created.SchoolName, created.SchoolID)
     return sid, nil
 }
 
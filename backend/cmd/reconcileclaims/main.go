@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/firebase"
+	adminRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/admin"
+	schoolRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/school"
+	userRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/user"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
+)
+
+// DBのロール・学校IDとFirebaseカスタムクレームの差分を学校単位で検出・修正する
+func main() {
+	schoolID := flag.Int64("school-id", 0, "Target school id (required)")
+	dryRun := flag.Bool("dry-run", false, "Report drift without updating Firebase claims")
+	flag.Parse()
+
+	if *schoolID <= 0 {
+		log.Fatal("--school-id is required")
+	}
+
+	cfg := config.Load()
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("DB connect error: %v", err)
+	}
+	defer db.Close()
+
+	firebaseClient, err := firebase.NewFirebaseClient()
+	if err != nil {
+		log.Fatalf("Firebase init error: %v", err)
+	}
+
+	// 管理用コマンドのためテナントトランザクションは使用しない
+	appDB := database.NewDB(db)
+	adminUsecase := usecase.NewAdminUsecase(adminRepo.NewAdminRepository(appDB), userRepo.NewUserRepository(appDB), cfg)
+	adminUsecase.SetSchoolRepository(schoolRepo.NewSchoolRepository(appDB))
+	adminUsecase.SetFirebaseClient(firebaseClient)
+
+	report, err := adminUsecase.ReconcileSchoolClaims(context.Background(), *schoolID, *dryRun)
+	if err != nil {
+		log.Fatalf("reconcile error: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("output error: %v", err)
+	}
+
+	log.Printf("checked=%d in_sync=%d drifted=%d not_found=%d", report.Checked, report.InSync, len(report.Drifted), len(report.NotFound))
+}
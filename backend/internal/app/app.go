@@ -1,22 +1,31 @@
 package app
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/redis/go-redis/v9"
 
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
 	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
 	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
 	"github.com/rikut0904/bloomia/backend/internal/infrastructure/firebase"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/mail"
 	"github.com/rikut0904/bloomia/backend/internal/infrastructure/middleware"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/middleware/auth"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/security"
 	adminRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/admin"
+	auditRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/audit"
 	dashboardRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/dashboard"
+	exportRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/export"
+	recordRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/record"
 	redisRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/redis"
 	schoolRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/school"
 	userRepo "github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/user"
@@ -51,31 +60,169 @@ func NewApp(cfg *config.Config) (*App, error) {
 		}
 	}
 
-	// リポジトリ初期化
-	userRepository := userRepo.NewUserRepository(db)
-	schoolRepository := schoolRepo.NewSchoolRepository(db)
-	_ = redisRepo.NewRedisRepository(redisClient) // 将来使用予定
-	dashboardRepository := dashboardRepo.NewDashboardRepository(db)
-	adminRepository := adminRepo.NewAdminRepository(db)
+	// トークン検証（AUTH_PROVIDERで切り替え。firebaseでクライアント未設定の場合は無効）
+	var tokenVerifier auth.TokenVerifier
+	if cfg.AuthProvider != "firebase" || firebaseClient != nil {
+		tokenVerifier, err = auth.NewTokenVerifier(cfg, firebaseClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize token verifier: %w", err)
+		}
+	}
+
+	// パスワードログイン・代理ログイン用のトークン発行（JWT_SECRETを設定した場合）
+	// 外部IdPを使う場合もFirebaseを使えない学校のため、ローカル発行トークンを併せて検証する
+	// 併用時はJWT_SECRETだけで署名できるトークンを受け付けないよう、有効なセッション（sidクレーム）を必須にする
+	localVerifier, isLocalProvider := tokenVerifier.(*auth.LocalVerifier)
+	if !isLocalProvider && cfg.JWTSecret != "" {
+		localVerifier, err = auth.NewLocalVerifier(cfg.JWTSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize local token verifier: %w", err)
+		}
+		if tokenVerifier != nil {
+			tokenVerifier = auth.NewChainVerifier(tokenVerifier, localVerifier.WithRequiredSession())
+		} else {
+			tokenVerifier = localVerifier.WithRequiredSession()
+		}
+	}
+
+	// メール送信（Redisの再送キュー経由で送信）
+	mailer, err := mail.NewMailer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize mailer: %w", err)
+	}
+	mailQueue := mail.NewQueue(redisClient, mailer)
+	go mailQueue.Run(context.Background())
+
+	// リポジトリ初期化（認証済みリクエストはRLS用のテナントトランザクション内で実行）
+	tenantDB := database.NewDB(db)
+	userRepository := userRepo.NewUserRepository(tenantDB)
+	schoolRepository := schoolRepo.NewSchoolRepository(tenantDB)
+	sessionRepository := redisRepo.NewSessionRepository(redisClient)
+	credentialRepository := userRepo.NewCredentialRepository(tenantDB)
+	mfaRepository := userRepo.NewMFARepository(tenantDB)
+	userImportRepository := userRepo.NewUserImportRepository(tenantDB)
+	loginAttemptRepository := redisRepo.NewLoginAttemptRepository(redisClient)
+	schoolStatsCache := redisRepo.NewSchoolStatsCache(redisClient)
+	dashboardRepository := dashboardRepo.NewDashboardRepository(tenantDB)
+	adminRepository := adminRepo.NewAdminRepository(tenantDB)
+	recordRepository := recordRepo.NewRecordRepository(tenantDB)
+	auditRepository := auditRepo.NewAuditRepository(tenantDB)
+	exportRepository := exportRepo.NewExportRepository(tenantDB)
+	approvalRepository := userRepo.NewApprovalRepository(tenantDB)
+	userDeletionRepository := userRepo.NewUserDeletionRepository(tenantDB)
+	studentProfileRepository := userRepo.NewStudentProfileRepository(tenantDB)
+	academicYearRepository := schoolRepo.NewAcademicYearRepository(tenantDB)
+	districtRepository := schoolRepo.NewDistrictRepository(tenantDB)
+
+	// 学校コードの形式は起動時に検証し、採番時の失敗を防ぐ
+	if _, err := usecase.SchoolCodeFormat(cfg); err != nil {
+		return nil, fmt.Errorf("invalid SCHOOL_CODE_FORMAT: %w", err)
+	}
 
 	// ユースケース初期化
-	authUsecase := usecase.NewAuthUsecase(userRepository, adminRepository)
+	auditUsecase := usecase.NewAuditUsecase(auditRepository, adminRepository, cfg)
+	// 書き込みを行うユースケースは監査ログの記録時に対象校の統計のキャッシュも無効化する
+	writeAuditRecorder := usecase.NewStatsInvalidatingRecorder(auditUsecase, schoolStatsCache)
+	authUsecase := usecase.NewAuthUsecase(userRepository, adminRepository, cfg)
+	authUsecase.SetMailer(mailQueue)
 	schoolUsecase := usecase.NewSchoolUsecase(schoolRepository, userRepository, cfg)
+	schoolUsecase.SetAuditRecorder(writeAuditRecorder)
+	schoolUsecase.SetSchoolStatsCache(schoolStatsCache)
+	// 成績・出欠・提出物・ログインなどの変更はコミット後のDBの通知で統計のキャッシュを無効化する
+	go database.Listen(context.Background(), cfg.DatabaseURL, "school_stats_changed", schoolUsecase.InvalidateSchoolStats)
 	dashboardUsecase := usecase.NewDashboardUsecase(userRepository, cfg)
 	dashboardUsecase.SetDashboardRepository(dashboardRepository)
     adminUsecase := usecase.NewAdminUsecase(adminRepository, userRepository, cfg)
     adminUsecase.SetSchoolRepository(schoolRepository)
+    adminUsecase.SetMailer(mailQueue)
+    adminUsecase.SetFirebaseClient(firebaseClient)
+    adminUsecase.SetSessionRepository(sessionRepository)
+    adminUsecase.SetAuditRecorder(writeAuditRecorder)
+    adminUsecase.SetUserImportRepository(userImportRepository)
+    adminUsecase.SetUserDeletionRepository(userDeletionRepository)
+    adminUsecase.SetStudentProfileRepository(studentProfileRepository)
+    adminUsecase.SetDistrictRepository(districtRepository)
+
+	// 削除から猶予期間を過ぎたユーザーの個人情報を定期的に消去
+	go adminUsecase.RunErasureJob(context.Background(), time.Hour)
+	recordUsecase := usecase.NewRecordUsecase(recordRepository, adminRepository, cfg)
+	sessionUsecase := usecase.NewSessionUsecase(sessionRepository, userRepository, adminRepository, cfg)
+	// ローカル発行トークンを検証できる場合はパスワードログインでアクセストークンも発行
+	if localVerifier != nil {
+		sessionUsecase.SetTokenIssuer(localVerifier)
+		// サポート用の代理ログインもローカル発行トークンを使用
+		adminUsecase.SetTokenIssuer(localVerifier)
+	}
+	authUsecase.SetCredentialRepository(credentialRepository)
+	authUsecase.SetLoginAttemptRepository(loginAttemptRepository)
+	authUsecase.SetSessionUsecase(sessionUsecase)
+
+	// 二要素認証（シークレットの暗号化にENCRYPTION_KEYが必要。本番では必須）
+	mfaUsecase := usecase.NewMFAUsecase(mfaRepository, adminRepository, sessionUsecase, cfg)
+	mfaUsecase.SetLoginAttemptRepository(loginAttemptRepository)
+	if mfaCipher, err := security.NewCipher(cfg.EncryptionKey); err == nil {
+		mfaUsecase.SetCipher(mfaCipher)
+	} else if cfg.Environment == "production" {
+		return nil, fmt.Errorf("failed to initialize two-factor authentication: %w", err)
+	} else {
+		log.Printf("Two-factor authentication disabled: %v", err)
+	}
+
+	// 自己登録ユーザーの承認
+	approvalUsecase := usecase.NewApprovalUsecase(approvalRepository, cfg)
+	approvalUsecase.SetMailer(mailQueue)
+	approvalUsecase.SetAuditRecorder(writeAuditRecorder)
+
+	// 年度・学期の判定と年度更新
+	academicYearUsecase := usecase.NewAcademicYearUsecase(academicYearRepository, schoolRepository)
+	academicYearUsecase.SetAuditRecorder(writeAuditRecorder)
+
+	// 教育委員会（複数校）の管理と集計
+	districtUsecase := usecase.NewDistrictUsecase(districtRepository, schoolRepository, adminRepository)
+	districtUsecase.SetAuditRecorder(writeAuditRecorder)
+	districtUsecase.SetSchoolStatsCache(schoolStatsCache)
+
+	// データエクスポート（保護者の連絡先の復号にはENCRYPTION_KEYが必要）
+	exportUsecase := usecase.NewExportUsecase(exportRepository, cfg)
+	exportUsecase.SetAuditRecorder(auditUsecase)
+	if exportCipher, err := security.NewCipher(cfg.EncryptionKey); err == nil {
+		exportUsecase.SetCipher(exportCipher)
+	}
 
 	// ハンドラー初期化
 	authHandler := httpHandler.NewAuthHandler(authUsecase, cfg)
-	schoolHandler := httpHandler.NewSchoolHandler(schoolUsecase)
-	dashboardHandler := httpHandler.NewDashboardHandler(dashboardUsecase)
-	adminHandler := httpHandler.NewAdminHandler(adminUsecase, cfg)
+	schoolHandler := httpHandler.NewSchoolHandler(schoolUsecase, authUsecase, cfg)
+	dashboardHandler := httpHandler.NewDashboardHandler(dashboardUsecase, authUsecase, cfg)
+	adminHandler := httpHandler.NewAdminHandler(adminUsecase, authUsecase, cfg)
+	recordHandler := httpHandler.NewRecordHandler(recordUsecase, authUsecase, cfg)
+	sessionHandler := httpHandler.NewSessionHandler(sessionUsecase, authUsecase, cfg)
+	mfaHandler := httpHandler.NewMFAHandler(mfaUsecase, authUsecase, cfg)
+	auditHandler := httpHandler.NewAuditHandler(auditUsecase, authUsecase, cfg)
+	exportHandler := httpHandler.NewExportHandler(exportUsecase, authUsecase, cfg)
+	approvalHandler := httpHandler.NewApprovalHandler(approvalUsecase, authUsecase, cfg)
+	academicYearHandler := httpHandler.NewAcademicYearHandler(academicYearUsecase, authUsecase, cfg)
+	districtHandler := httpHandler.NewDistrictHandler(districtUsecase, adminUsecase, authUsecase, cfg)
+	for _, base := range []*httpHandler.BaseHandler{authHandler.BaseHandler, schoolHandler.BaseHandler, dashboardHandler.BaseHandler, adminHandler.BaseHandler, recordHandler.BaseHandler, sessionHandler.BaseHandler, mfaHandler.BaseHandler, auditHandler.BaseHandler, exportHandler.BaseHandler, approvalHandler.BaseHandler, academicYearHandler.BaseHandler, districtHandler.BaseHandler} {
+		base.SetTenantRunner(tenantDB)
+		base.SetSessionUsecase(sessionUsecase)
+		base.SetMFAUsecase(mfaUsecase)
+	}
+
+	// 開発用トークン発行（ローカル認証かつ本番以外の場合のみ）
+	var devHandler *httpHandler.DevHandler
+	if isLocalProvider && cfg.Environment != "production" {
+		devHandler = httpHandler.NewDevHandler(authUsecase, sessionUsecase)
+	}
+
+	// ローカル発行トークンはsidクレームのセッションが失効していないことも確認
+	if tokenVerifier != nil {
+		tokenVerifier = auth.NewSessionVerifier(tokenVerifier, sessionUsecase)
+	}
 
 	// ルーター設定
 	router := chi.NewRouter()
 	setupMiddleware(router, cfg)
-	setupRoutes(router, authHandler, schoolHandler, dashboardHandler, adminHandler, firebaseClient, cfg)
+	setupRoutes(router, authHandler, schoolHandler, dashboardHandler, adminHandler, recordHandler, sessionHandler, mfaHandler, auditHandler, exportHandler, approvalHandler, academicYearHandler, districtHandler, approvalUsecase, districtUsecase, devHandler, tokenVerifier, cfg)
 
 	return &App{
 		router: router,
@@ -95,13 +242,14 @@ func setupMiddleware(r *chi.Mux, cfg *config.Config) {
 	r.Use(chiMiddleware.Recoverer)
 	r.Use(chiMiddleware.RequestID)
 	r.Use(chiMiddleware.RealIP)
+	r.Use(middleware.AuditContext)
 	
 	// CORS設定
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{cfg.FrontendURL, "http://localhost:3000"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Session-ID"},
+		ExposedHeaders:   []string{"Link", middleware.ImpersonationHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -113,59 +261,167 @@ func setupMiddleware(r *chi.Mux, cfg *config.Config) {
 	})
 }
 
-func setupRoutes(r *chi.Mux, authHandler *httpHandler.AuthHandler, schoolHandler *httpHandler.SchoolHandler, dashboardHandler *httpHandler.DashboardHandler, adminHandler *httpHandler.AdminHandler, firebaseClient *firebase.FirebaseClient, cfg *config.Config) {
-	// Firebase認証ミドルウェア（FirebaseClientが設定されている場合のみ）
-	var firebaseAuthMiddleware func(http.Handler) http.Handler
-	if firebaseClient != nil {
-		firebaseAuthMiddleware = middleware.FirebaseAuthMiddleware(firebaseClient)
+func setupRoutes(r *chi.Mux, authHandler *httpHandler.AuthHandler, schoolHandler *httpHandler.SchoolHandler, dashboardHandler *httpHandler.DashboardHandler, adminHandler *httpHandler.AdminHandler, recordHandler *httpHandler.RecordHandler, sessionHandler *httpHandler.SessionHandler, mfaHandler *httpHandler.MFAHandler, auditHandler *httpHandler.AuditHandler, exportHandler *httpHandler.ExportHandler, approvalHandler *httpHandler.ApprovalHandler, academicYearHandler *httpHandler.AcademicYearHandler, districtHandler *httpHandler.DistrictHandler, accountStatus middleware.AccountStatusLookup, districtScope middleware.DistrictScopeLookup, devHandler *httpHandler.DevHandler, tokenVerifier auth.TokenVerifier, cfg *config.Config) {
+	// トークン認証ミドルウェア（トークン検証が設定されている場合のみ）
+	var tokenAuthMiddleware func(http.Handler) http.Handler
+	if tokenVerifier != nil {
+		tokenAuthMiddleware = middleware.TokenAuthMiddleware(tokenVerifier)
 	}
+	// 未承認・無効なアカウントを拒否（/auth/sync と /auth/status を除く認証済みルート）
+	requireApproved := middleware.RequireApprovedAccount(accountStatus)
+	// 教育委員会管理者の管轄校を解決（承認済みの認証済みルートで使用）
+	resolveDistrict := middleware.ResolveDistrictScope(districtScope)
 
 	// 認証不要のルート
 	r.Route("/api/v1", func(r chi.Router) {
 		// 認証関連
-		r.Post("/auth/register", authHandler.RegisterUser)
+		// 招待からの登録（トークンがあれば検証済みのUIDで紐付け、なければパスワードログイン用のアカウントを作成）
+		if tokenVerifier != nil {
+			r.With(middleware.OptionalTokenAuthMiddleware(tokenVerifier)).Post("/auth/register", authHandler.RegisterUser)
+		} else {
+			r.Post("/auth/register", authHandler.RegisterUser)
+		}
 		r.Post("/auth/verify", authHandler.VerifyUser)
-		r.HandleFunc("/auth/sync", authHandler.SyncUser) // POST/GET両方対応
+		r.Get("/auth/invitations/validate", authHandler.ValidateInvitation)
+		r.Post("/auth/sessions/refresh", sessionHandler.RefreshSession)
+
+		// パスワードログイン（Googleアカウントを使えない学校向け）
+		r.Post("/auth/login", authHandler.LoginUser)
+		r.Post("/auth/password/change", authHandler.ChangePassword)
+		r.Post("/auth/password/reset-request", authHandler.RequestPasswordReset)
+		r.Post("/auth/password/reset", authHandler.ResetPassword)
+
+		// 開発用トークン発行
+		if devHandler != nil {
+			r.Post("/dev/token", devHandler.IssueToken)
+		}
 		
+		// 承認前でも使えるルート（承認待ち・却下されたユーザーも承認状況を参照可能）
+		r.Group(func(r chi.Router) {
+			if tokenAuthMiddleware != nil {
+				r.Use(tokenAuthMiddleware)
+			}
+			r.Get("/auth/status", approvalHandler.GetAccountStatus)
+			// 初回サインイン時の同期（UID・メールアドレスはトークンの値のみ使用し、確認済みのメールアドレスで招待・メールドメインを照合）
+			r.HandleFunc("/auth/sync", authHandler.SyncUser) // POST/GET両方対応
+		})
+
 		// 認証が必要なルート
 		r.Group(func(r chi.Router) {
-			if firebaseAuthMiddleware != nil {
-				r.Use(firebaseAuthMiddleware)
+			if tokenAuthMiddleware != nil {
+				r.Use(tokenAuthMiddleware, requireApproved, resolveDistrict)
 			}
 			
 			// ダッシュボード
 			r.Get("/dashboard", dashboardHandler.GetDashboard)
 			r.Get("/dashboard/tasks", dashboardHandler.GetTasks)
 			r.Get("/dashboard/stats", dashboardHandler.GetStats)
+
+			// 成績・出席（閲覧範囲はロールに応じて絞り込み）
+			r.Get("/students/{id}/grades", recordHandler.GetStudentGrades)
+			r.Get("/students/{id}/attendance", recordHandler.GetStudentAttendance)
+			r.Get("/notifications", recordHandler.GetNotifications)
+			r.Get("/guardian/students", recordHandler.GetMyStudents)
+
+			// ログインセッション（端末一覧・失効）
+			r.Post("/auth/sessions", sessionHandler.CreateSession)
+			r.Get("/auth/sessions", sessionHandler.ListSessions)
+			r.Delete("/auth/sessions/{id}", sessionHandler.RevokeSession)
+
+			// 二要素認証（管理者ロールは重要な操作の前に本人確認が必要）
+			r.Get("/auth/mfa", mfaHandler.GetStatus)
+			r.Post("/auth/mfa/enroll", mfaHandler.Enroll)
+			r.Post("/auth/mfa/confirm", mfaHandler.Confirm)
+			r.Post("/auth/mfa/verify", mfaHandler.Verify)
+			r.Post("/auth/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
 		})
 		
         // 管理者機能（環境変数で認証を制御）
         r.Group(func(r chi.Router) {
 			// 本番環境では認証を有効にする
-			if !cfg.DisableAuth && firebaseAuthMiddleware != nil {
-				r.Use(firebaseAuthMiddleware)
+			authEnabled := !cfg.DisableAuth && tokenAuthMiddleware != nil
+			if authEnabled {
+				r.Use(tokenAuthMiddleware, requireApproved, resolveDistrict)
+			}
+
+			// 学校単位の権限チェック（認証が無効な場合はユースケース側の判定のみ）
+			requireSchool := func(perm policy.Permission) func(http.Handler) http.Handler {
+				if !authEnabled {
+					return func(next http.Handler) http.Handler { return next }
+				}
+				return middleware.RequireSchoolPermission(perm, "id")
+			}
+			requirePermission := func(perm policy.Permission) func(http.Handler) http.Handler {
+				if !authEnabled {
+					return func(next http.Handler) http.Handler { return next }
+				}
+				return middleware.RequirePermission(perm)
 			}
 			
 			// ユーザー管理
 			r.Get("/admin/users", adminHandler.GetAllUsers)
 			r.Get("/admin/users/{id}", adminHandler.GetUserByID)
 			r.Put("/admin/users/{id}", adminHandler.UpdateUser)
+			r.Put("/admin/users/{id}/class", adminHandler.TransferClass)
 			r.Put("/admin/users/role", adminHandler.UpdateUserRole)
 			r.Put("/admin/users/status", adminHandler.UpdateUserStatus)
 			r.Post("/admin/invite", adminHandler.InviteUser)
+			r.Get("/admin/invitations", adminHandler.ListInvitations)
+			r.Post("/admin/invitations", adminHandler.InviteUser)
+			r.Post("/admin/invitations/{id}/revoke", adminHandler.RevokeInvitation)
+			r.Post("/admin/invitations/{id}/resend", adminHandler.ResendInvitation)
+			r.Post("/admin/users/{id}/logout", adminHandler.ForceLogoutUser)
+			r.Delete("/admin/users/{id}", adminHandler.DeleteUser)
+			r.Post("/admin/users/{id}/restore", adminHandler.RestoreUser)
+			r.Post("/admin/users/{id}/erase", adminHandler.EraseUser)
+			r.With(requirePermission(policy.UsersImpersonate)).Post("/admin/users/{id}/impersonate", adminHandler.Impersonate)
+			r.Delete("/admin/users/{id}/mfa", mfaHandler.ResetUserMFA)
+			r.With(requireSchool(policy.UsersUpdateStatus)).Post("/admin/schools/{id}/logout", adminHandler.ForceLogoutSchool)
+			r.With(requireSchool(policy.UsersInvite)).Post("/admin/schools/{id}/students/import", adminHandler.ImportStudents)
+			r.With(requireSchool(policy.UsersUpdateStatus)).Get("/admin/schools/{id}/approvals", approvalHandler.ListPending)
+			r.With(requireSchool(policy.UsersUpdateStatus)).Post("/admin/schools/{id}/approvals/approve", approvalHandler.Approve)
+			r.With(requireSchool(policy.UsersUpdateStatus)).Post("/admin/schools/{id}/approvals/reject", approvalHandler.Reject)
+			r.Get("/admin/guardians/{id}/students", recordHandler.GetGuardianStudents)
+			r.Post("/admin/guardians/{id}/students", recordHandler.LinkGuardianStudent)
+			r.Delete("/admin/guardians/{id}/students/{studentId}", recordHandler.UnlinkGuardianStudent)
 			r.Get("/admin/schools", adminHandler.GetAllSchools)
             // 学校作成（管理者用）
             r.Post("/admin/schools", adminHandler.CreateSchool)
 			r.Get("/admin/stats", adminHandler.GetUserStats)
+
+			// 監査ログ
+			r.With(requirePermission(policy.AuditRead)).Get("/admin/audit", auditHandler.ListEvents)
+			r.With(requirePermission(policy.AuditRead)).Get("/admin/audit/verify", auditHandler.VerifyChain)
+
+			// データエクスポート
+			r.With(requirePermission(policy.DataExport)).Get("/admin/export", exportHandler.Export)
 			
 			// 学校管理
-			r.Post("/schools", schoolHandler.CreateSchool)
-			r.Get("/schools", schoolHandler.GetSchools)
-			r.Get("/schools/{id}", schoolHandler.GetSchoolByID)
-			r.Put("/schools/{id}", schoolHandler.UpdateSchool)
-			r.Delete("/schools/{id}", schoolHandler.DeleteSchool)
-			r.Get("/schools/{id}/stats", schoolHandler.GetSchoolStats)
-			r.Get("/schools/{id}/users", schoolHandler.GetSchoolUsers)
+			r.With(requirePermission(policy.SchoolsCreate)).Post("/schools", schoolHandler.CreateSchool)
+			r.With(requirePermission(policy.SchoolsRead)).Get("/schools", schoolHandler.GetSchools)
+			r.With(requirePermission(policy.SchoolsUpdate)).Get("/schools/code-check", schoolHandler.ValidateSchoolCode)
+			r.With(requireSchool(policy.SchoolsRead)).Get("/schools/{id}", schoolHandler.GetSchoolByID)
+			r.With(requireSchool(policy.SchoolsUpdate)).Put("/schools/{id}", schoolHandler.UpdateSchool)
+			r.With(requireSchool(policy.SchoolsUpdate)).Patch("/schools/{id}", schoolHandler.UpdateSchool)
+			r.With(requireSchool(policy.SchoolsDelete)).Delete("/schools/{id}", schoolHandler.DeleteSchool)
+			r.With(requireSchool(policy.SchoolsDelete)).Post("/schools/{id}/restore", schoolHandler.RestoreSchool)
+			r.With(requireSchool(policy.SchoolsUpdate)).Put("/schools/{id}/code", schoolHandler.ChangeSchoolCode)
+			r.With(requireSchool(policy.SchoolsRead)).Get("/schools/{id}/code-aliases", schoolHandler.ListSchoolCodeAliases)
+			r.With(requireSchool(policy.SchoolsRead)).Get("/schools/{id}/academic-year", academicYearHandler.GetCurrentPeriod)
+			r.With(requireSchool(policy.AcademicYearRollover)).Post("/schools/{id}/academic-year/rollover", academicYearHandler.Rollover)
+			r.With(requireSchool(policy.UsersStats)).Get("/schools/{id}/stats", schoolHandler.GetSchoolStats)
+
+			// 教育委員会（管轄校の範囲はユースケースで判定）
+			r.With(requirePermission(policy.DistrictsRead)).Get("/districts", districtHandler.ListDistricts)
+			r.With(requirePermission(policy.DistrictsManage)).Post("/districts", districtHandler.CreateDistrict)
+			r.With(requirePermission(policy.DistrictsRead)).Get("/districts/{id}", districtHandler.GetDistrict)
+			r.With(requirePermission(policy.DistrictsRead)).Get("/districts/{id}/schools", districtHandler.ListDistrictSchools)
+			r.With(requirePermission(policy.UsersStats)).Get("/districts/{id}/stats", districtHandler.GetDistrictStats)
+			r.With(requirePermission(policy.DistrictsManage)).Put("/districts/{id}/schools/{schoolId}", districtHandler.AssignSchool)
+			r.With(requirePermission(policy.DistrictsManage)).Delete("/districts/{id}/schools/{schoolId}", districtHandler.RemoveSchool)
+			r.With(requirePermission(policy.DistrictsManage)).Post("/districts/{id}/admins", districtHandler.AssignAdmin)
+			r.With(requireSchool(policy.UsersRead)).Get("/schools/{id}/users", schoolHandler.GetSchoolUsers)
+			r.With(requireSchool(policy.UsersInvite)).Post("/schools/{id}/students", schoolHandler.CreateStudent)
 		})
 	})
 }
@@ -0,0 +1,201 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+)
+
+// 監査対象の操作
+const (
+	ActionUserUpdateRole    = "user.update_role"
+	ActionUserUpdateStatus  = "user.update_status"
+	ActionUserApprove       = "user.approve"
+	ActionUserReject        = "user.reject"
+	ActionUserUpdate        = "user.update"
+	ActionUserTransferClass = "user.transfer_class"
+	ActionUserInvite        = "user.invite"
+	ActionUserImport        = "user.import"
+	ActionUserDelete        = "user.delete"
+	ActionUserRestore       = "user.restore"
+	ActionUserErase         = "user.erase"
+	ActionUserImpersonate   = "user.impersonate"
+	ActionSchoolCreate      = "school.create"
+	ActionSchoolUpdate      = "school.update"
+	ActionSchoolDelete      = "school.delete"
+	ActionSchoolRestore     = "school.restore"
+	ActionSchoolRollover    = "school.rollover"
+	ActionSchoolChangeCode  = "school.change_code"
+	ActionDistrictCreate    = "district.create"
+	ActionDistrictAssign    = "district.assign_school"
+	ActionDistrictUnassign  = "district.remove_school"
+	ActionDistrictAdmin     = "district.assign_admin"
+	ActionDataExport        = "data.export"
+)
+
+// 操作対象の種類
+const (
+	TargetUser       = "user"
+	TargetInvitation = "invitation"
+	TargetSchool     = "school"
+	TargetDistrict   = "district"
+	TargetDataset    = "dataset"
+)
+
+// Redacted 個人情報の項目で、値の代わりに記録する値
+const Redacted = "[redacted]"
+
+// personalFields ユーザー・招待の監査ログに値を記録しない個人情報の項目（変更があったことのみ記録する）
+// 監査ログは追記のみで後から消去できないため、個人情報の消去後も氏名・メールアドレスなどが残らないようにする
+var personalFields = map[string]bool{
+	"name":           true,
+	"email":          true,
+	"furigana":       true,
+	"avatar_url":     true,
+	"student_number": true,
+}
+
+// ActorSystem 認証情報のない操作（バッチ処理など）の操作者ロール
+const ActorSystem = "system"
+
+// Actor 操作を行ったユーザーとリクエストの情報
+// リクエスト開始時にコンテキストへ設定し、認証の各段階で判明した情報を追記する
+type Actor struct {
+	UserID    string // usersテーブルのID
+	UID       string // Firebase UID
+	Role      string
+	SchoolID  string
+	RequestID string
+	IPAddress string
+
+	ImpersonatorUID string // 代理ログイン中の場合、実際に操作している管理者のFirebase UID
+}
+
+// Entry 監査ログに記録する操作内容
+type Entry struct {
+	Action     string
+	TargetType string
+	TargetID   string
+	SchoolID   string                 // 操作対象が属する学校（ない場合は空）
+	Before     map[string]interface{} // 作成時はnil
+	After      map[string]interface{} // 削除時はnil
+}
+
+type actorContextKey struct{}
+
+// NewContext 操作者情報をコンテキストに設定
+func NewContext(ctx context.Context, actor *Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext コンテキストから操作者情報を取得（未設定の場合はnil）
+func ActorFromContext(ctx context.Context) *Actor {
+	actor, _ := ctx.Value(actorContextKey{}).(*Actor)
+	return actor
+}
+
+// Diff 変更前後で値が異なる項目のみを取り出す
+func Diff(before, after map[string]interface{}) map[string]entities.AuditChange {
+	changes := map[string]entities.AuditChange{}
+	for key, value := range after {
+		previous, ok := before[key]
+		if !ok || !reflect.DeepEqual(previous, value) {
+			changes[key] = entities.AuditChange{Before: previous, After: value}
+		}
+	}
+	for key, value := range before {
+		if _, ok := after[key]; !ok {
+			changes[key] = entities.AuditChange{Before: value, After: nil}
+		}
+	}
+	return changes
+}
+
+// RedactPersonalData ユーザー・招待の変更内容から個人情報の値を取り除く（対象はIDのみで識別する）
+func RedactPersonalData(targetType string, changes map[string]entities.AuditChange) {
+	if targetType != TargetUser && targetType != TargetInvitation {
+		return
+	}
+	for key, change := range changes {
+		if !personalFields[key] {
+			continue
+		}
+		if change.Before != nil {
+			change.Before = Redacted
+		}
+		if change.After != nil {
+			change.After = Redacted
+		}
+		changes[key] = change
+	}
+}
+
+// hashPayload ハッシュ計算に使う項目（フィールド順を固定してJSON化する）
+type hashPayload struct {
+	PrevHash   string                          `json:"prev_hash"`
+	SchoolID   *string                         `json:"school_id"`
+	ActorID    *string                         `json:"actor_id"`
+	ActorUID   string                          `json:"actor_uid"`
+	ActorRole  string                          `json:"actor_role"`
+	Action     string                          `json:"action"`
+	TargetType string                          `json:"target_type"`
+	TargetID   string                          `json:"target_id"`
+	Changes    map[string]entities.AuditChange `json:"changes"`
+	RequestID  string                          `json:"request_id"`
+	IPAddress  string                          `json:"ip_address"`
+	CreatedAt  string                          `json:"created_at"`
+
+	// 追加した項目は未設定の場合に省略し、既存のイベントのハッシュを変えない
+	ImpersonatorID  *string `json:"impersonator_id,omitempty"`
+	ImpersonatorUID string  `json:"impersonator_uid,omitempty"`
+}
+
+// ComputeHash 直前のイベントのハッシュとイベント内容からSHA-256のハッシュを計算
+func ComputeHash(prevHash string, event *entities.AuditEvent) (string, error) {
+	payload, err := json.Marshal(hashPayload{
+		PrevHash:   prevHash,
+		SchoolID:   event.SchoolID,
+		ActorID:    event.ActorID,
+		ActorUID:   event.ActorUID,
+		ActorRole:  event.ActorRole,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Changes:    normalizeChanges(event.Changes),
+		RequestID:  event.RequestID,
+		IPAddress:  event.IPAddress,
+		CreatedAt:  event.CreatedAt.UTC().Format(time.RFC3339Nano),
+
+		ImpersonatorID:  event.ImpersonatorID,
+		ImpersonatorUID: event.ImpersonatorUID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit event: %w", err)
+	}
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// normalizeChanges DBから読み戻した値と同じ表現になるようJSONを経由して正規化
+func normalizeChanges(changes map[string]entities.AuditChange) map[string]entities.AuditChange {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return changes
+	}
+	normalized := map[string]entities.AuditChange{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return changes
+	}
+	return normalized
+}
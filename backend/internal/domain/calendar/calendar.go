@@ -0,0 +1,84 @@
+package calendar
+
+import (
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+)
+
+const (
+	// MaxTerms 1年度の学期の数の上限（授業・成績・ポイントのsemester列は1〜3）
+	MaxTerms = 3
+	// TermDateLayout 学期の開始日・終了日の形式
+	TermDateLayout = "01-02"
+
+	defaultYearStartMonth = 4
+	dateLayout            = "2006-01-02"
+)
+
+// DayOfAcademicYear "MM-DD"を年度の開始月から数えた順序に変換（比較用）
+func DayOfAcademicYear(value string, yearStartMonth int) (int, error) {
+	date, err := time.Parse(TermDateLayout, value)
+	if err != nil {
+		return 0, err
+	}
+	return monthOffset(int(date.Month()), yearStartMonth)*31 + date.Day(), nil
+}
+
+// Current 指定日時点の学校の年度と学期（学校のタイムゾーンで判定する）
+// 学期が設定されていない場合は年度を4か月ずつ3学期に分ける
+func Current(school *entities.School, now time.Time) entities.AcademicPeriod {
+	startMonth := school.AcademicYearStart
+	if startMonth < 1 || startMonth > 12 {
+		startMonth = defaultYearStartMonth
+	}
+
+	location := time.UTC
+	var terms []entities.AcademicTerm
+	if school.Settings != nil {
+		if loaded, err := time.LoadLocation(school.Settings.Timezone); err == nil {
+			location = loaded
+		}
+		terms = school.Settings.Calendar.Terms
+	}
+
+	local := now.In(location)
+	year := local.Year()
+	if int(local.Month()) < startMonth {
+		year--
+	}
+	yearStart := time.Date(year, time.Month(startMonth), 1, 0, 0, 0, 0, location)
+
+	period := entities.AcademicPeriod{
+		AcademicYear: year,
+		YearStartsOn: yearStart.Format(dateLayout),
+		YearEndsOn:   yearStart.AddDate(1, 0, -1).Format(dateLayout),
+	}
+
+	if len(terms) == 0 {
+		period.Semester = monthOffset(int(local.Month()), startMonth)/4 + 1
+		return period
+	}
+
+	// 最後に始まった学期を現在の学期とする（最初の学期の前は1学期）
+	today := monthOffset(int(local.Month()), startMonth)*31 + local.Day()
+	period.Semester = 1
+	for i, term := range terms {
+		start, err := DayOfAcademicYear(term.Start, startMonth)
+		if err != nil || today < start {
+			break
+		}
+		period.Semester = i + 1
+		if end, err := DayOfAcademicYear(term.End, startMonth); err == nil && today <= end {
+			period.TermName = term.Name
+		} else {
+			period.TermName = ""
+		}
+	}
+	return period
+}
+
+// monthOffset 年度の開始月から数えた月の位置（0〜11）
+func monthOffset(month, yearStartMonth int) int {
+	return (month - yearStartMonth + 12) % 12
+}
@@ -6,27 +6,35 @@ type UserManagement struct {
 	ID           string     `json:"id" db:"id"`
 	FirebaseUID  string     `json:"firebase_uid" db:"firebase_uid"` // Firebase UID
 	Name         string     `json:"name" db:"name"`
+	Furigana     *string    `json:"furigana,omitempty" db:"furigana"`
+	AvatarURL    *string    `json:"avatar_url,omitempty" db:"avatar_url"`
+	StudentNumber *string   `json:"student_number,omitempty" db:"student_number"`
 	Email        string     `json:"email" db:"email"`
 	Role         string     `json:"role" db:"role"`
 	SchoolID     *string    `json:"school_id" db:"school_id"`
 	SchoolName   *string    `json:"school_name" db:"school_name"`
+	Grade        *int       `json:"grade,omitempty" db:"grade"`
+	ClassID      *string    `json:"class_id,omitempty" db:"class_id"`
+	ClassName    *string    `json:"class_name,omitempty" db:"class_name"`
 	IsActive     bool       `json:"is_active" db:"is_active"`
 	IsApproved   bool       `json:"is_approved" db:"is_approved"`
+	MustChangePassword bool `json:"must_change_password" db:"must_change_password"`
 	LastLoginAt  *time.Time `json:"last_login_at" db:"last_login_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 type UpdateUserRoleRequest struct {
 	UserID   string `json:"user_id" validate:"required"`
-	Role     string `json:"role" validate:"required,oneof=admin school_admin teacher student"`
+	Role     string `json:"role" validate:"required,oneof=admin school_admin teacher student guardian"`
 	SchoolID *string `json:"school_id,omitempty"`
 }
 
 type CreateUserRequest struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
-	Role     string `json:"role" validate:"required,oneof=admin school_admin teacher student"`
+	Role     string `json:"role" validate:"required,oneof=admin school_admin teacher student guardian"`
 	SchoolID string `json:"school_id" validate:"required"`
 	Password string `json:"password" validate:"required,min=8"`
 }
@@ -36,6 +44,65 @@ type UserListResponse struct {
 	TotalCount int              `json:"total_count"`
 	Page       int              `json:"page"`
 	PerPage    int              `json:"per_page"`
+	NextCursor *string          `json:"next_cursor,omitempty"` // 次のページを取得するカーソル（最後のページではnull）
+}
+
+// ユーザー一覧の並び替え項目
+const (
+	UserSortName        = "name"
+	UserSortFurigana    = "furigana"
+	UserSortEmail       = "email"
+	UserSortRole        = "role"
+	UserSortIsApproved  = "is_approved"
+	UserSortIsActive    = "is_active"
+	UserSortGrade       = "grade"
+	UserSortClass       = "class"
+	UserSortLastLoginAt = "last_login_at"
+	UserSortCreatedAt   = "created_at"
+)
+
+// UserSortColumns ユーザー一覧で並び替えに使える項目（リポジトリは各項目の並び替えの式を定義する）
+var UserSortColumns = []string{
+	UserSortName, UserSortFurigana, UserSortEmail, UserSortRole,
+	UserSortIsApproved, UserSortIsActive, UserSortGrade, UserSortClass,
+	UserSortLastLoginAt, UserSortCreatedAt,
+}
+
+// IsUserSortColumn ユーザー一覧で並び替えに使える項目かを判定
+func IsUserSortColumn(column string) bool {
+	for _, c := range UserSortColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// UserListFilter 管理者用ユーザー一覧の検索・絞り込み・並び替え条件
+type UserListFilter struct {
+	SchoolID      *string
+	Query         *string // 氏名・ふりがな・メールアドレスの部分一致
+	Role          *string
+	IsApproved    *bool
+	IsActive      *bool
+	Grade         *int
+	ClassID       *string
+	LastLoginFrom *time.Time
+	LastLoginTo   *time.Time
+	Deleted       bool // trueの場合は削除済み（復元・消去の対象）のユーザーのみ
+	SortBy        string
+	Descending    bool
+	Page          int
+	PerPage       int
+	After         *UserListCursor // 指定時はPageを使わず、この位置の次の行から取得する
+}
+
+// UserListCursor キーセットページネーションの位置（直前のページの最後の行の並び替えキーとID）
+type UserListCursor struct {
+	SortBy     string `json:"s"`
+	Descending bool   `json:"d"`
+	SortKey    string `json:"k"`
+	ID         string `json:"id"`
 }
 
 type SchoolOption struct {
@@ -52,9 +119,57 @@ type UserInvitation struct {
 	Role      string    `json:"role" db:"role"`
 	SchoolID  string    `json:"school_id" db:"school_id"`
 	Message   string    `json:"message" db:"message"`
-	Status    string    `json:"status" db:"status"` // pending, accepted, expired
-	Token     string    `json:"token" db:"token"`
+	Status    string    `json:"status" db:"status"` // pending, accepted, expired, revoked
+	Token     string    `json:"-" db:"token"` // 招待メールでのみ本人に送る
 	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-}
\ No newline at end of file
+}
+type InvitationListResponse struct {
+	Invitations []UserInvitation `json:"invitations"`
+	TotalCount  int              `json:"total_count"`
+	Page        int              `json:"page"`
+	PerPage     int              `json:"per_page"`
+}
+
+// ClaimsDrift DBとFirebaseカスタムクレームの差分
+type ClaimsDrift struct {
+	UserID        string `json:"user_id"`
+	FirebaseUID   string `json:"firebase_uid"`
+	Email         string `json:"email"`
+	DBRole        string `json:"db_role"`
+	DBSchoolID    string `json:"db_school_id"`
+	ClaimRole     string `json:"claim_role"`
+	ClaimSchoolID string `json:"claim_school_id"`
+	Fixed         bool   `json:"fixed"`
+	Error         string `json:"error,omitempty"`
+}
+
+// ClaimsReconcileReport カスタムクレーム整合性チェックの結果
+type ClaimsReconcileReport struct {
+	SchoolID string        `json:"school_id"`
+	DryRun   bool          `json:"dry_run"`
+	Checked  int           `json:"checked"`
+	InSync   int           `json:"in_sync"`
+	Drifted  []ClaimsDrift `json:"drifted"`
+	NotFound []string      `json:"not_found"` // Firebaseに存在しないUID
+}
+
+// ErasedUserName 個人情報を消去したユーザーの表示名
+const ErasedUserName = "削除済みユーザー"
+
+// ErasedUIDPrefix 個人情報を消去したユーザーのUIDの接頭辞（Firebaseアカウントは削除済み）
+const ErasedUIDPrefix = "erased_"
+
+// ImpersonationToken サポート用の代理ログイントークン（actクレームで代理ログインであることを示す）
+type ImpersonationToken struct {
+	AccessToken string          `json:"access_token"`
+	TokenType   string          `json:"token_type"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	User        *UserManagement `json:"user"` // 代理ログイン先のユーザー
+}
+
+// ClassTransferRequest 生徒のクラス移動（class_idがnilの場合はクラスから外す）
+type ClassTransferRequest struct {
+	ClassID *string `json:"class_id"`
+}
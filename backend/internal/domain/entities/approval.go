@@ -0,0 +1,48 @@
+package entities
+
+import "time"
+
+// 自己登録ユーザーの承認状況
+const (
+	ApprovalStatusPending  = "pending"
+	ApprovalStatusApproved = "approved"
+	ApprovalStatusRejected = "rejected"
+)
+
+// MaxApprovalBatchSize 一括承認・却下で一度に指定できるユーザー数
+const MaxApprovalBatchSize = 500
+
+// AccountStatus ログイン中のユーザーのアカウント状態（承認待ち画面の表示用）
+type AccountStatus struct {
+	UserID          string     `json:"user_id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	SchoolID        *string    `json:"school_id"`
+	SchoolName      *string    `json:"school_name"`
+	IsActive        bool       `json:"is_active"`
+	IsApproved      bool       `json:"is_approved"`
+	ApprovalStatus  string     `json:"approval_status"`
+	RejectionReason *string    `json:"rejection_reason,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// PendingApprovalListResponse 承認待ちユーザー一覧
+type PendingApprovalListResponse struct {
+	Users      []UserManagement `json:"users"`
+	TotalCount int              `json:"total_count"`
+	Page       int              `json:"page"`
+	PerPage    int              `json:"per_page"`
+}
+
+// ApprovalReviewRequest 一括承認・却下のリクエスト
+type ApprovalReviewRequest struct {
+	UserIDs []string `json:"user_ids"`
+	Reason  string   `json:"reason"` // 却下時は必須（本人への通知に含める）
+}
+
+// ApprovalReviewResult 一括承認・却下の結果
+type ApprovalReviewResult struct {
+	Reviewed []string `json:"reviewed"`
+	Skipped  []string `json:"skipped"` // 承認待ちでない・他校・権限外のユーザー
+}
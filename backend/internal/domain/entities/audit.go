@@ -0,0 +1,58 @@
+package entities
+
+import "time"
+
+// AuditEvent 管理操作の監査ログ（学校ごとにハッシュチェーンで改ざんを検知する）
+type AuditEvent struct {
+	ID         string                 `json:"id"`
+	SchoolID   *string                `json:"school_id,omitempty"` // 学校に属さない操作はnil
+	ActorID    *string                `json:"actor_id,omitempty"`  // usersテーブルのID（解決できない場合はnil）
+	ActorUID   string                 `json:"actor_uid,omitempty"`
+	ActorRole  string                 `json:"actor_role"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type"`
+	TargetID   string                 `json:"target_id"`
+	Changes    map[string]AuditChange `json:"changes,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	IPAddress  string                 `json:"ip_address,omitempty"`
+	PrevHash   string                 `json:"prev_hash"`
+	Hash       string                 `json:"hash"`
+	CreatedAt  time.Time              `json:"created_at"`
+
+	// 代理ログイン中の操作の場合、実際に操作した管理者（ActorIDは代理ログイン先のユーザー）
+	ImpersonatorID  *string `json:"impersonator_id,omitempty"`
+	ImpersonatorUID string  `json:"impersonator_uid,omitempty"`
+}
+
+// AuditChange 項目ごとの変更前後の値
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditEventFilter 監査ログの絞り込み条件
+type AuditEventFilter struct {
+	SchoolID       *string
+	ActorID        *string
+	ImpersonatorID *string
+	Action         *string
+	TargetType     *string
+	TargetID       *string
+	From           *time.Time
+	To             *time.Time
+}
+
+type AuditEventListResponse struct {
+	Events     []AuditEvent `json:"events"`
+	TotalCount int          `json:"total_count"`
+	Page       int          `json:"page"`
+	PerPage    int          `json:"per_page"`
+}
+
+// AuditChainVerification ハッシュチェーンの検証結果
+type AuditChainVerification struct {
+	SchoolID      *string `json:"school_id,omitempty"`
+	CheckedEvents int     `json:"checked_events"`
+	Valid         bool    `json:"valid"`
+	BrokenEventID string  `json:"broken_event_id,omitempty"` // 最初に不整合が見つかったイベント
+}
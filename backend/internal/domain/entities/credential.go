@@ -0,0 +1,20 @@
+package entities
+
+// UserCredentials パスワードログイン用の認証情報
+type UserCredentials struct {
+	UserID             string
+	FirebaseUID        string
+	Name               string
+	Email              string
+	PasswordHash       string // 未設定の場合は空（パスワードログイン不可）
+	MustChangePassword bool
+	IsActive           bool
+	IsApproved         bool
+}
+
+// LoginResult パスワードログインの結果
+type LoginResult struct {
+	User               *UserManagement `json:"user"`
+	MustChangePassword bool            `json:"must_change_password"` // trueの場合はトークンを発行しない
+	Tokens             *SessionTokens  `json:"tokens,omitempty"`
+}
@@ -0,0 +1,29 @@
+package entities
+
+import "time"
+
+// District 教育委員会（複数の学校をまとめて管理する単位）
+type District struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Code        string    `json:"code"`
+	Prefecture  *string   `json:"prefecture,omitempty"`
+	SchoolCount int       `json:"school_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// DistrictStats 教育委員会の所属校をまとめた統計
+type DistrictStats struct {
+	DistrictID string                `json:"district_id"`
+	Totals     map[string]int        `json:"totals"` // ロール別のユーザー数など各校の合計
+	Schools    []DistrictSchoolStats `json:"schools"`
+}
+
+// DistrictSchoolStats 所属校ごとの統計
+type DistrictSchoolStats struct {
+	SchoolID    string         `json:"school_id"`
+	SchoolName  string         `json:"school_name"`
+	Stats       *SchoolStats   `json:"stats"`
+	UsersByRole map[string]int `json:"users_by_role"` // GetUserStatsByRoleの結果
+}
@@ -0,0 +1,48 @@
+package entities
+
+// エクスポート対象のデータ
+const (
+	ExportDatasetUsers   = "users"
+	ExportDatasetClasses = "classes"
+	ExportDatasetSchools = "schools"
+)
+
+// エクスポート形式
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+	ExportFormatJSON = "json"
+)
+
+// ExportColumns データごとに出力できる列（先頭から既定の出力順）
+var ExportColumns = map[string][]string{
+	ExportDatasetUsers: {
+		"id", "name", "furigana", "email", "role", "school_id", "school_name", "class_id", "class_name",
+		"student_number", "grade", "is_active", "is_approved", "last_login_at", "created_at",
+		"guardian_name", "guardian_phone", "guardian_email",
+	},
+	ExportDatasetClasses: {
+		"id", "school_id", "name", "grade", "academic_year", "homeroom_teacher_id", "sub_teacher_id",
+		"max_students", "current_students", "classroom", "is_active", "created_at",
+	},
+	ExportDatasetSchools: {
+		"id", "name", "code", "email_domain", "address", "phone_number", "principal_name", "vice_principal_name",
+		"student_capacity", "academic_year_start", "is_active", "created_at",
+	},
+}
+
+// EncryptedExportColumns 暗号化して保存している列（出力時に復号し、閲覧権限が必要）
+// 列を明示的に指定しない場合は出力しない
+var EncryptedExportColumns = map[string]bool{
+	"guardian_name":  true,
+	"guardian_phone": true,
+	"guardian_email": true,
+}
+
+// ExportRequest エクスポート条件
+type ExportRequest struct {
+	Dataset  string
+	Format   string
+	Columns  []string // 空の場合は暗号化列を除く全列
+	SchoolID *string  // 全体スコープの場合のみ任意。学校スコープでは自校に固定
+}
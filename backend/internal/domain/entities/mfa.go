@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+// MFASettings 二要素認証（TOTP）の登録情報
+type MFASettings struct {
+	UserID          string
+	SecretEncrypted string     // ENCRYPTION_KEYで暗号化したTOTPシークレット
+	ConfirmedAt     *time.Time // 登録確認前はnil
+	LastUsedStep    int64      // 最後に使用したTOTPの時刻ステップ
+}
+
+// MFAStatus 二要素認証の登録状況
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"` // ロール上、登録が必須かどうか
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// MFAEnrollment 認証アプリに登録するための情報
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"` // otpauth:// 形式（QRコード用）
+}
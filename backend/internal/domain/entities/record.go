@@ -0,0 +1,45 @@
+package entities
+
+import "time"
+
+// Grade 成績
+type Grade struct {
+	ID           int64     `json:"id" db:"id"`
+	StudentID    int64     `json:"student_id" db:"student_id"`
+	StudentName  string    `json:"student_name" db:"student_name"`
+	CourseID     int64     `json:"course_id" db:"course_id"`
+	CourseName   string    `json:"course_name" db:"course_name"`
+	AssignmentID *int64    `json:"assignment_id" db:"assignment_id"`
+	GradeType    string    `json:"grade_type" db:"grade_type"`
+	Points       int       `json:"points" db:"points"`
+	MaxPoints    int       `json:"max_points" db:"max_points"`
+	Percentage   float64   `json:"percentage" db:"percentage"`
+	Semester     int       `json:"semester" db:"semester"`
+	AcademicYear int       `json:"academic_year" db:"academic_year"`
+	GradedAt     time.Time `json:"graded_at" db:"graded_at"`
+}
+
+// AttendanceRecord 出席記録
+type AttendanceRecord struct {
+	ID             int64     `json:"id" db:"id"`
+	StudentID      int64     `json:"student_id" db:"student_id"`
+	StudentName    string    `json:"student_name" db:"student_name"`
+	CourseID       int64     `json:"course_id" db:"course_id"`
+	CourseName     string    `json:"course_name" db:"course_name"`
+	AttendanceDate time.Time `json:"attendance_date" db:"attendance_date"`
+	Period         int       `json:"period" db:"period"`
+	Status         string    `json:"status" db:"status"` // present / absent / late / sick / official
+	Reason         *string   `json:"reason" db:"reason"`
+	RecordedAt     time.Time `json:"recorded_at" db:"recorded_at"`
+}
+
+// GuardianStudent 保護者と生徒の紐付け
+type GuardianStudent struct {
+	GuardianID   string    `json:"guardian_id" db:"guardian_id"`
+	StudentID    string    `json:"student_id" db:"student_id"`
+	StudentName  string    `json:"student_name" db:"student_name"`
+	SchoolID     string    `json:"school_id" db:"school_id"`
+	ClassID      *string   `json:"class_id" db:"class_id"`
+	Relationship *string   `json:"relationship" db:"relationship"` // 続柄
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,94 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SchoolSettingsVersion 現在の学校設定ドキュメントのバージョン
+// バージョンのない設定（theme/backgroundのみの初期形式）は読み込み時にこの形式へ変換する
+const SchoolSettingsVersion = 1
+
+// SchoolSettings 学校ごとの設定（schools.settingsにJSONBで保存）
+type SchoolSettings struct {
+	Version  int              `json:"version"`
+	Theme    string           `json:"theme"`    // テーマ名（フロントエンドのtheme-<name>クラス）
+	Locale   string           `json:"locale"`   // 表示言語（ja / en）
+	Timezone string           `json:"timezone"` // IANAタイムゾーン名
+	Features SchoolFeatures   `json:"features"`
+	Calendar AcademicCalendar `json:"calendar"`
+}
+
+// SchoolFeatures 学校ごとに有効にする機能
+type SchoolFeatures struct {
+	Chat           bool `json:"chat"`
+	Whiteboard     bool `json:"whiteboard"`
+	LearningNotes  bool `json:"learning_notes"`
+	Gamification   bool `json:"gamification"`
+	GuardianAccess bool `json:"guardian_access"`
+}
+
+// AcademicCalendar 学期の区切り（年度の開始月はschools.academic_year_startで管理）
+type AcademicCalendar struct {
+	Terms []AcademicTerm `json:"terms"`
+}
+
+// AcademicTerm 学期（日付は年をまたいでも扱えるよう"MM-DD"形式）
+type AcademicTerm struct {
+	Name  string `json:"name"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// SchoolUpdate 学校情報の部分更新（nilの項目は変更しない、任意項目は空文字で削除）
+type SchoolUpdate struct {
+	SchoolName        *string         `json:"school_name,omitempty"`
+	Address           *string         `json:"address,omitempty"`
+	Phone             *string         `json:"phone,omitempty"`
+	EmailDomain       *string         `json:"email_domain,omitempty"`
+	ThemeColor        *string         `json:"theme_color,omitempty"`
+	BackgroundColor   *string         `json:"background_color,omitempty"`
+	LogoURL           *string         `json:"logo_url,omitempty"`
+	PrincipalName     *string         `json:"principal_name,omitempty"`
+	VicePrincipalName *string         `json:"vice_principal_name,omitempty"`
+	StudentCapacity   *int            `json:"student_capacity,omitempty"`
+	AcademicYearStart *int            `json:"academic_year_start,omitempty"`
+	Settings          json.RawMessage `json:"settings,omitempty"` // 指定したキーのみ現在の設定に上書きする
+}
+
+// AcademicPeriod 学校の現在の年度と学期
+type AcademicPeriod struct {
+	AcademicYear int    `json:"academic_year"`
+	Semester     int    `json:"semester"`
+	TermName     string `json:"term_name,omitempty"` // 設定された学期の期間外（長期休業など）は空
+	YearStartsOn string `json:"year_starts_on"`      // YYYY-MM-DD
+	YearEndsOn   string `json:"year_ends_on"`
+}
+
+// AcademicYearRollover 年度更新の結果（DryRunの場合は実行した場合の件数）
+type AcademicYearRollover struct {
+	SchoolID          string `json:"school_id"`
+	FromYear          int    `json:"from_year"`
+	ToYear            int    `json:"to_year"`
+	DryRun            bool   `json:"dry_run"`
+	PromotedStudents  int    `json:"promoted_students"`
+	GraduatedStudents int    `json:"graduated_students"`
+	UngradedStudents  int    `json:"ungraded_students"` // 学年が未設定のため進級・卒業の対象外
+	ClonedClasses     int    `json:"cloned_classes"`
+	ClonedCourses     int    `json:"cloned_courses"`
+}
+
+// SchoolCodeAlias 変更前の学校コード（引き続きその学校として解決する）
+type SchoolCodeAlias struct {
+	Code       string    `json:"code"`
+	ReplacedBy *string   `json:"replaced_by,omitempty"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+// SchoolCodeCheck 学校コードの検証結果（入力中の確認用）
+type SchoolCodeCheck struct {
+	Code      string `json:"code"` // 正規化したコード
+	Valid     bool   `json:"valid"`
+	Available bool   `json:"available"`        // 他校（変更前のコードを含む）が使用していない
+	Reason    string `json:"reason,omitempty"` // 使用できない理由
+}
@@ -0,0 +1,83 @@
+package entities
+
+import "time"
+
+// SchoolStatsPeriod 学校統計の集計期間（学校のタイムゾーンの日付で開始日・終了日を含む）
+type SchoolStatsPeriod struct {
+	From  string    `json:"from"` // YYYY-MM-DD
+	To    string    `json:"to"`   // YYYY-MM-DD
+	Start time.Time `json:"-"`    // 開始日の0時
+	End   time.Time `json:"-"`    // 終了日の翌日0時（この時刻を含まない）
+}
+
+// SchoolStats 学校ごとの統計レポート
+type SchoolStats struct {
+	SchoolID    string                `json:"school_id"`
+	Period      SchoolStatsPeriod     `json:"period"`
+	Users       SchoolUserStats       `json:"users"`
+	Logins      SchoolLoginStats      `json:"logins"`
+	Classes     SchoolClassStats      `json:"classes"`
+	Assignments SchoolAssignmentStats `json:"assignments"`
+	Attendance  SchoolAttendanceStats `json:"attendance"`
+	Grades      SchoolGradeStats      `json:"grades"`
+	GeneratedAt time.Time             `json:"generated_at"`
+}
+
+// SchoolUserStats 在籍ユーザー数（削除済みのユーザーは含めない）
+type SchoolUserStats struct {
+	Total           int            `json:"total"`
+	Active          int            `json:"active"`
+	Approved        int            `json:"approved"`
+	PendingApproval int            `json:"pending_approval"`
+	ByRole          map[string]int `json:"by_role"`
+	StudentsByGrade map[string]int `json:"students_by_grade"` // 学年（"1"〜"3"、未設定は"unassigned"）ごとの生徒数
+}
+
+// SchoolLoginStats 最終ログイン日時によるログイン状況（集計期間によらず現在時点）
+type SchoolLoginStats struct {
+	Last7Days     int `json:"last_7_days"`
+	Last30Days    int `json:"last_30_days"`
+	NeverLoggedIn int `json:"never_logged_in"`
+}
+
+// SchoolClassStats 有効なクラスの定員に対する在籍状況
+type SchoolClassStats struct {
+	Total    int              `json:"total"`
+	Capacity int              `json:"capacity"`
+	Enrolled int              `json:"enrolled"`
+	FillRate float64          `json:"fill_rate"` // 在籍人数 / 定員（%）
+	Classes  []ClassFillStats `json:"classes"`
+}
+
+// ClassFillStats クラスごとの在籍状況
+type ClassFillStats struct {
+	ClassID     string  `json:"class_id"`
+	Name        string  `json:"name"`
+	Grade       int     `json:"grade"`
+	Enrolled    int     `json:"enrolled"`
+	MaxStudents int     `json:"max_students"`
+	FillRate    float64 `json:"fill_rate"`
+}
+
+// SchoolAssignmentStats 集計期間内が期限の公開済み課題の提出状況
+type SchoolAssignmentStats struct {
+	Total          int     `json:"total"`
+	Expected       int     `json:"expected"` // 課題ごとのクラスの生徒数の合計
+	Submitted      int     `json:"submitted"`
+	Late           int     `json:"late"`
+	SubmissionRate float64 `json:"submission_rate"` // 提出数 / Expected（%）
+}
+
+// SchoolAttendanceStats 集計期間内の出席記録
+type SchoolAttendanceStats struct {
+	Records        int            `json:"records"`
+	ByStatus       map[string]int `json:"by_status"`
+	AttendanceRate float64        `json:"attendance_rate"` // 出席・遅刻 / 公欠を除く記録数（%）
+}
+
+// SchoolGradeStats 集計期間内に採点された成績の分布
+type SchoolGradeStats struct {
+	Count          int            `json:"count"`
+	AveragePercent float64        `json:"average_percent"`
+	Distribution   map[string]int `json:"distribution"` // 得点率の区間（"0-59"、"60-69"…"90-100"）ごとの件数
+}
@@ -0,0 +1,45 @@
+package entities
+
+import "time"
+
+// セッションの認証方式
+const (
+	SessionAuthToken    = "token"    // 外部IdP（Firebase / OIDC）のトークン
+	SessionAuthPassword = "password" // ローカルのパスワードログイン
+	SessionAuthDev      = "dev"      // 開発用トークン（/dev/token）
+
+	SessionAuthImpersonation = "impersonation" // 管理者による代理ログイン
+)
+
+// Session 端末ごとのログインセッション（Redisに保存）
+type Session struct {
+	ID               string     `json:"id"`
+	UserID           string     `json:"user_id"`
+	FirebaseUID      string     `json:"firebase_uid"`
+	SchoolID         string     `json:"school_id,omitempty"`
+	DeviceName       string     `json:"device_name"`
+	IPAddress        string     `json:"ip_address"`
+	UserAgent        string     `json:"user_agent,omitempty"`
+	AuthMethod       string     `json:"auth_method"`
+	RefreshTokenHash string     `json:"-"`
+	MFAVerifiedAt    *time.Time `json:"mfa_verified_at,omitempty"` // 二要素認証で本人確認した日時
+	CreatedAt        time.Time  `json:"created_at"`
+	LastSeenAt       time.Time  `json:"last_seen_at"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	Current          bool       `json:"current"` // リクエスト元のセッションかどうか
+}
+
+// SessionTokens セッション作成・更新時に返すトークン
+type SessionTokens struct {
+	Session              *Session   `json:"session"`
+	RefreshToken         string     `json:"refresh_token"`
+	AccessToken          string     `json:"access_token,omitempty"` // AUTH_PROVIDER=local またはパスワードログインの場合のみ
+	AccessTokenExpiresAt *time.Time `json:"access_token_expires_at,omitempty"`
+}
+
+// SessionClient セッションを作成した端末の情報
+type SessionClient struct {
+	DeviceName string
+	IPAddress  string
+	UserAgent  string
+}
@@ -9,6 +9,15 @@ type User struct {
 	Email          string    `json:"email" db:"email"`
 	Role           string    `json:"role" db:"role"`
 	SchoolID       string    `json:"school_id" db:"school_id"`           // VARCHAR school ID
+	PasswordHash   string    `json:"-" db:"password_hash"`               // ローカル認証用（bcrypt）
+	MustChangePassword bool  `json:"must_change_password" db:"must_change_password"`
+	IsApproved     bool      `json:"is_approved" db:"is_approved"`       // 未承認のユーザーはログイン不可
+	// プロフィール（更新時はnilの項目を変更しない。空文字は値の削除）
+	Furigana       *string   `json:"furigana,omitempty" db:"furigana"`
+	AvatarURL      *string   `json:"avatar_url,omitempty" db:"avatar_url"`
+	StudentNumber  *string   `json:"student_number,omitempty" db:"student_number"` // 学校内で一意
+	Grade          *int      `json:"grade,omitempty" db:"grade"`                   // 1〜3
+	ClassID        *string   `json:"class_id,omitempty" db:"class_id"`             // 変更はクラス移動で行う
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -22,6 +31,19 @@ type School struct {
 	Address     *string   `json:"address" db:"address"`           // Address
 	Phone       *string   `json:"phone" db:"phone"`               // Phone number
 	Email       *string   `json:"email" db:"email"`               // Email
+
+	EmailDomain       *string         `json:"email_domain" db:"email_domain"`               // 自動で所属させるメールドメイン
+	ThemeColor        string          `json:"theme_color" db:"theme_color"`
+	BackgroundColor   string          `json:"background_color" db:"background_color"`
+	LogoURL           *string         `json:"logo_url" db:"logo_url"`
+	PrincipalName     *string         `json:"principal_name" db:"principal_name"`
+	VicePrincipalName *string         `json:"vice_principal_name" db:"vice_principal_name"`
+	StudentCapacity   int             `json:"student_capacity" db:"student_capacity"`
+	AcademicYearStart int             `json:"academic_year_start" db:"academic_year_start"` // 年度の開始月（1〜12）
+	Settings          *SchoolSettings `json:"settings" db:"settings"`
+	IsActive          bool            `json:"is_active" db:"is_active"`
+	DistrictID        *string         `json:"district_id" db:"district_id"` // 所属する教育委員会
+
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,73 @@
+package entities
+
+// ImportedUIDPrefix 名簿から取り込み、まだ招待を受諾していないユーザーの仮UID
+// 招待の受諾時に実際のUIDへ置き換える
+const ImportedUIDPrefix = "import_"
+
+// RosterRow 名簿ファイルの1行（値は未検証の文字列のまま保持する）
+type RosterRow struct {
+	Line          int    `json:"line"` // ヘッダーを1行目とした行番号
+	Name          string `json:"name"`
+	Furigana      string `json:"furigana"`
+	Email         string `json:"email"`
+	StudentNumber string `json:"student_number"`
+	Grade         string `json:"grade"`
+	ClassName     string `json:"class"`
+}
+
+// StudentImport 検証済みの取り込み内容
+type StudentImport struct {
+	Name          string
+	Furigana      string
+	Email         string
+	StudentNumber string
+	Grade         *int
+	ClassID       *int64
+	FirebaseUID   string // 新規作成時のみ使用
+}
+
+// RosterUser 取り込み時の重複チェックに使う既存ユーザー
+// メールアドレスで検索した他校のユーザーはIDとメールアドレスのみ
+type RosterUser struct {
+	ID            string
+	Email         string
+	Role          string
+	SchoolID      string
+	StudentNumber string
+	ClassID       *int64
+}
+
+// ClassCapacity クラスの定員と現在の在籍人数（MaxStudentsが0の場合は定員なし）
+type ClassCapacity struct {
+	MaxStudents int
+	Enrolled    int
+}
+
+// UserImportRowError 行ごとの検証エラー
+type UserImportRowError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// UserImportRowResult 行ごとの取り込み結果（create または update）
+type UserImportRowResult struct {
+	Line          int    `json:"line"`
+	StudentNumber string `json:"student_number"`
+	Email         string `json:"email"`
+	Action        string `json:"action"`
+	UserID        string `json:"user_id,omitempty"` // ドライランでは空
+	Invited       bool   `json:"invited"`
+}
+
+// UserImportResult 名簿取り込みの結果
+// 検証エラーが1件でもある場合は何も登録しない
+type UserImportResult struct {
+	DryRun    bool                  `json:"dry_run"`
+	TotalRows int                   `json:"total_rows"`
+	Created   int                   `json:"created"`
+	Updated   int                   `json:"updated"`
+	Invited   int                   `json:"invited"`
+	Rows      []UserImportRowResult `json:"rows"`
+	Errors    []UserImportRowError  `json:"errors"`
+}
@@ -0,0 +1,347 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ロール
+const (
+	RoleAdmin         = "admin"
+	RoleDistrictAdmin = "district_admin" // 教育委員会の管理者（管轄する複数校を管理）
+	RoleSchoolAdmin   = "school_admin"
+	RoleTeacher       = "teacher"
+	RoleStudent       = "student"
+	RoleGuardian      = "guardian"
+)
+
+// Permission 「リソース:操作」形式の権限名
+type Permission string
+
+const (
+	UsersRead         Permission = "users:read"
+	UsersInvite       Permission = "users:invite"
+	UsersUpdate       Permission = "users:update"
+	UsersUpdateRole   Permission = "users:update_role"
+	UsersUpdateStatus Permission = "users:update_status"
+	UsersStats        Permission = "users:stats"
+	UsersResetMFA     Permission = "users:reset_mfa"
+	UsersDelete       Permission = "users:delete"      // 論理削除と猶予期間内の復元
+	UsersErase        Permission = "users:erase"       // 個人情報の即時消去（取り消し不可）
+	UsersImpersonate  Permission = "users:impersonate" // サポート用の代理ログイン
+
+	InvitationsManage Permission = "invitations:manage"
+
+	SchoolsRead   Permission = "schools:read"
+	SchoolsCreate Permission = "schools:create"
+	SchoolsUpdate Permission = "schools:update"
+	SchoolsDelete Permission = "schools:delete"
+
+	AcademicYearRollover Permission = "academic_year:rollover" // 進級・卒業と前年度の読み取り専用化
+
+	DistrictsRead   Permission = "districts:read"   // 教育委員会と所属校の一覧・統計
+	DistrictsManage Permission = "districts:manage" // 教育委員会の作成と学校・管理者の割り当て
+
+	GradesRead  Permission = "grades:read"
+	GradesWrite Permission = "grades:write"
+
+	AttendanceRead  Permission = "attendance:read"
+	AttendanceWrite Permission = "attendance:write"
+
+	NotificationsRead Permission = "notifications:read"
+
+	DashboardRead Permission = "dashboard:read"
+
+	AuditRead Permission = "audit:read"
+
+	DataExport           Permission = "data:export"
+	GuardianContactsRead Permission = "guardian_contacts:read"
+)
+
+// Scope 権限の適用範囲（値が大きいほど広い）
+type Scope int
+
+const (
+	ScopeNone     Scope = iota // 権限なし
+	ScopeOwn                   // 自分自身のレコードのみ
+	ScopeLinked                // 自分と紐付けられた生徒（保護者の子）のみ
+	ScopeClass                 // 担当クラスのみ
+	ScopeSchool                // 所属校のみ
+	ScopeDistrict              // 管轄する教育委員会の学校のみ
+	ScopeGlobal                // 全体
+)
+
+// ErrForbidden 権限不足を表すエラー
+var ErrForbidden = errors.New("insufficient permissions")
+
+// rolePermissions ロールごとの権限とスコープの対応表
+var rolePermissions = map[string]map[Permission]Scope{
+	RoleAdmin: {
+		UsersRead:            ScopeGlobal,
+		UsersInvite:          ScopeGlobal,
+		UsersUpdate:          ScopeGlobal,
+		UsersUpdateRole:      ScopeGlobal,
+		UsersUpdateStatus:    ScopeGlobal,
+		UsersStats:           ScopeGlobal,
+		UsersResetMFA:        ScopeGlobal,
+		UsersDelete:          ScopeGlobal,
+		UsersErase:           ScopeGlobal,
+		UsersImpersonate:     ScopeGlobal,
+		InvitationsManage:    ScopeGlobal,
+		SchoolsRead:          ScopeGlobal,
+		SchoolsCreate:        ScopeGlobal,
+		SchoolsUpdate:        ScopeGlobal,
+		SchoolsDelete:        ScopeGlobal,
+		AcademicYearRollover: ScopeGlobal,
+		DistrictsRead:        ScopeGlobal,
+		DistrictsManage:      ScopeGlobal,
+		GradesRead:           ScopeGlobal,
+		GradesWrite:          ScopeGlobal,
+		AttendanceRead:       ScopeGlobal,
+		AttendanceWrite:      ScopeGlobal,
+		DashboardRead:        ScopeOwn,
+		AuditRead:            ScopeGlobal,
+		DataExport:           ScopeGlobal,
+		GuardianContactsRead: ScopeGlobal,
+	},
+	// 管轄校の管理と集計（削除・消去、成績・出席の入力、保護者の連絡先は各校に任せる）
+	RoleDistrictAdmin: {
+		UsersRead:            ScopeDistrict,
+		UsersInvite:          ScopeDistrict,
+		UsersUpdate:          ScopeDistrict,
+		UsersUpdateRole:      ScopeDistrict,
+		UsersUpdateStatus:    ScopeDistrict,
+		UsersStats:           ScopeDistrict,
+		UsersResetMFA:        ScopeDistrict,
+		InvitationsManage:    ScopeDistrict,
+		SchoolsRead:          ScopeDistrict,
+		SchoolsUpdate:        ScopeDistrict,
+		AcademicYearRollover: ScopeDistrict,
+		DistrictsRead:        ScopeDistrict,
+		GradesRead:           ScopeDistrict,
+		AttendanceRead:       ScopeDistrict,
+		DashboardRead:        ScopeOwn,
+		AuditRead:            ScopeDistrict,
+		DataExport:           ScopeDistrict,
+	},
+	RoleSchoolAdmin: {
+		UsersRead:            ScopeSchool,
+		UsersInvite:          ScopeSchool,
+		UsersUpdate:          ScopeSchool,
+		UsersUpdateRole:      ScopeSchool,
+		UsersUpdateStatus:    ScopeSchool,
+		UsersStats:           ScopeSchool,
+		UsersResetMFA:        ScopeSchool,
+		UsersDelete:          ScopeSchool,
+		InvitationsManage:    ScopeSchool,
+		SchoolsRead:          ScopeSchool,
+		SchoolsUpdate:        ScopeSchool,
+		AcademicYearRollover: ScopeSchool,
+		GradesRead:           ScopeSchool,
+		GradesWrite:          ScopeSchool,
+		AttendanceRead:       ScopeSchool,
+		AttendanceWrite:      ScopeSchool,
+		DashboardRead:        ScopeOwn,
+		AuditRead:            ScopeSchool,
+		DataExport:           ScopeSchool,
+		GuardianContactsRead: ScopeSchool,
+	},
+	RoleTeacher: {
+		SchoolsRead:     ScopeSchool,
+		GradesRead:      ScopeClass,
+		GradesWrite:     ScopeClass,
+		AttendanceRead:  ScopeClass,
+		AttendanceWrite: ScopeClass,
+		DashboardRead:   ScopeOwn,
+	},
+	RoleStudent: {
+		SchoolsRead:       ScopeSchool,
+		GradesRead:        ScopeOwn,
+		AttendanceRead:    ScopeOwn,
+		NotificationsRead: ScopeOwn,
+		DashboardRead:     ScopeOwn,
+	},
+	RoleGuardian: {
+		SchoolsRead:       ScopeSchool,
+		GradesRead:        ScopeLinked,
+		AttendanceRead:    ScopeLinked,
+		NotificationsRead: ScopeLinked,
+		DashboardRead:     ScopeOwn,
+	},
+}
+
+// assignableRoles ロールごとに付与可能なロール
+// 教育委員会管理者は管轄する教育委員会と合わせて割り当てるため、ここには含めない
+var assignableRoles = map[string]map[string]bool{
+	RoleAdmin: {
+		RoleAdmin:       true,
+		RoleSchoolAdmin: true,
+		RoleTeacher:     true,
+		RoleStudent:     true,
+		RoleGuardian:    true,
+	},
+	RoleDistrictAdmin: {
+		RoleSchoolAdmin: true,
+		RoleTeacher:     true,
+		RoleStudent:     true,
+		RoleGuardian:    true,
+	},
+	RoleSchoolAdmin: {
+		RoleTeacher:  true,
+		RoleStudent:  true,
+		RoleGuardian: true,
+	},
+}
+
+// Subject 操作を行うユーザー
+type Subject struct {
+	UserID     string
+	Role       string
+	SchoolID   string
+	ClassIDs   []string // 担当・所属クラス
+	StudentIDs []string // 保護者の場合は紐付けられた生徒
+
+	DistrictID        string   // 教育委員会管理者の場合は管轄する教育委員会
+	DistrictSchoolIDs []string // 教育委員会管理者の場合は管轄する学校
+}
+
+// Resource 操作対象のリソースの所属情報（不明な項目は空）
+type Resource struct {
+	OwnerID    string
+	SchoolID   string
+	ClassID    string
+	DistrictID string
+}
+
+// ScopeOf ロールが持つ権限のスコープを取得
+func ScopeOf(role string, perm Permission) Scope {
+	return rolePermissions[role][perm]
+}
+
+// Allows ロールが権限をいずれかのスコープで持つかを判定
+func Allows(role string, perm Permission) bool {
+	return ScopeOf(role, perm) != ScopeNone
+}
+
+// Can 操作者がリソースに対して権限を持つかをスコープを含めて判定
+func Can(subject Subject, perm Permission, resource Resource) bool {
+	switch ScopeOf(subject.Role, perm) {
+	case ScopeGlobal:
+		return true
+	case ScopeDistrict:
+		return inDistrict(subject, resource)
+	case ScopeSchool:
+		return subject.SchoolID != "" && resource.SchoolID == subject.SchoolID
+	case ScopeClass:
+		return inClass(subject, resource) || isOwner(subject, resource)
+	case ScopeLinked:
+		return isLinked(subject, resource) || isOwner(subject, resource)
+	case ScopeOwn:
+		return isOwner(subject, resource)
+	default:
+		return false
+	}
+}
+
+// Authorize Canの結果をエラーとして返す
+func Authorize(subject Subject, perm Permission, resource Resource) error {
+	if !Can(subject, perm, resource) {
+		return fmt.Errorf("%w: %s", ErrForbidden, perm)
+	}
+	return nil
+}
+
+// Require ロールが権限を持たない場合にエラーを返す
+func Require(role string, perm Permission) error {
+	if !Allows(role, perm) {
+		return fmt.Errorf("%w: %s", ErrForbidden, perm)
+	}
+	return nil
+}
+
+// CanAssignRole 操作者が対象ロールを付与できるかを判定
+func CanAssignRole(role, targetRole string) bool {
+	return assignableRoles[role][targetRole]
+}
+
+// IsAdminRole 学校・教育委員会・システム全体のいずれかを管理するロールかを判定
+func IsAdminRole(role string) bool {
+	return role == RoleAdmin || role == RoleDistrictAdmin || role == RoleSchoolAdmin
+}
+
+// Roles 定義済みのロール一覧
+func Roles() []string {
+	return []string{RoleAdmin, RoleDistrictAdmin, RoleSchoolAdmin, RoleTeacher, RoleStudent, RoleGuardian}
+}
+
+// DistrictScope 教育委員会管理者が管轄する教育委員会と学校
+type DistrictScope struct {
+	DistrictID string
+	SchoolIDs  []string
+}
+
+type districtContextKey struct{}
+
+// NewDistrictContext 管轄する教育委員会をコンテキストに設定
+func NewDistrictContext(ctx context.Context, scope *DistrictScope) context.Context {
+	return context.WithValue(ctx, districtContextKey{}, scope)
+}
+
+// DistrictScopeFromContext コンテキストから管轄する教育委員会を取得（未設定の場合はnil）
+func DistrictScopeFromContext(ctx context.Context) *DistrictScope {
+	scope, _ := ctx.Value(districtContextKey{}).(*DistrictScope)
+	return scope
+}
+
+// WithDistrict コンテキストの管轄する教育委員会を操作者に反映
+func WithDistrict(ctx context.Context, subject Subject) Subject {
+	if scope := DistrictScopeFromContext(ctx); scope != nil {
+		subject.DistrictID = scope.DistrictID
+		subject.DistrictSchoolIDs = scope.SchoolIDs
+	}
+	return subject
+}
+
+func inDistrict(subject Subject, resource Resource) bool {
+	if resource.DistrictID != "" {
+		return subject.DistrictID != "" && resource.DistrictID == subject.DistrictID
+	}
+	if resource.SchoolID == "" {
+		return false
+	}
+	for _, schoolID := range subject.DistrictSchoolIDs {
+		if schoolID == resource.SchoolID {
+			return true
+		}
+	}
+	return false
+}
+
+func inClass(subject Subject, resource Resource) bool {
+	if resource.ClassID == "" {
+		return false
+	}
+	for _, classID := range subject.ClassIDs {
+		if classID == resource.ClassID {
+			return true
+		}
+	}
+	return false
+}
+
+func isLinked(subject Subject, resource Resource) bool {
+	if resource.OwnerID == "" {
+		return false
+	}
+	for _, studentID := range subject.StudentIDs {
+		if studentID == resource.OwnerID {
+			return true
+		}
+	}
+	return false
+}
+
+func isOwner(subject Subject, resource Resource) bool {
+	return subject.UserID != "" && resource.OwnerID == subject.UserID
+}
@@ -0,0 +1,355 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+// allPermissions 定義済みの権限（追加した場合はexpectedScopesにも追加する）
+var allPermissions = []Permission{
+	UsersRead, UsersInvite, UsersUpdate, UsersUpdateRole, UsersUpdateStatus, UsersStats,
+	UsersResetMFA, UsersDelete, UsersErase, UsersImpersonate,
+	InvitationsManage,
+	SchoolsRead, SchoolsCreate, SchoolsUpdate, SchoolsDelete,
+	AcademicYearRollover,
+	DistrictsRead, DistrictsManage,
+	GradesRead, GradesWrite,
+	AttendanceRead, AttendanceWrite,
+	NotificationsRead,
+	DashboardRead,
+	AuditRead,
+	DataExport, GuardianContactsRead,
+}
+
+// expectedScopes ロール×権限ごとに期待するスコープ（記載のない組み合わせはScopeNone）
+// rolePermissionsを変更した場合は、意図した変更かをこの表で確認する
+var expectedScopes = map[string]map[Permission]Scope{
+	RoleAdmin: {
+		UsersRead:            ScopeGlobal,
+		UsersInvite:          ScopeGlobal,
+		UsersUpdate:          ScopeGlobal,
+		UsersUpdateRole:      ScopeGlobal,
+		UsersUpdateStatus:    ScopeGlobal,
+		UsersStats:           ScopeGlobal,
+		UsersResetMFA:        ScopeGlobal,
+		UsersDelete:          ScopeGlobal,
+		UsersErase:           ScopeGlobal,
+		UsersImpersonate:     ScopeGlobal,
+		InvitationsManage:    ScopeGlobal,
+		SchoolsRead:          ScopeGlobal,
+		SchoolsCreate:        ScopeGlobal,
+		SchoolsUpdate:        ScopeGlobal,
+		SchoolsDelete:        ScopeGlobal,
+		AcademicYearRollover: ScopeGlobal,
+		DistrictsRead:        ScopeGlobal,
+		DistrictsManage:      ScopeGlobal,
+		GradesRead:           ScopeGlobal,
+		GradesWrite:          ScopeGlobal,
+		AttendanceRead:       ScopeGlobal,
+		AttendanceWrite:      ScopeGlobal,
+		DashboardRead:        ScopeOwn,
+		AuditRead:            ScopeGlobal,
+		DataExport:           ScopeGlobal,
+		GuardianContactsRead: ScopeGlobal,
+	},
+	RoleDistrictAdmin: {
+		UsersRead:            ScopeDistrict,
+		UsersInvite:          ScopeDistrict,
+		UsersUpdate:          ScopeDistrict,
+		UsersUpdateRole:      ScopeDistrict,
+		UsersUpdateStatus:    ScopeDistrict,
+		UsersStats:           ScopeDistrict,
+		UsersResetMFA:        ScopeDistrict,
+		InvitationsManage:    ScopeDistrict,
+		SchoolsRead:          ScopeDistrict,
+		SchoolsUpdate:        ScopeDistrict,
+		AcademicYearRollover: ScopeDistrict,
+		DistrictsRead:        ScopeDistrict,
+		GradesRead:           ScopeDistrict,
+		AttendanceRead:       ScopeDistrict,
+		DashboardRead:        ScopeOwn,
+		AuditRead:            ScopeDistrict,
+		DataExport:           ScopeDistrict,
+	},
+	RoleSchoolAdmin: {
+		UsersRead:            ScopeSchool,
+		UsersInvite:          ScopeSchool,
+		UsersUpdate:          ScopeSchool,
+		UsersUpdateRole:      ScopeSchool,
+		UsersUpdateStatus:    ScopeSchool,
+		UsersStats:           ScopeSchool,
+		UsersResetMFA:        ScopeSchool,
+		UsersDelete:          ScopeSchool,
+		InvitationsManage:    ScopeSchool,
+		SchoolsRead:          ScopeSchool,
+		SchoolsUpdate:        ScopeSchool,
+		AcademicYearRollover: ScopeSchool,
+		GradesRead:           ScopeSchool,
+		GradesWrite:          ScopeSchool,
+		AttendanceRead:       ScopeSchool,
+		AttendanceWrite:      ScopeSchool,
+		DashboardRead:        ScopeOwn,
+		AuditRead:            ScopeSchool,
+		DataExport:           ScopeSchool,
+		GuardianContactsRead: ScopeSchool,
+	},
+	RoleTeacher: {
+		SchoolsRead:     ScopeSchool,
+		GradesRead:      ScopeClass,
+		GradesWrite:     ScopeClass,
+		AttendanceRead:  ScopeClass,
+		AttendanceWrite: ScopeClass,
+		DashboardRead:   ScopeOwn,
+	},
+	RoleStudent: {
+		SchoolsRead:       ScopeSchool,
+		GradesRead:        ScopeOwn,
+		AttendanceRead:    ScopeOwn,
+		NotificationsRead: ScopeOwn,
+		DashboardRead:     ScopeOwn,
+	},
+	RoleGuardian: {
+		SchoolsRead:       ScopeSchool,
+		GradesRead:        ScopeLinked,
+		AttendanceRead:    ScopeLinked,
+		NotificationsRead: ScopeLinked,
+		DashboardRead:     ScopeOwn,
+	},
+}
+
+// testSubject 全ロール共通の操作者（学校s1・クラスc1に所属し、生徒st1と紐付き、教育委員会d1でs1とs2を管轄）
+func testSubject(role string) Subject {
+	return Subject{
+		UserID:            "u1",
+		Role:              role,
+		SchoolID:          "s1",
+		ClassIDs:          []string{"c1"},
+		StudentIDs:        []string{"st1"},
+		DistrictID:        "d1",
+		DistrictSchoolIDs: []string{"s1", "s2"},
+	}
+}
+
+// resourceCases 操作対象ごとに、許可されるスコープ
+var resourceCases = []struct {
+	name     string
+	resource Resource
+	allowed  map[Scope]bool
+}{
+	{
+		name:     "own record",
+		resource: Resource{OwnerID: "u1", SchoolID: "s1", ClassID: "c1"},
+		allowed:  map[Scope]bool{ScopeOwn: true, ScopeLinked: true, ScopeClass: true, ScopeSchool: true, ScopeDistrict: true, ScopeGlobal: true},
+	},
+	{
+		name:     "linked student",
+		resource: Resource{OwnerID: "st1", SchoolID: "s1", ClassID: "c2"},
+		allowed:  map[Scope]bool{ScopeLinked: true, ScopeSchool: true, ScopeDistrict: true, ScopeGlobal: true},
+	},
+	{
+		name:     "same class",
+		resource: Resource{OwnerID: "u9", SchoolID: "s1", ClassID: "c1"},
+		allowed:  map[Scope]bool{ScopeClass: true, ScopeSchool: true, ScopeDistrict: true, ScopeGlobal: true},
+	},
+	{
+		name:     "same school, other class",
+		resource: Resource{OwnerID: "u9", SchoolID: "s1", ClassID: "c2"},
+		allowed:  map[Scope]bool{ScopeSchool: true, ScopeDistrict: true, ScopeGlobal: true},
+	},
+	{
+		name:     "other school in the district",
+		resource: Resource{OwnerID: "u9", SchoolID: "s2", ClassID: "c3"},
+		allowed:  map[Scope]bool{ScopeDistrict: true, ScopeGlobal: true},
+	},
+	{
+		name:     "other school outside the district",
+		resource: Resource{OwnerID: "u9", SchoolID: "s3", ClassID: "c4"},
+		allowed:  map[Scope]bool{ScopeGlobal: true},
+	},
+	{
+		name:     "own district",
+		resource: Resource{DistrictID: "d1"},
+		allowed:  map[Scope]bool{ScopeDistrict: true, ScopeGlobal: true},
+	},
+	{
+		name:     "other district",
+		resource: Resource{DistrictID: "d2"},
+		allowed:  map[Scope]bool{ScopeGlobal: true},
+	},
+	{
+		name:     "no owner or school",
+		resource: Resource{},
+		allowed:  map[Scope]bool{ScopeGlobal: true},
+	},
+}
+
+func TestScopeOf(t *testing.T) {
+	for _, role := range Roles() {
+		for _, perm := range allPermissions {
+			want := expectedScopes[role][perm]
+			if got := ScopeOf(role, perm); got != want {
+				t.Errorf("ScopeOf(%s, %s) = %d, want %d", role, perm, got, want)
+			}
+			if got := Allows(role, perm); got != (want != ScopeNone) {
+				t.Errorf("Allows(%s, %s) = %v, want %v", role, perm, got, want != ScopeNone)
+			}
+		}
+	}
+}
+
+func TestRolePermissionsAreListed(t *testing.T) {
+	listed := make(map[Permission]bool, len(allPermissions))
+	for _, perm := range allPermissions {
+		listed[perm] = true
+	}
+	for role, permissions := range rolePermissions {
+		if _, ok := expectedScopes[role]; !ok {
+			t.Errorf("role %s is missing from expectedScopes", role)
+		}
+		for perm := range permissions {
+			if !listed[perm] {
+				t.Errorf("permission %s of role %s is missing from allPermissions", perm, role)
+			}
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	for _, role := range Roles() {
+		subject := testSubject(role)
+		for _, perm := range allPermissions {
+			scope := expectedScopes[role][perm]
+			for _, tc := range resourceCases {
+				want := tc.allowed[scope]
+				err := Authorize(subject, perm, tc.resource)
+				if want && err != nil {
+					t.Errorf("Authorize(%s, %s, %s) = %v, want allowed", role, perm, tc.name, err)
+				}
+				if !want && !errors.Is(err, ErrForbidden) {
+					t.Errorf("Authorize(%s, %s, %s) = %v, want ErrForbidden", role, perm, tc.name, err)
+				}
+				if got := Can(subject, perm, tc.resource); got != want {
+					t.Errorf("Can(%s, %s, %s) = %v, want %v", role, perm, tc.name, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestAuthorizeUnknownRole(t *testing.T) {
+	subject := testSubject("superuser")
+	for _, perm := range allPermissions {
+		for _, tc := range resourceCases {
+			if err := Authorize(subject, perm, tc.resource); !errors.Is(err, ErrForbidden) {
+				t.Errorf("Authorize(unknown role, %s, %s) = %v, want ErrForbidden", perm, tc.name, err)
+			}
+		}
+	}
+}
+
+func TestAuthorizeWithoutAssignment(t *testing.T) {
+	cases := []struct {
+		name     string
+		subject  Subject
+		perm     Permission
+		resource Resource
+	}{
+		{
+			name:     "school admin without a school",
+			subject:  Subject{UserID: "u1", Role: RoleSchoolAdmin},
+			perm:     UsersRead,
+			resource: Resource{OwnerID: "u9"},
+		},
+		{
+			name:     "district admin without a district",
+			subject:  Subject{UserID: "u1", Role: RoleDistrictAdmin, SchoolID: "s1"},
+			perm:     UsersRead,
+			resource: Resource{OwnerID: "u9", SchoolID: "s1"},
+		},
+		{
+			name:     "district admin does not inherit its own school",
+			subject:  Subject{UserID: "u1", Role: RoleDistrictAdmin, SchoolID: "s3", DistrictID: "d1", DistrictSchoolIDs: []string{"s1"}},
+			perm:     SchoolsUpdate,
+			resource: Resource{SchoolID: "s3"},
+		},
+		{
+			name:     "teacher without classes",
+			subject:  Subject{UserID: "u1", Role: RoleTeacher, SchoolID: "s1"},
+			perm:     GradesRead,
+			resource: Resource{OwnerID: "u9", SchoolID: "s1"},
+		},
+		{
+			name:     "guardian without linked students",
+			subject:  Subject{UserID: "u1", Role: RoleGuardian, SchoolID: "s1"},
+			perm:     GradesRead,
+			resource: Resource{OwnerID: "st1", SchoolID: "s1"},
+		},
+		{
+			name:     "anonymous owner",
+			subject:  Subject{Role: RoleStudent, SchoolID: "s1"},
+			perm:     GradesRead,
+			resource: Resource{SchoolID: "s1"},
+		},
+	}
+
+	for _, tc := range cases {
+		if err := Authorize(tc.subject, tc.perm, tc.resource); !errors.Is(err, ErrForbidden) {
+			t.Errorf("%s: Authorize = %v, want ErrForbidden", tc.name, err)
+		}
+	}
+}
+
+func TestCanAssignRole(t *testing.T) {
+	// 付与できる組み合わせ（記載のない組み合わせは付与不可）
+	assignable := map[string][]string{
+		RoleAdmin:         {RoleAdmin, RoleSchoolAdmin, RoleTeacher, RoleStudent, RoleGuardian},
+		RoleDistrictAdmin: {RoleSchoolAdmin, RoleTeacher, RoleStudent, RoleGuardian},
+		RoleSchoolAdmin:   {RoleTeacher, RoleStudent, RoleGuardian},
+	}
+
+	targets := append(Roles(), "superuser", "")
+	for _, role := range append(Roles(), "superuser") {
+		want := make(map[string]bool)
+		for _, target := range assignable[role] {
+			want[target] = true
+		}
+		for _, target := range targets {
+			if got := CanAssignRole(role, target); got != want[target] {
+				t.Errorf("CanAssignRole(%s, %s) = %v, want %v", role, target, got, want[target])
+			}
+		}
+	}
+}
+
+func TestRequire(t *testing.T) {
+	for _, role := range Roles() {
+		for _, perm := range allPermissions {
+			err := Require(role, perm)
+			if expectedScopes[role][perm] == ScopeNone {
+				if !errors.Is(err, ErrForbidden) {
+					t.Errorf("Require(%s, %s) = %v, want ErrForbidden", role, perm, err)
+				}
+			} else if err != nil {
+				t.Errorf("Require(%s, %s) = %v, want nil", role, perm, err)
+			}
+		}
+	}
+}
+
+func TestIsAdminRole(t *testing.T) {
+	tests := map[string]bool{
+		RoleAdmin:         true,
+		RoleDistrictAdmin: true,
+		RoleSchoolAdmin:   true,
+		RoleTeacher:       false,
+		RoleStudent:       false,
+		RoleGuardian:      false,
+		"superuser":       false,
+		"":                false,
+	}
+	for role, want := range tests {
+		if got := IsAdminRole(role); got != want {
+			t.Errorf("IsAdminRole(%q) = %v, want %v", role, got, want)
+		}
+	}
+}
@@ -2,11 +2,26 @@ package repositories
 
 import (
     "context"
+    "errors"
     "time"
 
     "github.com/rikut0904/bloomia/backend/internal/domain/entities"
+    "github.com/rikut0904/bloomia/backend/internal/domain/policy"
+    "github.com/rikut0904/bloomia/backend/internal/domain/schoolcode"
 )
 
+// ErrUserNotFound 該当するユーザーが存在しない（DBの障害と区別するため、ユーザーの取得はこのエラーを返す）
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrClassFull 移動先のクラスの在籍人数が定員に達している
+var ErrClassFull = errors.New("class is full")
+
+// ErrEmailTaken メールアドレスが他のユーザーに使用されている
+var ErrEmailTaken = errors.New("email is already used by another account")
+
+// ErrInvalidUserListCursor ユーザー一覧のカーソルの並び替えキーが項目の型として解釈できない
+var ErrInvalidUserListCursor = errors.New("invalid user list cursor")
+
 type UserRepository interface {
 	// ユーザー管理
 	FindByUID(ctx context.Context, uid string) (*entities.User, error)
@@ -22,9 +37,11 @@ type UserRepository interface {
 	
 	// 学校関連
 	FindSchoolByID(ctx context.Context, schoolID int64) (*entities.School, error)
+	// FindSchoolByEmailDomain 該当する有効な学校がない場合はnilを返す
 	FindSchoolByEmailDomain(ctx context.Context, domain string) (*entities.School, error)
 	
 	// クラス関連
+	// FindClassByID 該当するクラスがない場合はnilを返す
 	FindClassByID(ctx context.Context, classID int64) (*entities.Class, error)
 }
 
@@ -38,10 +55,11 @@ type DashboardRepository interface {
 
 type AdminRepository interface {
     // ユーザー管理
-    GetAllUsers(ctx context.Context, page, perPage int, schoolID *string) ([]entities.UserManagement, int, error)
+    GetAllUsers(ctx context.Context, filter entities.UserListFilter) ([]entities.UserManagement, int, *entities.UserListCursor, error)
     UpdateUserRole(ctx context.Context, userID string, role string, schoolID *string) error
     UpdateUserStatus(ctx context.Context, userID string, isActive, isApproved bool) error
     GetUserByID(ctx context.Context, userID string) (*entities.UserManagement, error)
+    GetUserByFirebaseUID(ctx context.Context, firebaseUID string) (*entities.UserManagement, error)
     
     // 学校管理
     GetAllSchools(ctx context.Context) ([]entities.SchoolOption, error)
@@ -50,21 +68,84 @@ type AdminRepository interface {
     GetUserStatsByRole(ctx context.Context, schoolID *string) (map[string]int, error)
 
     // 招待
-    CreateUserInvitation(ctx context.Context, name, email, role, schoolID, message, token string, expiresAt time.Time) error
+    CreateUserInvitation(ctx context.Context, name, email, role, schoolID, message, token string, expiresAt time.Time) (*entities.UserInvitation, error)
+    GetInvitationByToken(ctx context.Context, token string) (*entities.UserInvitation, error)
+    GetInvitationByID(ctx context.Context, invitationID string) (*entities.UserInvitation, error)
+    // GetPendingInvitationByEmail 有効な招待がない場合はnilを返す
+    GetPendingInvitationByEmail(ctx context.Context, email string) (*entities.UserInvitation, error)
+    ListInvitations(ctx context.Context, page, perPage int, schoolID *string, status *string) ([]entities.UserInvitation, int, error)
+    UpdateInvitationStatus(ctx context.Context, invitationID string, status string) error
+    ExpireInvitations(ctx context.Context) (int64, error)
+    RegenerateInvitationToken(ctx context.Context, invitationID, token string, expiresAt time.Time) (*entities.UserInvitation, error)
+    AcceptInvitation(ctx context.Context, invitationID string, user *entities.User) (*entities.User, error)
 }
 
 type SchoolRepository interface {
 	// 学校CRUD操作
+	// CreateSchool コード（SchoolID）が他校で使用中（変更前のコードを含む）の場合はnilを返す
 	CreateSchool(ctx context.Context, school *entities.School) (*entities.School, error)
 	GetSchoolByID(ctx context.Context, schoolID int64) (*entities.School, error)
+	// GetSchoolByCode 変更前のコードでも解決する
 	GetSchoolByCode(ctx context.Context, code string) (*entities.School, error)
 	GetAllSchools(ctx context.Context, filters map[string]interface{}) ([]*entities.School, error)
-	UpdateSchool(ctx context.Context, schoolID int64, updateData entities.School) (*entities.School, error)
+	// UpdateSchool nilでない項目のみ更新
+	UpdateSchool(ctx context.Context, schoolID int64, update entities.SchoolUpdate) (*entities.School, error)
+	// FindSchoolIDByEmailDomain 削除済みを含めてメールドメインを使用している学校のIDを取得（該当なしの場合は空文字）
+	FindSchoolIDByEmailDomain(ctx context.Context, domain string) (string, error)
 	DeleteSchool(ctx context.Context, schoolID int64) error
+	// RestoreSchool deletedAfterより後に論理削除された学校を復元（対象がない場合はfalse）
+	RestoreSchool(ctx context.Context, schoolID int64, deletedAfter time.Time) (bool, error)
 	
 	// 学校統計・管理
-	GetSchoolStats(ctx context.Context, schoolID int64) (map[string]interface{}, error)
+	// GetSchoolStats 集計期間の統計レポートを作成（ログイン状況は現在時点）
+	GetSchoolStats(ctx context.Context, schoolID int64, period entities.SchoolStatsPeriod) (*entities.SchoolStats, error)
 	GetSchoolUsers(ctx context.Context, schoolID int64, role string) ([]*entities.User, error)
+
+	// 学校コード（作成・変更は同時に実行しても重複しないよう1つずつ処理する）
+	// SchoolCodeOwner コード（変更前のコードを含む）を使用している学校のID（RLSによらず全校から探し、未使用の場合は空文字）
+	SchoolCodeOwner(ctx context.Context, code string) (string, error)
+	// CreateSchoolWithGeneratedCode 形式の連番を採番し、同じトランザクションで学校を作成（使用中のコードは飛ばす）
+	CreateSchoolWithGeneratedCode(ctx context.Context, school *entities.School, format schoolcode.Format, prefectureCode string) (*entities.School, error)
+	// ChangeSchoolCode コードを変更して変更前のコードを別名として残す（他校が使用中の場合はfalse）
+	ChangeSchoolCode(ctx context.Context, schoolID int64, code string) (bool, error)
+	// ListSchoolCodeAliases 変更前のコードの一覧（新しい順）
+	ListSchoolCodeAliases(ctx context.Context, schoolID int64) ([]entities.SchoolCodeAlias, error)
+}
+
+// AcademicYearRepository 年度更新（進級・卒業・クラスと授業の複製）と年度のアーカイブ
+type AcademicYearRepository interface {
+	// LatestOpenClassYear 年度更新が済んでいないクラスの最新の年度（クラスがない場合は0）
+	LatestOpenClassYear(ctx context.Context, schoolID int64) (int, error)
+	IsArchived(ctx context.Context, schoolID int64, academicYear int) (bool, error)
+	// PreviewRollover 年度更新の対象件数を集計（変更は行わない）
+	PreviewRollover(ctx context.Context, schoolID int64, fromYear int) (*entities.AcademicYearRollover, error)
+	// Rollover 1つのトランザクションで年度更新を行い、fromYearを読み取り専用にする（更新済みの場合はnil）
+	Rollover(ctx context.Context, schoolID int64, fromYear int, archivedBy string) (*entities.AcademicYearRollover, error)
+}
+
+// DistrictRepository 教育委員会と所属校・教育委員会管理者の割り当て
+type DistrictRepository interface {
+	CreateDistrict(ctx context.Context, district *entities.District) (*entities.District, error)
+	// GetDistrictByID 該当する教育委員会がない場合はnilを返す
+	GetDistrictByID(ctx context.Context, districtID string) (*entities.District, error)
+	ListDistricts(ctx context.Context) ([]*entities.District, error)
+	// ListDistrictSchools 削除されていない所属校の一覧
+	ListDistrictSchools(ctx context.Context, districtID string) ([]entities.SchoolOption, error)
+	// SetSchoolDistrict 学校の所属先を変更（districtIDが空の場合は所属を外す、学校がない場合はfalse）
+	SetSchoolDistrict(ctx context.Context, schoolID, districtID string) (bool, error)
+	// AssignAdmin ユーザーを教育委員会管理者にする（ユーザーがいない場合はfalse）
+	AssignAdmin(ctx context.Context, districtID, userID string) (bool, error)
+	// GetScopeByFirebaseUID 教育委員会管理者が管轄する教育委員会と学校（教育委員会管理者でない場合はnil）
+	GetScopeByFirebaseUID(ctx context.Context, firebaseUID string) (*policy.DistrictScope, error)
+}
+
+// SchoolStatsCache 学校統計のキャッシュ（学校単位でまとめて無効化できるよう索引を持つ）
+type SchoolStatsCache interface {
+	// Get キャッシュがない・期限切れの場合はnilを返す
+	Get(ctx context.Context, schoolID, from, to string) (*entities.SchoolStats, error)
+	Set(ctx context.Context, stats *entities.SchoolStats, ttl time.Duration) error
+	// Invalidate 学校のすべての集計期間のキャッシュを削除
+	Invalidate(ctx context.Context, schoolID string) error
 }
 
 type RedisRepository interface {
@@ -72,3 +153,127 @@ type RedisRepository interface {
 	Get(ctx context.Context, key string) (string, error)
 	Delete(ctx context.Context, key string) error
 }
+
+// SessionRepository ログインセッション（ユーザー・学校単位で一括失効できるよう索引を持つ）
+type SessionRepository interface {
+	Create(ctx context.Context, session *entities.Session) error
+	// Update 既存セッションのみ更新し、失効済みの場合はfalseを返す
+	Update(ctx context.Context, session *entities.Session) (bool, error)
+	// Get 存在しない・期限切れの場合はnilを返す
+	Get(ctx context.Context, sessionID string) (*entities.Session, error)
+	ListByUser(ctx context.Context, userID string) ([]entities.Session, error)
+	Delete(ctx context.Context, sessionID string) error
+	DeleteByUser(ctx context.Context, userID string) (int, error)
+	DeleteBySchool(ctx context.Context, schoolID string) (int, error)
+}
+
+// CredentialRepository パスワードログイン用の認証情報とパスワード再設定トークン
+type CredentialRepository interface {
+	// GetCredentialsByEmail 該当ユーザーがいない場合はnilを返す
+	GetCredentialsByEmail(ctx context.Context, email string) (*entities.UserCredentials, error)
+	GetCredentialsByUserID(ctx context.Context, userID string) (*entities.UserCredentials, error)
+	UpdatePassword(ctx context.Context, userID, passwordHash string, mustChange bool) error
+
+	// パスワード再設定
+	CreatePasswordResetToken(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error
+	// ConsumePasswordResetToken 有効なトークンを使用済みにしてユーザーIDを返す（無効な場合は空文字）
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string) (string, error)
+}
+
+// LoginAttemptRepository ログイン失敗回数（アカウントロック判定用）
+type LoginAttemptRepository interface {
+	GetFailures(ctx context.Context, key string) (int, error)
+	// RecordFailure 失敗回数を加算し、最初の失敗からwindowの間保持する
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	ResetFailures(ctx context.Context, key string) error
+}
+
+// MFARepository 二要素認証（TOTP）の登録情報とリカバリーコード
+type MFARepository interface {
+	// GetSettings 未登録の場合はnilを返す
+	GetSettings(ctx context.Context, userID string) (*entities.MFASettings, error)
+	// SaveSecret 未確認の状態でシークレットを保存（既存の登録は置き換える）
+	SaveSecret(ctx context.Context, userID, secretEncrypted string) error
+	// Confirm 登録を確定し、リカバリーコードを設定する
+	Confirm(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error
+	// UseStep 指定ステップより前のコードのみ受け付け、使用済みとして記録する（再利用の場合はfalse）
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	Delete(ctx context.Context, userID string) error
+
+	// リカバリーコード
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	// ConsumeRecoveryCode 未使用のコードを使用済みにする（該当なしの場合はfalse）
+	ConsumeRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+}
+
+// UserImportRepository 名簿ファイルからの生徒の一括登録
+type UserImportRepository interface {
+	// ListCurrentClasses 今年度のクラスをクラス名からIDを引けるマップで返す
+	ListCurrentClasses(ctx context.Context, schoolID string) (map[string]int64, error)
+	// FindByStudentNumbers 学籍番号をキーとした既存ユーザー
+	FindByStudentNumbers(ctx context.Context, schoolID string, studentNumbers []string) (map[string]entities.RosterUser, error)
+	// FindByEmails 小文字のメールアドレスをキーとした既存ユーザー（他校・削除済みを含む全ユーザーが対象で、IDのみ返す）
+	FindByEmails(ctx context.Context, emails []string) (map[string]entities.RosterUser, error)
+	// LockClassCapacities クラスの行をロックし、定員と在籍人数を返す（トランザクションの終了まで他の所属変更を待たせる）
+	LockClassCapacities(ctx context.Context, classIDs []int64) (map[int64]entities.ClassCapacity, error)
+	// UpsertStudent (school_id, student_number) が一致する生徒は更新、なければ承認済みで作成する（前後のクラスの在籍人数も再集計）
+	// 移動先のクラスが定員に達している場合はErrClassFull、メールアドレスが使用済みの場合はErrEmailTaken
+	UpsertStudent(ctx context.Context, schoolID string, student *entities.StudentImport) (userID string, created bool, err error)
+}
+
+// ExportRepository エクスポート用にデータを1行ずつ読み出す
+type ExportRepository interface {
+	// StreamRows 列名はentities.ExportColumnsで検証済みであること。fnがエラーを返した時点で中断する
+	StreamRows(ctx context.Context, dataset string, columns []string, schoolID *string, fn func(values []interface{}) error) error
+}
+
+// AuditRepository 監査ログの永続化（追記のみ）
+type AuditRepository interface {
+	// Append 同じ学校のチェーンの末尾にハッシュを連結して追記し、ID・ハッシュ・作成日時を設定する
+	Append(ctx context.Context, event *entities.AuditEvent) error
+	List(ctx context.Context, filter entities.AuditEventFilter, page, perPage int) ([]entities.AuditEvent, int, error)
+	// ListChain チェーン検証用に、指定IDより後のイベントをID順に取得
+	ListChain(ctx context.Context, schoolID *string, afterID string, limit int) ([]entities.AuditEvent, error)
+}
+
+// ApprovalRepository 自己登録ユーザーの承認ワークフロー
+type ApprovalRepository interface {
+	GetAccountStatus(ctx context.Context, firebaseUID string) (*entities.AccountStatus, error)
+	ListPending(ctx context.Context, schoolID string, page, perPage int) ([]entities.UserManagement, int, error)
+	// ReviewUsers 指定した学校の承認待ちユーザーのうちrolesに含まれるものを承認・却下し、更新したユーザーを返す
+	ReviewUsers(ctx context.Context, schoolID string, userIDs []string, roles []string, approve bool, reason *string, reviewerID string) ([]entities.UserManagement, error)
+}
+
+// UserDeletionRepository ユーザーの論理削除・復元と個人情報の消去
+type UserDeletionRepository interface {
+	SoftDeleteUser(ctx context.Context, userID string) error
+	// RestoreUser deletedAfterより後に論理削除され、未消去のユーザーを復元（対象がない場合はfalse）
+	RestoreUser(ctx context.Context, userID string, deletedAfter time.Time) (bool, error)
+	// ListExpiredDeletions deletedBefore以前に論理削除され、未消去のユーザーID
+	ListExpiredDeletions(ctx context.Context, deletedBefore time.Time, limit int) ([]string, error)
+	// EraseUser 個人情報を匿名化し、投稿・ノート・提出物の本文を消去する（成績は残す）。消去済みの場合はfalse
+	EraseUser(ctx context.Context, userID string) (bool, error)
+}
+
+// StudentProfileRepository 生徒の学籍情報（学籍番号・クラス）の管理
+type StudentProfileRepository interface {
+	// FindUserIDByStudentNumber 学校内で学籍番号が一致するユーザーのID（該当なしの場合は空文字）
+	FindUserIDByStudentNumber(ctx context.Context, schoolID, studentNumber string) (string, error)
+	// TransferClass 生徒のクラスを変更（classIDがnilの場合はクラスから外す）し、移動前後のクラスの在籍人数を再集計
+	// 移動先が定員に達している場合はErrClassFull
+	TransferClass(ctx context.Context, userID string, classID *int64) error
+}
+
+// StudentRecordRepository 成績・出席などの生徒記録（閲覧者のロールに応じて対象を絞り込む）
+type StudentRecordRepository interface {
+	// 生徒記録
+	ListGrades(ctx context.Context, viewer policy.Subject, studentID string) ([]entities.Grade, error)
+	ListAttendance(ctx context.Context, viewer policy.Subject, studentID string, from, to *time.Time) ([]entities.AttendanceRecord, error)
+	ListNotifications(ctx context.Context, viewer policy.Subject, limit int) ([]entities.Notification, error)
+
+	// 保護者と生徒の紐付け
+	ListGuardianStudents(ctx context.Context, guardianID string) ([]entities.GuardianStudent, error)
+	LinkGuardianStudent(ctx context.Context, guardianID, studentID string, relationship *string) error
+	UnlinkGuardianStudent(ctx context.Context, guardianID, studentID string) error
+}
@@ -0,0 +1,142 @@
+package schoolcode
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultFormat 既定のコード形式（例: 東京都の1校目は"13-0001"）
+	DefaultFormat = "{pref}-{seq:4}"
+	// UnknownPrefecture 都道府県が未指定の場合の都道府県コード
+	UnknownPrefecture = "00"
+
+	// MinLength・MaxLength 学校コードの長さ
+	MinLength = 3
+	MaxLength = 32
+)
+
+// codePattern 英小文字・数字・ハイフン（先頭と末尾は英数字）
+var codePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// prefectures JIS X 0401の都道府県コード
+var prefectures = []string{
+	"北海道", "青森県", "岩手県", "宮城県", "秋田県", "山形県", "福島県",
+	"茨城県", "栃木県", "群馬県", "埼玉県", "千葉県", "東京都", "神奈川県",
+	"新潟県", "富山県", "石川県", "福井県", "山梨県", "長野県", "岐阜県",
+	"静岡県", "愛知県", "三重県", "滋賀県", "京都府", "大阪府", "兵庫県",
+	"奈良県", "和歌山県", "鳥取県", "島根県", "岡山県", "広島県", "山口県",
+	"徳島県", "香川県", "愛媛県", "高知県", "福岡県", "佐賀県", "長崎県",
+	"熊本県", "大分県", "宮崎県", "鹿児島県", "沖縄県",
+}
+
+// PrefectureCode 都道府県名（"東京都"・"東京"）または2桁のコードを都道府県コードに変換
+// 空の場合はUnknownPrefectureを返す
+func PrefectureCode(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return UnknownPrefecture, true
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 1 || n > len(prefectures) {
+			return "", false
+		}
+		return fmt.Sprintf("%02d", n), true
+	}
+	for i, name := range prefectures {
+		if value == name || value == shortPrefectureName(name) {
+			return fmt.Sprintf("%02d", i+1), true
+		}
+	}
+	return "", false
+}
+
+// shortPrefectureName 末尾の「都」「府」「県」を除いた名前（北海道はそのまま）
+func shortPrefectureName(name string) string {
+	for _, suffix := range []string{"都", "府", "県"} {
+		if strings.HasSuffix(name, suffix) {
+			return strings.TrimSuffix(name, suffix)
+		}
+	}
+	return name
+}
+
+// Normalize 比較・保存用に前後の空白を除いて小文字にする
+func Normalize(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// Validate 正規化済みのコードが使用できる文字・長さか
+func Validate(code string) error {
+	if len(code) < MinLength || len(code) > MaxLength {
+		return fmt.Errorf("code must be %d to %d characters", MinLength, MaxLength)
+	}
+	if !codePattern.MatchString(code) {
+		return fmt.Errorf("code may contain only lowercase letters, digits and hyphens, and must start and end with a letter or digit")
+	}
+	return nil
+}
+
+// Format 採番するコードの形式
+// {pref}は都道府県コード、{seq}または{seq:N}はN桁にゼロ埋めした連番に置き換える
+type Format struct {
+	prefix string
+	suffix string
+	width  int
+}
+
+var (
+	seqPattern     = regexp.MustCompile(`\{seq(?::([0-9]+))?\}`)
+	literalPattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+)
+
+// ParseFormat 形式を解析（{seq}はちょうど1つ必要）
+func ParseFormat(template string) (Format, error) {
+	matches := seqPattern.FindAllStringSubmatchIndex(template, -1)
+	if len(matches) != 1 {
+		return Format{}, fmt.Errorf("school code format %q must contain exactly one {seq}", template)
+	}
+
+	match := matches[0]
+	format := Format{prefix: template[:match[0]], suffix: template[match[1]:]}
+	if match[2] >= 0 {
+		width, _ := strconv.Atoi(template[match[2]:match[3]])
+		if width < 1 || width > 10 {
+			return Format{}, fmt.Errorf("school code format %q: sequence width must be 1 to 10", template)
+		}
+		format.width = width
+	}
+
+	// 連番と都道府県コード以外は固定の文字として検証する
+	literal := strings.ReplaceAll(format.prefix+format.suffix, "{pref}", "")
+	if strings.ContainsAny(literal, "{}") {
+		return Format{}, fmt.Errorf("school code format %q contains an unknown placeholder", template)
+	}
+	if literal != "" && !literalPattern.MatchString(literal) {
+		return Format{}, fmt.Errorf("school code format %q may contain only lowercase letters, digits and hyphens", template)
+	}
+	if err := Validate(format.Render(UnknownPrefecture, 1)); err != nil {
+		return Format{}, fmt.Errorf("school code format %q: %w", template, err)
+	}
+	return format, nil
+}
+
+// Scope 連番を共有する範囲（連番部分を除いたコード。都道府県ごとに連番を振る）
+func (f Format) Scope(prefectureCode string) string {
+	return f.fill(prefectureCode, "{seq}")
+}
+
+// Render 都道府県コードと連番からコードを作成
+func (f Format) Render(prefectureCode string, seq int) string {
+	number := strconv.Itoa(seq)
+	if len(number) < f.width {
+		number = strings.Repeat("0", f.width-len(number)) + number
+	}
+	return f.fill(prefectureCode, number)
+}
+
+func (f Format) fill(prefectureCode, number string) string {
+	return strings.ReplaceAll(f.prefix, "{pref}", prefectureCode) + number + strings.ReplaceAll(f.suffix, "{pref}", prefectureCode)
+}
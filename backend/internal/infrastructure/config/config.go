@@ -32,6 +32,34 @@ type Config struct {
 	DisableAuth       bool   // 開発環境で認証を無効化
 	MockUserRole      string // モックユーザーのロール
 	MockUserSchoolID  string // モックユーザーの学校ID
+
+	// トークン検証関連の設定
+	AuthProvider       string // firebase / oidc / local
+	OIDCIssuerURL      string
+	OIDCAudience       string
+	OIDCClaimNamespace string // 名前空間付きカスタムクレームのプレフィックス
+
+	// 二要素認証関連の設定
+	MFAStepUpMinutes   int // 重要な管理操作の前に二要素認証を求める間隔（分）
+
+	// 代理ログイン関連の設定
+	ImpersonationTTLMinutes int // サポート用の代理ログイントークンの有効期間（分）
+
+	// 削除関連の設定
+	DeletionGraceDays  int // 削除したユーザー・学校を復元できる期間（日）。過ぎたユーザーは個人情報を消去する
+
+	// 学校コード関連の設定
+	SchoolCodeFormat   string // 採番する学校コードの形式（{pref}は都道府県コード、{seq:N}はN桁の連番）
+
+	// メール送信関連の設定
+	MailBackend       string // smtp または spool
+	MailFrom          string
+	MailLocale        string // ja または en
+	MailSpoolDir      string // spoolバックエンドの保存先
+	SMTPHost          string
+	SMTPPort          string
+	SMTPUsername      string
+	SMTPPassword      string
 }
 
 func Load() *Config {
@@ -65,6 +93,34 @@ func Load() *Config {
 		DisableAuth:       getBoolEnv("DISABLE_AUTH", false),
 		MockUserRole:      getEnv("MOCK_USER_ROLE", "admin"),
 		MockUserSchoolID:  getEnv("MOCK_USER_SCHOOL_ID", "1"),
+
+		// トークン検証関連の設定
+		AuthProvider:       getEnv("AUTH_PROVIDER", "firebase"),
+		OIDCIssuerURL:      getEnv("OIDC_ISSUER_URL", defaultIssuerURL(os.Getenv("AUTH0_DOMAIN"))),
+		OIDCAudience:       getEnv("OIDC_AUDIENCE", ""),
+		OIDCClaimNamespace: getEnv("OIDC_CLAIM_NAMESPACE", ""),
+
+		// 二要素認証関連の設定
+		MFAStepUpMinutes:   getIntEnv("MFA_STEP_UP_MINUTES", 10),
+
+		// 代理ログイン関連の設定
+		ImpersonationTTLMinutes: getIntEnv("IMPERSONATION_TTL_MINUTES", 15),
+
+		// 削除関連の設定
+		DeletionGraceDays:  getIntEnv("DELETION_GRACE_DAYS", 30),
+
+		// 学校コード関連の設定
+		SchoolCodeFormat:   getEnv("SCHOOL_CODE_FORMAT", "{pref}-{seq:4}"),
+
+		// メール送信関連の設定
+		MailBackend:       getEnv("MAIL_BACKEND", "spool"),
+		MailFrom:          getEnv("MAIL_FROM", "no-reply@bloomia.local"),
+		MailLocale:        getEnv("MAIL_LOCALE", "ja"),
+		MailSpoolDir:      getEnv("MAIL_SPOOL_DIR", "./storage/mail"),
+		SMTPHost:          getEnv("SMTP_HOST", ""),
+		SMTPPort:          getEnv("SMTP_PORT", "587"),
+		SMTPUsername:      getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:      getEnv("SMTP_PASSWORD", ""),
 	}
 }
 
@@ -75,6 +131,14 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// defaultIssuerURL Auth0ドメインからissuer URLを生成
+func defaultIssuerURL(auth0Domain string) string {
+	if auth0Domain == "" {
+		return ""
+	}
+	return "https://" + auth0Domain + "/"
+}
+
 func getBoolEnv(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
@@ -82,4 +146,13 @@ func getBoolEnv(key string, defaultValue bool) bool {
 		}
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func getIntEnv(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
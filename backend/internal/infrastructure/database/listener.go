@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	listenerMinReconnect = 10 * time.Second
+	listenerMaxReconnect = time.Minute
+	// listenerPingInterval 通知がない間も接続の切断を検知できるよう確認する間隔
+	listenerPingInterval = 90 * time.Second
+)
+
+// Listen PostgreSQLのchannelへの通知を受け取るたびにfnを呼ぶ（ctxが終了するまで続ける）
+// NOTIFYはコミット時に配信されるため、ロールバックした変更の通知は届かない
+// 再接続までの間の通知は失われるため、fnはキャッシュの削除など取りこぼしても後で回復できる処理に使う
+func Listen(ctx context.Context, databaseURL, channel string, fn func(ctx context.Context, payload string)) {
+	listener := pq.NewListener(databaseURL, listenerMinReconnect, listenerMaxReconnect, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Listener on %s: %v", channel, err)
+		}
+	})
+	defer listener.Close()
+
+	// 接続できない場合も再接続後にLISTENされる
+	if err := listener.Listen(channel); err != nil {
+		log.Printf("Failed to listen on %s: %v", channel, err)
+	}
+
+	log.Printf("Listening on %s", channel)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Stopped listening on %s", channel)
+			return
+		case notification := <-listener.Notify:
+			// 再接続した場合はnil（切断中の通知は届かない）
+			if notification == nil {
+				log.Printf("Listener on %s reconnected; notifications may have been missed", channel)
+				continue
+			}
+			fn(ctx, notification.Extra)
+		case <-time.After(listenerPingInterval):
+			go listener.Ping()
+		}
+	}
+}
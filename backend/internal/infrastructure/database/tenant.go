@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// tenantRole RLSポリシーの対象となるDBロール
+// テーブル所有者はRLSをバイパスするため、テナントトランザクション内ではこのロールに切り替える
+const tenantRole = "authenticated"
+
+// Tenant RLSポリシーに渡すリクエスト元のユーザー情報
+type Tenant struct {
+	UserID   string
+	Role     string
+	SchoolID string
+}
+
+// Executor *sql.DB と *sql.Tx に共通するクエリ実行メソッド
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txContextKey struct{}
+
+type afterCommitKey struct{}
+
+// afterCommitHooks テナントトランザクションのコミット後に実行する処理
+type afterCommitHooks struct {
+	mu  sync.Mutex
+	fns []func(ctx context.Context)
+}
+
+func (h *afterCommitHooks) add(fn func(ctx context.Context)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fns = append(h.fns, fn)
+}
+
+func (h *afterCommitHooks) run(ctx context.Context) {
+	h.mu.Lock()
+	fns := h.fns
+	h.fns = nil
+	h.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(ctx)
+	}
+}
+
+// AfterCommit テナントトランザクションのコミット後にfnを実行する（トランザクション外ではすぐに実行する）
+// ロールバックした場合は実行しない。外部サービスへの反映やキャッシュの削除など、DBと一緒に取り消せない処理に使う
+// fnに渡すコンテキストはトランザクションを含まない
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if hooks, ok := ctx.Value(afterCommitKey{}).(*afterCommitHooks); ok {
+		hooks.add(fn)
+		return
+	}
+	fn(ctx)
+}
+
+// DB リクエスト単位のテナントトランザクションを透過的に扱う *sql.DB のラッパー
+// コンテキストにテナントトランザクションがあればその中でクエリを実行する
+type DB struct {
+	db *sql.DB
+}
+
+// NewDB *sql.DB をラップ
+func NewDB(db *sql.DB) *DB {
+	return &DB{db: db}
+}
+
+// executor コンテキストのトランザクション、なければ接続プールを返す
+func (d *DB) executor(ctx context.Context) Executor {
+	if tx, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return d.db
+}
+
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return d.executor(ctx).ExecContext(ctx, query, args...)
+}
+
+func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.executor(ctx).QueryContext(ctx, query, args...)
+}
+
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return d.executor(ctx).QueryRowContext(ctx, query, args...)
+}
+
+// WithTx トランザクション内でfnを実行
+// テナントトランザクション中はそのトランザクションを共有し、コミットは呼び出し元に任せる
+func (d *DB) WithTx(ctx context.Context, fn func(tx Executor) error) error {
+	if tx, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return fn(tx)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RunAsTenant テナントトランザクションを開始し、RLS用の設定を行ってからfnを実行
+// fnがエラーを返した場合はロールバックする
+func (d *DB) RunAsTenant(ctx context.Context, tenant Tenant, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return fmt.Errorf("tenant transaction already started")
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tenant transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SET LOCAL ROLE "+tenantRole); err != nil {
+		return fmt.Errorf("failed to set tenant role: %w", err)
+	}
+
+	// SET LOCAL はプレースホルダを使えないため set_config(..., true) で同等の設定を行う
+	if _, err := tx.ExecContext(ctx, `
+		SELECT set_config('app.current_user_id', $1, true),
+		       set_config('app.current_user_role', $2, true),
+		       set_config('app.current_user_school_id', $3, true)
+	`, tenant.UserID, tenant.Role, tenant.SchoolID); err != nil {
+		return fmt.Errorf("failed to set tenant settings: %w", err)
+	}
+
+	hooks := &afterCommitHooks{}
+	txCtx := context.WithValue(context.WithValue(ctx, txContextKey{}, tx), afterCommitKey{}, hooks)
+	if err := fn(txCtx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tenant transaction: %w", err)
+	}
+	hooks.run(ctx)
+	return nil
+}
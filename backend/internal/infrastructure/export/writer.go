@@ -0,0 +1,204 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/xuri/excelize/v2"
+)
+
+// Writer 行単位でエクスポートファイルを書き出す
+// Closeで書き出しを完了する（XLSXはClose時にまとめて出力される）
+type Writer interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// ContentType 形式ごとのContent-Type
+func ContentType(format string) string {
+	switch format {
+	case entities.ExportFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case entities.ExportFormatJSON:
+		return "application/json"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// NewWriter 形式に応じたWriterを作成し、ヘッダーを書き出す
+func NewWriter(format string, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case entities.ExportFormatCSV:
+		return newCSVWriter(w, columns)
+	case entities.ExportFormatXLSX:
+		return newXLSXWriter(w, columns)
+	case entities.ExportFormatJSON:
+		return newJSONWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// formatValue CSV用に値を文字列へ変換
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	// Excelで文字化けしないようBOMを付ける
+	if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return nil, fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return nil, fmt.Errorf("failed to write csv header: %w", err)
+	}
+	return &csvWriter{writer: writer, record: make([]string, len(columns))}, nil
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+	for i, value := range values {
+		c.record[i] = formatValue(value)
+	}
+	if err := c.writer.Write(c.record); err != nil {
+		return fmt.Errorf("failed to write csv row: %w", err)
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type jsonWriter struct {
+	w       io.Writer
+	columns []string
+	rows    int
+}
+
+func newJSONWriter(w io.Writer, columns []string) (*jsonWriter, error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return nil, fmt.Errorf("failed to write json: %w", err)
+	}
+	return &jsonWriter{w: w, columns: columns}, nil
+}
+
+func (j *jsonWriter) WriteRow(values []interface{}) error {
+	object := make(map[string]interface{}, len(j.columns))
+	for i, column := range j.columns {
+		if data, ok := values[i].([]byte); ok {
+			object[column] = string(data)
+			continue
+		}
+		object[column] = values[i]
+	}
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return fmt.Errorf("failed to encode json row: %w", err)
+	}
+	if j.rows > 0 {
+		data = append([]byte(","), data...)
+	}
+	j.rows++
+	if _, err := j.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write json row: %w", err)
+	}
+	return nil
+}
+
+func (j *jsonWriter) Close() error {
+	if _, err := io.WriteString(j.w, "]\n"); err != nil {
+		return fmt.Errorf("failed to write json: %w", err)
+	}
+	return nil
+}
+
+type xlsxWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, columns []string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create xlsx stream: %w", err)
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	writer := &xlsxWriter{w: w, file: file, stream: stream, row: 1}
+	if err := writer.WriteRow(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (x *xlsxWriter) WriteRow(values []interface{}) error {
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case []byte:
+			cells[i] = string(v)
+		case time.Time:
+			cells[i] = v.Format(time.RFC3339)
+		default:
+			cells[i] = v
+		}
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	if err := x.stream.SetRow(cell, cells); err != nil {
+		return fmt.Errorf("failed to write xlsx row: %w", err)
+	}
+	x.row++
+	return nil
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+
+	if err := x.stream.Flush(); err != nil {
+		return fmt.Errorf("failed to flush xlsx: %w", err)
+	}
+	if _, err := x.file.WriteTo(x.w); err != nil {
+		return fmt.Errorf("failed to write xlsx: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package firebase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
@@ -67,6 +68,15 @@ func (fc *FirebaseClient) VerifyIDToken(ctx context.Context, idToken string) (*a
 	return token, nil
 }
 
+// VerifyIDTokenAndCheckRevoked Firebase IDトークンを検証し、失効（RevokeRefreshTokens・アカウント無効化）も確認する
+func (fc *FirebaseClient) VerifyIDTokenAndCheckRevoked(ctx context.Context, idToken string) (*auth.Token, error) {
+	token, err := fc.Auth.VerifyIDTokenAndCheckRevoked(ctx, idToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify ID token: %w", err)
+	}
+	return token, nil
+}
+
 // GetUser Firebase UIDからユーザー情報を取得
 func (fc *FirebaseClient) GetUser(ctx context.Context, uid string) (*auth.UserRecord, error) {
 	user, err := fc.Auth.GetUser(ctx, uid)
@@ -125,4 +135,23 @@ func (fc *FirebaseClient) GetCustomUserClaims(ctx context.Context, uid string) (
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 	return user.CustomClaims, nil
+}
+
+// RevokeRefreshTokens リフレッシュトークンを失効させる
+func (fc *FirebaseClient) RevokeRefreshTokens(ctx context.Context, uid string) error {
+	err := fc.Auth.RevokeRefreshTokens(ctx, uid)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// IsUserNotFound Firebaseにユーザーが存在しないことを示すエラーかを判定（ラップされたエラーにも対応）
+func IsUserNotFound(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if auth.IsUserNotFound(err) {
+			return true
+		}
+	}
+	return false
 }
\ No newline at end of file
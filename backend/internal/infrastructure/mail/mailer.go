@@ -0,0 +1,77 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	netmail "net/mail"
+	"strings"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+)
+
+// Message 送信するメール
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// ErrInvalidRecipient 宛先がメールアドレスとして正しくない（ヘッダーインジェクションを防ぐため改行を含むものも拒否する）
+var ErrInvalidRecipient = errors.New("invalid mail recipient")
+
+// Mailer メール送信バックエンドのインターフェース
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewMailer 設定に応じたメール送信バックエンドを生成
+func NewMailer(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailBackend {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required for smtp mail backend")
+		}
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case "spool":
+		return NewSpoolMailer(cfg.MailSpoolDir, cfg.MailFrom)
+	default:
+		return nil, fmt.Errorf("unknown mail backend: %s", cfg.MailBackend)
+	}
+}
+
+// parseRecipient 宛先を検証してアドレス部分を取り出す
+func parseRecipient(to string) (string, error) {
+	if strings.ContainsAny(to, "\r\n") {
+		return "", fmt.Errorf("%w: contains a line break", ErrInvalidRecipient)
+	}
+	address, err := netmail.ParseAddress(to)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidRecipient, err)
+	}
+	return address.Address, nil
+}
+
+// buildMessage RFC 5322形式のメール本文を組み立てる
+// Toヘッダーには検証済みのアドレスのみ書き込む
+func buildMessage(from string, msg *Message) ([]byte, error) {
+	to, err := parseRecipient(msg.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Body)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,139 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	queueKey       = "mail:queue"
+	retryKey       = "mail:retry"
+	deadLetterKey  = "mail:dead"
+	maxAttempts    = 5
+	retryBaseDelay = 30 * time.Second
+	pollTimeout    = 5 * time.Second
+)
+
+// queuedMessage キューに積まれる送信ジョブ
+type queuedMessage struct {
+	Message   *Message `json:"message"`
+	Attempts  int      `json:"attempts"`
+	LastError string   `json:"last_error,omitempty"`
+}
+
+// Queue Redisを使った再送キュー付きのメール送信
+// SendはキューへのPushのみ行うため、SMTP障害がHTTPリクエストを失敗させない
+type Queue struct {
+	client *redis.Client
+	mailer Mailer
+}
+
+// NewQueue メール送信キューのコンストラクタ
+func NewQueue(client *redis.Client, mailer Mailer) *Queue {
+	return &Queue{
+		client: client,
+		mailer: mailer,
+	}
+}
+
+// Send メールを送信キューに追加
+// 宛先が正しくないメールは再送しても失敗するためキューに積まない
+func (q *Queue) Send(ctx context.Context, msg *Message) error {
+	if _, err := parseRecipient(msg.To); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(queuedMessage{Message: msg})
+	if err != nil {
+		return fmt.Errorf("failed to encode mail: %w", err)
+	}
+	if err := q.client.RPush(ctx, queueKey, payload).Err(); err != nil {
+		return fmt.Errorf("failed to enqueue mail: %w", err)
+	}
+	return nil
+}
+
+// Run ctxがキャンセルされるまでキューを処理する
+func (q *Queue) Run(ctx context.Context) {
+	log.Println("Mail queue worker started")
+	for ctx.Err() == nil {
+		q.promoteRetries(ctx)
+
+		result, err := q.client.BLPop(ctx, pollTimeout, queueKey).Result()
+		if err != nil {
+			if err != redis.Nil && ctx.Err() == nil {
+				log.Printf("Mail queue poll failed: %v", err)
+				time.Sleep(pollTimeout)
+			}
+			continue
+		}
+
+		q.deliver(ctx, result[1])
+	}
+	log.Println("Mail queue worker stopped")
+}
+
+// deliver 1件送信し、失敗した場合はバックオフ付きで再送キューに移す
+func (q *Queue) deliver(ctx context.Context, payload string) {
+	var job queuedMessage
+	if err := json.Unmarshal([]byte(payload), &job); err != nil || job.Message == nil {
+		log.Printf("Dropping malformed mail job: %v", err)
+		return
+	}
+
+	err := q.mailer.Send(ctx, job.Message)
+	if err == nil {
+		return
+	}
+
+	job.Attempts++
+	job.LastError = err.Error()
+	data, _ := json.Marshal(job)
+
+	// 宛先の誤りは再送しても解消しないため、再送せずdead letterに移す
+	if job.Attempts >= maxAttempts || errors.Is(err, ErrInvalidRecipient) {
+		log.Printf("Mail to %q failed %d times, moving to dead letter: %v", job.Message.To, job.Attempts, err)
+		if err := q.client.RPush(ctx, deadLetterKey, data).Err(); err != nil {
+			log.Printf("Failed to store dead letter mail: %v", err)
+		}
+		return
+	}
+
+	retryAt := time.Now().Add(retryBaseDelay * time.Duration(1<<(job.Attempts-1)))
+	log.Printf("Mail to %q failed (attempt %d), retrying at %s: %v", job.Message.To, job.Attempts, retryAt.Format(time.RFC3339), err)
+	if err := q.client.ZAdd(ctx, retryKey, redis.Z{Score: float64(retryAt.Unix()), Member: data}).Err(); err != nil {
+		log.Printf("Failed to schedule mail retry: %v", err)
+	}
+}
+
+// promoteRetries 再送時刻を過ぎたジョブを送信キューに戻す
+func (q *Queue) promoteRetries(ctx context.Context) {
+	due, err := q.client.ZRangeByScore(ctx, retryKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().Unix(), 10),
+	}).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to read mail retry queue: %v", err)
+		}
+		return
+	}
+
+	for _, payload := range due {
+		// 複数ワーカーでの重複を防ぐためZRemに成功したものだけ戻す
+		removed, err := q.client.ZRem(ctx, retryKey, payload).Result()
+		if err != nil || removed == 0 {
+			continue
+		}
+		if err := q.client.RPush(ctx, queueKey, payload).Err(); err != nil {
+			log.Printf("Failed to requeue mail: %v", err)
+		}
+	}
+}
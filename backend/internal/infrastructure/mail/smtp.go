@@ -0,0 +1,50 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+// SMTPMailer SMTPサーバー経由でメールを送信する
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer SMTPメール送信のコンストラクタ
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send メールを送信
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := buildMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+	to, err := parseRecipient(msg.To)
+	if err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{to}, data); err != nil {
+		return fmt.Errorf("failed to send mail via smtp: %w", err)
+	}
+	return nil
+}
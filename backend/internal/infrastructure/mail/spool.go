@@ -0,0 +1,59 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SpoolMailer メールをMaildir形式でローカルに保存する（開発・テスト用）
+type SpoolMailer struct {
+	dir  string
+	from string
+}
+
+// NewSpoolMailer スプールメール送信のコンストラクタ
+func NewSpoolMailer(dir, from string) (*SpoolMailer, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create mail spool directory: %w", err)
+		}
+	}
+
+	return &SpoolMailer{
+		dir:  dir,
+		from: from,
+	}, nil
+}
+
+// Send メールをスプールディレクトリに書き出す
+func (m *SpoolMailer) Send(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := buildMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("failed to generate spool file name: %w", err)
+	}
+	name := fmt.Sprintf("%d.%s.bloomia", time.Now().UnixNano(), hex.EncodeToString(suffix))
+
+	// tmpに書き込んでからnewへ移動（Maildirの配送手順）
+	tmpPath := filepath.Join(m.dir, "tmp", name)
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write spool file: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(m.dir, "new", name)); err != nil {
+		return fmt.Errorf("failed to deliver spool file: %w", err)
+	}
+	return nil
+}
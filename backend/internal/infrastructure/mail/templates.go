@@ -0,0 +1,222 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+// Template メールテンプレートの種類
+type Template string
+
+const (
+	TemplateInvitation    Template = "invitation"
+	TemplateApproval      Template = "approval"
+	TemplateRejection     Template = "rejection"
+	TemplatePasswordReset Template = "password_reset"
+)
+
+// DefaultLocale ロケール未指定・未対応時に使用するロケール
+const DefaultLocale = "ja"
+
+// InvitationData 招待メールのテンプレートデータ
+type InvitationData struct {
+	Name       string
+	SchoolName string
+	Role       string
+	Message    string
+	URL        string
+	ExpiresAt  time.Time
+}
+
+// ApprovalData 承認通知メールのテンプレートデータ
+type ApprovalData struct {
+	Name string
+	URL  string
+}
+
+// RejectionData 登録却下の通知メールのテンプレートデータ
+type RejectionData struct {
+	Name       string
+	SchoolName string
+	Reason     string
+}
+
+// PasswordResetData パスワード再設定メールのテンプレートデータ
+type PasswordResetData struct {
+	Name      string
+	URL       string
+	ExpiresAt time.Time
+}
+
+type messageTemplate struct {
+	subject string
+	body    string
+}
+
+var templateSources = map[Template]map[string]messageTemplate{
+	TemplateInvitation: {
+		"ja": {
+			subject: "【Bloomia】{{if .SchoolName}}{{.SchoolName}}から{{end}}招待が届いています",
+			body: `{{.Name}} 様
+
+{{if .SchoolName}}{{.SchoolName}}の{{end}}Bloomiaへ招待されました。
+以下のリンクからアカウント登録を完了してください。
+
+{{.URL}}
+{{if .Message}}
+メッセージ:
+{{.Message}}
+{{end}}
+このリンクの有効期限は {{.ExpiresAt.Format "2006年01月02日 15:04"}} までです。
+
+※このメールに心当たりがない場合は破棄してください。
+`,
+		},
+		"en": {
+			subject: "[Bloomia] You have been invited{{if .SchoolName}} to {{.SchoolName}}{{end}}",
+			body: `Hello {{.Name}},
+
+You have been invited to Bloomia{{if .SchoolName}} by {{.SchoolName}}{{end}}.
+Please complete your registration using the link below.
+
+{{.URL}}
+{{if .Message}}
+Message:
+{{.Message}}
+{{end}}
+This link expires on {{.ExpiresAt.Format "2006-01-02 15:04 MST"}}.
+
+If you did not expect this email, you can safely ignore it.
+`,
+		},
+	},
+	TemplateApproval: {
+		"ja": {
+			subject: "【Bloomia】アカウントが承認されました",
+			body: `{{.Name}} 様
+
+Bloomiaのアカウントが承認されました。
+以下のリンクからログインしてご利用ください。
+
+{{.URL}}
+`,
+		},
+		"en": {
+			subject: "[Bloomia] Your account has been approved",
+			body: `Hello {{.Name}},
+
+Your Bloomia account has been approved.
+You can now sign in using the link below.
+
+{{.URL}}
+`,
+		},
+	},
+	TemplateRejection: {
+		"ja": {
+			subject: "【Bloomia】アカウント登録の申請について",
+			body: `{{.Name}} 様
+
+{{if .SchoolName}}{{.SchoolName}}の{{end}}Bloomiaへのアカウント登録の申請は承認されませんでした。
+
+理由:
+{{.Reason}}
+
+お心当たりがない場合は、学校の管理者へお問い合わせください。
+`,
+		},
+		"en": {
+			subject: "[Bloomia] About your account registration",
+			body: `Hello {{.Name}},
+
+Your Bloomia account registration{{if .SchoolName}} for {{.SchoolName}}{{end}} was not approved.
+
+Reason:
+{{.Reason}}
+
+If you believe this is a mistake, please contact your school administrator.
+`,
+		},
+	},
+	TemplatePasswordReset: {
+		"ja": {
+			subject: "【Bloomia】パスワード再設定のご案内",
+			body: `{{.Name}} 様
+
+パスワード再設定のリクエストを受け付けました。
+以下のリンクから新しいパスワードを設定してください。
+
+{{.URL}}
+
+このリンクの有効期限は {{.ExpiresAt.Format "2006年01月02日 15:04"}} までです。
+
+※心当たりがない場合はこのメールを破棄してください。パスワードは変更されません。
+`,
+		},
+		"en": {
+			subject: "[Bloomia] Password reset instructions",
+			body: `Hello {{.Name}},
+
+We received a request to reset your password.
+Please set a new password using the link below.
+
+{{.URL}}
+
+This link expires on {{.ExpiresAt.Format "2006-01-02 15:04 MST"}}.
+
+If you did not request this, you can ignore this email and your password will not change.
+`,
+		},
+	},
+}
+
+// compiledTemplates 起動時にパース済みのテンプレート
+var compiledTemplates = mustCompileTemplates()
+
+type compiledTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+func mustCompileTemplates() map[Template]map[string]compiledTemplate {
+	compiled := make(map[Template]map[string]compiledTemplate)
+	for name, locales := range templateSources {
+		compiled[name] = make(map[string]compiledTemplate)
+		for locale, src := range locales {
+			id := fmt.Sprintf("%s.%s", name, locale)
+			compiled[name][locale] = compiledTemplate{
+				subject: template.Must(template.New(id + ".subject").Parse(src.subject)),
+				body:    template.Must(template.New(id + ".body").Parse(src.body)),
+			}
+		}
+	}
+	return compiled
+}
+
+// Render テンプレートからメールを生成（未対応ロケールはDefaultLocaleで生成）
+func Render(name Template, locale string, to string, data interface{}) (*Message, error) {
+	locales, ok := compiledTemplates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown mail template: %s", name)
+	}
+	tmpl, ok := locales[locale]
+	if !ok {
+		tmpl = locales[DefaultLocale]
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render mail subject: %w", err)
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("failed to render mail body: %w", err)
+	}
+
+	return &Message{
+		To:      to,
+		Subject: subject.String(),
+		Body:    body.String(),
+	}, nil
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+)
+
+// AccountStatusLookup 認証済みユーザーのアカウント状態の取得
+type AccountStatusLookup interface {
+	GetAccountStatus(ctx context.Context, firebaseUID string) (*entities.AccountStatus, error)
+}
+
+// AccountKey usersテーブルから解決したアカウントを保持するコンテキストキー
+const AccountKey contextKey = "account"
+
+// RequireApprovedAccount 承認済みかつ有効なアカウントのみ通すミドルウェア（TokenAuthMiddlewareの後に登録する）
+// 承認待ちのユーザーが使えるのは /auth/sync と /auth/status のみ
+// ロールと所属校はトークンのクレームではなくusersテーブルの値を正とし、以降の権限チェックとハンドラーで共有する
+func RequireApprovedAccount(lookup AccountStatusLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetAuthUserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "User not authenticated", http.StatusUnauthorized)
+				return
+			}
+
+			status, err := lookup.GetAccountStatus(r.Context(), user.UID)
+			if err != nil {
+				if errors.Is(err, repositories.ErrUserNotFound) {
+					http.Error(w, "User account not found", http.StatusForbidden)
+					return
+				}
+				log.Printf("Failed to resolve account status for uid %s: %v", user.UID, err)
+				http.Error(w, "Failed to resolve user account", http.StatusInternalServerError)
+				return
+			}
+			if !status.IsActive {
+				http.Error(w, "User account is inactive", http.StatusForbidden)
+				return
+			}
+			if !status.IsApproved {
+				http.Error(w, "User account is not approved", http.StatusForbidden)
+				return
+			}
+
+			// 降格・転校後もクレームが更新されるまで古い権限が残らないよう、DBの値で上書きする
+			resolved := *user
+			resolved.Role = status.Role
+			resolved.SchoolID = 0
+			if status.SchoolID != nil {
+				resolved.SchoolID, _ = strconv.ParseInt(*status.SchoolID, 10, 64)
+			}
+
+			ctx := context.WithValue(r.Context(), AuthUserKey, &resolved)
+			ctx = context.WithValue(ctx, AccountKey, status)
+			next.ServeHTTP(w, withAuditIdentity(r.WithContext(ctx), &resolved))
+		})
+	}
+}
+
+// GetAccountFromContext RequireApprovedAccountが解決したアカウントを取得
+func GetAccountFromContext(ctx context.Context) (*entities.AccountStatus, bool) {
+	account, ok := ctx.Value(AccountKey).(*entities.AccountStatus)
+	return account, ok
+}
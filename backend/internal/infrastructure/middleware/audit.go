@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+)
+
+// AuditContext 監査ログ用にリクエストIDと送信元IPをコンテキストに設定するミドルウェア
+// chiのRequestIDとRealIPの後に登録する
+func AuditContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ipAddress := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			ipAddress = host
+		}
+
+		ctx := audit.NewContext(r.Context(), &audit.Actor{
+			RequestID: chiMiddleware.GetReqID(r.Context()),
+			IPAddress: ipAddress,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withAuditIdentity 検証済みトークンの情報を監査ログの操作者に追加
+func withAuditIdentity(r *http.Request, authUser *AuthUser) *http.Request {
+	actor := audit.Actor{}
+	if existing := audit.ActorFromContext(r.Context()); existing != nil {
+		actor = *existing
+	}
+
+	actor.UID = authUser.UID
+	actor.Role = authUser.Role
+	actor.ImpersonatorUID = authUser.ImpersonatorUID
+	if authUser.SchoolID > 0 {
+		actor.SchoolID = strconv.FormatInt(authUser.SchoolID, 10)
+	}
+	return r.WithContext(audit.NewContext(r.Context(), &actor))
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+// ChainVerifier 複数のトークン検証を順に試す（外部IdPとローカル発行トークンの併用）
+type ChainVerifier struct {
+	verifiers []TokenVerifier
+}
+
+// NewChainVerifier 検証の優先順に指定する
+func NewChainVerifier(verifiers ...TokenVerifier) *ChainVerifier {
+	return &ChainVerifier{verifiers: verifiers}
+}
+
+// VerifyToken いずれかの検証に成功した結果を返す
+func (v *ChainVerifier) VerifyToken(ctx context.Context, rawToken string) (*VerifiedToken, error) {
+	var errs []error
+	for _, verifier := range v.verifiers {
+		verified, err := verifier.VerifyToken(ctx, rawToken)
+		if err == nil {
+			return verified, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/firebase"
+)
+
+// FirebaseVerifier Firebase IDトークンを検証する
+type FirebaseVerifier struct {
+	client *firebase.FirebaseClient
+}
+
+// NewFirebaseVerifier Firebaseトークン検証のコンストラクタ
+func NewFirebaseVerifier(client *firebase.FirebaseClient) *FirebaseVerifier {
+	return &FirebaseVerifier{client: client}
+}
+
+// VerifyToken Firebase IDトークンを検証しカスタムクレームを取り出す
+// 強制ログアウト後のトークンを拒否するため、失効済みかどうかも確認する
+func (v *FirebaseVerifier) VerifyToken(ctx context.Context, rawToken string) (*VerifiedToken, error) {
+	token, err := v.client.VerifyIDTokenAndCheckRevoked(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VerifiedToken{
+		UID:           token.UID,
+		Email:         stringClaim(token.Claims, "email"),
+		EmailVerified: boolClaim(token.Claims, "email_verified"),
+		Name:          stringClaim(token.Claims, "name"),
+		Role:          stringClaim(token.Claims, "role"),
+		SchoolID:      int64Claim(token.Claims, "school_id"),
+	}, nil
+}
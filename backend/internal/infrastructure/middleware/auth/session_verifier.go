@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+// SessionChecker sidクレームのセッションが有効か確認する
+type SessionChecker interface {
+	CheckTokenSession(ctx context.Context, sessionID, uid string) error
+}
+
+// SessionVerifier sidクレームを含むトークンは、そのセッションが有効な場合のみ受け付ける
+// セッションを失効させると、発行済みのアクセストークンも有効期限前に使えなくなる
+type SessionVerifier struct {
+	verifier TokenVerifier
+	sessions SessionChecker
+}
+
+// NewSessionVerifier トークン検証にセッションの確認を追加する
+func NewSessionVerifier(verifier TokenVerifier, sessions SessionChecker) *SessionVerifier {
+	return &SessionVerifier{verifier: verifier, sessions: sessions}
+}
+
+// VerifyToken トークンを検証し、sidクレームがあればセッションを確認する（代理ログイン用トークンはsidクレームが必須）
+func (v *SessionVerifier) VerifyToken(ctx context.Context, rawToken string) (*VerifiedToken, error) {
+	verified, err := v.verifier.VerifyToken(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// 代理ログイン用トークンは強制ログアウトで失効できるよう、セッションに紐づくもののみ受け付ける
+	if verified.ImpersonatorUID != "" && verified.SessionID == "" {
+		return nil, fmt.Errorf("impersonation token has no session")
+	}
+	if verified.SessionID != "" {
+		if err := v.sessions.CheckTokenSession(ctx, verified.SessionID, verified.UID); err != nil {
+			return nil, fmt.Errorf("token session is not valid: %w", err)
+		}
+	}
+	return verified, nil
+}
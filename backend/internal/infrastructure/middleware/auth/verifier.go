@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/firebase"
+)
+
+// VerifiedToken 検証済みトークンから取り出したユーザー情報
+type VerifiedToken struct {
+	UID           string
+	Email         string
+	EmailVerified bool // IdPがメールアドレスの所有を確認済みか（email_verifiedクレーム）
+	Name          string
+	Role          string // クレーム未設定の場合は空
+	SchoolID      int64  // クレーム未設定の場合は0
+
+	SessionID       string // ローカル発行トークンの場合、発行元のセッション（sidクレーム）
+	ImpersonatorUID string // 代理ログイン用トークンの場合、発行を受けた管理者のUID
+}
+
+// TokenVerifier Bearerトークンを検証するインターフェース
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, rawToken string) (*VerifiedToken, error)
+}
+
+// NewTokenVerifier 設定（AUTH_PROVIDER）に応じたトークン検証を生成
+func NewTokenVerifier(cfg *config.Config, firebaseClient *firebase.FirebaseClient) (TokenVerifier, error) {
+	switch cfg.AuthProvider {
+	case "firebase":
+		if firebaseClient == nil {
+			return nil, fmt.Errorf("firebase client is required for firebase auth provider")
+		}
+		return NewFirebaseVerifier(firebaseClient), nil
+	case "oidc":
+		return NewJWKSVerifier(cfg.OIDCIssuerURL, cfg.OIDCAudience, cfg.OIDCClaimNamespace)
+	case "local":
+		return NewLocalVerifier(cfg.JWTSecret)
+	default:
+		return nil, fmt.Errorf("unknown auth provider: %s", cfg.AuthProvider)
+	}
+}
+
+// stringClaim クレームから文字列値を取得
+func stringClaim(claims map[string]interface{}, key string) string {
+	value, _ := claims[key].(string)
+	return value
+}
+
+// boolClaim クレームから真偽値を取得（未設定・型が異なる場合はfalse）
+func boolClaim(claims map[string]interface{}, key string) bool {
+	value, _ := claims[key].(bool)
+	return value
+}
+
+// int64Claim クレームから整数値を取得（JSONの数値はfloat64で表現される）
+func int64Claim(claims map[string]interface{}, key string) int64 {
+	switch value := claims[key].(type) {
+	case float64:
+		return int64(value)
+	case int64:
+		return value
+	default:
+		return 0
+	}
+}
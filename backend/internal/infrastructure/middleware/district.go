@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+)
+
+// DistrictScopeLookup 教育委員会管理者が管轄する教育委員会と学校の取得
+type DistrictScopeLookup interface {
+	GetDistrictScope(ctx context.Context, firebaseUID string) (*policy.DistrictScope, error)
+}
+
+// ResolveDistrictScope 教育委員会管理者の管轄をコンテキストに設定するミドルウェア（RequireApprovedAccountの後に登録する）
+// 管轄校の判定（policy.ScopeDistrict）はこのコンテキストを参照する
+func ResolveDistrictScope(lookup DistrictScopeLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetAuthUserFromContext(r.Context())
+			if !ok || user.Role != policy.RoleDistrictAdmin {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scope, err := lookup.GetDistrictScope(r.Context(), user.UID)
+			if err != nil {
+				log.Printf("Failed to resolve district scope for uid %s: %v", user.UID, err)
+				http.Error(w, "Failed to resolve district", http.StatusInternalServerError)
+				return
+			}
+			if scope == nil {
+				http.Error(w, "User is not assigned to a district", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(policy.NewDistrictContext(r.Context(), scope)))
+		})
+	}
+}
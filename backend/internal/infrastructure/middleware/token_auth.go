@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/middleware/auth"
+)
+
+// AuthUser 検証済みトークンから取得した認証ユーザー情報
+type AuthUser struct {
+	UID           string
+	Email         string
+	EmailVerified bool
+	DisplayName   string
+	Role          string
+	SchoolID      int64
+
+	SessionID       string // トークンに含まれるセッション（sidクレーム）。有効なセッションが必要
+	ImpersonatorUID string // 代理ログイン中の場合、操作している管理者のUID
+}
+
+// ImpersonationHeader 代理ログイン用トークンでのリクエストであることを示すレスポンスヘッダー
+const ImpersonationHeader = "X-Impersonated-By"
+
+type contextKey string
+
+const AuthUserKey contextKey = "auth_user"
+
+// TokenAuthMiddleware Bearerトークン認証ミドルウェア（検証方式はTokenVerifierで切り替え）
+func TokenAuthMiddleware(verifier auth.TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Authorizationヘッダーからトークンを取得
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+				return
+			}
+
+			// Bearer トークンの形式をチェック
+			tokenParts := strings.Split(authHeader, " ")
+			if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+				http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
+				return
+			}
+
+			// トークンを検証
+			token, err := verifier.VerifyToken(r.Context(), tokenParts[1])
+			if err != nil {
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			// ユーザー情報をコンテキストに追加
+			authUser := &AuthUser{
+				UID:           token.UID,
+				Email:         token.Email,
+				EmailVerified: token.EmailVerified,
+				DisplayName:   token.Name,
+				Role:          token.Role,
+				SchoolID:      token.SchoolID,
+
+				SessionID:       token.SessionID,
+				ImpersonatorUID: token.ImpersonatorUID,
+			}
+			if authUser.ImpersonatorUID != "" {
+				w.Header().Set(ImpersonationHeader, authUser.ImpersonatorUID)
+			}
+
+			ctx := context.WithValue(r.Context(), AuthUserKey, authUser)
+			next.ServeHTTP(w, withAuditIdentity(r.WithContext(ctx), authUser))
+		})
+	}
+}
+
+// OptionalTokenAuthMiddleware Authorizationヘッダーがある場合のみトークンを検証する（未指定の場合はそのまま通す）
+func OptionalTokenAuthMiddleware(verifier auth.TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		authenticated := TokenAuthMiddleware(verifier)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			authenticated.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetAuthUserFromContext コンテキストから認証ユーザー情報を取得
+func GetAuthUserFromContext(ctx context.Context) (*AuthUser, bool) {
+	user, ok := ctx.Value(AuthUserKey).(*AuthUser)
+	return user, ok
+}
+
+// RequirePermission ロールに権限があることを要求するミドルウェア（スコープはユースケースで判定）
+func RequirePermission(perm policy.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetAuthUserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "User not authenticated", http.StatusUnauthorized)
+				return
+			}
+
+			if !policy.Allows(user.Role, perm) {
+				http.Error(w, "Insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSchoolPermission URLパラメータの学校に対する権限を要求するミドルウェア
+func RequireSchoolPermission(perm policy.Permission, schoolIDParam string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetAuthUserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "User not authenticated", http.StatusUnauthorized)
+				return
+			}
+
+			subject := policy.WithDistrict(r.Context(), policy.Subject{Role: user.Role})
+			if user.SchoolID > 0 {
+				subject.SchoolID = strconv.FormatInt(user.SchoolID, 10)
+			}
+			resource := policy.Resource{SchoolID: chi.URLParam(r, schoolIDParam)}
+
+			if !policy.Can(subject, perm, resource) {
+				http.Error(w, "Insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
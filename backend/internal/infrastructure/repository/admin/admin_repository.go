@@ -3,93 +3,189 @@ package admin
 import (
     "context"
     "database/sql"
+    "errors"
     "fmt"
+    "strings"
     "time"
 
+	"github.com/lib/pq"
 	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
 	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
 )
 
 type adminRepository struct {
-    db *sql.DB
+    db *database.DB
 }
 
-func NewAdminRepository(db *sql.DB) repositories.AdminRepository {
+func NewAdminRepository(db *database.DB) repositories.AdminRepository {
 	return &adminRepository{db: db}
 }
 
-func (r *adminRepository) GetAllUsers(ctx context.Context, page, perPage int, schoolID *string) ([]entities.UserManagement, int, error) {
-	// Count total users
-	countQuery := `
-		SELECT COUNT(*) 
-		FROM users u 
-		LEFT JOIN schools s ON u.school_id = s.id
-		WHERE 1=1
-	`
+// userSortColumns 並び替え項目（entities.UserSortColumns）ごとの式と型（カーソルの比較でNULLを扱わないようCOALESCEで補完）
+var userSortColumns = map[string]struct {
+	expr    string
+	sqlType string
+}{
+	entities.UserSortName:        {"u.name", "text"},
+	entities.UserSortFurigana:    {"COALESCE(u.furigana, '')", "text"},
+	entities.UserSortEmail:       {"u.email", "text"},
+	entities.UserSortRole:        {"u.role", "text"},
+	entities.UserSortIsApproved:  {"COALESCE(u.is_approved, false)", "boolean"},
+	entities.UserSortIsActive:    {"COALESCE(u.is_active, false)", "boolean"},
+	entities.UserSortGrade:       {"COALESCE(u.grade, 0)", "integer"},
+	entities.UserSortClass:       {"COALESCE(c.name, '')", "text"},
+	entities.UserSortLastLoginAt: {"COALESCE(u.last_login_at, '-infinity'::timestamptz)", "timestamptz"},
+	entities.UserSortCreatedAt:   {"COALESCE(u.created_at, '-infinity'::timestamptz)", "timestamptz"},
+}
+
+// userListFilterClause 一覧の絞り込み条件をWHERE句に変換
+func userListFilterClause(filter entities.UserListFilter) (string, []interface{}) {
+	// 削除済みのユーザーは明示的に指定した場合のみ表示する
+	conditions := []string{"u.deleted_at IS NULL"}
+	if filter.Deleted {
+		conditions = []string{"u.deleted_at IS NOT NULL"}
+	}
 	args := []interface{}{}
-	argIndex := 1
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", fmt.Sprintf("$%d", len(args))))
+	}
 
-	if schoolID != nil {
-		countQuery += fmt.Sprintf(" AND u.school_id = $%d", argIndex)
-		args = append(args, *schoolID)
-		argIndex++
+	if filter.SchoolID != nil {
+		add("u.school_id = ?", *filter.SchoolID)
+	}
+	if filter.Query != nil {
+		// トライグラム索引を使う部分一致（%と_は文字として扱う）
+		add("(u.name ILIKE ? OR u.furigana ILIKE ? OR u.email ILIKE ?)", "%"+likeEscaper.Replace(*filter.Query)+"%")
+	}
+	if filter.Role != nil {
+		add("u.role = ?", *filter.Role)
+	}
+	if filter.IsApproved != nil {
+		add("u.is_approved = ?", *filter.IsApproved)
+	}
+	if filter.IsActive != nil {
+		add("u.is_active = ?", *filter.IsActive)
+	}
+	if filter.Grade != nil {
+		add("u.grade = ?", *filter.Grade)
+	}
+	if filter.ClassID != nil {
+		add("u.class_id = ?", *filter.ClassID)
+	}
+	if filter.LastLoginFrom != nil {
+		add("u.last_login_at >= ?", *filter.LastLoginFrom)
+	}
+	if filter.LastLoginTo != nil {
+		add("u.last_login_at < ?", *filter.LastLoginTo)
 	}
 
-	var totalCount int
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	return strings.Join(conditions, " AND "), args
+}
+
+// likeEscaper LIKEの特殊文字をエスケープ
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (r *adminRepository) GetAllUsers(ctx context.Context, filter entities.UserListFilter) ([]entities.UserManagement, int, *entities.UserListCursor, error) {
+	sort, ok := userSortColumns[filter.SortBy]
+	if !ok {
+		return nil, 0, nil, fmt.Errorf("unknown sort column: %s", filter.SortBy)
 	}
 
-	// Get paginated users
-	query := `
-		SELECT u.id, u.firebase_uid, u.name, u.email, u.role, 
-			   u.school_id, s.name,
-			   u.is_active, u.is_approved,
-			   u.created_at, u.updated_at
+	from := `
 		FROM users u
 		LEFT JOIN schools s ON u.school_id = s.id
-		WHERE 1=1
+		LEFT JOIN classes c ON u.class_id = c.id
 	`
+	where, args := userListFilterClause(filter)
 
-	if schoolID != nil {
-		query += fmt.Sprintf(" AND u.school_id = $%d", argIndex)
-		args = append(args, *schoolID)
-		argIndex++
+	// Count total users
+	var totalCount int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) `+from+` WHERE `+where, args...).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
 	}
 
-	query += fmt.Sprintf(" ORDER BY u.created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, perPage, (page-1)*perPage)
+	// カーソル指定時は直前のページの最後の行より後ろを取得（大量件数でもOFFSETを使わない）
+	if filter.After != nil {
+		args = append(args, filter.After.SortKey, filter.After.ID)
+		where += fmt.Sprintf(" AND (%s, u.id) %s ($%d::%s, $%d::bigint)", sort.expr, comparison, len(args)-1, sort.sqlType, len(args))
+	}
+
+	query := `
+		SELECT u.id, u.firebase_uid, u.name, u.furigana, u.student_number, u.email, u.role,
+			   u.school_id, s.name, u.grade, u.class_id, c.name,
+			   u.is_active, u.is_approved, u.must_change_password, u.last_login_at,
+			   u.deleted_at, u.created_at, u.updated_at, (` + sort.expr + `)::text
+	` + from + ` WHERE ` + where +
+		fmt.Sprintf(" ORDER BY %s %s, u.id %s", sort.expr, direction, direction)
+
+	// 次のページの有無を判定するため1件多く取得
+	args = append(args, filter.PerPage+1)
+	query += fmt.Sprintf(" LIMIT $%d", len(args))
+	if filter.After == nil {
+		args = append(args, (filter.Page-1)*filter.PerPage)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query users: %w", err)
+		// 改ざんされたカーソルの並び替えキーは項目の型への変換で失敗する（データ例外）
+		var pqErr *pq.Error
+		if filter.After != nil && errors.As(err, &pqErr) && pqErr.Code.Class() == "22" {
+			return nil, 0, nil, fmt.Errorf("%w: %v", repositories.ErrInvalidUserListCursor, err)
+		}
+		return nil, 0, nil, fmt.Errorf("failed to query users: %w", err)
 	}
 	defer rows.Close()
 
 	var users []entities.UserManagement
+	var next *entities.UserListCursor
+	var lastSortKey string
 	for rows.Next() {
 		var user entities.UserManagement
-		var schoolID sql.NullInt64
-		var schoolName sql.NullString
-		
+		var schoolID, classID, grade sql.NullInt64
+		var furigana, schoolName, className sql.NullString
+		var sortKey string
+
 		err := rows.Scan(
 			&user.ID,
 			&user.FirebaseUID,
 			&user.Name,
+			&furigana,
+			&user.StudentNumber,
 			&user.Email,
 			&user.Role,
 			&schoolID,
 			&schoolName,
+			&grade,
+			&classID,
+			&className,
 			&user.IsActive,
 			&user.IsApproved,
+			&user.MustChangePassword,
+			&user.LastLoginAt,
+			&user.DeletedAt,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&sortKey,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+			return nil, 0, nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		if len(users) == filter.PerPage {
+			last := users[len(users)-1]
+			next = &entities.UserListCursor{SortBy: filter.SortBy, Descending: filter.Descending, SortKey: lastSortKey, ID: last.ID}
+			break
 		}
-		
+
 		// NULLチェックしてポインターに変換
 		if schoolID.Valid {
 			schoolIDStr := fmt.Sprintf("%d", schoolID.Int64)
@@ -98,17 +194,38 @@ func (r *adminRepository) GetAllUsers(ctx context.Context, page, perPage int, sc
 		if schoolName.Valid {
 			user.SchoolName = &schoolName.String
 		}
-		
+		if furigana.Valid {
+			user.Furigana = &furigana.String
+		}
+		if grade.Valid {
+			gradeValue := int(grade.Int64)
+			user.Grade = &gradeValue
+		}
+		if classID.Valid {
+			classIDStr := fmt.Sprintf("%d", classID.Int64)
+			user.ClassID = &classIDStr
+		}
+		if className.Valid {
+			user.ClassName = &className.String
+		}
+
 		users = append(users, user)
+		lastSortKey = sortKey
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to iterate users: %w", err)
 	}
 
-	return users, totalCount, nil
+	return users, totalCount, next, nil
 }
 
 func (r *adminRepository) UpdateUserRole(ctx context.Context, userID string, role string, schoolID *string) error {
 	query := `
 		UPDATE users 
-		SET role = $2, school_id = COALESCE($3, school_id), updated_at = NOW()
+		SET role = $2, school_id = COALESCE($3, school_id),
+			-- 教育委員会管理者から外れた場合は管轄も外す
+			district_id = CASE WHEN $2 = 'district_admin' THEN district_id ELSE NULL END,
+			updated_at = NOW()
 		WHERE id::text = $1
 	`
 	
@@ -123,7 +240,11 @@ func (r *adminRepository) UpdateUserRole(ctx context.Context, userID string, rol
 func (r *adminRepository) UpdateUserStatus(ctx context.Context, userID string, isActive, isApproved bool) error {
 	query := `
 		UPDATE users 
-		SET is_active = $2, is_approved = $3, updated_at = NOW()
+		SET is_active = $2, is_approved = $3,
+			-- 承認した場合は承認待ちから外し、以前の却下理由を消す
+			approval_reviewed_at = CASE WHEN $3 AND NOT is_approved THEN NOW() ELSE approval_reviewed_at END,
+			rejection_reason = CASE WHEN $3 THEN NULL ELSE rejection_reason END,
+			updated_at = NOW()
 		WHERE id = $1
 	`
 	
@@ -137,29 +258,39 @@ func (r *adminRepository) UpdateUserStatus(ctx context.Context, userID string, i
 
 func (r *adminRepository) GetUserByID(ctx context.Context, userID string) (*entities.UserManagement, error) {
 	query := `
-		SELECT u.id, u.firebase_uid, u.name, u.email, u.role, 
-			   u.school_id, s.name,
-			   u.is_active, u.is_approved,
-			   u.created_at, u.updated_at
+		SELECT u.id, u.firebase_uid, u.name, u.furigana, u.avatar_url, u.student_number, u.email, u.role, 
+			   u.school_id, s.name, u.grade, u.class_id, c.name,
+			   u.is_active, u.is_approved, u.must_change_password, u.last_login_at,
+			   u.deleted_at, u.created_at, u.updated_at
 		FROM users u
 		LEFT JOIN schools s ON u.school_id = s.id
+		LEFT JOIN classes c ON u.class_id = c.id
 		WHERE u.id = $1
 	`
 	
 	var user entities.UserManagement
-	var schoolID sql.NullInt64
-	var schoolName sql.NullString
+	var schoolID, classID, grade sql.NullInt64
+	var schoolName, className sql.NullString
 	
 	err := r.db.QueryRowContext(ctx, query, userID).Scan(
 		&user.ID,
 		&user.FirebaseUID,
 		&user.Name,
+		&user.Furigana,
+		&user.AvatarURL,
+		&user.StudentNumber,
 		&user.Email,
 		&user.Role,
 		&schoolID,
 		&schoolName,
+		&grade,
+		&classID,
+		&className,
 		&user.IsActive,
 		&user.IsApproved,
+		&user.MustChangePassword,
+		&user.LastLoginAt,
+		&user.DeletedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -172,6 +303,17 @@ func (r *adminRepository) GetUserByID(ctx context.Context, userID string) (*enti
 	if schoolName.Valid {
 		user.SchoolName = &schoolName.String
 	}
+	if grade.Valid {
+		gradeValue := int(grade.Int64)
+		user.Grade = &gradeValue
+	}
+	if classID.Valid {
+		classIDStr := fmt.Sprintf("%d", classID.Int64)
+		user.ClassID = &classIDStr
+	}
+	if className.Valid {
+		user.ClassName = &className.String
+	}
 	
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -183,10 +325,64 @@ func (r *adminRepository) GetUserByID(ctx context.Context, userID string) (*enti
 	return &user, nil
 }
 
+// activeAccountExpr ログイン可能なアカウントか（削除済みのユーザー・学校は無効として扱う）
+const activeAccountExpr = `(COALESCE(u.is_active, false) AND u.deleted_at IS NULL AND s.deleted_at IS NULL)`
+
+// GetUserByFirebaseUID Firebase UIDからアカウント状態を含むユーザー情報を取得
+func (r *adminRepository) GetUserByFirebaseUID(ctx context.Context, firebaseUID string) (*entities.UserManagement, error) {
+	query := `
+		SELECT u.id, u.firebase_uid, u.name, u.email, u.role, 
+			   u.school_id, s.name,
+			   ` + activeAccountExpr + `, u.is_approved, u.must_change_password, u.last_login_at,
+			   u.created_at, u.updated_at
+		FROM users u
+		LEFT JOIN schools s ON u.school_id = s.id
+		WHERE u.firebase_uid = $1
+	`
+	
+	var user entities.UserManagement
+	var schoolID sql.NullInt64
+	var schoolName sql.NullString
+	
+	err := r.db.QueryRowContext(ctx, query, firebaseUID).Scan(
+		&user.ID,
+		&user.FirebaseUID,
+		&user.Name,
+		&user.Email,
+		&user.Role,
+		&schoolID,
+		&schoolName,
+		&user.IsActive,
+		&user.IsApproved,
+		&user.MustChangePassword,
+		&user.LastLoginAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: firebase_uid=%s", repositories.ErrUserNotFound, firebaseUID)
+		}
+		return nil, fmt.Errorf("failed to get user by firebase uid: %w", err)
+	}
+	
+	// NULLチェックしてポインターに変換
+	if schoolID.Valid {
+		schoolIDStr := fmt.Sprintf("%d", schoolID.Int64)
+		user.SchoolID = &schoolIDStr
+	}
+	if schoolName.Valid {
+		user.SchoolName = &schoolName.String
+	}
+	
+	return &user, nil
+}
+
 func (r *adminRepository) GetAllSchools(ctx context.Context) ([]entities.SchoolOption, error) {
 	query := `
 		SELECT id, name, code
 		FROM schools
+		WHERE deleted_at IS NULL
 		ORDER BY name
 	`
 	
@@ -216,11 +412,12 @@ func (r *adminRepository) GetUserStatsByRole(ctx context.Context, schoolID *stri
 	roleQuery := `
 		SELECT role, COUNT(*) as count
 		FROM users
+		WHERE deleted_at IS NULL
 	`
 	args := []interface{}{}
 	
 	if schoolID != nil {
-		roleQuery += " WHERE school_id = $1"
+		roleQuery += " AND school_id = $1"
 		args = append(args, *schoolID)
 	}
 	
@@ -250,7 +447,7 @@ func (r *adminRepository) GetUserStatsByRole(ctx context.Context, schoolID *stri
 	stats["total_users"] = totalUsers
 	
 	// 学校統計を取得
-	schoolQuery := `SELECT COUNT(*) FROM schools`
+	schoolQuery := `SELECT COUNT(*) FROM schools WHERE deleted_at IS NULL`
 	var totalSchools int
 	err = r.db.QueryRowContext(ctx, schoolQuery).Scan(&totalSchools)
 	if err != nil {
@@ -270,15 +467,252 @@ func (r *adminRepository) GetUserStatsByRole(ctx context.Context, schoolID *stri
 }
 
 // CreateUserInvitation inserts a user invitation into user_invitations table
-func (r *adminRepository) CreateUserInvitation(ctx context.Context, name, email, role, schoolID, message, token string, expiresAt time.Time) error {
+func (r *adminRepository) CreateUserInvitation(ctx context.Context, name, email, role, schoolID, message, token string, expiresAt time.Time) (*entities.UserInvitation, error) {
     // schoolID is string; convert to SQL parameter as-is since column is BIGINT
     query := `
         INSERT INTO user_invitations (name, email, role, school_id, message, status, token, expires_at, created_at, updated_at)
         VALUES ($1, $2, $3, $4::bigint, $5, 'pending', $6, $7, NOW(), NOW())
+        RETURNING ` + invitationColumns
+    invitation, err := scanInvitation(r.db.QueryRowContext(ctx, query, name, email, role, schoolID, message, token, expiresAt))
+    if err != nil {
+        return nil, fmt.Errorf("failed to create user invitation: %w", err)
+    }
+    return invitation, nil
+}
+
+// invitationColumns user_invitationsの取得カラム（scanInvitationと順序を合わせる）
+const invitationColumns = `id, name, email, role, school_id, message, status, token, expires_at, created_at, updated_at`
+
+type rowScanner interface {
+    Scan(dest ...interface{}) error
+}
+
+// scanInvitation 招待レコードを構造体に読み込む
+func scanInvitation(row rowScanner) (*entities.UserInvitation, error) {
+    var invitation entities.UserInvitation
+    var id, schoolID int64
+    var message sql.NullString
+    err := row.Scan(
+        &id,
+        &invitation.Name,
+        &invitation.Email,
+        &invitation.Role,
+        &schoolID,
+        &message,
+        &invitation.Status,
+        &invitation.Token,
+        &invitation.ExpiresAt,
+        &invitation.CreatedAt,
+        &invitation.UpdatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    invitation.ID = fmt.Sprintf("%d", id)
+    invitation.SchoolID = fmt.Sprintf("%d", schoolID)
+    if message.Valid {
+        invitation.Message = message.String
+    }
+    return &invitation, nil
+}
+
+// GetInvitationByToken 招待トークンから招待を取得
+func (r *adminRepository) GetInvitationByToken(ctx context.Context, token string) (*entities.UserInvitation, error) {
+    query := `SELECT ` + invitationColumns + ` FROM user_invitations WHERE token = $1`
+    invitation, err := scanInvitation(r.db.QueryRowContext(ctx, query, token))
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil, fmt.Errorf("invitation not found")
+        }
+        return nil, fmt.Errorf("failed to get invitation by token: %w", err)
+    }
+    return invitation, nil
+}
+
+// GetInvitationByID 招待IDから招待を取得
+func (r *adminRepository) GetInvitationByID(ctx context.Context, invitationID string) (*entities.UserInvitation, error) {
+    query := `SELECT ` + invitationColumns + ` FROM user_invitations WHERE id::text = $1`
+    invitation, err := scanInvitation(r.db.QueryRowContext(ctx, query, invitationID))
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil, fmt.Errorf("invitation not found with id: %s", invitationID)
+        }
+        return nil, fmt.Errorf("failed to get invitation by id: %w", err)
+    }
+    return invitation, nil
+}
+
+// GetPendingInvitationByEmail メールアドレス宛ての有効な招待を取得（該当なしの場合はnil）
+func (r *adminRepository) GetPendingInvitationByEmail(ctx context.Context, email string) (*entities.UserInvitation, error) {
+    query := `SELECT ` + invitationColumns + ` FROM user_invitations
+        WHERE LOWER(email) = LOWER($1) AND status = 'pending' AND expires_at > NOW()
+        ORDER BY created_at DESC
+        LIMIT 1`
+    invitation, err := scanInvitation(r.db.QueryRowContext(ctx, query, email))
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil, nil
+        }
+        return nil, fmt.Errorf("failed to get pending invitation by email: %w", err)
+    }
+    return invitation, nil
+}
+
+// ListInvitations 学校・ステータスで絞り込んだ招待一覧を取得
+func (r *adminRepository) ListInvitations(ctx context.Context, page, perPage int, schoolID *string, status *string) ([]entities.UserInvitation, int, error) {
+    where := " WHERE 1=1"
+    args := []interface{}{}
+    argIndex := 1
+
+    if schoolID != nil {
+        where += fmt.Sprintf(" AND school_id = $%d::bigint", argIndex)
+        args = append(args, *schoolID)
+        argIndex++
+    }
+    if status != nil {
+        where += fmt.Sprintf(" AND status = $%d", argIndex)
+        args = append(args, *status)
+        argIndex++
+    }
+
+    var totalCount int
+    if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_invitations`+where, args...).Scan(&totalCount); err != nil {
+        return nil, 0, fmt.Errorf("failed to count invitations: %w", err)
+    }
+
+    query := `SELECT ` + invitationColumns + ` FROM user_invitations` + where +
+        fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+    args = append(args, perPage, (page-1)*perPage)
+
+    rows, err := r.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, 0, fmt.Errorf("failed to query invitations: %w", err)
+    }
+    defer rows.Close()
+
+    invitations := []entities.UserInvitation{}
+    for rows.Next() {
+        invitation, err := scanInvitation(rows)
+        if err != nil {
+            return nil, 0, fmt.Errorf("failed to scan invitation: %w", err)
+        }
+        invitations = append(invitations, *invitation)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, 0, fmt.Errorf("error reading invitations: %w", err)
+    }
+
+    return invitations, totalCount, nil
+}
+
+// UpdateInvitationStatus 招待ステータスを更新（pendingの招待のみ対象）
+func (r *adminRepository) UpdateInvitationStatus(ctx context.Context, invitationID string, status string) error {
+    query := `
+        UPDATE user_invitations
+        SET status = $2, updated_at = NOW()
+        WHERE id::text = $1 AND status = 'pending'
     `
-    _, err := r.db.ExecContext(ctx, query, name, email, role, schoolID, message, token, expiresAt)
+    result, err := r.db.ExecContext(ctx, query, invitationID, status)
     if err != nil {
-        return fmt.Errorf("failed to create user invitation: %w", err)
+        return fmt.Errorf("failed to update invitation status: %w", err)
+    }
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("failed to get rows affected: %w", err)
+    }
+    if rowsAffected == 0 {
+        return fmt.Errorf("pending invitation not found with id: %s", invitationID)
     }
     return nil
 }
+
+// ExpireInvitations 有効期限切れのpending招待をexpiredに更新
+func (r *adminRepository) ExpireInvitations(ctx context.Context) (int64, error) {
+    result, err := r.db.ExecContext(ctx, `
+        UPDATE user_invitations
+        SET status = 'expired', updated_at = NOW()
+        WHERE status = 'pending' AND expires_at < NOW()
+    `)
+    if err != nil {
+        return 0, fmt.Errorf("failed to expire invitations: %w", err)
+    }
+    return result.RowsAffected()
+}
+
+// RegenerateInvitationToken pendingの招待のトークンと有効期限を再発行する（取り消し・承諾済みの招待は対象外）
+func (r *adminRepository) RegenerateInvitationToken(ctx context.Context, invitationID, token string, expiresAt time.Time) (*entities.UserInvitation, error) {
+    query := `
+        UPDATE user_invitations
+        SET token = $2, expires_at = $3, updated_at = NOW()
+        WHERE id::text = $1 AND status = 'pending'
+        RETURNING ` + invitationColumns
+    invitation, err := scanInvitation(r.db.QueryRowContext(ctx, query, invitationID, token, expiresAt))
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil, fmt.Errorf("resendable invitation not found with id: %s", invitationID)
+        }
+        return nil, fmt.Errorf("failed to regenerate invitation token: %w", err)
+    }
+    return invitation, nil
+}
+
+// AcceptInvitation 招待に基づくユーザー作成と招待の承諾を1トランザクションで実行
+func (r *adminRepository) AcceptInvitation(ctx context.Context, invitationID string, user *entities.User) (*entities.User, error) {
+    err := r.db.WithTx(ctx, func(tx database.Executor) error {
+        // 同時登録を防ぐため招待行をロック
+        var status string
+        var expiresAt time.Time
+        err := tx.QueryRowContext(ctx, `
+            SELECT status, expires_at FROM user_invitations WHERE id::text = $1 FOR UPDATE
+        `, invitationID).Scan(&status, &expiresAt)
+        if err != nil {
+            if err == sql.ErrNoRows {
+                return fmt.Errorf("invitation not found with id: %s", invitationID)
+            }
+            return fmt.Errorf("failed to lock invitation: %w", err)
+        }
+        if status != "pending" {
+            return fmt.Errorf("invitation is no longer valid")
+        }
+        if time.Now().After(expiresAt) {
+            return fmt.Errorf("invitation has expired")
+        }
+
+        // 名簿から取り込み済みのユーザーは仮UIDを置き換えて紐付ける
+        err = tx.QueryRowContext(ctx, `
+            UPDATE users
+            SET firebase_uid = $1, password_hash = COALESCE(NULLIF($3, ''), password_hash), updated_at = NOW()
+            WHERE LOWER(email) = LOWER($2) AND firebase_uid LIKE $4
+            RETURNING id, name, role, school_id::text, created_at, updated_at
+        `, user.FirebaseUID, user.Email, user.PasswordHash, entities.ImportedUIDPrefix+"%").Scan(
+            &user.ID, &user.DisplayName, &user.Role, &user.SchoolID, &user.CreatedAt, &user.UpdatedAt)
+        if err != nil && err != sql.ErrNoRows {
+            return fmt.Errorf("failed to link imported user: %w", err)
+        }
+
+        // 招待済みユーザーは承認済みとして作成
+        if err == sql.ErrNoRows {
+            err = tx.QueryRowContext(ctx, `
+                INSERT INTO users (firebase_uid, name, email, role, school_id, password_hash, is_active, is_approved, created_at, updated_at)
+                VALUES ($1, $2, $3, $4, $5::bigint, NULLIF($6, ''), true, true, NOW(), NOW())
+                RETURNING id, created_at, updated_at
+            `, user.FirebaseUID, user.DisplayName, user.Email, user.Role, user.SchoolID, user.PasswordHash).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+            if err != nil {
+                return fmt.Errorf("failed to create user: %w", err)
+            }
+        }
+
+        if _, err := tx.ExecContext(ctx, `
+            UPDATE user_invitations SET status = 'accepted', updated_at = NOW() WHERE id::text = $1
+        `, invitationID); err != nil {
+            return fmt.Errorf("failed to accept invitation: %w", err)
+        }
+
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return user, nil
+}
@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+)
+
+// TestUserSortColumnsHaveExpressions 並び替えに使える項目ごとに式が定義されていることを確認
+func TestUserSortColumnsHaveExpressions(t *testing.T) {
+	for _, column := range entities.UserSortColumns {
+		if _, ok := userSortColumns[column]; !ok {
+			t.Errorf("sort column %q has no expression", column)
+		}
+	}
+	if len(userSortColumns) != len(entities.UserSortColumns) {
+		t.Errorf("userSortColumns has %d columns, entities.UserSortColumns has %d", len(userSortColumns), len(entities.UserSortColumns))
+	}
+}
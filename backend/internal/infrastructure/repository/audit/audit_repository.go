@@ -0,0 +1,245 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	domainaudit "github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+type auditRepository struct {
+	db *database.DB
+}
+
+func NewAuditRepository(db *database.DB) repositories.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+const auditEventColumns = `id::text, school_id::text, actor_id::text, actor_uid, actor_role, action, target_type, target_id,
+	changes, request_id, ip_address, prev_hash, hash, created_at, impersonator_id::text, impersonator_uid`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAuditEvent(row rowScanner) (*entities.AuditEvent, error) {
+	var event entities.AuditEvent
+	var schoolID, actorID, impersonatorID sql.NullString
+	var changes []byte
+	err := row.Scan(
+		&event.ID,
+		&schoolID,
+		&actorID,
+		&event.ActorUID,
+		&event.ActorRole,
+		&event.Action,
+		&event.TargetType,
+		&event.TargetID,
+		&changes,
+		&event.RequestID,
+		&event.IPAddress,
+		&event.PrevHash,
+		&event.Hash,
+		&event.CreatedAt,
+		&impersonatorID,
+		&event.ImpersonatorUID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if schoolID.Valid {
+		event.SchoolID = &schoolID.String
+	}
+	if actorID.Valid {
+		event.ActorID = &actorID.String
+	}
+	if impersonatorID.Valid {
+		event.ImpersonatorID = &impersonatorID.String
+	}
+	if len(changes) > 0 {
+		if err := json.Unmarshal(changes, &event.Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode audit changes: %w", err)
+		}
+	}
+	return &event, nil
+}
+
+func (r *auditRepository) Append(ctx context.Context, event *entities.AuditEvent) error {
+	var changes interface{}
+	if len(event.Changes) > 0 {
+		data, err := json.Marshal(event.Changes)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit changes: %w", err)
+		}
+		changes = string(data)
+	}
+
+	return r.db.WithTx(ctx, func(tx database.Executor) error {
+		// 同じチェーンへの同時追記で分岐しないよう、チェーン単位でロックする
+		if _, err := tx.ExecContext(ctx, `
+			SELECT pg_advisory_xact_lock(hashtext('audit_events'), COALESCE($1::bigint, 0)::int)
+		`, event.SchoolID); err != nil {
+			return fmt.Errorf("failed to lock audit chain: %w", err)
+		}
+
+		var prevHash string
+		err := tx.QueryRowContext(ctx, `
+			SELECT hash FROM audit_events
+			WHERE school_id IS NOT DISTINCT FROM $1::bigint
+			ORDER BY id DESC
+			LIMIT 1
+		`, event.SchoolID).Scan(&prevHash)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to get previous audit hash: %w", err)
+		}
+
+		// DBの精度（マイクロ秒）に揃えてからハッシュを計算し、読み戻した値で再計算できるようにする
+		event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		event.PrevHash = prevHash
+		hash, err := domainaudit.ComputeHash(prevHash, event)
+		if err != nil {
+			return err
+		}
+		event.Hash = hash
+
+		query := `
+			INSERT INTO audit_events (
+				school_id, actor_id, actor_uid, actor_role, action, target_type, target_id,
+				changes, request_id, ip_address, prev_hash, hash, created_at,
+				impersonator_id, impersonator_uid
+			) VALUES ($1::bigint, $2::bigint, $3, $4, $5, $6, $7, $8::jsonb, $9, $10, $11, $12, $13, $14::bigint, $15)
+			RETURNING id::text
+		`
+		if err := tx.QueryRowContext(ctx, query,
+			event.SchoolID,
+			event.ActorID,
+			event.ActorUID,
+			event.ActorRole,
+			event.Action,
+			event.TargetType,
+			event.TargetID,
+			changes,
+			event.RequestID,
+			event.IPAddress,
+			event.PrevHash,
+			event.Hash,
+			event.CreatedAt,
+			event.ImpersonatorID,
+			event.ImpersonatorUID,
+		).Scan(&event.ID); err != nil {
+			return fmt.Errorf("failed to insert audit event: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *auditRepository) List(ctx context.Context, filter entities.AuditEventFilter, page, perPage int) ([]entities.AuditEvent, int, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.SchoolID != nil {
+		where += fmt.Sprintf(" AND school_id = $%d::bigint", argIndex)
+		args = append(args, *filter.SchoolID)
+		argIndex++
+	}
+	if filter.ActorID != nil {
+		where += fmt.Sprintf(" AND actor_id = $%d::bigint", argIndex)
+		args = append(args, *filter.ActorID)
+		argIndex++
+	}
+	if filter.ImpersonatorID != nil {
+		where += fmt.Sprintf(" AND impersonator_id = $%d::bigint", argIndex)
+		args = append(args, *filter.ImpersonatorID)
+		argIndex++
+	}
+	if filter.Action != nil {
+		where += fmt.Sprintf(" AND action = $%d", argIndex)
+		args = append(args, *filter.Action)
+		argIndex++
+	}
+	if filter.TargetType != nil {
+		where += fmt.Sprintf(" AND target_type = $%d", argIndex)
+		args = append(args, *filter.TargetType)
+		argIndex++
+	}
+	if filter.TargetID != nil {
+		where += fmt.Sprintf(" AND target_id = $%d", argIndex)
+		args = append(args, *filter.TargetID)
+		argIndex++
+	}
+	if filter.From != nil {
+		where += fmt.Sprintf(" AND created_at >= $%d", argIndex)
+		args = append(args, *filter.From)
+		argIndex++
+	}
+	if filter.To != nil {
+		where += fmt.Sprintf(" AND created_at < $%d", argIndex)
+		args = append(args, *filter.To)
+		argIndex++
+	}
+
+	var totalCount int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_events`+where, args...).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	query := `SELECT ` + auditEventColumns + ` FROM audit_events` + where +
+		fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, perPage, (page-1)*perPage)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []entities.AuditEvent{}
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		events = append(events, *event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error reading audit events: %w", err)
+	}
+
+	return events, totalCount, nil
+}
+
+func (r *auditRepository) ListChain(ctx context.Context, schoolID *string, afterID string, limit int) ([]entities.AuditEvent, error) {
+	query := `SELECT ` + auditEventColumns + ` FROM audit_events
+		WHERE school_id IS NOT DISTINCT FROM $1::bigint AND id > $2::bigint
+		ORDER BY id
+		LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit chain: %w", err)
+	}
+	defer rows.Close()
+
+	events := []entities.AuditEvent{}
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		events = append(events, *event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading audit chain: %w", err)
+	}
+
+	return events, nil
+}
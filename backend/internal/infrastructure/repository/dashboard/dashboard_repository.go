@@ -2,18 +2,18 @@ package dashboard
 
 import (
 	"context"
-	"database/sql"
 	"time"
 
 	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
 	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
 )
 
 type dashboardRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
-func NewDashboardRepository(db *sql.DB) repositories.DashboardRepository {
+func NewDashboardRepository(db *database.DB) repositories.DashboardRepository {
 	return &dashboardRepository{db: db}
 }
 
@@ -0,0 +1,144 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+type exportRepository struct {
+	db *database.DB
+}
+
+func NewExportRepository(db *database.DB) repositories.ExportRepository {
+	return &exportRepository{db: db}
+}
+
+// exportSource データごとの取得元と列の対応
+type exportSource struct {
+	from        string
+	schoolScope string // 学校で絞り込む列
+	orderBy     string
+	columns     map[string]string
+}
+
+var exportSources = map[string]exportSource{
+	entities.ExportDatasetUsers: {
+		from: `users u
+			LEFT JOIN schools s ON s.id = u.school_id
+			LEFT JOIN classes c ON c.id = u.class_id`,
+		schoolScope: "u.school_id",
+		orderBy:     "u.id",
+		columns: map[string]string{
+			"id":             "u.id",
+			"name":           "u.name",
+			"furigana":       "u.furigana",
+			"email":          "u.email",
+			"role":           "u.role",
+			"school_id":      "u.school_id",
+			"school_name":    "s.name",
+			"class_id":       "u.class_id",
+			"class_name":     "c.name",
+			"student_number": "u.student_number",
+			"grade":          "u.grade",
+			"is_active":      "u.is_active",
+			"is_approved":    "u.is_approved",
+			"last_login_at":  "u.last_login_at",
+			"created_at":     "u.created_at",
+			"guardian_name":  "u.guardian_name_encrypted",
+			"guardian_phone": "u.guardian_phone_encrypted",
+			"guardian_email": "u.guardian_email_encrypted",
+		},
+	},
+	entities.ExportDatasetClasses: {
+		from:        "classes c",
+		schoolScope: "c.school_id",
+		orderBy:     "c.id",
+		columns: map[string]string{
+			"id":                  "c.id",
+			"school_id":           "c.school_id",
+			"name":                "c.name",
+			"grade":               "c.grade",
+			"academic_year":       "c.academic_year",
+			"homeroom_teacher_id": "c.homeroom_teacher_id",
+			"sub_teacher_id":      "c.sub_teacher_id",
+			"max_students":        "c.max_students",
+			"current_students":    "c.current_students",
+			"classroom":           "c.classroom",
+			"is_active":           "c.is_active",
+			"created_at":          "c.created_at",
+		},
+	},
+	entities.ExportDatasetSchools: {
+		from:        "schools s",
+		schoolScope: "s.id",
+		orderBy:     "s.id",
+		columns: map[string]string{
+			"id":                  "s.id",
+			"name":                "s.name",
+			"code":                "s.code",
+			"email_domain":        "s.email_domain",
+			"address":             "s.address",
+			"phone_number":        "s.phone_number",
+			"principal_name":      "s.principal_name",
+			"vice_principal_name": "s.vice_principal_name",
+			"student_capacity":    "s.student_capacity",
+			"academic_year_start": "s.academic_year_start",
+			"is_active":           "s.is_active",
+			"created_at":          "s.created_at",
+		},
+	},
+}
+
+func (r *exportRepository) StreamRows(ctx context.Context, dataset string, columns []string, schoolID *string, fn func(values []interface{}) error) error {
+	source, ok := exportSources[dataset]
+	if !ok {
+		return fmt.Errorf("unknown export dataset: %s", dataset)
+	}
+
+	expressions := make([]string, len(columns))
+	for i, column := range columns {
+		expression, ok := source.columns[column]
+		if !ok {
+			return fmt.Errorf("unknown export column: %s", column)
+		}
+		expressions[i] = expression
+	}
+
+	query := `SELECT ` + strings.Join(expressions, ", ") + ` FROM ` + source.from
+	args := []interface{}{}
+	if schoolID != nil {
+		query += ` WHERE ` + source.schoolScope + ` = $1::bigint`
+		args = append(args, *schoolID)
+	}
+	query += ` ORDER BY ` + source.orderBy
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query %s for export: %w", dataset, err)
+	}
+	defer rows.Close()
+
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return fmt.Errorf("failed to scan %s for export: %w", dataset, err)
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading %s for export: %w", dataset, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,332 @@
+package record
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+// attendanceStatusLabels 出欠ステータスの表示名
+var attendanceStatusLabels = map[string]string{
+	"absent":   "欠席",
+	"late":     "遅刻",
+	"sick":     "病欠",
+	"official": "公欠",
+}
+
+type recordRepository struct {
+	db *database.DB
+}
+
+func NewRecordRepository(db *database.DB) repositories.StudentRecordRepository {
+	return &recordRepository{db: db}
+}
+
+// visibleStudentCondition 閲覧者のロールに応じた絞り込み条件を生成
+// 生徒をstu（users）、授業をc（courses）として参照するクエリで使用する
+func visibleStudentCondition(viewer policy.Subject, argIndex int) (string, []interface{}) {
+	switch viewer.Role {
+	case policy.RoleAdmin:
+		return "TRUE", nil
+	case policy.RoleSchoolAdmin:
+		if viewer.SchoolID == "" {
+			return "FALSE", nil
+		}
+		return fmt.Sprintf("stu.school_id::text = $%d", argIndex), []interface{}{viewer.SchoolID}
+	case policy.RoleDistrictAdmin:
+		if len(viewer.DistrictSchoolIDs) == 0 {
+			return "FALSE", nil
+		}
+		return fmt.Sprintf("stu.school_id::text = ANY($%d)", argIndex), []interface{}{pq.Array(viewer.DistrictSchoolIDs)}
+	case policy.RoleTeacher:
+		if viewer.UserID == "" {
+			return "FALSE", nil
+		}
+		// 担当授業の記録、または担任クラスの生徒の記録
+		return fmt.Sprintf(`(
+			c.teacher_id IN (SELECT t.id FROM teachers t WHERE t.user_id::text = $%[1]d)
+			OR stu.class_id IN (
+				SELECT cl.id FROM classes cl
+				JOIN teachers t ON cl.homeroom_teacher_id = t.id
+				WHERE t.user_id::text = $%[1]d
+			)
+		)`, argIndex), []interface{}{viewer.UserID}
+	case policy.RoleStudent:
+		if viewer.UserID == "" {
+			return "FALSE", nil
+		}
+		return fmt.Sprintf("stu.id::text = $%d", argIndex), []interface{}{viewer.UserID}
+	case policy.RoleGuardian:
+		if viewer.UserID == "" {
+			return "FALSE", nil
+		}
+		return fmt.Sprintf(
+			"stu.id IN (SELECT gs.student_id FROM guardian_students gs WHERE gs.guardian_id::text = $%d)",
+			argIndex,
+		), []interface{}{viewer.UserID}
+	default:
+		return "FALSE", nil
+	}
+}
+
+func (r *recordRepository) ListGrades(ctx context.Context, viewer policy.Subject, studentID string) ([]entities.Grade, error) {
+	condition, args := visibleStudentCondition(viewer, 2)
+	query := fmt.Sprintf(`
+		SELECT g.id, g.student_id, stu.name, g.course_id, c.course_name, g.assignment_id,
+			   g.grade_type, g.points, g.max_points, COALESCE(g.percentage, 0),
+			   g.semester, g.academic_year, g.graded_at
+		FROM grades g
+		JOIN users stu ON g.student_id = stu.id
+		JOIN courses c ON g.course_id = c.id
+		WHERE g.student_id::text = $1 AND %s
+		ORDER BY g.academic_year DESC, g.semester DESC, g.graded_at DESC
+	`, condition)
+
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{studentID}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query grades: %w", err)
+	}
+	defer rows.Close()
+
+	grades := []entities.Grade{}
+	for rows.Next() {
+		var grade entities.Grade
+		var assignmentID sql.NullInt64
+		if err := rows.Scan(
+			&grade.ID,
+			&grade.StudentID,
+			&grade.StudentName,
+			&grade.CourseID,
+			&grade.CourseName,
+			&assignmentID,
+			&grade.GradeType,
+			&grade.Points,
+			&grade.MaxPoints,
+			&grade.Percentage,
+			&grade.Semester,
+			&grade.AcademicYear,
+			&grade.GradedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan grade: %w", err)
+		}
+		if assignmentID.Valid {
+			grade.AssignmentID = &assignmentID.Int64
+		}
+		grades = append(grades, grade)
+	}
+
+	return grades, rows.Err()
+}
+
+func (r *recordRepository) ListAttendance(ctx context.Context, viewer policy.Subject, studentID string, from, to *time.Time) ([]entities.AttendanceRecord, error) {
+	args := []interface{}{studentID}
+	argIndex := 2
+
+	dateCondition := ""
+	if from != nil {
+		dateCondition += fmt.Sprintf(" AND a.attendance_date >= $%d", argIndex)
+		args = append(args, *from)
+		argIndex++
+	}
+	if to != nil {
+		dateCondition += fmt.Sprintf(" AND a.attendance_date <= $%d", argIndex)
+		args = append(args, *to)
+		argIndex++
+	}
+
+	condition, visibilityArgs := visibleStudentCondition(viewer, argIndex)
+	args = append(args, visibilityArgs...)
+
+	query := fmt.Sprintf(`
+		SELECT a.id, a.student_id, stu.name, a.course_id, c.course_name,
+			   a.attendance_date, a.period, a.status, a.reason, a.recorded_at
+		FROM attendance a
+		JOIN users stu ON a.student_id = stu.id
+		JOIN courses c ON a.course_id = c.id
+		WHERE a.student_id::text = $1%s AND %s
+		ORDER BY a.attendance_date DESC, a.period
+	`, dateCondition, condition)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attendance: %w", err)
+	}
+	defer rows.Close()
+
+	records := []entities.AttendanceRecord{}
+	for rows.Next() {
+		var record entities.AttendanceRecord
+		var reason sql.NullString
+		if err := rows.Scan(
+			&record.ID,
+			&record.StudentID,
+			&record.StudentName,
+			&record.CourseID,
+			&record.CourseName,
+			&record.AttendanceDate,
+			&record.Period,
+			&record.Status,
+			&reason,
+			&record.RecordedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan attendance: %w", err)
+		}
+		if reason.Valid {
+			record.Reason = &reason.String
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// ListNotifications 閲覧可能な生徒の成績登録・欠席等を新しい順に通知として取得
+func (r *recordRepository) ListNotifications(ctx context.Context, viewer policy.Subject, limit int) ([]entities.Notification, error) {
+	condition, args := visibleStudentCondition(viewer, 2)
+	query := fmt.Sprintf(`
+		SELECT id, kind, student_id, school_id, student_name, course_name, detail, occurred_at
+		FROM (
+			SELECT g.id, 'grade' AS kind, stu.id AS student_id, stu.school_id, stu.name AS student_name,
+				   c.course_name, g.points || '/' || g.max_points AS detail, g.graded_at AS occurred_at
+			FROM grades g
+			JOIN users stu ON g.student_id = stu.id
+			JOIN courses c ON g.course_id = c.id
+			WHERE %[1]s
+			UNION ALL
+			SELECT a.id, 'attendance', stu.id, stu.school_id, stu.name,
+				   c.course_name, a.status, a.recorded_at
+			FROM attendance a
+			JOIN users stu ON a.student_id = stu.id
+			JOIN courses c ON a.course_id = c.id
+			WHERE a.status <> 'present' AND %[1]s
+		) events
+		ORDER BY occurred_at DESC
+		LIMIT $1
+	`, condition)
+
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{limit}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []entities.Notification{}
+	for rows.Next() {
+		var (
+			notification entities.Notification
+			studentName  string
+			courseName   string
+			detail       string
+		)
+		if err := rows.Scan(
+			&notification.ID,
+			&notification.Type,
+			&notification.UserID,
+			&notification.SchoolID,
+			&studentName,
+			&courseName,
+			&detail,
+			&notification.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+
+		switch notification.Type {
+		case "grade":
+			notification.Title = "成績が登録されました"
+			notification.Message = fmt.Sprintf("%sさんの%sの成績: %s", studentName, courseName, detail)
+		case "attendance":
+			notification.Title = "出欠が記録されました"
+			notification.Message = fmt.Sprintf("%sさんの%s: %s", studentName, courseName, attendanceStatusLabels[detail])
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (r *recordRepository) ListGuardianStudents(ctx context.Context, guardianID string) ([]entities.GuardianStudent, error) {
+	query := `
+		SELECT gs.guardian_id, gs.student_id, stu.name, stu.school_id, stu.class_id,
+			   gs.relationship, gs.created_at
+		FROM guardian_students gs
+		JOIN users stu ON gs.student_id = stu.id
+		WHERE gs.guardian_id::text = $1
+		ORDER BY stu.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, guardianID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query guardian students: %w", err)
+	}
+	defer rows.Close()
+
+	students := []entities.GuardianStudent{}
+	for rows.Next() {
+		var (
+			link         entities.GuardianStudent
+			classID      sql.NullString
+			relationship sql.NullString
+		)
+		if err := rows.Scan(
+			&link.GuardianID,
+			&link.StudentID,
+			&link.StudentName,
+			&link.SchoolID,
+			&classID,
+			&relationship,
+			&link.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan guardian student: %w", err)
+		}
+		if classID.Valid {
+			link.ClassID = &classID.String
+		}
+		if relationship.Valid {
+			link.Relationship = &relationship.String
+		}
+		students = append(students, link)
+	}
+
+	return students, rows.Err()
+}
+
+func (r *recordRepository) LinkGuardianStudent(ctx context.Context, guardianID, studentID string, relationship *string) error {
+	query := `
+		INSERT INTO guardian_students (guardian_id, student_id, relationship)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (guardian_id, student_id) DO UPDATE SET relationship = EXCLUDED.relationship
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, guardianID, studentID, relationship); err != nil {
+		return fmt.Errorf("failed to link guardian and student: %w", err)
+	}
+
+	return nil
+}
+
+func (r *recordRepository) UnlinkGuardianStudent(ctx context.Context, guardianID, studentID string) error {
+	query := `DELETE FROM guardian_students WHERE guardian_id::text = $1 AND student_id::text = $2`
+
+	result, err := r.db.ExecContext(ctx, query, guardianID, studentID)
+	if err != nil {
+		return fmt.Errorf("failed to unlink guardian and student: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("guardian link not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+)
+
+const loginFailuresKeyPrefix = "login_failures:"
+
+type loginAttemptRepository struct {
+	client *redis.Client
+}
+
+func NewLoginAttemptRepository(client *redis.Client) repositories.LoginAttemptRepository {
+	return &loginAttemptRepository{client: client}
+}
+
+func (r *loginAttemptRepository) GetFailures(ctx context.Context, key string) (int, error) {
+	count, err := r.client.Get(ctx, loginFailuresKeyPrefix+key).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get login failures: %w", err)
+	}
+	return count, nil
+}
+
+func (r *loginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, loginFailuresKeyPrefix+key)
+		// 最初の失敗時のみ期限を設定（失敗を続けてもロック期間は延びない）
+		pipe.ExpireNX(ctx, loginFailuresKeyPrefix+key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return int(incr.Val()), nil
+}
+
+func (r *loginAttemptRepository) ResetFailures(ctx context.Context, key string) error {
+	if err := r.client.Del(ctx, loginFailuresKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+	return nil
+}
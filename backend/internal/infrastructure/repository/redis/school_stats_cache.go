@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+)
+
+const schoolStatsKeyPrefix = "stats:school:"
+
+type schoolStatsCache struct {
+	client *redis.Client
+}
+
+func NewSchoolStatsCache(client *redis.Client) repositories.SchoolStatsCache {
+	return &schoolStatsCache{client: client}
+}
+
+func schoolStatsKey(schoolID, from, to string) string {
+	return schoolStatsKeyPrefix + schoolID + ":" + from + ":" + to
+}
+
+// schoolStatsIndexKey 学校ごとにキャッシュした集計期間のキーの一覧（まとめて無効化するため）
+func schoolStatsIndexKey(schoolID string) string {
+	return schoolStatsKeyPrefix + schoolID + ":keys"
+}
+
+func (c *schoolStatsCache) Get(ctx context.Context, schoolID, from, to string) (*entities.SchoolStats, error) {
+	data, err := c.client.Get(ctx, schoolStatsKey(schoolID, from, to)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get school stats cache: %w", err)
+	}
+
+	var stats entities.SchoolStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("failed to decode school stats cache: %w", err)
+	}
+	return &stats, nil
+}
+
+func (c *schoolStatsCache) Set(ctx context.Context, stats *entities.SchoolStats, ttl time.Duration) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("failed to encode school stats cache: %w", err)
+	}
+
+	key := schoolStatsKey(stats.SchoolID, stats.Period.From, stats.Period.To)
+	indexKey := schoolStatsIndexKey(stats.SchoolID)
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, data, ttl)
+		pipe.SAdd(ctx, indexKey, key)
+		pipe.Expire(ctx, indexKey, ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set school stats cache: %w", err)
+	}
+	return nil
+}
+
+func (c *schoolStatsCache) Invalidate(ctx context.Context, schoolID string) error {
+	indexKey := schoolStatsIndexKey(schoolID)
+	keys, err := c.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list school stats cache keys: %w", err)
+	}
+
+	if err := c.client.Del(ctx, append(keys, indexKey)...).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate school stats cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+)
+
+const (
+	sessionKeyPrefix        = "session:"
+	userSessionsKeyPrefix   = "sessions:user:"
+	schoolSessionsKeyPrefix = "sessions:school:"
+)
+
+// storedSession Redisに保存する形式（APIレスポンスに含めないリフレッシュトークンのハッシュを保持）
+type storedSession struct {
+	entities.Session
+	RefreshTokenHash string `json:"refresh_token_hash"`
+}
+
+type sessionRepository struct {
+	client *redis.Client
+}
+
+func NewSessionRepository(client *redis.Client) repositories.SessionRepository {
+	return &sessionRepository{client: client}
+}
+
+func sessionKey(sessionID string) string {
+	return sessionKeyPrefix + sessionID
+}
+
+func userSessionsKey(userID string) string {
+	return userSessionsKeyPrefix + userID
+}
+
+func schoolSessionsKey(schoolID string) string {
+	return schoolSessionsKeyPrefix + schoolID
+}
+
+func encodeSession(session *entities.Session) ([]byte, error) {
+	stored := storedSession{Session: *session, RefreshTokenHash: session.RefreshTokenHash}
+	stored.Current = false
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session: %w", err)
+	}
+	return data, nil
+}
+
+func decodeSession(data string) (*entities.Session, error) {
+	var stored storedSession
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	session := stored.Session
+	session.RefreshTokenHash = stored.RefreshTokenHash
+	return &session, nil
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	data, err := encodeSession(session)
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return fmt.Errorf("session already expired")
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sessionKey(session.ID), data, ttl)
+		pipe.SAdd(ctx, userSessionsKey(session.UserID), session.ID)
+		if session.SchoolID != "" {
+			pipe.SAdd(ctx, schoolSessionsKey(session.SchoolID), session.ID)
+		}
+		extendIndexes(ctx, pipe, session, ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) Update(ctx context.Context, session *entities.Session) (bool, error) {
+	data, err := encodeSession(session)
+	if err != nil {
+		return false, err
+	}
+
+	// 失効済みのセッションを復活させないよう、存在する場合のみ上書きする（XX）
+	err = r.client.SetArgs(ctx, sessionKey(session.ID), data, redis.SetArgs{
+		Mode:     "XX",
+		ExpireAt: session.ExpiresAt,
+	}).Err()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	// リフレッシュで有効期限が延びた場合に索引が先に消えないようにする
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		extendIndexes(ctx, pipe, session, time.Until(session.ExpiresAt))
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to extend session indexes: %w", err)
+	}
+
+	return true, nil
+}
+
+// extendIndexes 索引の有効期限を最も長く残るセッションに合わせる
+// NXで未設定の場合に設定し、GTで現在より長い場合のみ延長する（Redis 7以上）
+func extendIndexes(ctx context.Context, pipe redis.Pipeliner, session *entities.Session, ttl time.Duration) {
+	keys := []string{userSessionsKey(session.UserID)}
+	if session.SchoolID != "" {
+		keys = append(keys, schoolSessionsKey(session.SchoolID))
+	}
+	for _, key := range keys {
+		pipe.ExpireNX(ctx, key, ttl)
+		pipe.ExpireGT(ctx, key, ttl)
+	}
+}
+
+func (r *sessionRepository) Get(ctx context.Context, sessionID string) (*entities.Session, error) {
+	data, err := r.client.Get(ctx, sessionKey(sessionID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return decodeSession(data)
+}
+
+// loadSessions 索引のセッションIDからセッションを取得し、期限切れのIDは索引から除去
+func (r *sessionRepository) loadSessions(ctx context.Context, indexKey string) ([]entities.Session, error) {
+	ids, err := r.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session ids: %w", err)
+	}
+
+	sessions := []entities.Session{}
+	if len(ids) == 0 {
+		return sessions, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = sessionKey(id)
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	var stale []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			stale = append(stale, ids[i])
+			continue
+		}
+		session, err := decodeSession(data)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+
+	if len(stale) > 0 {
+		if err := r.client.SRem(ctx, indexKey, stale...).Err(); err != nil {
+			return nil, fmt.Errorf("failed to prune sessions: %w", err)
+		}
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepository) ListByUser(ctx context.Context, userID string) ([]entities.Session, error) {
+	return r.loadSessions(ctx, userSessionsKey(userID))
+}
+
+// deleteSessions セッション本体と各索引からの参照を削除
+func (r *sessionRepository) deleteSessions(ctx context.Context, sessions []entities.Session, indexKeys ...string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, session := range sessions {
+			pipe.Del(ctx, sessionKey(session.ID))
+			pipe.SRem(ctx, userSessionsKey(session.UserID), session.ID)
+			if session.SchoolID != "" {
+				pipe.SRem(ctx, schoolSessionsKey(session.SchoolID), session.ID)
+			}
+		}
+		if len(indexKeys) > 0 {
+			pipe.Del(ctx, indexKeys...)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) Delete(ctx context.Context, sessionID string) error {
+	session, err := r.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return nil
+	}
+
+	return r.deleteSessions(ctx, []entities.Session{*session})
+}
+
+func (r *sessionRepository) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	sessions, err := r.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.deleteSessions(ctx, sessions, userSessionsKey(userID)); err != nil {
+		return 0, err
+	}
+	return len(sessions), nil
+}
+
+func (r *sessionRepository) DeleteBySchool(ctx context.Context, schoolID string) (int, error) {
+	sessions, err := r.loadSessions(ctx, schoolSessionsKey(schoolID))
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.deleteSessions(ctx, sessions, schoolSessionsKey(schoolID)); err != nil {
+		return 0, err
+	}
+	return len(sessions), nil
+}
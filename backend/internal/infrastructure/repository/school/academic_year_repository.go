@@ -0,0 +1,234 @@
+package school
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/user"
+)
+
+type academicYearRepository struct {
+	db *database.DB
+}
+
+func NewAcademicYearRepository(db *database.DB) repositories.AcademicYearRepository {
+	return &academicYearRepository{db: db}
+}
+
+// rolloverStudents 進級・卒業の対象となる生徒（学年は1〜3）
+const rolloverStudents = `role = 'student' AND school_id = $1 AND deleted_at IS NULL AND graduated_at IS NULL`
+
+// 翌年度に同じ名前のクラスがまだないクラス
+const classesToClone = `
+	FROM classes oc
+	WHERE oc.school_id = $1 AND oc.academic_year = $2 AND oc.is_active = true
+	  AND NOT EXISTS (
+		SELECT 1 FROM classes nc
+		WHERE nc.school_id = oc.school_id AND nc.name = oc.name AND nc.academic_year = $2 + 1
+	  )`
+
+// 翌年度の同じ名前のクラスに同じ教科・学期の授業がまだない授業
+const coursesToClone = `
+	FROM courses c
+	JOIN classes oc ON oc.id = c.class_id
+	WHERE oc.school_id = $1 AND oc.academic_year = $2 AND oc.is_active = true
+	  AND c.academic_year = $2 AND c.is_active = true
+	  AND NOT EXISTS (
+		SELECT 1 FROM courses x
+		JOIN classes xc ON xc.id = x.class_id
+		WHERE xc.school_id = oc.school_id AND xc.name = oc.name AND xc.academic_year = $2 + 1
+		  AND x.academic_year = $2 + 1 AND x.subject_id = c.subject_id AND x.semester = c.semester
+	  )`
+
+func (r *academicYearRepository) LatestOpenClassYear(ctx context.Context, schoolID int64) (int, error) {
+	query := `
+		SELECT COALESCE(MAX(academic_year), 0) FROM classes
+		WHERE school_id = $1 AND NOT app_is_archived_year(school_id, academic_year)
+	`
+
+	var year int
+	if err := r.db.QueryRowContext(ctx, query, schoolID).Scan(&year); err != nil {
+		return 0, fmt.Errorf("failed to get latest academic year: %w", err)
+	}
+	return year, nil
+}
+
+func (r *academicYearRepository) IsArchived(ctx context.Context, schoolID int64, academicYear int) (bool, error) {
+	var archived bool
+	if err := r.db.QueryRowContext(ctx, `SELECT app_is_archived_year($1, $2)`, schoolID, academicYear).Scan(&archived); err != nil {
+		return false, fmt.Errorf("failed to check archived academic year: %w", err)
+	}
+	return archived, nil
+}
+
+func (r *academicYearRepository) PreviewRollover(ctx context.Context, schoolID int64, fromYear int) (*entities.AcademicYearRollover, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users WHERE ` + rolloverStudents + ` AND grade < 3),
+			(SELECT COUNT(*) FROM users WHERE ` + rolloverStudents + ` AND grade = 3),
+			(SELECT COUNT(*) FROM users WHERE ` + rolloverStudents + ` AND grade IS NULL),
+			(SELECT COUNT(*) ` + classesToClone + `),
+			(SELECT COUNT(*) ` + coursesToClone + `)
+	`
+
+	result := newRollover(schoolID, fromYear)
+	result.DryRun = true
+	err := r.db.QueryRowContext(ctx, query, schoolID, fromYear).Scan(
+		&result.PromotedStudents,
+		&result.GraduatedStudents,
+		&result.UngradedStudents,
+		&result.ClonedClasses,
+		&result.ClonedCourses,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview academic year rollover: %w", err)
+	}
+	return result, nil
+}
+
+func (r *academicYearRepository) Rollover(ctx context.Context, schoolID int64, fromYear int, archivedBy string) (*entities.AcademicYearRollover, error) {
+	var result *entities.AcademicYearRollover
+	err := r.db.WithTx(ctx, func(tx database.Executor) error {
+		// 同じ学校の年度更新が同時に実行されないよう学校の行をロック
+		if _, err := tx.ExecContext(ctx, `SELECT 1 FROM schools WHERE id = $1 FOR UPDATE`, schoolID); err != nil {
+			return fmt.Errorf("failed to lock school for rollover: %w", err)
+		}
+
+		var archived bool
+		if err := tx.QueryRowContext(ctx, `SELECT app_is_archived_year($1, $2)`, schoolID, fromYear).Scan(&archived); err != nil {
+			return fmt.Errorf("failed to check archived academic year: %w", err)
+		}
+		if archived {
+			return nil
+		}
+
+		rollover := newRollover(schoolID, fromYear)
+
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE `+rolloverStudents+` AND grade IS NULL`, schoolID).Scan(&rollover.UngradedStudents); err != nil {
+			return fmt.Errorf("failed to count ungraded students: %w", err)
+		}
+
+		// クラスと授業の枠組みを翌年度に複製（在籍人数・学級目標は引き継がない）
+		cloned, err := execCount(ctx, tx, `
+			INSERT INTO classes (school_id, name, grade, academic_year, homeroom_teacher_id, sub_teacher_id,
+			                     max_students, classroom, class_color, is_active)
+			SELECT oc.school_id, oc.name, oc.grade, oc.academic_year + 1, oc.homeroom_teacher_id, oc.sub_teacher_id,
+			       oc.max_students, oc.classroom, oc.class_color, true
+		`+classesToClone, schoolID, fromYear)
+		if err != nil {
+			return fmt.Errorf("failed to clone classes: %w", err)
+		}
+		rollover.ClonedClasses = cloned
+
+		cloned, err = execCount(ctx, tx, `
+			INSERT INTO courses (subject_id, class_id, teacher_id, course_name, description, academic_year,
+			                     semester, weekly_hours, classroom, textbook, is_active)
+			SELECT c.subject_id,
+			       (SELECT nc.id FROM classes nc
+			        WHERE nc.school_id = oc.school_id AND nc.name = oc.name AND nc.academic_year = $2 + 1),
+			       c.teacher_id, c.course_name, c.description, $2 + 1,
+			       c.semester, c.weekly_hours, c.classroom, c.textbook, true
+		`+coursesToClone, schoolID, fromYear)
+		if err != nil {
+			return fmt.Errorf("failed to clone courses: %w", err)
+		}
+		rollover.ClonedCourses = cloned
+
+		// 最高学年の生徒は卒業（進級より先に行う）
+		graduated, err := execCount(ctx, tx, `
+			UPDATE users SET is_active = false, graduated_at = NOW(), updated_at = NOW()
+			WHERE `+rolloverStudents+` AND grade = 3
+		`, schoolID)
+		if err != nil {
+			return fmt.Errorf("failed to graduate students: %w", err)
+		}
+		rollover.GraduatedStudents = graduated
+
+		promoted, err := execCount(ctx, tx, `
+			UPDATE users SET grade = grade + 1, updated_at = NOW()
+			WHERE `+rolloverStudents+` AND grade < 3
+		`, schoolID)
+		if err != nil {
+			return fmt.Errorf("failed to promote students: %w", err)
+		}
+		rollover.PromotedStudents = promoted
+
+		// 前年度のクラスの所属を外す（新年度のクラスは改めて割り当てる）
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE users SET class_id = NULL, updated_at = NOW()
+			WHERE class_id IN (SELECT id FROM classes WHERE school_id = $1 AND academic_year = $2)
+		`, schoolID, fromYear); err != nil {
+			return fmt.Errorf("failed to clear previous classes: %w", err)
+		}
+
+		// 前年度のクラスの在籍人数を再集計（卒業・進級の後も人数が残らないように）
+		var previousClasses pq.Int64Array
+		if err := tx.QueryRowContext(ctx, `
+			SELECT COALESCE(array_agg(id), '{}') FROM classes WHERE school_id = $1 AND academic_year = $2
+		`, schoolID, fromYear).Scan(&previousClasses); err != nil {
+			return fmt.Errorf("failed to list previous classes: %w", err)
+		}
+		if err := user.RecountClassStudents(ctx, tx, previousClasses); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE courses SET is_active = false, updated_at = NOW()
+			WHERE academic_year = $2 AND class_id IN (SELECT id FROM classes WHERE school_id = $1 AND academic_year = $2)
+		`, schoolID, fromYear); err != nil {
+			return fmt.Errorf("failed to deactivate courses: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE classes SET is_active = false, updated_at = NOW()
+			WHERE school_id = $1 AND academic_year = $2
+		`, schoolID, fromYear); err != nil {
+			return fmt.Errorf("failed to deactivate classes: %w", err)
+		}
+
+		// 以降はトリガーにより前年度のクラス・授業・成績・ポイントを変更できない
+		summary, err := json.Marshal(rollover)
+		if err != nil {
+			return fmt.Errorf("failed to encode rollover summary: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO academic_year_archives (school_id, academic_year, archived_by, summary)
+			VALUES ($1, $2, NULLIF($3, '')::bigint, $4)
+		`, schoolID, fromYear, archivedBy, string(summary)); err != nil {
+			return fmt.Errorf("failed to archive academic year: %w", err)
+		}
+
+		result = rollover
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func newRollover(schoolID int64, fromYear int) *entities.AcademicYearRollover {
+	return &entities.AcademicYearRollover{
+		SchoolID: strconv.FormatInt(schoolID, 10),
+		FromYear: fromYear,
+		ToYear:   fromYear + 1,
+	}
+}
+
+// execCount 更新を実行して対象の行数を返す
+func execCount(ctx context.Context, tx database.Executor, query string, args ...interface{}) (int, error) {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(rows), nil
+}
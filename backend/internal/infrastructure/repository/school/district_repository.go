@@ -0,0 +1,176 @@
+package school
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+type districtRepository struct {
+	db *database.DB
+}
+
+func NewDistrictRepository(db *database.DB) repositories.DistrictRepository {
+	return &districtRepository{db: db}
+}
+
+const districtColumns = `d.id::text, d.name, d.code, d.prefecture,
+	(SELECT COUNT(*) FROM schools s WHERE s.district_id = d.id AND s.deleted_at IS NULL),
+	d.created_at, d.updated_at`
+
+func scanDistrict(row rowScanner) (*entities.District, error) {
+	var d entities.District
+	var prefecture sql.NullString
+	if err := row.Scan(&d.ID, &d.Name, &d.Code, &prefecture, &d.SchoolCount, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return nil, err
+	}
+	d.Prefecture = nullStringPtr(prefecture)
+	return &d, nil
+}
+
+func (r *districtRepository) CreateDistrict(ctx context.Context, district *entities.District) (*entities.District, error) {
+	query := `
+		INSERT INTO districts (name, code, prefecture, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING id::text, name, code, prefecture, 0, created_at, updated_at
+	`
+
+	created, err := scanDistrict(r.db.QueryRowContext(ctx, query, district.Name, district.Code, district.Prefecture))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create district: %w", err)
+	}
+	return created, nil
+}
+
+func (r *districtRepository) GetDistrictByID(ctx context.Context, districtID string) (*entities.District, error) {
+	query := `SELECT ` + districtColumns + ` FROM districts d WHERE d.id = $1`
+
+	district, err := scanDistrict(r.db.QueryRowContext(ctx, query, districtID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get district: %w", err)
+	}
+	return district, nil
+}
+
+func (r *districtRepository) ListDistricts(ctx context.Context) ([]*entities.District, error) {
+	query := `SELECT ` + districtColumns + ` FROM districts d ORDER BY d.name, d.id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list districts: %w", err)
+	}
+	defer rows.Close()
+
+	districts := []*entities.District{}
+	for rows.Next() {
+		district, err := scanDistrict(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan district: %w", err)
+		}
+		districts = append(districts, district)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading districts: %w", err)
+	}
+	return districts, nil
+}
+
+func (r *districtRepository) ListDistrictSchools(ctx context.Context, districtID string) ([]entities.SchoolOption, error) {
+	query := `
+		SELECT id::text, name, code FROM schools
+		WHERE district_id = $1 AND deleted_at IS NULL
+		ORDER BY name, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, districtID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list district schools: %w", err)
+	}
+	defer rows.Close()
+
+	schools := []entities.SchoolOption{}
+	for rows.Next() {
+		var school entities.SchoolOption
+		if err := rows.Scan(&school.ID, &school.Name, &school.Code); err != nil {
+			return nil, fmt.Errorf("failed to scan district school: %w", err)
+		}
+		schools = append(schools, school)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading district schools: %w", err)
+	}
+	return schools, nil
+}
+
+func (r *districtRepository) SetSchoolDistrict(ctx context.Context, schoolID, districtID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE schools SET district_id = NULLIF($2, '')::bigint, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, schoolID, districtID)
+	if err != nil {
+		return false, fmt.Errorf("failed to set school district: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *districtRepository) AssignAdmin(ctx context.Context, districtID, userID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET role = 'district_admin', district_id = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+	`, districtID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to assign district admin: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *districtRepository) GetScopeByFirebaseUID(ctx context.Context, firebaseUID string) (*policy.DistrictScope, error) {
+	query := `
+		SELECT u.district_id::text, s.id::text
+		FROM users u
+		LEFT JOIN schools s ON s.district_id = u.district_id AND s.deleted_at IS NULL
+		WHERE u.firebase_uid = $1 AND u.role = 'district_admin' AND u.deleted_at IS NULL
+		ORDER BY s.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, firebaseUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get district scope: %w", err)
+	}
+	defer rows.Close()
+
+	var scope *policy.DistrictScope
+	for rows.Next() {
+		var districtID string
+		var schoolID sql.NullString
+		if err := rows.Scan(&districtID, &schoolID); err != nil {
+			return nil, fmt.Errorf("failed to scan district scope: %w", err)
+		}
+		if scope == nil {
+			scope = &policy.DistrictScope{DistrictID: districtID, SchoolIDs: []string{}}
+		}
+		if schoolID.Valid {
+			scope.SchoolIDs = append(scope.SchoolIDs, schoolID.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading district scope: %w", err)
+	}
+	return scope, nil
+}
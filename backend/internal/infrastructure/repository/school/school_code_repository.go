@@ -0,0 +1,162 @@
+package school
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/schoolcode"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+// maxCodeAttempts 採番したコードが手動で設定されたコードと重複した場合に連番を進める回数の上限
+const maxCodeAttempts = 100
+
+// lockSchoolCodes 学校コードの作成・変更をトランザクションの終了まで1つずつに制限する
+// コードは学校と別名の2つのテーブルにまたがるため、一意制約の代わりにこのロックで重複を防ぐ
+func lockSchoolCodes(ctx context.Context, tx database.Executor) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('school_codes'))`); err != nil {
+		return fmt.Errorf("failed to lock school codes: %w", err)
+	}
+	return nil
+}
+
+// schoolCodeOwner コード（変更前のコードを含む）を使用している学校のID（未使用の場合は空文字）
+func schoolCodeOwner(ctx context.Context, tx database.Executor, code string) (string, error) {
+	var owner sql.NullString
+	if err := tx.QueryRowContext(ctx, `SELECT app_school_code_owner($1)::text`, code).Scan(&owner); err != nil {
+		return "", fmt.Errorf("failed to check school code: %w", err)
+	}
+	return owner.String, nil
+}
+
+func (r *schoolRepository) SchoolCodeOwner(ctx context.Context, code string) (string, error) {
+	return schoolCodeOwner(ctx, r.db, code)
+}
+
+func (r *schoolRepository) CreateSchoolWithGeneratedCode(ctx context.Context, school *entities.School, format schoolcode.Format, prefectureCode string) (*entities.School, error) {
+	scope := format.Scope(prefectureCode)
+
+	var created *entities.School
+	err := r.db.WithTx(ctx, func(tx database.Executor) error {
+		if err := lockSchoolCodes(ctx, tx); err != nil {
+			return err
+		}
+
+		for attempt := 0; attempt < maxCodeAttempts; attempt++ {
+			var seq int
+			if err := tx.QueryRowContext(ctx, `
+				INSERT INTO school_code_sequences (scope, last_value, updated_at)
+				VALUES ($1, 1, NOW())
+				ON CONFLICT (scope) DO UPDATE
+				SET last_value = school_code_sequences.last_value + 1, updated_at = NOW()
+				RETURNING last_value
+			`, scope).Scan(&seq); err != nil {
+				return fmt.Errorf("failed to allocate school code: %w", err)
+			}
+
+			code := format.Render(prefectureCode, seq)
+			owner, err := schoolCodeOwner(ctx, tx, code)
+			if err != nil {
+				return err
+			}
+			if owner != "" {
+				continue
+			}
+
+			created, err = insertSchool(ctx, tx, school, code)
+			return err
+		}
+		return fmt.Errorf("failed to allocate school code for %s: %d consecutive codes are in use", scope, maxCodeAttempts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *schoolRepository) ChangeSchoolCode(ctx context.Context, schoolID int64, code string) (bool, error) {
+	changed := false
+	err := r.db.WithTx(ctx, func(tx database.Executor) error {
+		if err := lockSchoolCodes(ctx, tx); err != nil {
+			return err
+		}
+
+		var current string
+		err := tx.QueryRowContext(ctx, `
+			SELECT code FROM schools WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+		`, schoolID).Scan(&current)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("school not found with id: %d", schoolID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock school for code change: %w", err)
+		}
+		if current == code {
+			changed = true
+			return nil
+		}
+
+		owner, err := schoolCodeOwner(ctx, tx, code)
+		if err != nil {
+			return err
+		}
+		if owner != "" && owner != strconv.FormatInt(schoolID, 10) {
+			return nil
+		}
+
+		// 自校の変更前のコードに戻す場合は別名から外す
+		if _, err := tx.ExecContext(ctx, `DELETE FROM school_code_aliases WHERE code = $1`, code); err != nil {
+			return fmt.Errorf("failed to reclaim school code alias: %w", err)
+		}
+		// 変更した操作者はRLS用の設定から記録する（管理用コマンドなどテナント外ではNULL）
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO school_code_aliases (code, school_id, replaced_by, replaced_at)
+			VALUES ($1, $2, NULLIF(current_setting('app.current_user_id', true), '')::bigint, NOW())
+		`, current, schoolID); err != nil {
+			return fmt.Errorf("failed to record school code alias: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE schools SET code = $2, updated_at = NOW() WHERE id = $1
+		`, schoolID, code); err != nil {
+			return fmt.Errorf("failed to change school code: %w", err)
+		}
+
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+func (r *schoolRepository) ListSchoolCodeAliases(ctx context.Context, schoolID int64) ([]entities.SchoolCodeAlias, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT code, replaced_by::text, replaced_at
+		FROM school_code_aliases
+		WHERE school_id = $1
+		ORDER BY replaced_at DESC, code
+	`, schoolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list school code aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []entities.SchoolCodeAlias{}
+	for rows.Next() {
+		var alias entities.SchoolCodeAlias
+		var replacedBy sql.NullString
+		if err := rows.Scan(&alias.Code, &replacedBy, &alias.ReplacedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan school code alias: %w", err)
+		}
+		alias.ReplacedBy = nullStringPtr(replacedBy)
+		aliases = append(aliases, alias)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading school code aliases: %w", err)
+	}
+	return aliases, nil
+}
@@ -3,177 +3,280 @@ package school
 import (
     "context"
     "database/sql"
+    "encoding/json"
     "fmt"
+    "time"
 
     "github.com/rikut0904/bloomia/backend/internal/domain/entities"
     "github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+    "github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
 )
 
 type schoolRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
-func NewSchoolRepository(db *sql.DB) repositories.SchoolRepository {
+func NewSchoolRepository(db *database.DB) repositories.SchoolRepository {
 	return &schoolRepository{db: db}
 }
 
+const schoolColumns = `id, name, code, address, phone_number, email_domain,
+	COALESCE(theme_color, '#FF7F50'), COALESCE(background_color, '#fdf8f0'), logo_url,
+	principal_name, vice_principal_name, COALESCE(student_capacity, 500), COALESCE(academic_year_start, 4),
+	settings, COALESCE(is_active, false), district_id::text, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSchool(row rowScanner) (*entities.School, error) {
+	var s entities.School
+	var id int64
+	var address, phone, emailDomain, logoURL, principal, vicePrincipal, districtID sql.NullString
+	var settings []byte
+	err := row.Scan(
+		&id,
+		&s.SchoolName,
+		&s.SchoolID,
+		&address,
+		&phone,
+		&emailDomain,
+		&s.ThemeColor,
+		&s.BackgroundColor,
+		&logoURL,
+		&principal,
+		&vicePrincipal,
+		&s.StudentCapacity,
+		&s.AcademicYearStart,
+		&settings,
+		&s.IsActive,
+		&districtID,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.ID = fmt.Sprintf("%d", id)
+	s.Address = nullStringPtr(address)
+	s.Phone = nullStringPtr(phone)
+	s.EmailDomain = nullStringPtr(emailDomain)
+	s.LogoURL = nullStringPtr(logoURL)
+	s.PrincipalName = nullStringPtr(principal)
+	s.VicePrincipalName = nullStringPtr(vicePrincipal)
+	s.DistrictID = nullStringPtr(districtID)
+
+	s.Settings, err = decodeSchoolSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func nullStringPtr(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
+
+// defaultSchoolSettings 設定ドキュメントに含まれない項目の初期値
+func defaultSchoolSettings() entities.SchoolSettings {
+	return entities.SchoolSettings{
+		Version:  entities.SchoolSettingsVersion,
+		Theme:    "coral",
+		Locale:   "ja",
+		Timezone: "Asia/Tokyo",
+		Features: entities.SchoolFeatures{
+			Chat:           true,
+			Whiteboard:     true,
+			LearningNotes:  true,
+			Gamification:   true,
+			GuardianAccess: true,
+		},
+		Calendar: entities.AcademicCalendar{Terms: []entities.AcademicTerm{}},
+	}
+}
+
+// decodeSchoolSettings 保存された設定を現在のバージョンの形式で読み込む
+// バージョン0の設定にあるbackgroundはbackground_color列で管理するため引き継がない
+func decodeSchoolSettings(raw []byte) (*entities.SchoolSettings, error) {
+	settings := defaultSchoolSettings()
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &settings); err != nil {
+			return nil, fmt.Errorf("failed to decode school settings: %w", err)
+		}
+	}
+	if settings.Version < entities.SchoolSettingsVersion {
+		settings.Version = entities.SchoolSettingsVersion
+	}
+	if settings.Calendar.Terms == nil {
+		settings.Calendar.Terms = []entities.AcademicTerm{}
+	}
+	return &settings, nil
+}
+
 func (r *schoolRepository) CreateSchool(ctx context.Context, school *entities.School) (*entities.School, error) {
-    // Map current entity fields to README schema: name <- SchoolName, code <- SchoolID
-    query := `
-        INSERT INTO schools (name, code, address, phone_number, principal_name, student_capacity)
-        VALUES ($1, $2, $3, $4, $5, $6)
-        RETURNING id, name, code, address, phone_number, principal_name, student_capacity, created_at, updated_at
-    `
+	var created *entities.School
+	err := r.db.WithTx(ctx, func(tx database.Executor) error {
+		if err := lockSchoolCodes(ctx, tx); err != nil {
+			return err
+		}
+		owner, err := schoolCodeOwner(ctx, tx, school.SchoolID)
+		if err != nil || owner != "" {
+			return err
+		}
 
-    var (
-        id int64
-        name string
-        code string
-        address sql.NullString
-        phone sql.NullString
-        principal sql.NullString
-        capacity sql.NullInt64
-        createdAt, updatedAt sql.NullTime
-    )
-
-    err := r.db.QueryRowContext(ctx, query,
-        school.SchoolName,
-        school.SchoolID,
-        school.Address,
-        school.Phone,
-        nil,
-        nil,
-    ).Scan(&id, &name, &code, &address, &phone, &principal, &capacity, &createdAt, &updatedAt)
+		created, err = insertSchool(ctx, tx, school, school.SchoolID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
 
-    if err != nil {
-        return nil, fmt.Errorf("failed to create school: %w", err)
-    }
+// insertSchool 学校を作成（名前・コード・住所・電話番号のみ。その他は既定値）
+func insertSchool(ctx context.Context, tx database.Executor, school *entities.School, code string) (*entities.School, error) {
+	// Map current entity fields to README schema: name <- SchoolName, code <- SchoolID
+	query := `
+		INSERT INTO schools (name, code, address, phone_number)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + schoolColumns
 
-    // Populate back minimal fields
-    school.ID = fmt.Sprintf("%d", id)
-    school.SchoolName = name
-    school.SchoolID = code
-    if createdAt.Valid {
-        school.CreatedAt = createdAt.Time
-    }
-    if updatedAt.Valid {
-        school.UpdatedAt = updatedAt.Time
-    }
+	created, err := scanSchool(tx.QueryRowContext(ctx, query,
+		school.SchoolName,
+		code,
+		school.Address,
+		school.Phone,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create school: %w", err)
+	}
 
-    return school, nil
+	return created, nil
 }
 
 func (r *schoolRepository) GetSchoolByID(ctx context.Context, schoolID int64) (*entities.School, error) {
-    query := `
-        SELECT id, name, code, address, phone_number, created_at, updated_at
-        FROM schools WHERE id = $1
-    `
-    var s entities.School
-    var id int64
-    var address sql.NullString
-    var phone sql.NullString
-    err := r.db.QueryRowContext(ctx, query, schoolID).Scan(&id, &s.SchoolName, &s.SchoolID, &address, &phone, &s.CreatedAt, &s.UpdatedAt)
-    if err != nil {
-        if err == sql.ErrNoRows {
-            return nil, fmt.Errorf("school not found with id: %d", schoolID)
-        }
-        return nil, fmt.Errorf("failed to get school: %w", err)
-    }
-    s.ID = fmt.Sprintf("%d", id)
-    if address.Valid { s.Address = &address.String }
-    if phone.Valid { s.Phone = &phone.String }
-    return &s, nil
+	query := `SELECT ` + schoolColumns + ` FROM schools WHERE id = $1 AND deleted_at IS NULL`
+
+	school, err := scanSchool(r.db.QueryRowContext(ctx, query, schoolID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("school not found with id: %d", schoolID)
+		}
+		return nil, fmt.Errorf("failed to get school: %w", err)
+	}
+	return school, nil
 }
 
 func (r *schoolRepository) GetSchoolByCode(ctx context.Context, code string) (*entities.School, error) {
-    query := `
-        SELECT id, name, code, address, phone_number, created_at, updated_at
-        FROM schools 
-        WHERE code = $1
-    `
-    var s entities.School
-    var id int64
-    var address sql.NullString
-    var phone sql.NullString
-    err := r.db.QueryRowContext(ctx, query, code).Scan(&id, &s.SchoolName, &s.SchoolID, &address, &phone, &s.CreatedAt, &s.UpdatedAt)
-    if err != nil {
-        if err == sql.ErrNoRows {
-            return nil, fmt.Errorf("school not found with code: %s", code)
-        }
-        return nil, fmt.Errorf("failed to find school by code: %w", err)
-    }
-    s.ID = fmt.Sprintf("%d", id)
-    if address.Valid { s.Address = &address.String }
-    if phone.Valid { s.Phone = &phone.String }
-    return &s, nil
+	// 変更前のコードでも同じ学校として解決する
+	query := `SELECT ` + schoolColumns + ` FROM schools
+		WHERE deleted_at IS NULL
+		  AND (code = $1 OR id = (SELECT school_id FROM school_code_aliases WHERE code = $1))`
+
+	school, err := scanSchool(r.db.QueryRowContext(ctx, query, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("school not found with code: %s", code)
+		}
+		return nil, fmt.Errorf("failed to find school by code: %w", err)
+	}
+	return school, nil
+}
+
+func (r *schoolRepository) FindSchoolIDByEmailDomain(ctx context.Context, domain string) (string, error) {
+	query := `SELECT id::text FROM schools WHERE LOWER(email_domain) = LOWER($1)`
+
+	var schoolID string
+	if err := r.db.QueryRowContext(ctx, query, domain).Scan(&schoolID); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to find school by email domain: %w", err)
+	}
+	return schoolID, nil
 }
 
 func (r *schoolRepository) GetAllSchools(ctx context.Context, filters map[string]interface{}) ([]*entities.School, error) {
-    query := `
-        SELECT id, name, code, address, phone_number, created_at, updated_at
-        FROM schools
-        ORDER BY name
-    `
-    rows, err := r.db.QueryContext(ctx, query)
-    if err != nil {
-        return nil, fmt.Errorf("failed to get schools: %w", err)
-    }
-    defer rows.Close()
-
-    var schools []*entities.School
-    for rows.Next() {
-        var school entities.School
-        var id int64
-        var address sql.NullString
-        var phone sql.NullString
-        err := rows.Scan(&id, &school.SchoolName, &school.SchoolID, &address, &phone, &school.CreatedAt, &school.UpdatedAt)
-        if err != nil {
-            return nil, fmt.Errorf("failed to scan school: %w", err)
-        }
-        school.ID = fmt.Sprintf("%d", id)
-        if address.Valid { school.Address = &address.String }
-        if phone.Valid { school.Phone = &phone.String }
-        schools = append(schools, &school)
-    }
+	query := `SELECT ` + schoolColumns + ` FROM schools WHERE deleted_at IS NULL ORDER BY name`
 
-    if err = rows.Err(); err != nil {
-        return nil, fmt.Errorf("error reading schools: %w", err)
-    }
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schools: %w", err)
+	}
+	defer rows.Close()
 
-    return schools, nil
+	var schools []*entities.School
+	for rows.Next() {
+		school, err := scanSchool(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan school: %w", err)
+		}
+		schools = append(schools, school)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading schools: %w", err)
+	}
+
+	return schools, nil
 }
 
-func (r *schoolRepository) UpdateSchool(ctx context.Context, schoolID int64, updateData entities.School) (*entities.School, error) {
-    query := `
-        UPDATE schools 
-        SET name = $2, address = $3, phone_number = $4, updated_at = NOW()
-        WHERE id = $1
-        RETURNING id, name, code, address, phone_number, created_at, updated_at
-    `
-    var s entities.School
-    var id int64
-    var address sql.NullString
-    var phone sql.NullString
-    err := r.db.QueryRowContext(ctx, query,
-        schoolID,
-        updateData.SchoolName,
-        updateData.Address,
-        updateData.Phone,
-    ).Scan(&id, &s.SchoolName, &s.SchoolID, &address, &phone, &s.CreatedAt, &s.UpdatedAt)
-    if err != nil {
-        if err == sql.ErrNoRows {
-            return nil, fmt.Errorf("school not found for update: %d", schoolID)
-        }
-        return nil, fmt.Errorf("failed to update school: %w", err)
-    }
-    s.ID = fmt.Sprintf("%d", id)
-    if address.Valid { s.Address = &address.String }
-    if phone.Valid { s.Phone = &phone.String }
-    return &s, nil
+// UpdateSchool 指定された項目のみ更新（任意項目は空文字でNULLにする）
+func (r *schoolRepository) UpdateSchool(ctx context.Context, schoolID int64, update entities.SchoolUpdate) (*entities.School, error) {
+	query := `
+		UPDATE schools SET
+			name = COALESCE($2, name),
+			address = CASE WHEN $3::text IS NULL THEN address ELSE NULLIF($3, '') END,
+			phone_number = CASE WHEN $4::text IS NULL THEN phone_number ELSE NULLIF($4, '') END,
+			email_domain = CASE WHEN $5::text IS NULL THEN email_domain ELSE NULLIF($5, '') END,
+			theme_color = COALESCE($6, theme_color),
+			background_color = COALESCE($7, background_color),
+			logo_url = CASE WHEN $8::text IS NULL THEN logo_url ELSE NULLIF($8, '') END,
+			principal_name = CASE WHEN $9::text IS NULL THEN principal_name ELSE NULLIF($9, '') END,
+			vice_principal_name = CASE WHEN $10::text IS NULL THEN vice_principal_name ELSE NULLIF($10, '') END,
+			student_capacity = COALESCE($11, student_capacity),
+			academic_year_start = COALESCE($12, academic_year_start),
+			settings = COALESCE($13::jsonb, settings),
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING ` + schoolColumns
+
+	var settings interface{}
+	if update.Settings != nil {
+		settings = string(update.Settings)
+	}
+
+	school, err := scanSchool(r.db.QueryRowContext(ctx, query,
+		schoolID,
+		update.SchoolName,
+		update.Address,
+		update.Phone,
+		update.EmailDomain,
+		update.ThemeColor,
+		update.BackgroundColor,
+		update.LogoURL,
+		update.PrincipalName,
+		update.VicePrincipalName,
+		update.StudentCapacity,
+		update.AcademicYearStart,
+		settings,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("school not found for update: %d", schoolID)
+		}
+		return nil, fmt.Errorf("failed to update school: %w", err)
+	}
+	return school, nil
 }
 
+// DeleteSchool 学校を論理削除（所属ユーザー・クラスなどのデータは残し、復元できるようにする）
 func (r *schoolRepository) DeleteSchool(ctx context.Context, schoolID int64) error {
-    query := `DELETE FROM schools WHERE id = $1`
+    query := `UPDATE schools SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
     result, err := r.db.ExecContext(ctx, query, schoolID)
     if err != nil {
         return fmt.Errorf("failed to delete school: %w", err)
@@ -191,15 +294,21 @@ func (r *schoolRepository) DeleteSchool(ctx context.Context, schoolID int64) err
 	return nil
 }
 
-func (r *schoolRepository) GetSchoolStats(ctx context.Context, schoolID int64) (map[string]interface{}, error) {
-    // Return basic stats from users table
-    stats := map[string]interface{}{}
-    var total, active int
-    if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(CASE WHEN is_active THEN 1 ELSE 0 END),0) FROM users WHERE school_id = $1`, fmt.Sprintf("%d", schoolID)).Scan(&total, &active); err == nil {
-        stats["total_users"] = total
-        stats["active_users"] = active
-    }
-    return stats, nil
+func (r *schoolRepository) RestoreSchool(ctx context.Context, schoolID int64, deletedAfter time.Time) (bool, error) {
+	query := `
+		UPDATE schools SET deleted_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL AND deleted_at > $2
+	`
+	result, err := r.db.ExecContext(ctx, query, schoolID, deletedAfter)
+	if err != nil {
+		return false, fmt.Errorf("failed to restore school: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
 }
 
 func (r *schoolRepository) GetSchoolUsers(ctx context.Context, schoolID int64, role string) ([]*entities.User, error) {
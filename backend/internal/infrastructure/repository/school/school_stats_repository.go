@@ -0,0 +1,257 @@
+package school
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+)
+
+// gradeBands 成績分布の区間（loadGradeStatsのCASE式と対応させる）
+var gradeBands = []string{"0-59", "60-69", "70-79", "80-89", "90-100"}
+
+// percentOf partがwholeに占める割合（%、小数点以下1桁）
+func percentOf(part, whole int) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(whole)) / 10
+}
+
+func (r *schoolRepository) GetSchoolStats(ctx context.Context, schoolID int64, period entities.SchoolStatsPeriod) (*entities.SchoolStats, error) {
+	stats := &entities.SchoolStats{
+		SchoolID:    strconv.FormatInt(schoolID, 10),
+		Period:      period,
+		GeneratedAt: time.Now(),
+	}
+
+	if err := r.loadUserStats(ctx, schoolID, stats); err != nil {
+		return nil, err
+	}
+	if err := r.loadClassStats(ctx, schoolID, stats); err != nil {
+		return nil, err
+	}
+	if err := r.loadAssignmentStats(ctx, schoolID, period, stats); err != nil {
+		return nil, err
+	}
+	if err := r.loadAttendanceStats(ctx, schoolID, period, stats); err != nil {
+		return nil, err
+	}
+	if err := r.loadGradeStats(ctx, schoolID, period, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// loadUserStats ロール・学年別のユーザー数と承認・ログイン状況
+func (r *schoolRepository) loadUserStats(ctx context.Context, schoolID int64, stats *entities.SchoolStats) error {
+	query := `
+		SELECT role, grade, COUNT(*),
+			COUNT(*) FILTER (WHERE COALESCE(is_active, false)),
+			COUNT(*) FILTER (WHERE COALESCE(is_approved, false)),
+			COUNT(*) FILTER (WHERE NOT COALESCE(is_approved, false) AND approval_reviewed_at IS NULL),
+			COUNT(*) FILTER (WHERE last_login_at >= NOW() - INTERVAL '7 days'),
+			COUNT(*) FILTER (WHERE last_login_at >= NOW() - INTERVAL '30 days'),
+			COUNT(*) FILTER (WHERE last_login_at IS NULL)
+		FROM users
+		WHERE school_id = $1 AND deleted_at IS NULL
+		GROUP BY role, grade
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolID)
+	if err != nil {
+		return fmt.Errorf("failed to get user stats: %w", err)
+	}
+	defer rows.Close()
+
+	users := &stats.Users
+	users.ByRole = map[string]int{}
+	users.StudentsByGrade = map[string]int{}
+	for rows.Next() {
+		var role string
+		var grade sql.NullInt64
+		var total, active, approved, pending, last7, last30, never int
+		if err := rows.Scan(&role, &grade, &total, &active, &approved, &pending, &last7, &last30, &never); err != nil {
+			return fmt.Errorf("failed to scan user stats: %w", err)
+		}
+
+		users.Total += total
+		users.Active += active
+		users.Approved += approved
+		users.PendingApproval += pending
+		users.ByRole[role] += total
+		if role == "student" {
+			key := "unassigned"
+			if grade.Valid {
+				key = strconv.FormatInt(grade.Int64, 10)
+			}
+			users.StudentsByGrade[key] += total
+		}
+
+		stats.Logins.Last7Days += last7
+		stats.Logins.Last30Days += last30
+		stats.Logins.NeverLoggedIn += never
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading user stats: %w", err)
+	}
+	return nil
+}
+
+// loadClassStats 有効なクラスごとの定員と在籍する生徒数（current_studentsではなく実際の所属から数える）
+func (r *schoolRepository) loadClassStats(ctx context.Context, schoolID int64, stats *entities.SchoolStats) error {
+	query := `
+		SELECT c.id::text, c.name, c.grade, COALESCE(c.max_students, 0),
+			(SELECT COUNT(*) FROM users u
+			 WHERE u.class_id = c.id AND u.role = 'student' AND u.deleted_at IS NULL)
+		FROM classes c
+		WHERE c.school_id = $1 AND COALESCE(c.is_active, false)
+		ORDER BY c.grade, c.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolID)
+	if err != nil {
+		return fmt.Errorf("failed to get class stats: %w", err)
+	}
+	defer rows.Close()
+
+	classes := &stats.Classes
+	classes.Classes = []entities.ClassFillStats{}
+	for rows.Next() {
+		var class entities.ClassFillStats
+		if err := rows.Scan(&class.ClassID, &class.Name, &class.Grade, &class.MaxStudents, &class.Enrolled); err != nil {
+			return fmt.Errorf("failed to scan class stats: %w", err)
+		}
+		class.FillRate = percentOf(class.Enrolled, class.MaxStudents)
+
+		classes.Total++
+		classes.Capacity += class.MaxStudents
+		classes.Enrolled += class.Enrolled
+		classes.Classes = append(classes.Classes, class)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading class stats: %w", err)
+	}
+	classes.FillRate = percentOf(classes.Enrolled, classes.Capacity)
+	return nil
+}
+
+// loadAssignmentStats 集計期間内が期限の公開済み課題の提出率（対象は課題のクラスに在籍する生徒）
+func (r *schoolRepository) loadAssignmentStats(ctx context.Context, schoolID int64, period entities.SchoolStatsPeriod, stats *entities.SchoolStats) error {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(t.expected), 0), COALESCE(SUM(t.submitted), 0), COALESCE(SUM(t.late), 0)
+		FROM (
+			SELECT
+				(SELECT COUNT(*) FROM users u
+				 WHERE u.class_id = c.id AND u.role = 'student' AND u.deleted_at IS NULL) AS expected,
+				(SELECT COUNT(*) FROM submissions s WHERE s.assignment_id = a.id) AS submitted,
+				(SELECT COUNT(*) FROM submissions s WHERE s.assignment_id = a.id AND s.is_late) AS late
+			FROM assignments a
+			JOIN courses co ON co.id = a.course_id
+			JOIN classes c ON c.id = co.class_id
+			WHERE c.school_id = $1 AND a.is_published
+			  AND a.due_date >= $2 AND a.due_date < $3
+		) t
+	`
+
+	assignments := &stats.Assignments
+	if err := r.db.QueryRowContext(ctx, query, schoolID, period.Start, period.End).Scan(
+		&assignments.Total, &assignments.Expected, &assignments.Submitted, &assignments.Late,
+	); err != nil {
+		return fmt.Errorf("failed to get assignment stats: %w", err)
+	}
+	assignments.SubmissionRate = percentOf(assignments.Submitted, assignments.Expected)
+	return nil
+}
+
+// loadAttendanceStats 集計期間内の出欠の内訳と出席率（公欠は分母から除く）
+func (r *schoolRepository) loadAttendanceStats(ctx context.Context, schoolID int64, period entities.SchoolStatsPeriod, stats *entities.SchoolStats) error {
+	query := `
+		SELECT at.status, COUNT(*)
+		FROM attendance at
+		JOIN courses co ON co.id = at.course_id
+		JOIN classes c ON c.id = co.class_id
+		WHERE c.school_id = $1 AND at.attendance_date BETWEEN $2::date AND $3::date
+		GROUP BY at.status
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolID, period.From, period.To)
+	if err != nil {
+		return fmt.Errorf("failed to get attendance stats: %w", err)
+	}
+	defer rows.Close()
+
+	attendance := &stats.Attendance
+	attendance.ByStatus = map[string]int{"present": 0, "absent": 0, "late": 0, "sick": 0, "official": 0}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return fmt.Errorf("failed to scan attendance stats: %w", err)
+		}
+		attendance.ByStatus[status] = count
+		attendance.Records += count
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading attendance stats: %w", err)
+	}
+
+	attended := attendance.ByStatus["present"] + attendance.ByStatus["late"]
+	attendance.AttendanceRate = percentOf(attended, attendance.Records-attendance.ByStatus["official"])
+	return nil
+}
+
+// loadGradeStats 集計期間内に採点された成績の平均と得点率の分布
+func (r *schoolRepository) loadGradeStats(ctx context.Context, schoolID int64, period entities.SchoolStatsPeriod, stats *entities.SchoolStats) error {
+	query := `
+		SELECT CASE
+				WHEN g.percentage >= 90 THEN '90-100'
+				WHEN g.percentage >= 80 THEN '80-89'
+				WHEN g.percentage >= 70 THEN '70-79'
+				WHEN g.percentage >= 60 THEN '60-69'
+				ELSE '0-59'
+			END AS band,
+			COUNT(*), COALESCE(SUM(g.percentage), 0)
+		FROM grades g
+		JOIN courses co ON co.id = g.course_id
+		JOIN classes c ON c.id = co.class_id
+		WHERE c.school_id = $1 AND g.graded_at >= $2 AND g.graded_at < $3
+		GROUP BY band
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolID, period.Start, period.End)
+	if err != nil {
+		return fmt.Errorf("failed to get grade stats: %w", err)
+	}
+	defer rows.Close()
+
+	grades := &stats.Grades
+	grades.Distribution = make(map[string]int, len(gradeBands))
+	for _, band := range gradeBands {
+		grades.Distribution[band] = 0
+	}
+
+	var total float64
+	for rows.Next() {
+		var band string
+		var count int
+		var sum float64
+		if err := rows.Scan(&band, &count, &sum); err != nil {
+			return fmt.Errorf("failed to scan grade stats: %w", err)
+		}
+		grades.Distribution[band] = count
+		grades.Count += count
+		total += sum
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading grade stats: %w", err)
+	}
+	if grades.Count > 0 {
+		grades.AveragePercent = math.Round(total/float64(grades.Count)*10) / 10
+	}
+	return nil
+}
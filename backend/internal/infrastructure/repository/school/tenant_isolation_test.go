@@ -0,0 +1,218 @@
+package school_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/migration"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/repository/school"
+)
+
+// TEST_DATABASE_URL テスト用のPostgreSQL（未設定の場合はスキップする）
+// マイグレーションを適用するため、本番や開発用のDBは指定しないこと
+const testDatabaseURLEnv = "TEST_DATABASE_URL"
+
+// tenantFixture 2校分のテストデータ
+type tenantFixture struct {
+	schoolA, schoolB int64
+	adminA, adminB   int64
+	classA, classB   int64
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	url := os.Getenv(testDatabaseURLEnv)
+	if url == "" {
+		t.Skipf("%s is not set", testDatabaseURLEnv)
+	}
+
+	db, err := database.Connect(url)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := migration.NewMigrator(db, "../../../../migrations").Up(context.Background()); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+// seedSchools 所有者権限（RLS対象外）で2校とそれぞれの学校管理者・クラスを作成し、テスト後に削除する
+func seedSchools(t *testing.T, db *sql.DB) tenantFixture {
+	t.Helper()
+	ctx := context.Background()
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	var f tenantFixture
+	for i, target := range []struct {
+		school, admin, class *int64
+	}{
+		{&f.schoolA, &f.adminA, &f.classA},
+		{&f.schoolB, &f.adminB, &f.classB},
+	} {
+		label := fmt.Sprintf("%s-%d", suffix, i)
+		if err := db.QueryRowContext(ctx,
+			`INSERT INTO schools (name, code) VALUES ($1, $2) RETURNING id`,
+			"RLS Test School "+label, "rls-"+label,
+		).Scan(target.school); err != nil {
+			t.Fatalf("failed to insert school: %v", err)
+		}
+		if err := db.QueryRowContext(ctx, `
+			INSERT INTO users (firebase_uid, name, email, role, school_id, is_approved)
+			VALUES ($1, $2, $3, 'school_admin', $4, true) RETURNING id`,
+			"rls-uid-"+label, "RLS Admin "+label, "rls-"+label+"@example.com", *target.school,
+		).Scan(target.admin); err != nil {
+			t.Fatalf("failed to insert user: %v", err)
+		}
+		if err := db.QueryRowContext(ctx, `
+			INSERT INTO classes (school_id, name, grade, academic_year)
+			VALUES ($1, '1-A', 1, 2026) RETURNING id`,
+			*target.school,
+		).Scan(target.class); err != nil {
+			t.Fatalf("failed to insert class: %v", err)
+		}
+	}
+
+	t.Cleanup(func() {
+		for _, query := range []string{
+			`DELETE FROM classes WHERE school_id IN ($1, $2)`,
+			`DELETE FROM users WHERE school_id IN ($1, $2)`,
+			`DELETE FROM schools WHERE id IN ($1, $2)`,
+		} {
+			if _, err := db.ExecContext(context.Background(), query, f.schoolA, f.schoolB); err != nil {
+				t.Errorf("failed to clean up: %v", err)
+			}
+		}
+	})
+	return f
+}
+
+func schoolAdminTenant(userID, schoolID int64) database.Tenant {
+	return database.Tenant{
+		UserID:   strconv.FormatInt(userID, 10),
+		Role:     "school_admin",
+		SchoolID: strconv.FormatInt(schoolID, 10),
+	}
+}
+
+func TestTenantIsolationBetweenSchools(t *testing.T) {
+	db := openTestDB(t)
+	f := seedSchools(t, db)
+	tenantDB := database.NewDB(db)
+	repo := school.NewSchoolRepository(tenantDB)
+
+	tests := []struct {
+		name           string
+		tenant         database.Tenant
+		ownSchool      int64
+		otherSchool    int64
+		otherSchoolKey string
+	}{
+		{"school A", schoolAdminTenant(f.adminA, f.schoolA), f.schoolA, f.schoolB, "B"},
+		{"school B", schoolAdminTenant(f.adminB, f.schoolB), f.schoolB, f.schoolA, "A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tenantDB.RunAsTenant(context.Background(), tt.tenant, func(ctx context.Context) error {
+				schools, err := repo.GetAllSchools(ctx, nil)
+				if err != nil {
+					return err
+				}
+				visible := make(map[string]bool)
+				for _, s := range schools {
+					visible[s.ID] = true
+				}
+				if !visible[strconv.FormatInt(tt.ownSchool, 10)] {
+					t.Errorf("own school %d is not visible", tt.ownSchool)
+				}
+				if visible[strconv.FormatInt(tt.otherSchool, 10)] {
+					t.Errorf("school %s is visible from another school", tt.otherSchoolKey)
+				}
+
+				if _, err := repo.GetSchoolByID(ctx, tt.ownSchool); err != nil {
+					t.Errorf("GetSchoolByID(own) error = %v", err)
+				}
+				if _, err := repo.GetSchoolByID(ctx, tt.otherSchool); err == nil {
+					t.Errorf("GetSchoolByID(%s) returned a school from another school", tt.otherSchoolKey)
+				}
+
+				users, err := repo.GetSchoolUsers(ctx, tt.otherSchool, "")
+				if err != nil {
+					return err
+				}
+				if len(users) != 0 {
+					t.Errorf("GetSchoolUsers(%s) returned %d users, want 0", tt.otherSchoolKey, len(users))
+				}
+
+				var classes int
+				if err := tenantDB.QueryRowContext(ctx,
+					`SELECT COUNT(*) FROM classes WHERE school_id = $1`, tt.otherSchool,
+				).Scan(&classes); err != nil {
+					return err
+				}
+				if classes != 0 {
+					t.Errorf("classes of school %s visible: %d", tt.otherSchoolKey, classes)
+				}
+
+				result, err := tenantDB.ExecContext(ctx,
+					`UPDATE schools SET name = name || ' (changed)' WHERE id = $1`, tt.otherSchool)
+				if err != nil {
+					return err
+				}
+				if n, _ := result.RowsAffected(); n != 0 {
+					t.Errorf("updated %d rows of school %s, want 0", n, tt.otherSchoolKey)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("RunAsTenant() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestTenantAdminSeesAllSchools(t *testing.T) {
+	db := openTestDB(t)
+	f := seedSchools(t, db)
+	tenantDB := database.NewDB(db)
+	repo := school.NewSchoolRepository(tenantDB)
+
+	admin := database.Tenant{UserID: strconv.FormatInt(f.adminA, 10), Role: "admin", SchoolID: strconv.FormatInt(f.schoolA, 10)}
+	err := tenantDB.RunAsTenant(context.Background(), admin, func(ctx context.Context) error {
+		for _, id := range []int64{f.schoolA, f.schoolB} {
+			if _, err := repo.GetSchoolByID(ctx, id); err != nil {
+				t.Errorf("GetSchoolByID(%d) error = %v", id, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunAsTenant() error = %v", err)
+	}
+}
+
+// TestTenantCannotInsertIntoOtherSchool WITH CHECKのないポリシーでもUSINGが挿入行に適用されることを確認
+func TestTenantCannotInsertIntoOtherSchool(t *testing.T) {
+	db := openTestDB(t)
+	f := seedSchools(t, db)
+	tenantDB := database.NewDB(db)
+
+	err := tenantDB.RunAsTenant(context.Background(), schoolAdminTenant(f.adminA, f.schoolA), func(ctx context.Context) error {
+		_, err := tenantDB.ExecContext(ctx, `
+			INSERT INTO classes (school_id, name, grade, academic_year)
+			VALUES ($1, 'rls-insert', 1, 2026)`, f.schoolB)
+		return err
+	})
+	if err == nil {
+		t.Fatal("inserted a class into another school")
+	}
+}
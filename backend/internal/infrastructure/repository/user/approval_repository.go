@@ -0,0 +1,159 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+type approvalRepository struct {
+	db *database.DB
+}
+
+func NewApprovalRepository(db *database.DB) repositories.ApprovalRepository {
+	return &approvalRepository{db: db}
+}
+
+// pendingApprovalCondition 承認待ち（未承認かつ未審査）のユーザー
+const pendingApprovalCondition = `u.is_approved = false AND u.approval_reviewed_at IS NULL AND u.deleted_at IS NULL`
+
+func (r *approvalRepository) GetAccountStatus(ctx context.Context, firebaseUID string) (*entities.AccountStatus, error) {
+	query := `
+		SELECT u.id::text, u.name, u.email, u.role, u.school_id::text, s.name,
+			   (COALESCE(u.is_active, false) AND u.deleted_at IS NULL AND s.deleted_at IS NULL), COALESCE(u.is_approved, false),
+			   u.rejection_reason, u.approval_reviewed_at
+		FROM users u
+		LEFT JOIN schools s ON u.school_id = s.id
+		WHERE u.firebase_uid = $1
+	`
+
+	var status entities.AccountStatus
+	err := r.db.QueryRowContext(ctx, query, firebaseUID).Scan(
+		&status.UserID,
+		&status.Name,
+		&status.Email,
+		&status.Role,
+		&status.SchoolID,
+		&status.SchoolName,
+		&status.IsActive,
+		&status.IsApproved,
+		&status.RejectionReason,
+		&status.ReviewedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: firebase_uid=%s", repositories.ErrUserNotFound, firebaseUID)
+		}
+		return nil, fmt.Errorf("failed to get account status: %w", err)
+	}
+
+	switch {
+	case status.IsApproved:
+		status.ApprovalStatus = entities.ApprovalStatusApproved
+	case status.ReviewedAt != nil:
+		status.ApprovalStatus = entities.ApprovalStatusRejected
+	default:
+		status.ApprovalStatus = entities.ApprovalStatusPending
+	}
+
+	return &status, nil
+}
+
+func (r *approvalRepository) ListPending(ctx context.Context, schoolID string, page, perPage int) ([]entities.UserManagement, int, error) {
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM users u WHERE u.school_id = $1::bigint AND ` + pendingApprovalCondition
+	if err := r.db.QueryRowContext(ctx, countQuery, schoolID).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count pending approvals: %w", err)
+	}
+
+	// 申請の古い順に処理できるよう登録日時の昇順
+	query := `
+		SELECT ` + approvalUserColumns + `
+		FROM users u
+		LEFT JOIN schools s ON u.school_id = s.id
+		WHERE u.school_id = $1::bigint AND ` + pendingApprovalCondition + `
+		ORDER BY u.created_at, u.id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolID, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query pending approvals: %w", err)
+	}
+	defer rows.Close()
+
+	users, err := scanApprovalUsers(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, totalCount, nil
+}
+
+func (r *approvalRepository) ReviewUsers(ctx context.Context, schoolID string, userIDs []string, roles []string, approve bool, reason *string, reviewerID string) ([]entities.UserManagement, error) {
+	// 承認待ちのユーザーのみ更新するため、同時に審査しても二重に通知されない
+	query := `
+		WITH reviewed AS (
+			UPDATE users u
+			SET is_approved = $4,
+				approval_reviewed_at = NOW(),
+				approval_reviewed_by = NULLIF($6, '')::bigint,
+				rejection_reason = $5,
+				updated_at = NOW()
+			WHERE u.school_id = $1::bigint
+			  AND u.id = ANY($2::bigint[])
+			  AND u.role = ANY($3)
+			  AND ` + pendingApprovalCondition + `
+			RETURNING u.*
+		)
+		SELECT ` + approvalUserColumns + `
+		FROM reviewed u
+		LEFT JOIN schools s ON u.school_id = s.id
+		ORDER BY u.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolID, pq.Array(userIDs), pq.Array(roles), approve, reason, reviewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review users: %w", err)
+	}
+	defer rows.Close()
+
+	return scanApprovalUsers(rows)
+}
+
+const approvalUserColumns = `u.id::text, u.firebase_uid, u.name, u.email, u.role, u.school_id::text, s.name,
+			   COALESCE(u.is_active, false), COALESCE(u.is_approved, false), u.must_change_password, u.last_login_at,
+			   u.created_at, u.updated_at`
+
+func scanApprovalUsers(rows *sql.Rows) ([]entities.UserManagement, error) {
+	var users []entities.UserManagement
+	for rows.Next() {
+		var user entities.UserManagement
+		if err := rows.Scan(
+			&user.ID,
+			&user.FirebaseUID,
+			&user.Name,
+			&user.Email,
+			&user.Role,
+			&user.SchoolID,
+			&user.SchoolName,
+			&user.IsActive,
+			&user.IsApproved,
+			&user.MustChangePassword,
+			&user.LastLoginAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate users: %w", err)
+	}
+	return users, nil
+}
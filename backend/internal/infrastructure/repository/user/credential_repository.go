@@ -0,0 +1,135 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+type credentialRepository struct {
+	db *database.DB
+}
+
+func NewCredentialRepository(db *database.DB) repositories.CredentialRepository {
+	return &credentialRepository{db: db}
+}
+
+// 削除済みのユーザー・学校は無効として扱う
+const credentialColumns = `id, firebase_uid, name, email, COALESCE(password_hash, ''), must_change_password,
+	(COALESCE(is_active, false) AND deleted_at IS NULL AND NOT EXISTS (
+		SELECT 1 FROM schools s WHERE s.id = users.school_id AND s.deleted_at IS NOT NULL
+	)), is_approved`
+
+func scanCredentials(row *sql.Row) (*entities.UserCredentials, error) {
+	var credentials entities.UserCredentials
+	err := row.Scan(
+		&credentials.UserID,
+		&credentials.FirebaseUID,
+		&credentials.Name,
+		&credentials.Email,
+		&credentials.PasswordHash,
+		&credentials.MustChangePassword,
+		&credentials.IsActive,
+		&credentials.IsApproved,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &credentials, nil
+}
+
+func (r *credentialRepository) GetCredentialsByEmail(ctx context.Context, email string) (*entities.UserCredentials, error) {
+	query := `SELECT ` + credentialColumns + ` FROM users WHERE LOWER(email) = LOWER($1)`
+
+	credentials, err := scanCredentials(r.db.QueryRowContext(ctx, query, email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get credentials by email: %w", err)
+	}
+
+	return credentials, nil
+}
+
+func (r *credentialRepository) GetCredentialsByUserID(ctx context.Context, userID string) (*entities.UserCredentials, error) {
+	query := `SELECT ` + credentialColumns + ` FROM users WHERE id::text = $1`
+
+	credentials, err := scanCredentials(r.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found with id: %s", userID)
+		}
+		return nil, fmt.Errorf("failed to get credentials by id: %w", err)
+	}
+
+	return credentials, nil
+}
+
+func (r *credentialRepository) UpdatePassword(ctx context.Context, userID, passwordHash string, mustChange bool) error {
+	query := `
+		UPDATE users
+		SET password_hash = $2, must_change_password = $3, password_changed_at = NOW(), updated_at = NOW()
+		WHERE id::text = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, passwordHash, mustChange)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found with id: %s", userID)
+	}
+
+	return nil
+}
+
+func (r *credentialRepository) CreatePasswordResetToken(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	return r.db.WithTx(ctx, func(tx database.Executor) error {
+		// 未使用の古いトークンは無効化し、最新のリンクのみ有効にする
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE password_reset_tokens SET used_at = NOW()
+			WHERE user_id::text = $1 AND used_at IS NULL
+		`, userID); err != nil {
+			return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+			VALUES ($1::bigint, $2, $3)
+		`, userID, tokenHash, expiresAt); err != nil {
+			return fmt.Errorf("failed to create password reset token: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *credentialRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string) (string, error) {
+	// 使用済みへの更新と取得を1文で行い、同じトークンの同時使用を防ぐ
+	query := `
+		UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id::text
+	`
+
+	var userID string
+	if err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(&userID); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	return userID, nil
+}
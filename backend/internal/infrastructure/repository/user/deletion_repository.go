@@ -0,0 +1,176 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+type userDeletionRepository struct {
+	db *database.DB
+}
+
+func NewUserDeletionRepository(db *database.DB) repositories.UserDeletionRepository {
+	return &userDeletionRepository{db: db}
+}
+
+func (r *userDeletionRepository) SoftDeleteUser(ctx context.Context, userID string) error {
+	query := `
+		UPDATE users SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING class_id
+	`
+
+	return r.db.WithTx(ctx, func(tx database.Executor) error {
+		var classID sql.NullInt64
+		err := tx.QueryRowContext(ctx, query, userID).Scan(&classID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user not found or already deleted: %s", userID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+
+		// 削除した生徒はクラスの在籍人数から外す
+		return RecountClassStudents(ctx, tx, classIDsOf(classID))
+	})
+}
+
+func (r *userDeletionRepository) RestoreUser(ctx context.Context, userID string, deletedAfter time.Time) (bool, error) {
+	query := `
+		UPDATE users SET deleted_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at > $2 AND erased_at IS NULL
+		RETURNING class_id
+	`
+
+	restored := false
+	err := r.db.WithTx(ctx, func(tx database.Executor) error {
+		var classID sql.NullInt64
+		err := tx.QueryRowContext(ctx, query, userID, deletedAfter).Scan(&classID)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore user: %w", err)
+		}
+
+		restored = true
+		return RecountClassStudents(ctx, tx, classIDsOf(classID))
+	})
+	if err != nil {
+		return false, err
+	}
+	return restored, nil
+}
+
+func (r *userDeletionRepository) ListExpiredDeletions(ctx context.Context, deletedBefore time.Time, limit int) ([]string, error) {
+	query := `
+		SELECT id::text FROM users
+		WHERE deleted_at IS NOT NULL AND deleted_at <= $1 AND erased_at IS NULL
+		ORDER BY deleted_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, deletedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired deletions: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate expired deletions: %w", err)
+	}
+	return userIDs, nil
+}
+
+func (r *userDeletionRepository) EraseUser(ctx context.Context, userID string) (bool, error) {
+	erased := false
+	err := r.db.WithTx(ctx, func(tx database.Executor) error {
+		// 同時に実行された消去ジョブと重複しないよう行をロック
+		var email string
+		var classID sql.NullInt64
+		err := tx.QueryRowContext(ctx, `
+			SELECT email, class_id FROM users WHERE id = $1 AND erased_at IS NULL FOR UPDATE
+		`, userID).Scan(&email, &classID)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock user for erasure: %w", err)
+		}
+
+		// 個人を特定できる項目を匿名化（IDは成績などの集計のため残す）
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE users SET
+				name = $2,
+				furigana = NULL,
+				email = 'erased-' || id::text || '@erased.invalid',
+				firebase_uid = $3::text || id::text,
+				avatar_url = NULL,
+				student_number = NULL,
+				guardian_name_encrypted = NULL,
+				guardian_phone_encrypted = NULL,
+				guardian_email_encrypted = NULL,
+				password_hash = NULL,
+				must_change_password = false,
+				rejection_reason = NULL,
+				last_login_at = NULL,
+				is_active = false,
+				deleted_at = COALESCE(deleted_at, NOW()),
+				erased_at = NOW(),
+				updated_at = NOW()
+			WHERE id = $1
+		`, userID, entities.ErasedUserName, entities.ErasedUIDPrefix); err != nil {
+			return fmt.Errorf("failed to anonymise user: %w", err)
+		}
+
+		// 本文・ファイルなど本人が作成した内容を消去（提出物の点数・提出状況は残す）
+		statements := []struct {
+			query string
+			what  string
+		}{
+			{`UPDATE messages SET message = '', file_url = NULL, file_name = NULL, is_deleted = true WHERE sender_id = $1`, "messages"},
+			{`UPDATE learning_notes SET title = '', content = '', tags = NULL, word_count = 0, is_shared = false, shared_with_teacher = false, updated_at = NOW() WHERE student_id = $1`, "learning notes"},
+			{`UPDATE submissions SET content = NULL, file_url = NULL, file_name = NULL, file_size = NULL, feedback = NULL WHERE student_id = $1`, "submissions"},
+			{`DELETE FROM guardian_students WHERE guardian_id = $1 OR student_id = $1`, "guardian links"},
+			{`DELETE FROM password_reset_tokens WHERE user_id = $1`, "password reset tokens"},
+			{`DELETE FROM user_mfa_recovery_codes WHERE user_id = $1`, "recovery codes"},
+			{`DELETE FROM user_mfa WHERE user_id = $1`, "two-factor settings"},
+		}
+		for _, statement := range statements {
+			if _, err := tx.ExecContext(ctx, statement.query, userID); err != nil {
+				return fmt.Errorf("failed to erase %s: %w", statement.what, err)
+			}
+		}
+
+		// 招待はメールアドレスで紐付いているため削除
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_invitations WHERE LOWER(email) = LOWER($1)`, email); err != nil {
+			return fmt.Errorf("failed to erase invitations: %w", err)
+		}
+
+		// 論理削除を経ずに消去された場合も在籍人数から外す
+		if err := RecountClassStudents(ctx, tx, classIDsOf(classID)); err != nil {
+			return err
+		}
+
+		erased = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return erased, nil
+}
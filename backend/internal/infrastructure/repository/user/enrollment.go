@@ -0,0 +1,73 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+// RecountClassStudents 指定したクラスの在籍人数（current_students）を再集計する
+// 増減ではなく再集計し、過去の不整合も併せて解消する。生徒の所属・削除状態を変更したトランザクション内で呼ぶこと
+func RecountClassStudents(ctx context.Context, tx database.Executor, classIDs []int64) error {
+	if len(classIDs) == 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE classes c
+		SET current_students = (
+			SELECT COUNT(*) FROM users u
+			WHERE u.class_id = c.id AND u.role = 'student' AND u.deleted_at IS NULL
+		), updated_at = NOW()
+		WHERE c.id = ANY($1)
+	`, pq.Array(classIDs)); err != nil {
+		return fmt.Errorf("failed to update class enrollment: %w", err)
+	}
+	return nil
+}
+
+// lockClassCapacity クラスの行をロックし、userID以外の在籍人数が定員に達していればErrClassFullを返す（新規の生徒はuserIDを空にする）
+// ロックにより同じクラスへの同時の移動で定員を超えないようにする
+func lockClassCapacity(ctx context.Context, tx database.Executor, classID int64, userID string) error {
+	var maxStudents sql.NullInt64
+	err := tx.QueryRowContext(ctx, `SELECT max_students FROM classes WHERE id = $1 FOR UPDATE`, classID).Scan(&maxStudents)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("class not found with id: %d", classID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock class: %w", err)
+	}
+	if !maxStudents.Valid || maxStudents.Int64 <= 0 {
+		return nil
+	}
+
+	var enrolled int64
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM users
+		WHERE class_id = $1 AND role = 'student' AND deleted_at IS NULL
+		  AND id IS DISTINCT FROM NULLIF($2, '')::bigint
+	`, classID, userID).Scan(&enrolled); err != nil {
+		return fmt.Errorf("failed to count class enrollment: %w", err)
+	}
+	if enrolled >= maxStudents.Int64 {
+		return repositories.ErrClassFull
+	}
+	return nil
+}
+
+// classIDsOf NULLでないクラスIDを重複なく返す
+func classIDsOf(ids ...sql.NullInt64) []int64 {
+	classIDs := []int64{}
+	seen := make(map[int64]bool)
+	for _, id := range ids {
+		if id.Valid && !seen[id.Int64] {
+			seen[id.Int64] = true
+			classIDs = append(classIDs, id.Int64)
+		}
+	}
+	return classIDs
+}
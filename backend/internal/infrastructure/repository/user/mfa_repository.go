@@ -0,0 +1,165 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+type mfaRepository struct {
+	db *database.DB
+}
+
+func NewMFARepository(db *database.DB) repositories.MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) GetSettings(ctx context.Context, userID string) (*entities.MFASettings, error) {
+	query := `
+		SELECT user_id::text, secret_encrypted, confirmed_at, last_used_step
+		FROM user_mfa
+		WHERE user_id::text = $1
+	`
+
+	var settings entities.MFASettings
+	var confirmedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&settings.UserID,
+		&settings.SecretEncrypted,
+		&confirmedAt,
+		&settings.LastUsedStep,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get mfa settings: %w", err)
+	}
+
+	if confirmedAt.Valid {
+		settings.ConfirmedAt = &confirmedAt.Time
+	}
+	return &settings, nil
+}
+
+func (r *mfaRepository) SaveSecret(ctx context.Context, userID, secretEncrypted string) error {
+	return r.db.WithTx(ctx, func(tx database.Executor) error {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO user_mfa (user_id, secret_encrypted, confirmed_at, last_used_step, created_at, updated_at)
+			VALUES ($1::bigint, $2, NULL, 0, NOW(), NOW())
+			ON CONFLICT (user_id) DO UPDATE
+			SET secret_encrypted = EXCLUDED.secret_encrypted, confirmed_at = NULL, last_used_step = 0, updated_at = NOW()
+		`, userID, secretEncrypted); err != nil {
+			return fmt.Errorf("failed to save mfa secret: %w", err)
+		}
+
+		// 以前の登録のリカバリーコードは無効にする
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa_recovery_codes WHERE user_id::text = $1`, userID); err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *mfaRepository) Confirm(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error {
+	return r.db.WithTx(ctx, func(tx database.Executor) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE user_mfa SET confirmed_at = NOW(), last_used_step = $2, updated_at = NOW()
+			WHERE user_id::text = $1 AND confirmed_at IS NULL
+		`, userID, step)
+		if err != nil {
+			return fmt.Errorf("failed to confirm mfa: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("mfa enrollment not found for user: %s", userID)
+		}
+
+		return replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes)
+	})
+}
+
+func (r *mfaRepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_mfa SET last_used_step = $2, updated_at = NOW()
+		WHERE user_id::text = $1 AND last_used_step < $2
+	`, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record mfa step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *mfaRepository) Delete(ctx context.Context, userID string) error {
+	return r.db.WithTx(ctx, func(tx database.Executor) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa_recovery_codes WHERE user_id::text = $1`, userID); err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id::text = $1`, userID); err != nil {
+			return fmt.Errorf("failed to delete mfa settings: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	return r.db.WithTx(ctx, func(tx database.Executor) error {
+		return replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	})
+}
+
+// replaceRecoveryCodes 既存のリカバリーコードを削除して新しいコードを登録
+func replaceRecoveryCodes(ctx context.Context, tx database.Executor, userID string, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa_recovery_codes WHERE user_id::text = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, codeHash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO user_mfa_recovery_codes (user_id, code_hash, created_at)
+			VALUES ($1::bigint, $2, NOW())
+		`, userID, codeHash); err != nil {
+			return fmt.Errorf("failed to create recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *mfaRepository) ConsumeRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_mfa_recovery_codes SET used_at = NOW()
+		WHERE user_id::text = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *mfaRepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM user_mfa_recovery_codes WHERE user_id::text = $1 AND used_at IS NULL
+	`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
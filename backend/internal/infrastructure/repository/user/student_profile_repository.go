@@ -0,0 +1,74 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+type studentProfileRepository struct {
+	db *database.DB
+}
+
+func NewStudentProfileRepository(db *database.DB) repositories.StudentProfileRepository {
+	return &studentProfileRepository{db: db}
+}
+
+func (r *studentProfileRepository) FindUserIDByStudentNumber(ctx context.Context, schoolID, studentNumber string) (string, error) {
+	query := `
+		SELECT id::text FROM users
+		WHERE school_id = $1::bigint AND student_number = $2
+	`
+
+	var userID string
+	if err := r.db.QueryRowContext(ctx, query, schoolID, studentNumber).Scan(&userID); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to find user by student number: %w", err)
+	}
+	return userID, nil
+}
+
+func (r *studentProfileRepository) TransferClass(ctx context.Context, userID string, classID *int64) error {
+	return r.db.WithTx(ctx, func(tx database.Executor) error {
+		// 同じ生徒の移動が同時に実行されても在籍人数がずれないよう行をロック
+		var previousClassID sql.NullInt64
+		err := tx.QueryRowContext(ctx, `
+			SELECT class_id FROM users WHERE id = $1 FOR UPDATE
+		`, userID).Scan(&previousClassID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user not found with id: %s", userID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock user for class transfer: %w", err)
+		}
+
+		// 定員の確認は移動先のクラスをロックしてから行う（同時の移動で定員を超えないように）
+		if classID != nil && (!previousClassID.Valid || previousClassID.Int64 != *classID) {
+			if err := lockClassCapacity(ctx, tx, *classID, userID); err != nil {
+				return err
+			}
+		}
+
+		// 学年はクラスの学年に合わせる
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE users
+			SET class_id = $2,
+			    grade = COALESCE((SELECT grade FROM classes WHERE id = $2), grade),
+			    updated_at = NOW()
+			WHERE id = $1
+		`, userID, classID); err != nil {
+			return fmt.Errorf("failed to transfer class: %w", err)
+		}
+
+		var newClassID sql.NullInt64
+		if classID != nil {
+			newClassID = sql.NullInt64{Int64: *classID, Valid: true}
+		}
+		return RecountClassStudents(ctx, tx, classIDsOf(previousClassID, newClassID))
+	})
+}
@@ -0,0 +1,226 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+type userImportRepository struct {
+	db *database.DB
+}
+
+func NewUserImportRepository(db *database.DB) repositories.UserImportRepository {
+	return &userImportRepository{db: db}
+}
+
+func (r *userImportRepository) ListCurrentClasses(ctx context.Context, schoolID string) (map[string]int64, error) {
+	// 年度は学校ごとの開始月（academic_year_start）で切り替える
+	query := `
+		SELECT c.name, c.id
+		FROM classes c
+		JOIN schools s ON s.id = c.school_id
+		WHERE c.school_id = $1::bigint
+		  AND c.is_active = true
+		  AND c.academic_year = CASE
+		      WHEN EXTRACT(MONTH FROM NOW()) >= COALESCE(s.academic_year_start, 4) THEN EXTRACT(YEAR FROM NOW())
+		      ELSE EXTRACT(YEAR FROM NOW()) - 1
+		  END
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query classes: %w", err)
+	}
+	defer rows.Close()
+
+	classes := map[string]int64{}
+	for rows.Next() {
+		var name string
+		var id int64
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, fmt.Errorf("failed to scan class: %w", err)
+		}
+		classes[name] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading classes: %w", err)
+	}
+
+	return classes, nil
+}
+
+func (r *userImportRepository) FindByStudentNumbers(ctx context.Context, schoolID string, studentNumbers []string) (map[string]entities.RosterUser, error) {
+	query := `
+		SELECT id::text, email, role, school_id::text, student_number, class_id
+		FROM users
+		WHERE school_id = $1::bigint AND student_number = ANY($2)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolID, pq.Array(studentNumbers))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query existing users: %w", err)
+	}
+	defer rows.Close()
+
+	byNumber := map[string]entities.RosterUser{}
+	for rows.Next() {
+		var user entities.RosterUser
+		var userSchoolID sql.NullString
+		var classID sql.NullInt64
+		if err := rows.Scan(&user.ID, &user.Email, &user.Role, &userSchoolID, &user.StudentNumber, &classID); err != nil {
+			return nil, fmt.Errorf("failed to scan existing user: %w", err)
+		}
+		user.SchoolID = userSchoolID.String
+		if classID.Valid {
+			user.ClassID = &classID.Int64
+		}
+		byNumber[user.StudentNumber] = user
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading existing users: %w", err)
+	}
+
+	return byNumber, nil
+}
+
+func (r *userImportRepository) FindByEmails(ctx context.Context, emails []string) (map[string]entities.RosterUser, error) {
+	// メールアドレスは全校で一意のため、RLSで見えない他校・削除済みのユーザーも含めて確認する
+	query := `SELECT owner_email, owner_id::text FROM app_email_owners($1)`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(emails))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query email owners: %w", err)
+	}
+	defer rows.Close()
+
+	byEmail := map[string]entities.RosterUser{}
+	for rows.Next() {
+		var user entities.RosterUser
+		if err := rows.Scan(&user.Email, &user.ID); err != nil {
+			return nil, fmt.Errorf("failed to scan email owner: %w", err)
+		}
+		byEmail[user.Email] = user
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading email owners: %w", err)
+	}
+
+	return byEmail, nil
+}
+
+func (r *userImportRepository) LockClassCapacities(ctx context.Context, classIDs []int64) (map[int64]entities.ClassCapacity, error) {
+	capacities := map[int64]entities.ClassCapacity{}
+	if len(classIDs) == 0 {
+		return capacities, nil
+	}
+
+	// 在籍人数はcurrent_studentsではなく実際の所属から数える
+	query := `
+		WITH locked AS (
+			SELECT id, COALESCE(max_students, 0) AS max_students
+			FROM classes WHERE id = ANY($1)
+			ORDER BY id
+			FOR UPDATE
+		)
+		SELECT l.id, l.max_students,
+		       (SELECT COUNT(*) FROM users u
+		        WHERE u.class_id = l.id AND u.role = 'student' AND u.deleted_at IS NULL)
+		FROM locked l
+	`
+
+	err := r.db.WithTx(ctx, func(tx database.Executor) error {
+		rows, err := tx.QueryContext(ctx, query, pq.Array(classIDs))
+		if err != nil {
+			return fmt.Errorf("failed to lock classes: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id int64
+			var capacity entities.ClassCapacity
+			if err := rows.Scan(&id, &capacity.MaxStudents, &capacity.Enrolled); err != nil {
+				return fmt.Errorf("failed to scan class capacity: %w", err)
+			}
+			capacities[id] = capacity
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return capacities, nil
+}
+
+func (r *userImportRepository) UpsertStudent(ctx context.Context, schoolID string, student *entities.StudentImport) (string, bool, error) {
+	// 名簿で空欄の任意項目は既存の値を残す
+	// xmax = 0 の場合は今回挿入された行
+	query := `
+		INSERT INTO users (
+			firebase_uid, name, furigana, email, role, school_id, class_id, student_number, grade,
+			is_active, is_approved, created_at, updated_at
+		) VALUES ($1, $2, NULLIF($3, ''), $4, 'student', $5::bigint, $6, $7, $8, true, true, NOW(), NOW())
+		ON CONFLICT (school_id, student_number) DO UPDATE
+		SET name = EXCLUDED.name,
+		    furigana = COALESCE(EXCLUDED.furigana, users.furigana),
+		    email = EXCLUDED.email,
+		    class_id = COALESCE(EXCLUDED.class_id, users.class_id),
+		    grade = COALESCE(EXCLUDED.grade, users.grade),
+		    updated_at = NOW()
+		RETURNING id::text, (xmax = 0), class_id
+	`
+
+	var userID string
+	var created bool
+	err := r.db.WithTx(ctx, func(tx database.Executor) error {
+		// 既存の生徒は行をロックし、更新前のクラスを在籍人数の再集計に使う
+		var existingID string
+		var previousClassID sql.NullInt64
+		err := tx.QueryRowContext(ctx, `
+			SELECT id::text, class_id FROM users
+			WHERE school_id = $1::bigint AND student_number = $2
+			FOR UPDATE
+		`, schoolID, student.StudentNumber).Scan(&existingID, &previousClassID)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to lock student %s: %w", student.StudentNumber, err)
+		}
+
+		// TransferClassと同じく、移動先のクラスをロックしてから定員を確認する
+		if student.ClassID != nil && (!previousClassID.Valid || previousClassID.Int64 != *student.ClassID) {
+			if err := lockClassCapacity(ctx, tx, *student.ClassID, existingID); err != nil {
+				return err
+			}
+		}
+
+		var classID sql.NullInt64
+		if err := tx.QueryRowContext(ctx, query,
+			student.FirebaseUID,
+			student.Name,
+			student.Furigana,
+			student.Email,
+			schoolID,
+			student.ClassID,
+			student.StudentNumber,
+			student.Grade,
+		).Scan(&userID, &created, &classID); err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "users_email_key" {
+				return fmt.Errorf("%w: %s", repositories.ErrEmailTaken, student.Email)
+			}
+			return fmt.Errorf("failed to upsert student %s: %w", student.StudentNumber, err)
+		}
+
+		return RecountClassStudents(ctx, tx, classIDsOf(previousClassID, classID))
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	return userID, created, nil
+}
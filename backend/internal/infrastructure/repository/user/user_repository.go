@@ -7,34 +7,70 @@ import (
 
 	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
 	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
 )
 
 type userRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
-func NewUserRepository(db *sql.DB) repositories.UserRepository {
+func NewUserRepository(db *database.DB) repositories.UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) FindByUID(ctx context.Context, uid string) (*entities.User, error) {
-    query := `
-        SELECT id, firebase_uid, name as display_name, email, role, school_id::text as school_id, created_at, updated_at
-        FROM users 
-        WHERE firebase_uid = $1
-    `
-	
+const userColumns = `id, firebase_uid, name as display_name, email, role, school_id::text as school_id,
+	furigana, avatar_url, student_number, grade, class_id::text, created_at, updated_at`
+
+func scanUser(row *sql.Row) (*entities.User, error) {
 	var user entities.User
-	err := r.db.QueryRowContext(ctx, query, uid).Scan(
+	var furigana, avatarURL, studentNumber, classID sql.NullString
+	var grade sql.NullInt64
+	err := row.Scan(
 		&user.ID,
 		&user.FirebaseUID,
 		&user.DisplayName,
 		&user.Email,
 		&user.Role,
 		&user.SchoolID,
+		&furigana,
+		&avatarURL,
+		&studentNumber,
+		&grade,
+		&classID,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if furigana.Valid {
+		user.Furigana = &furigana.String
+	}
+	if avatarURL.Valid {
+		user.AvatarURL = &avatarURL.String
+	}
+	if studentNumber.Valid {
+		user.StudentNumber = &studentNumber.String
+	}
+	if grade.Valid {
+		gradeValue := int(grade.Int64)
+		user.Grade = &gradeValue
+	}
+	if classID.Valid {
+		user.ClassID = &classID.String
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUID(ctx context.Context, uid string) (*entities.User, error) {
+    query := `
+        SELECT ` + userColumns + `
+        FROM users 
+        WHERE firebase_uid = $1
+    `
+	
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, uid))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -43,27 +79,17 @@ func (r *userRepository) FindByUID(ctx context.Context, uid string) (*entities.U
 		return nil, fmt.Errorf("failed to find user by uid: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*entities.User, error) {
     query := `
-        SELECT id, firebase_uid, name as display_name, email, role, school_id::text as school_id, created_at, updated_at
+        SELECT ` + userColumns + `
         FROM users 
         WHERE email = $1
     `
 	
-	var user entities.User
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID,
-		&user.FirebaseUID,
-		&user.DisplayName,
-		&user.Email,
-		&user.Role,
-		&user.SchoolID,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, email))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -72,7 +98,7 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*entiti
 		return nil, fmt.Errorf("failed to find user by email: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 func (r *userRepository) Create(ctx context.Context, user *entities.User) error {
@@ -126,7 +152,7 @@ func (r *userRepository) Update(ctx context.Context, user *entities.User) error
 func (r *userRepository) UpdateLastLogin(ctx context.Context, uid string) error {
 	query := `
 		UPDATE users 
-		SET updated_at = NOW() 
+		SET last_login_at = NOW() 
 		WHERE firebase_uid = $1
 	`
 
@@ -144,35 +170,89 @@ func (r *userRepository) FindSchoolByID(ctx context.Context, schoolID int64) (*e
 	return nil, fmt.Errorf("school management not yet implemented for current schema")
 }
 
+// FindSchoolByEmailDomain メールドメインに対応する有効な学校を取得（該当なしの場合はnil）
 func (r *userRepository) FindSchoolByEmailDomain(ctx context.Context, domain string) (*entities.School, error) {
-	// This method will be implemented when school management is needed
-	return nil, fmt.Errorf("school management not yet implemented for current schema")
+	query := `
+		SELECT id, name, code, address, phone_number, created_at, updated_at
+		FROM schools
+		WHERE LOWER(email_domain) = LOWER($1) AND is_active = true AND deleted_at IS NULL
+	`
+
+	var school entities.School
+	var id int64
+	var address, phone sql.NullString
+	err := r.db.QueryRowContext(ctx, query, domain).Scan(
+		&id,
+		&school.SchoolName,
+		&school.SchoolID,
+		&address,
+		&phone,
+		&school.CreatedAt,
+		&school.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find school by email domain: %w", err)
+	}
+
+	school.ID = fmt.Sprintf("%d", id)
+	if address.Valid {
+		school.Address = &address.String
+	}
+	if phone.Valid {
+		school.Phone = &phone.String
+	}
+	return &school, nil
 }
 
 func (r *userRepository) FindClassByID(ctx context.Context, classID int64) (*entities.Class, error) {
-	// This method will be implemented when class management is needed
-	return nil, fmt.Errorf("class management not yet implemented for current schema")
+	query := `
+		SELECT id, school_id, name, grade, academic_year, homeroom_teacher_id, sub_teacher_id,
+			   COALESCE(max_students, 0), COALESCE(current_students, 0), classroom, class_motto,
+			   COALESCE(class_color, ''), COALESCE(is_active, false), created_at, updated_at
+		FROM classes
+		WHERE id = $1
+	`
+
+	var class entities.Class
+	err := r.db.QueryRowContext(ctx, query, classID).Scan(
+		&class.ID,
+		&class.SchoolID,
+		&class.Name,
+		&class.Grade,
+		&class.AcademicYear,
+		&class.HomeroomTeacherID,
+		&class.SubTeacherID,
+		&class.MaxStudents,
+		&class.CurrentStudents,
+		&class.Classroom,
+		&class.ClassMotto,
+		&class.ClassColor,
+		&class.IsActive,
+		&class.CreatedAt,
+		&class.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find class by id: %w", err)
+	}
+
+	return &class, nil
 }
 
 // GetUserByFirebaseUID Firebase UIDでユーザーを取得
 func (r *userRepository) GetUserByFirebaseUID(ctx context.Context, firebaseUID string) (*entities.User, error) {
     query := `
-        SELECT id, firebase_uid, name as display_name, email, role, school_id::text as school_id, created_at, updated_at
+        SELECT ` + userColumns + `
         FROM users 
         WHERE firebase_uid = $1
     `
 	
-	var user entities.User
-	err := r.db.QueryRowContext(ctx, query, firebaseUID).Scan(
-		&user.ID,
-		&user.FirebaseUID,
-		&user.DisplayName,
-		&user.Email,
-		&user.Role,
-		&user.SchoolID,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, firebaseUID))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -181,14 +261,14 @@ func (r *userRepository) GetUserByFirebaseUID(ctx context.Context, firebaseUID s
 		return nil, fmt.Errorf("failed to find user by firebase uid: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // CreateUser ユーザーを作成（戻り値として作成されたユーザーを返す）
 func (r *userRepository) CreateUser(ctx context.Context, user *entities.User) (*entities.User, error) {
     query := `
-        INSERT INTO users (firebase_uid, name, email, role, school_id, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5::bigint, NOW(), NOW())
+        INSERT INTO users (firebase_uid, name, email, role, school_id, password_hash, must_change_password, is_approved, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5::bigint, NULLIF($6, ''), $7, $8, NOW(), NOW())
         RETURNING id, created_at, updated_at
     `
 
@@ -198,6 +278,9 @@ func (r *userRepository) CreateUser(ctx context.Context, user *entities.User) (*
 		user.Email,
 		user.Role,
 		user.SchoolID,
+		user.PasswordHash,
+		user.MustChangePassword,
+		user.IsApproved,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
@@ -208,31 +291,34 @@ func (r *userRepository) CreateUser(ctx context.Context, user *entities.User) (*
 }
 
 // UpdateUser ユーザーを更新（戻り値として更新されたユーザーを返す）
+// 空文字・nilの項目は変更しない。プロフィール項目は空文字を指定すると値を削除する
 func (r *userRepository) UpdateUser(ctx context.Context, userID string, updateData entities.User) (*entities.User, error) {
     query := `
         UPDATE users 
-        SET name = $2, email = $3, role = $4, school_id = $5::bigint, updated_at = NOW()
+        SET name = COALESCE(NULLIF($2, ''), name),
+            email = COALESCE(NULLIF($3, ''), email),
+            role = COALESCE(NULLIF($4, ''), role),
+            school_id = COALESCE(NULLIF($5, '')::bigint, school_id),
+            furigana = CASE WHEN $6::text IS NULL THEN furigana ELSE NULLIF($6, '') END,
+            avatar_url = CASE WHEN $7::text IS NULL THEN avatar_url ELSE NULLIF($7, '') END,
+            student_number = CASE WHEN $8::text IS NULL THEN student_number ELSE NULLIF($8, '') END,
+            grade = COALESCE($9::integer, grade),
+            updated_at = NOW()
         WHERE id = $1
-        RETURNING id, firebase_uid, name as display_name, email, role, school_id::text as school_id, created_at, updated_at
+        RETURNING ` + userColumns + `
     `
 
-	var user entities.User
-	err := r.db.QueryRowContext(ctx, query,
+	user, err := scanUser(r.db.QueryRowContext(ctx, query,
 		userID,
 		updateData.DisplayName,
 		updateData.Email,
 		updateData.Role,
 		updateData.SchoolID,
-	).Scan(
-		&user.ID,
-		&user.FirebaseUID,
-		&user.DisplayName,
-		&user.Email,
-		&user.Role,
-		&user.SchoolID,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+		updateData.Furigana,
+		updateData.AvatarURL,
+		updateData.StudentNumber,
+		updateData.Grade,
+	))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -241,5 +327,5 @@ func (r *userRepository) UpdateUser(ctx context.Context, userID string, updateDa
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
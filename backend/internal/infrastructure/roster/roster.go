@@ -0,0 +1,178 @@
+package roster
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// MaxRows 1ファイルで取り込める最大行数（ヘッダーを除く）
+const MaxRows = 2000
+
+// ErrUnsupportedFormat CSV・XLSX以外のファイル
+var ErrUnsupportedFormat = errors.New("unsupported roster format: use .csv or .xlsx")
+
+// headerAliases 列名（英語・日本語）と項目の対応
+var headerAliases = map[string]string{
+	"name":           "name",
+	"氏名":             "name",
+	"名前":             "name",
+	"furigana":       "furigana",
+	"ふりがな":           "furigana",
+	"フリガナ":           "furigana",
+	"email":          "email",
+	"メールアドレス":        "email",
+	"メール":            "email",
+	"student_number": "student_number",
+	"学籍番号":           "student_number",
+	"grade":          "grade",
+	"学年":             "grade",
+	"class":          "class",
+	"クラス":            "class",
+	"組":              "class",
+}
+
+// requiredColumns 必須の列
+var requiredColumns = []string{"name", "email", "student_number"}
+
+// Parse ファイル名の拡張子に応じてCSVまたはXLSXの名簿を読み込む
+// 1行目はヘッダーとし、空行は読み飛ばす
+func Parse(filename string, r io.Reader) ([]entities.RosterRow, error) {
+	var records [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, err = readCSV(r)
+	case ".xlsx":
+		records, err = readXLSX(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("roster is empty")
+	}
+
+	columns, err := mapHeader(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []entities.RosterRow{}
+	for i, record := range records[1:] {
+		if isBlank(record) {
+			continue
+		}
+		if len(rows) >= MaxRows {
+			return nil, fmt.Errorf("roster has too many rows (max %d)", MaxRows)
+		}
+
+		value := func(field string) string {
+			index, ok := columns[field]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+		rows = append(rows, entities.RosterRow{
+			Line:          i + 2,
+			Name:          value("name"),
+			Furigana:      value("furigana"),
+			Email:         value("email"),
+			StudentNumber: value("student_number"),
+			Grade:         value("grade"),
+			ClassName:     value("class"),
+		})
+	}
+
+	return rows, nil
+}
+
+// readCSV CSVを読み込む（Excelで保存されたShift_JISのファイルにも対応）
+func readCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read roster: %w", err)
+	}
+
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		decoded, _, err := transform.Bytes(japanese.ShiftJIS.NewDecoder(), data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode roster: %w", err)
+		}
+		data = decoded
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse csv: %w", err)
+	}
+	return records, nil
+}
+
+// readXLSX 先頭のシートを読み込む
+func readXLSX(r io.Reader) ([][]string, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open xlsx: %w", err)
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("xlsx has no sheets")
+	}
+
+	records, err := file.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read xlsx rows: %w", err)
+	}
+	return records, nil
+}
+
+// mapHeader ヘッダー行から項目ごとの列番号を求める
+func mapHeader(header []string) (map[string]int, error) {
+	columns := map[string]int{}
+	for i, cell := range header {
+		field, ok := headerAliases[strings.ToLower(strings.TrimSpace(cell))]
+		if !ok {
+			continue
+		}
+		if _, duplicated := columns[field]; duplicated {
+			return nil, fmt.Errorf("duplicate column: %s", cell)
+		}
+		columns[field] = i
+	}
+
+	for _, field := range requiredColumns {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("missing required column: %s", field)
+		}
+	}
+	return columns, nil
+}
+
+func isBlank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
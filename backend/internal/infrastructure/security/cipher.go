@@ -0,0 +1,64 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// Cipher ENCRYPTION_KEYを使ったAES-256-GCMによる暗号化（DBに保存する秘密情報用）
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher 暗号化キーからCipherを生成（キーはSHA-256で256bitに変換する）
+func NewCipher(key string) (*Cipher, error) {
+	if key == "" {
+		return nil, fmt.Errorf("ENCRYPTION_KEY is not configured")
+	}
+
+	derived := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(derived[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt 平文を暗号化し、nonceを先頭に付けてBase64で返す
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt Encryptで暗号化した値を復号
+func (c *Cipher) Decrypt(encoded string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode ciphertext: %w", err)
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", fmt.Errorf("ciphertext is too short")
+	}
+
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
+)
+
+// AcademicYearHandler 学校の年度・学期と年度更新のハンドラー
+type AcademicYearHandler struct {
+	*BaseHandler
+	academicYearUsecase *usecase.AcademicYearUsecase
+}
+
+func NewAcademicYearHandler(academicYearUsecase *usecase.AcademicYearUsecase, authUsecase *usecase.AuthUsecase, cfg *config.Config) *AcademicYearHandler {
+	return &AcademicYearHandler{
+		BaseHandler:         NewBaseHandler(cfg, authUsecase),
+		academicYearUsecase: academicYearUsecase,
+	}
+}
+
+// GetCurrentPeriod 学校の現在の年度と学期を取得
+func (h *AcademicYearHandler) GetCurrentPeriod(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		schoolID, err := convertSchoolIDToInt64(chi.URLParam(r, "id"))
+		if err != nil {
+			h.SendErrorResponse(w, "Invalid school ID", http.StatusBadRequest)
+			return nil
+		}
+
+		period, err := h.academicYearUsecase.CurrentPeriod(r.Context(), schoolID, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, period, http.StatusOK)
+		return nil
+	})
+}
+
+// Rollover 年度更新（from_yearで対象年度を指定、dry_run=trueで件数の確認のみ）
+// 実行時は元に戻せないため二要素認証の本人確認が必要
+func (h *AcademicYearHandler) Rollover(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		schoolID, err := convertSchoolIDToInt64(chi.URLParam(r, "id"))
+		if err != nil {
+			h.SendErrorResponse(w, "Invalid school ID", http.StatusBadRequest)
+			return nil
+		}
+
+		fromYear, ok := parseIntQueryParam(w, r, "from_year")
+		if !ok {
+			return nil
+		}
+		year := 0
+		if fromYear != nil {
+			year = *fromYear
+		}
+
+		dryRun := getBoolQueryParam(r, "dry_run", false)
+		if !dryRun {
+			if err := h.requireStepUp(r, authCtx); err != nil {
+				return err
+			}
+		}
+
+		result, err := h.academicYearUsecase.Rollover(r.Context(), schoolID, year, dryRun, authCtx.RequesterID, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			switch {
+			case errors.Is(err, usecase.ErrInvalidRollover):
+				h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return nil
+			case errors.Is(err, usecase.ErrAcademicYearArchived):
+				h.SendErrorResponse(w, err.Error(), http.StatusConflict)
+				return nil
+			}
+			return err
+		}
+
+		h.SendJSONResponse(w, result, http.StatusOK)
+		return nil
+	})
+}
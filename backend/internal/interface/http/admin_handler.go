@@ -3,11 +3,14 @@ package http
 import (
     "net/http"
     "encoding/json"
+    "errors"
     "strings"
 
     "github.com/go-chi/chi/v5"
     "github.com/rikut0904/bloomia/backend/internal/domain/entities"
+    "github.com/rikut0904/bloomia/backend/internal/domain/policy"
     "github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+    "github.com/rikut0904/bloomia/backend/internal/infrastructure/roster"
     "github.com/rikut0904/bloomia/backend/internal/usecase"
 )
 
@@ -16,23 +19,72 @@ type AdminHandler struct {
 	adminUsecase *usecase.AdminUsecase
 }
 
-func NewAdminHandler(adminUsecase *usecase.AdminUsecase, cfg *config.Config) *AdminHandler {
+func NewAdminHandler(adminUsecase *usecase.AdminUsecase, authUsecase *usecase.AuthUsecase, cfg *config.Config) *AdminHandler {
 	return &AdminHandler{
-		BaseHandler:  NewBaseHandler(cfg),
+		BaseHandler:  NewBaseHandler(cfg, authUsecase),
 		adminUsecase: adminUsecase,
 	}
 }
 
+// GetAllUsers ユーザー一覧を検索・絞り込み・並び替えして取得
+// q（氏名・ふりがな・メールの部分一致）, role, is_approved, is_active, grade, class_id,
+// last_login_from/last_login_to（RFC3339）, deleted（削除済みのみ）, sort, order=asc|desc, page/per_page または cursor
 func (h *AdminHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
 		// ページネーションパラメータを取得
 		page, perPage := getPaginationParams(r)
 		
 		// フィルタパラメータを取得
-		schoolID := getStringQueryParam(r, "school_id")
+		filter := entities.UserListFilter{
+			SchoolID: getStringQueryParam(r, "school_id"),
+			Query:    getStringQueryParam(r, "q"),
+			Role:     getStringQueryParam(r, "role"),
+			ClassID:  getStringQueryParam(r, "class_id"),
+			SortBy:   r.URL.Query().Get("sort"),
+			Page:     page,
+			PerPage:  perPage,
+		}
+
+		var ok bool
+		if filter.IsApproved, ok = parseBoolQueryParam(w, r, "is_approved"); !ok {
+			return nil
+		}
+		if filter.IsActive, ok = parseBoolQueryParam(w, r, "is_active"); !ok {
+			return nil
+		}
+		if filter.Grade, ok = parseIntQueryParam(w, r, "grade"); !ok {
+			return nil
+		}
+		if filter.LastLoginFrom, ok = parseTimeQueryParam(w, r, "last_login_from"); !ok {
+			return nil
+		}
+		if filter.LastLoginTo, ok = parseTimeQueryParam(w, r, "last_login_to"); !ok {
+			return nil
+		}
+		deleted, ok := parseBoolQueryParam(w, r, "deleted")
+		if !ok {
+			return nil
+		}
+		filter.Deleted = deleted != nil && *deleted
+
+		// 並び順は未指定の場合、登録日時の新しい順
+		switch order := r.URL.Query().Get("order"); order {
+		case "asc":
+		case "desc":
+			filter.Descending = true
+		case "":
+			filter.Descending = filter.SortBy == ""
+		default:
+			h.SendErrorResponse(w, "Invalid order: "+order, http.StatusBadRequest)
+			return nil
+		}
 
-		users, err := h.adminUsecase.GetAllUsers(r.Context(), page, perPage, schoolID, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		users, err := h.adminUsecase.GetAllUsers(r.Context(), filter, r.URL.Query().Get("cursor"), authCtx.RequesterRole, authCtx.RequesterSchoolID)
 		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidUserListQuery) {
+				h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
 			return err
 		}
 
@@ -48,6 +100,10 @@ func (h *AdminHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
 			return h.ParseJSONAndValidate(w, r, &req, ValidateUpdateUserRoleRequest)
 		},
 		func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+			if err := h.requireStepUp(r, authCtx); err != nil {
+				return err
+			}
+
 			var req entities.UpdateUserRoleRequest
 			parseJSONRequest(w, r, &req) // Already validated above
 
@@ -69,6 +125,10 @@ func (h *AdminHandler) UpdateUserStatus(w http.ResponseWriter, r *http.Request)
 			return h.ParseJSONAndValidate(w, r, &req, ValidateUpdateUserStatusRequest)
 		},
 		func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+			if err := h.requireStepUp(r, authCtx); err != nil {
+				return err
+			}
+
 			var req UpdateUserStatusRequest
 			parseJSONRequest(w, r, &req) // Already validated above
 
@@ -85,7 +145,7 @@ func (h *AdminHandler) UpdateUserStatus(w http.ResponseWriter, r *http.Request)
 
 func (h *AdminHandler) GetAllSchools(w http.ResponseWriter, r *http.Request) {
 	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
-		schools, err := h.adminUsecase.GetAllSchools(r.Context(), authCtx.RequesterRole)
+		schools, err := h.adminUsecase.GetAllSchools(r.Context(), authCtx.RequesterRole, authCtx.RequesterSchoolID)
 		if err != nil {
 			return err
 		}
@@ -125,17 +185,275 @@ func (h *AdminHandler) InviteUser(w http.ResponseWriter, r *http.Request) {
 			var req InviteUserRequest
 			parseJSONRequest(w, r, &req) // Already validated above
 
-			err := h.adminUsecase.InviteUser(r.Context(), req.Name, req.Email, req.Role, req.SchoolID, req.Message, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+			invitation, err := h.adminUsecase.InviteUser(r.Context(), req.Name, req.Email, req.Role, req.SchoolID, req.Message, authCtx.RequesterRole, authCtx.RequesterSchoolID)
 			if err != nil {
 				return err
 			}
 
-			h.SendSuccessResponse(w, "User invitation sent successfully", nil)
+			h.SendSuccessResponse(w, "User invitation sent successfully", invitation)
 			return nil
 		},
 	)
 }
 
+// ListInvitations 招待一覧
+func (h *AdminHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		// ページネーションパラメータを取得
+		page, perPage := getPaginationParams(r)
+
+		// フィルタパラメータを取得
+		schoolID := getStringQueryParam(r, "school_id")
+		status := getStringQueryParam(r, "status")
+		if status != nil && !ValidInvitationStatuses[*status] {
+			h.SendErrorResponse(w, "Invalid status: "+*status, http.StatusBadRequest)
+			return nil
+		}
+
+		invitations, err := h.adminUsecase.ListInvitations(r.Context(), page, perPage, schoolID, status, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, invitations, http.StatusOK)
+		return nil
+	})
+}
+
+// RevokeInvitation 招待取り消し
+func (h *AdminHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		invitationID := chi.URLParam(r, "id")
+		if invitationID == "" {
+			h.SendErrorResponse(w, "invitation id required", http.StatusBadRequest)
+			return nil
+		}
+
+		if err := h.adminUsecase.RevokeInvitation(r.Context(), invitationID, authCtx.RequesterRole, authCtx.RequesterSchoolID); err != nil {
+			return err
+		}
+
+		h.SendSuccessResponse(w, "Invitation revoked successfully", nil)
+		return nil
+	})
+}
+
+// ResendInvitation 招待再送信（トークン再発行）
+func (h *AdminHandler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		invitationID := chi.URLParam(r, "id")
+		if invitationID == "" {
+			h.SendErrorResponse(w, "invitation id required", http.StatusBadRequest)
+			return nil
+		}
+
+		invitation, err := h.adminUsecase.ResendInvitation(r.Context(), invitationID, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return err
+		}
+
+		h.SendSuccessResponse(w, "Invitation resent successfully", invitation)
+		return nil
+	})
+}
+
+// ForceLogoutUser 管理者用：ユーザーの全端末を強制ログアウト
+func (h *AdminHandler) ForceLogoutUser(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
+
+		userID := chi.URLParam(r, "id")
+		if userID == "" {
+			h.SendErrorResponse(w, "user id required", http.StatusBadRequest)
+			return nil
+		}
+
+		revoked, err := h.adminUsecase.ForceLogoutUser(r.Context(), userID, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return err
+		}
+
+		h.SendSuccessResponse(w, "User logged out successfully", map[string]interface{}{
+			"revoked_sessions": revoked,
+		})
+		return nil
+	})
+}
+
+// DeleteUser 管理者用：ユーザーを削除（猶予期間内は復元可能）
+func (h *AdminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodDelete, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
+
+		userID := chi.URLParam(r, "id")
+		if userID == "" {
+			h.SendErrorResponse(w, "user id required", http.StatusBadRequest)
+			return nil
+		}
+
+		if err := h.adminUsecase.DeleteUser(r.Context(), userID, authCtx.RequesterID, authCtx.RequesterRole, authCtx.RequesterSchoolID); err != nil {
+			if errors.Is(err, usecase.ErrCannotDeleteSelf) {
+				h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
+			return err
+		}
+
+		h.SendSuccessResponse(w, "User deleted successfully", nil)
+		return nil
+	})
+}
+
+// RestoreUser 管理者用：削除したユーザーを猶予期間内に復元
+func (h *AdminHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		userID := chi.URLParam(r, "id")
+		if userID == "" {
+			h.SendErrorResponse(w, "user id required", http.StatusBadRequest)
+			return nil
+		}
+
+		if err := h.adminUsecase.RestoreUser(r.Context(), userID, authCtx.RequesterRole, authCtx.RequesterSchoolID); err != nil {
+			if errors.Is(err, usecase.ErrRestoreUnavailable) {
+				h.SendErrorResponse(w, err.Error(), http.StatusNotFound)
+				return nil
+			}
+			return err
+		}
+
+		h.SendSuccessResponse(w, "User restored successfully", nil)
+		return nil
+	})
+}
+
+// EraseUser 管理者用：削除請求に応じてユーザーの個人情報を直ちに消去（元に戻せない）
+func (h *AdminHandler) EraseUser(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
+
+		userID := chi.URLParam(r, "id")
+		if userID == "" {
+			h.SendErrorResponse(w, "user id required", http.StatusBadRequest)
+			return nil
+		}
+
+		if err := h.adminUsecase.EraseUser(r.Context(), userID, authCtx.RequesterID, authCtx.RequesterRole, authCtx.RequesterSchoolID); err != nil {
+			if errors.Is(err, usecase.ErrCannotDeleteSelf) {
+				h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
+			return err
+		}
+
+		h.SendSuccessResponse(w, "User personal data erased successfully", nil)
+		return nil
+	})
+}
+
+// Impersonate システム管理者用：サポートのため対象ユーザーとして操作できる短時間のトークンを発行
+func (h *AdminHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
+
+		userID := chi.URLParam(r, "id")
+		if userID == "" {
+			h.SendErrorResponse(w, "user id required", http.StatusBadRequest)
+			return nil
+		}
+
+		token, err := h.adminUsecase.Impersonate(r.Context(), userID, authCtx.RequesterUID, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			if errors.Is(err, usecase.ErrImpersonationUnavailable) {
+				h.SendErrorResponse(w, err.Error(), http.StatusConflict)
+				return nil
+			}
+			return err
+		}
+
+		h.SendJSONResponse(w, token, http.StatusCreated)
+		return nil
+	})
+}
+
+// ForceLogoutSchool 管理者用：学校の全ユーザーを強制ログアウト
+func (h *AdminHandler) ForceLogoutSchool(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
+
+		schoolID := chi.URLParam(r, "id")
+		if schoolID == "" {
+			h.SendErrorResponse(w, "school id required", http.StatusBadRequest)
+			return nil
+		}
+
+		revoked, err := h.adminUsecase.ForceLogoutSchool(r.Context(), schoolID, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return err
+		}
+
+		h.SendSuccessResponse(w, "School users logged out successfully", map[string]interface{}{
+			"revoked_sessions": revoked,
+		})
+		return nil
+	})
+}
+
+// maxRosterUploadSize 名簿ファイルの最大サイズ
+const maxRosterUploadSize = 10 << 20
+
+// ImportStudents 管理者用：CSV・XLSXの名簿から生徒を一括登録（multipartのfile。dry_run=trueで検証のみ、invite=falseで招待しない）
+func (h *AdminHandler) ImportStudents(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
+
+		schoolID := chi.URLParam(r, "id")
+		if schoolID == "" {
+			h.SendErrorResponse(w, "school id required", http.StatusBadRequest)
+			return nil
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxRosterUploadSize)
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			h.SendErrorResponse(w, "roster file is required", http.StatusBadRequest)
+			return nil
+		}
+		defer file.Close()
+
+		rows, err := roster.Parse(header.Filename, file)
+		if err != nil {
+			h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return nil
+		}
+
+		dryRun := getBoolQueryParam(r, "dry_run", false)
+		invite := getBoolQueryParam(r, "invite", true)
+		result, err := h.adminUsecase.ImportStudents(r.Context(), schoolID, rows, dryRun, invite, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return err
+		}
+
+		status := http.StatusOK
+		if len(result.Errors) > 0 {
+			status = http.StatusUnprocessableEntity
+		}
+		h.SendJSONResponse(w, result, status)
+		return nil
+	})
+}
+
 // GetUserByID 管理者用：ユーザー詳細
 func (h *AdminHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
     // 開発環境では認証をバイパス
@@ -178,11 +496,19 @@ func (h *AdminHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 // UpdateUser 管理者用：ユーザー更新
 func (h *AdminHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
     h.HandleWithAuth(w, r, http.MethodPut, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+        if err := h.requireStepUp(r, authCtx); err != nil {
+        	return err
+        }
+
         var req struct {
             Name     *string `json:"name"`
             Email    *string `json:"email"`
             Role     *string `json:"role"`
             SchoolID *string `json:"school_id"`
+            Furigana      *string `json:"furigana"`
+            AvatarURL     *string `json:"avatar_url"`
+            StudentNumber *string `json:"student_number"`
+            Grade         *int    `json:"grade"`
         }
         if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
             h.SendErrorResponse(w, "Invalid request body", http.StatusBadRequest)
@@ -199,9 +525,17 @@ func (h *AdminHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
         if req.Email != nil { update.Email = *req.Email }
         if req.Role != nil { update.Role = *req.Role }
         if req.SchoolID != nil { update.SchoolID = *req.SchoolID }
+        update.Furigana = req.Furigana
+        update.AvatarURL = req.AvatarURL
+        update.StudentNumber = req.StudentNumber
+        update.Grade = req.Grade
 
         updated, err := h.adminUsecase.UpdateUser(r.Context(), userID, update, authCtx.RequesterRole, authCtx.RequesterSchoolID)
         if err != nil {
+            if errors.Is(err, usecase.ErrInvalidStudentProfile) {
+                h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+                return nil
+            }
             return err
         }
         h.SendJSONResponse(w, map[string]interface{}{"success": true, "user": updated}, http.StatusOK)
@@ -209,9 +543,38 @@ func (h *AdminHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
     })
 }
 
+// TransferClass 管理者用：生徒のクラスを移動（class_idがnullの場合はクラスから外す）
+func (h *AdminHandler) TransferClass(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPut, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		userID := chi.URLParam(r, "id")
+		if userID == "" {
+			h.SendErrorResponse(w, "user id required", http.StatusBadRequest)
+			return nil
+		}
+
+		var req entities.ClassTransferRequest
+		if !parseJSONRequest(w, r, &req) {
+			return nil
+		}
+
+		updated, err := h.adminUsecase.TransferClass(r.Context(), userID, req.ClassID, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidStudentProfile) {
+				h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
+			return err
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{"success": true, "user": updated}, http.StatusOK)
+		return nil
+	})
+}
+
 // AdminCreateSchoolRequest 管理者用 学校作成リクエスト
 type AdminCreateSchoolRequest struct {
     Name           string  `json:"name"`
+    Code           string  `json:"code,omitempty"` // 省略した場合は都道府県ごとの連番で採番する
     Type           *string `json:"type,omitempty"`
     Prefecture     *string `json:"prefecture,omitempty"`
     City           *string `json:"city,omitempty"`
@@ -224,10 +587,13 @@ type AdminCreateSchoolRequest struct {
 // CreateSchool 管理者用に学校を作成（READMEのスキーマに準拠）
 func (h *AdminHandler) CreateSchool(w http.ResponseWriter, r *http.Request) {
     h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
-        if authCtx.RequesterRole != "admin" && authCtx.RequesterRole != "school_admin" {
+        if !policy.Allows(authCtx.RequesterRole, policy.SchoolsCreate) {
             h.SendErrorResponse(w, "insufficient permissions", http.StatusForbidden)
             return nil
         }
+        if err := h.requireStepUp(r, authCtx); err != nil {
+            return err
+        }
 
         var req AdminCreateSchoolRequest
         if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -239,8 +605,6 @@ func (h *AdminHandler) CreateSchool(w http.ResponseWriter, r *http.Request) {
             return nil
         }
 
-        // コード生成（name から slug）
-        code := generateCodeFromName(req.Name)
         // 住所はプレーンに連結
         var fullAddress *string
         if req.Address != nil || req.Prefecture != nil || req.City != nil {
@@ -252,14 +616,23 @@ func (h *AdminHandler) CreateSchool(w http.ResponseWriter, r *http.Request) {
         }
 
         school := &entities.School{
-            SchoolID:   code,
+            SchoolID:   req.Code,
             SchoolName: req.Name,
+            Prefecture: req.Prefecture,
             Address:    fullAddress,
             Phone:      req.Phone,
         }
 
         created, err := h.adminUsecase.CreateSchool(r.Context(), school)
         if err != nil {
+            if errors.Is(err, usecase.ErrInvalidSchoolCode) {
+                h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+                return nil
+            }
+            if errors.Is(err, usecase.ErrSchoolCodeTaken) {
+                h.SendErrorResponse(w, err.Error(), http.StatusConflict)
+                return nil
+            }
             return err
         }
 
@@ -267,20 +640,3 @@ func (h *AdminHandler) CreateSchool(w http.ResponseWriter, r *http.Request) {
         return nil
     })
 }
-
-func generateCodeFromName(name string) string {
-    code := ""
-    for _, r := range name {
-        if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
-            code += string(r)
-        } else if r >= 'A' && r <= 'Z' {
-            code += string(r + 32)
-        } else if r == ' ' || r == '　' {
-            code += "-"
-        }
-    }
-    if code == "" {
-        code = "school"
-    }
-    return code
-}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/middleware"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
+)
+
+// ApprovalHandler 自己登録ユーザーの承認ワークフローのハンドラー
+type ApprovalHandler struct {
+	*BaseHandler
+	approvalUsecase *usecase.ApprovalUsecase
+}
+
+func NewApprovalHandler(approvalUsecase *usecase.ApprovalUsecase, authUsecase *usecase.AuthUsecase, cfg *config.Config) *ApprovalHandler {
+	return &ApprovalHandler{
+		BaseHandler:     NewBaseHandler(cfg, authUsecase),
+		approvalUsecase: approvalUsecase,
+	}
+}
+
+// GetAccountStatus 自分のアカウントの承認状況を取得
+// 承認待ち・却下されたユーザーも参照できるよう、承認済みを要求する認証処理は通さない
+func (h *ApprovalHandler) GetAccountStatus(w http.ResponseWriter, r *http.Request) {
+	if !validateMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	authUser, ok := middleware.GetAuthUserFromContext(r.Context())
+	if !ok {
+		h.SendErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	status, err := h.approvalUsecase.GetAccountStatus(r.Context(), authUser.UID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			h.SendErrorResponse(w, "User account not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to get account status for uid %s: %v", authUser.UID, err)
+		h.SendErrorResponse(w, "Failed to get account status", http.StatusInternalServerError)
+		return
+	}
+
+	h.SendJSONResponse(w, status, http.StatusOK)
+}
+
+// ListPending 学校の承認待ちユーザー一覧を取得
+func (h *ApprovalHandler) ListPending(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		schoolID := chi.URLParam(r, "id")
+		if schoolID == "" {
+			h.SendErrorResponse(w, "school id required", http.StatusBadRequest)
+			return nil
+		}
+
+		page, perPage := getPaginationParams(r)
+		approvals, err := h.approvalUsecase.ListPending(r.Context(), schoolID, page, perPage, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, approvals, http.StatusOK)
+		return nil
+	})
+}
+
+// Approve 承認待ちのユーザーを一括承認（{"user_ids": [...], "reason": "..."}）
+func (h *ApprovalHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.approvalUsecase.Approve)
+}
+
+// Reject 承認待ちのユーザーを一括却下（reasonは必須で、本人への通知に含まれる）
+func (h *ApprovalHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.approvalUsecase.Reject)
+}
+
+// review 一括承認・却下の共通処理
+func (h *ApprovalHandler) review(w http.ResponseWriter, r *http.Request, review func(ctx context.Context, schoolID string, req entities.ApprovalReviewRequest, requesterID string, requesterRole string, requesterSchoolID string) (*entities.ApprovalReviewResult, error)) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		schoolID := chi.URLParam(r, "id")
+		if schoolID == "" {
+			h.SendErrorResponse(w, "school id required", http.StatusBadRequest)
+			return nil
+		}
+
+		var req entities.ApprovalReviewRequest
+		if !parseJSONRequest(w, r, &req) {
+			return nil
+		}
+
+		result, err := review(r.Context(), schoolID, req, authCtx.RequesterID, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidApprovalRequest) {
+				h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
+			return err
+		}
+
+		h.SendJSONResponse(w, result, http.StatusOK)
+		return nil
+	})
+}
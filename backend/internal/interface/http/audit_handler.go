@@ -0,0 +1,85 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
+)
+
+// AuditHandler 監査ログの閲覧・改ざん検証のハンドラー
+type AuditHandler struct {
+	*BaseHandler
+	auditUsecase *usecase.AuditUsecase
+}
+
+func NewAuditHandler(auditUsecase *usecase.AuditUsecase, authUsecase *usecase.AuthUsecase, cfg *config.Config) *AuditHandler {
+	return &AuditHandler{
+		BaseHandler:  NewBaseHandler(cfg, authUsecase),
+		auditUsecase: auditUsecase,
+	}
+}
+
+// ListEvents 監査ログ一覧（school_id, actor_id, impersonator_id, action, target_type, target_id, from, to で絞り込み）
+func (h *AuditHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		page, perPage := getPaginationParams(r)
+
+		filter := entities.AuditEventFilter{
+			SchoolID:   getStringQueryParam(r, "school_id"),
+			ActorID:    getStringQueryParam(r, "actor_id"),
+			Action:     getStringQueryParam(r, "action"),
+			TargetType: getStringQueryParam(r, "target_type"),
+			TargetID:   getStringQueryParam(r, "target_id"),
+
+			ImpersonatorID: getStringQueryParam(r, "impersonator_id"),
+		}
+		for key, value := range map[string]*string{"school_id": filter.SchoolID, "actor_id": filter.ActorID, "impersonator_id": filter.ImpersonatorID} {
+			if value != nil {
+				if _, err := strconv.ParseInt(*value, 10, 64); err != nil {
+					h.SendErrorResponse(w, "Invalid "+key+": "+*value, http.StatusBadRequest)
+					return nil
+				}
+			}
+		}
+
+		var ok bool
+		if filter.From, ok = parseTimeQueryParam(w, r, "from"); !ok {
+			return nil
+		}
+		if filter.To, ok = parseTimeQueryParam(w, r, "to"); !ok {
+			return nil
+		}
+
+		events, err := h.auditUsecase.ListEvents(r.Context(), filter, page, perPage, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, events, http.StatusOK)
+		return nil
+	})
+}
+
+// VerifyChain 監査ログのハッシュチェーンを検証（school_id省略時は学校に属さない操作のチェーン）
+func (h *AuditHandler) VerifyChain(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		schoolID := getStringQueryParam(r, "school_id")
+		if schoolID != nil {
+			if _, err := strconv.ParseInt(*schoolID, 10, 64); err != nil {
+				h.SendErrorResponse(w, "Invalid school_id: "+*schoolID, http.StatusBadRequest)
+				return nil
+			}
+		}
+
+		result, err := h.auditUsecase.VerifyChain(r.Context(), schoolID, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, result, http.StatusOK)
+		return nil
+	})
+}
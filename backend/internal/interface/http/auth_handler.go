@@ -2,9 +2,13 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strings"
 
 	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/middleware"
 	"github.com/rikut0904/bloomia/backend/internal/usecase"
 )
 
@@ -15,7 +19,7 @@ type AuthHandler struct {
 
 func NewAuthHandler(authUsecase *usecase.AuthUsecase, cfg *config.Config) *AuthHandler {
 	return &AuthHandler{
-		BaseHandler: NewBaseHandler(cfg),
+		BaseHandler: NewBaseHandler(cfg, authUsecase),
 		authUsecase: authUsecase,
 	}
 }
@@ -52,24 +56,29 @@ func (h *AuthHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
-// ValidateInvitation 招待トークンを検証
+// ValidateInvitation 招待トークンを検証（登録前のため認証不要）
 func (h *AuthHandler) ValidateInvitation(w http.ResponseWriter, r *http.Request) {
-	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
-		token := r.URL.Query().Get("token")
-		if token == "" {
-			h.SendErrorResponse(w, "Token is required", http.StatusBadRequest)
-			return nil
-		}
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-		invitation, err := h.authUsecase.ValidateInvitation(r.Context(), token)
-		if err != nil {
-			return err
-		}
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		writeErrorResponse(w, "Token is required", http.StatusBadRequest)
+		return
+	}
+
+	invitation, err := h.authUsecase.ValidateInvitation(r.Context(), token)
+	if err != nil {
+		writeErrorResponse(w, err.Error(), http.StatusNotFound)
+		return
+	}
 
-		h.SendJSONResponse(w, map[string]interface{}{
-			"invitation": invitation,
-		}, http.StatusOK)
-		return nil
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"invitation": invitation,
 	})
 }
 
@@ -98,6 +107,16 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Firebase UIDは検証済みトークンからのみ取得（トークンがない場合はパスワードログイン用のアカウントを作成）
+	var firebaseUID string
+	if authUser, ok := middleware.GetAuthUserFromContext(r.Context()); ok {
+		if authUser.Email != "" && !strings.EqualFold(authUser.Email, strings.TrimSpace(req.Email)) {
+			writeErrorResponse(w, "Email does not match the token", http.StatusForbidden)
+			return
+		}
+		firebaseUID = authUser.UID
+	}
+
 	// ユーザー登録
 	user, err := h.authUsecase.RegisterUser(
 		r.Context(),
@@ -105,6 +124,7 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		req.Name,
 		req.Email,
 		req.Password,
+		firebaseUID,
 	)
 	if err != nil {
 		writeErrorResponse(w, err.Error(), http.StatusBadRequest)
@@ -119,7 +139,7 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// LoginUser ユーザーログイン
+// LoginUser メールアドレスとパスワードでログイン
 func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -127,8 +147,9 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email      string `json:"email"`
+		Password   string `json:"password"`
+		DeviceName string `json:"device_name"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -142,22 +163,127 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// ログイン
-	user, err := h.authUsecase.LoginUser(r.Context(), req.Email, req.Password)
+	// ログイン（初回ログイン時はパスワード変更が必要な旨のみ返す）
+	result, err := h.authUsecase.LoginUser(r.Context(), req.Email, req.Password, sessionClient(r, req.DeviceName))
 	if err != nil {
-		writeErrorResponse(w, err.Error(), http.StatusUnauthorized)
+		writePasswordAuthError(w, err)
 		return
 	}
 
-	// JWTトークンを生成（簡略化）
-	token := "jwt_token_here" // 実際のJWT実装が必要
+	writeJSONResponse(w, result, http.StatusOK)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"user":  user,
-		"token": token,
-	})
+// ChangePassword 現在のパスワードを確認して変更（初回ログイン時の強制変更を含む）
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Email           string `json:"email"`
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+		DeviceName      string `json:"device_name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Email == "" || req.CurrentPassword == "" || req.NewPassword == "" {
+		writeErrorResponse(w, "Email, current password and new password are required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.authUsecase.ChangePassword(r.Context(), req.Email, req.CurrentPassword, req.NewPassword, sessionClient(r, req.DeviceName))
+	if err != nil {
+		writePasswordAuthError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, result, http.StatusOK)
+}
+
+// RequestPasswordReset パスワード再設定メールの送信
+func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Email == "" {
+		writeErrorResponse(w, "Email is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authUsecase.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		log.Printf("Failed to request password reset: %v", err)
+		writeErrorResponse(w, "Failed to request password reset", http.StatusInternalServerError)
+		return
+	}
+
+	// アカウントの有無にかかわらず同じレスポンスを返す
+	writeSuccessResponse(w, "If the account exists, a password reset email has been sent", nil)
+}
+
+// ResetPassword 再設定トークンで新しいパスワードを設定
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Token == "" || req.NewPassword == "" {
+		writeErrorResponse(w, "Token and new password are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authUsecase.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		writePasswordAuthError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, "Password has been reset successfully", nil)
+}
+
+// writePasswordAuthError パスワード認証のエラーをステータスコードに変換して送信
+func writePasswordAuthError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidCredentials):
+		writeErrorResponse(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, usecase.ErrAccountLocked):
+		writeErrorResponse(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, usecase.ErrAccountDisabled):
+		writeErrorResponse(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, usecase.ErrInvalidResetToken),
+		errors.Is(err, usecase.ErrWeakPassword),
+		errors.Is(err, usecase.ErrPasswordReused):
+		writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("Password authentication failed: %v", err)
+		writeErrorResponse(w, "Authentication failed", http.StatusInternalServerError)
+	}
 }
 
 // VerifyUser Firebase UIDと管理者権限を検証
@@ -248,8 +374,6 @@ func (h *AuthHandler) syncUserCreate(w http.ResponseWriter, r *http.Request) {
 		FirebaseUID string  `json:"firebase_uid"`
 		Email       string  `json:"email"`
 		DisplayName *string `json:"display_name"`
-		SchoolID    *string `json:"school_id"`
-		Role        *string `json:"role"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -257,21 +381,49 @@ func (h *AuthHandler) syncUserCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.FirebaseUID == "" || req.Email == "" {
+	// UID・メールアドレスは検証済みトークンの値のみ使用（リクエストボディの値はトークンと一致する場合のみ許可）
+	var (
+		firebaseUID   string
+		email         string
+		emailVerified bool
+	)
+	if authUser, ok := middleware.GetAuthUserFromContext(r.Context()); ok {
+		if req.FirebaseUID != "" && req.FirebaseUID != authUser.UID {
+			writeErrorResponse(w, "Firebase UID does not match the token", http.StatusForbidden)
+			return
+		}
+		firebaseUID = authUser.UID
+		email = authUser.Email
+		emailVerified = authUser.EmailVerified
+	} else if h.config.DisableAuth {
+		// 開発環境：認証を無効化している場合のみボディの値を使用
+		firebaseUID = req.FirebaseUID
+		email = req.Email
+		emailVerified = true
+	} else {
+		writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	if firebaseUID == "" || email == "" {
 		writeErrorResponse(w, "Firebase UID and email are required", http.StatusBadRequest)
 		return
 	}
 
 	user, err := h.authUsecase.SyncUser(
 		r.Context(),
-		req.FirebaseUID,
-		req.Email,
+		firebaseUID,
+		email,
+		emailVerified,
 		req.DisplayName,
-		req.SchoolID,
-		req.Role,
 	)
 	if err != nil {
-		writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, usecase.ErrSignupNotAllowed), errors.Is(err, usecase.ErrUnverifiedSignIn):
+			writeErrorResponse(w, err.Error(), http.StatusForbidden)
+		default:
+			writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -284,8 +436,19 @@ func (h *AuthHandler) syncUserCreate(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// syncUserGet 自分のユーザー情報を取得（uidを指定する場合はトークンのUIDと一致する必要がある）
 func (h *AuthHandler) syncUserGet(w http.ResponseWriter, r *http.Request) {
 	firebaseUID := r.URL.Query().Get("uid")
+	if authUser, ok := middleware.GetAuthUserFromContext(r.Context()); ok {
+		if firebaseUID != "" && firebaseUID != authUser.UID {
+			writeErrorResponse(w, "Firebase UID does not match the token", http.StatusForbidden)
+			return
+		}
+		firebaseUID = authUser.UID
+	} else if !h.config.DisableAuth {
+		writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
 	if firebaseUID == "" {
 		writeErrorResponse(w, "Firebase UID is required", http.StatusBadRequest)
 		return
@@ -1,21 +1,182 @@
 package http
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
 	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/middleware"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
 )
 
+// TenantRunner 認証済みリクエストをRLS用のテナントトランザクション内で実行する
+type TenantRunner interface {
+	RunAsTenant(ctx context.Context, tenant database.Tenant, fn func(ctx context.Context) error) error
+}
+
+// sessionHeader ログインセッションIDを送るリクエストヘッダー
+const sessionHeader = "X-Session-ID"
+
 // BaseHandler 共通ハンドラー機能を提供する構造体
 type BaseHandler struct {
-	config *config.Config
+	config         *config.Config
+	authUsecase    *usecase.AuthUsecase
+	sessionUsecase *usecase.SessionUsecase
+	mfaUsecase     *usecase.MFAUsecase
+	tenantRunner   TenantRunner
 }
 
 // NewBaseHandler ベースハンドラーのコンストラクタ
-func NewBaseHandler(cfg *config.Config) *BaseHandler {
+func NewBaseHandler(cfg *config.Config, authUsecase *usecase.AuthUsecase) *BaseHandler {
 	return &BaseHandler{
-		config: cfg,
+		config:      cfg,
+		authUsecase: authUsecase,
+	}
+}
+
+// SetTenantRunner allows injecting TenantRunner
+func (b *BaseHandler) SetTenantRunner(runner TenantRunner) {
+	b.tenantRunner = runner
+}
+
+// SetSessionUsecase allows injecting SessionUsecase
+func (b *BaseHandler) SetSessionUsecase(sessionUsecase *usecase.SessionUsecase) {
+	b.sessionUsecase = sessionUsecase
+}
+
+// SetMFAUsecase allows injecting MFAUsecase
+func (b *BaseHandler) SetMFAUsecase(mfaUsecase *usecase.MFAUsecase) {
+	b.mfaUsecase = mfaUsecase
+}
+
+// requireStepUp 重要な管理操作の前に、直近の二要素認証での本人確認を求める
+func (b *BaseHandler) requireStepUp(r *http.Request, authCtx AuthContext) error {
+	// 代理ログイン中は本人確認ができないため、重要な操作は行えない
+	if err := forbidImpersonation(authCtx); err != nil {
+		return err
+	}
+	if b.mfaUsecase == nil || b.config.DisableAuth {
+		return nil
+	}
+	return b.mfaUsecase.RequireStepUp(r.Context(), authCtx.RequesterID, authCtx.RequesterRole, authCtx.SessionID)
+}
+
+// forbidImpersonation 代理ログイン中は行えない操作（セッション作成・二要素認証の設定など本人のみの操作）
+func forbidImpersonation(authCtx AuthContext) error {
+	if authCtx.ImpersonatorUID != "" {
+		return fmt.Errorf("%w: not allowed while impersonating", policy.ErrForbidden)
+	}
+	return nil
+}
+
+// authenticate 検証済みトークンとusersテーブルから認証コンテキストを構築
+// 失敗した場合はエラーレスポンスを書き込みfalseを返す
+func (b *BaseHandler) authenticate(w http.ResponseWriter, r *http.Request) (AuthContext, bool) {
+	// 開発環境：認証を無効化
+	if b.config.DisableAuth {
+		return getMockAuthContext(b.config), true
+	}
+
+	authUser, ok := middleware.GetAuthUserFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return AuthContext{}, false
+	}
+
+	// ロール・所属校・アカウント状態はクレームではなくDBの値を正とする
+	account, err := b.resolveAccount(r.Context(), authUser.UID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			writeErrorResponse(w, "User account not found", http.StatusForbidden)
+			return AuthContext{}, false
+		}
+		log.Printf("Failed to resolve account for uid %s: %v", authUser.UID, err)
+		writeErrorResponse(w, "Failed to resolve user account", http.StatusInternalServerError)
+		return AuthContext{}, false
+	}
+	if !account.IsActive {
+		writeErrorResponse(w, "User account is inactive", http.StatusForbidden)
+		return AuthContext{}, false
 	}
+	if !account.IsApproved {
+		writeErrorResponse(w, "User account is not approved", http.StatusForbidden)
+		return AuthContext{}, false
+	}
+
+	authCtx := AuthContext{
+		RequesterID:     account.UserID,
+		RequesterUID:    authUser.UID,
+		RequesterRole:   account.Role,
+		ImpersonatorUID: authUser.ImpersonatorUID,
+	}
+	if account.SchoolID != nil {
+		authCtx.RequesterSchoolID = *account.SchoolID
+	}
+	// 教育委員会管理者の管轄はResolveDistrictScopeが設定したものを使う
+	if scope := policy.DistrictScopeFromContext(r.Context()); scope != nil {
+		authCtx.DistrictID = scope.DistrictID
+		authCtx.DistrictSchoolIDs = scope.SchoolIDs
+	}
+
+	// セッションが失効していないか確認（強制ログアウト済みの端末を拒否）
+	// ローカル発行トークンはsidクレームのセッションが必須で、X-Session-IDはトークンのセッションと一致する必要がある
+	sessionID := authUser.SessionID
+	if header := r.Header.Get(sessionHeader); header != "" {
+		if sessionID != "" && header != sessionID {
+			writeErrorResponse(w, "Session does not match token", http.StatusUnauthorized)
+			return AuthContext{}, false
+		}
+		sessionID = header
+	}
+	if authUser.SessionID != "" && b.sessionUsecase == nil {
+		log.Printf("Session usecase is not configured; cannot validate session %s", sessionID)
+		writeErrorResponse(w, "Failed to validate session", http.StatusInternalServerError)
+		return AuthContext{}, false
+	}
+	if sessionID != "" && b.sessionUsecase != nil {
+		if err := b.sessionUsecase.ValidateSession(r.Context(), sessionID, account.UserID, clientIP(r)); err != nil {
+			if errors.Is(err, usecase.ErrSessionInvalid) {
+				writeErrorResponse(w, "Session has been revoked", http.StatusUnauthorized)
+				return AuthContext{}, false
+			}
+			log.Printf("Failed to validate session %s: %v", sessionID, err)
+			writeErrorResponse(w, "Failed to validate session", http.StatusInternalServerError)
+			return AuthContext{}, false
+		}
+		authCtx.SessionID = sessionID
+	}
+
+	return authCtx, true
+}
+
+// resolveAccount RequireApprovedAccountが解決したアカウントを使い、未解決の場合はDBから取得する
+func (b *BaseHandler) resolveAccount(ctx context.Context, firebaseUID string) (*entities.AccountStatus, error) {
+	if account, ok := middleware.GetAccountFromContext(ctx); ok {
+		return account, nil
+	}
+
+	account, err := b.authUsecase.GetAccountByFirebaseUID(ctx, firebaseUID)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.AccountStatus{
+		UserID:     account.ID,
+		Name:       account.Name,
+		Email:      account.Email,
+		Role:       account.Role,
+		SchoolID:   account.SchoolID,
+		IsActive:   account.IsActive,
+		IsApproved: account.IsApproved,
+	}, nil
 }
 
 // HandleWithAuth 認証付きリクエストハンドラー
@@ -31,13 +192,13 @@ func (b *BaseHandler) HandleWithAuth(
 	}
 
 	// 認証コンテキストを取得
-	authCtx := getAuthContext(b.config)
-
-	// ハンドラー実行
-	if err := handler(w, r, authCtx); err != nil {
-		writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	authCtx, ok := b.authenticate(w, r)
+	if !ok {
 		return
 	}
+
+	// ハンドラー実行
+	b.runHandler(w, r, authCtx, handler)
 }
 
 // HandleWithValidation バリデーション付きリクエストハンドラー
@@ -59,13 +220,109 @@ func (b *BaseHandler) HandleWithValidation(
 	}
 
 	// 認証コンテキストを取得
-	authCtx := getAuthContext(b.config)
+	authCtx, ok := b.authenticate(w, r)
+	if !ok {
+		return
+	}
 
 	// ハンドラー実行
-	if err := handler(w, r, authCtx); err != nil {
-		writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	b.runHandler(w, r, authCtx, handler)
+}
+
+// StreamWithAuth 認証付きでレスポンスをバッファせずに書き出すハンドラー（読み取り専用の大量出力向け）
+// 出力開始後はステータスを変更できないため、ハンドラーは出力前にエラーを返すこと
+func (b *BaseHandler) StreamWithAuth(
+	w http.ResponseWriter,
+	r *http.Request,
+	method string,
+	handler func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error,
+) {
+	if !validateMethod(w, r, method) {
+		return
+	}
+
+	authCtx, ok := b.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	r = withAuditActor(r, authCtx)
+	if b.tenantRunner == nil {
+		if err := handler(w, r, authCtx); err != nil {
+			writeHandlerError(w, err)
+		}
+		return
+	}
+
+	err := b.tenantRunner.RunAsTenant(r.Context(), authCtx.tenant(), func(ctx context.Context) error {
+		return handler(w, r.WithContext(ctx), authCtx)
+	})
+	if err != nil {
+		writeHandlerError(w, err)
+	}
+}
+
+// runHandler ハンドラーを実行（TenantRunnerが設定されている場合はRLS用のトランザクション内で実行）
+func (b *BaseHandler) runHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+	authCtx AuthContext,
+	handler func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error,
+) {
+	r = withAuditActor(r, authCtx)
+
+	if b.tenantRunner == nil {
+		if err := handler(w, r, authCtx); err != nil {
+			writeHandlerError(w, err)
+		}
+		return
+	}
+
+	// コミットの成否が確定するまでレスポンスをバッファする
+	buffered := newBufferedResponseWriter()
+	err := b.tenantRunner.RunAsTenant(r.Context(), authCtx.tenant(), func(ctx context.Context) error {
+		return handler(buffered, r.WithContext(ctx), authCtx)
+	})
+	if err != nil {
+		writeHandlerError(w, err)
 		return
 	}
+	buffered.flushTo(w)
+}
+
+// withAuditActor 認証コンテキストの内容を監査ログの操作者に反映
+func withAuditActor(r *http.Request, authCtx AuthContext) *http.Request {
+	actor := audit.Actor{}
+	if existing := audit.ActorFromContext(r.Context()); existing != nil {
+		actor = *existing
+	}
+
+	actor.UserID = authCtx.RequesterID
+	actor.Role = authCtx.RequesterRole
+	actor.SchoolID = authCtx.RequesterSchoolID
+	if authCtx.RequesterUID != "" {
+		actor.UID = authCtx.RequesterUID
+	}
+	return r.WithContext(audit.NewContext(r.Context(), &actor))
+}
+
+// writeHandlerError ハンドラーのエラーをステータスコードに変換して送信
+func writeHandlerError(w http.ResponseWriter, err error) {
+	if errors.Is(err, policy.ErrForbidden) {
+		writeErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	// クライアントは二要素認証の登録・入力画面へ誘導する
+	if errors.Is(err, usecase.ErrStepUpRequired) || errors.Is(err, usecase.ErrMFAEnrollmentRequired) {
+		writeErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	// 名簿の取り込み中に他の操作と競合した場合は取り消して、再度の取り込みを促す
+	if errors.Is(err, usecase.ErrImportConflict) {
+		writeErrorResponse(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
 }
 
 // ParseJSONAndValidate JSONパースとバリデーションを一括実行
@@ -101,4 +358,36 @@ func (b *BaseHandler) SendJSONResponse(w http.ResponseWriter, data interface{},
 // SendErrorResponse エラーレスポンスを送信
 func (b *BaseHandler) SendErrorResponse(w http.ResponseWriter, message string, status int) {
 	writeErrorResponse(w, message, status)
-}
\ No newline at end of file
+}
+
+// bufferedResponseWriter トランザクション完了までレスポンスを保持するResponseWriter
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedResponseWriter() *bufferedResponseWriter {
+	return &bufferedResponseWriter{header: http.Header{}, status: http.StatusOK}
+}
+
+func (b *bufferedResponseWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+func (b *bufferedResponseWriter) WriteHeader(status int) {
+	b.status = status
+}
+
+// flushTo 保持したレスポンスを書き出す
+func (b *bufferedResponseWriter) flushTo(w http.ResponseWriter) {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(b.status)
+	w.Write(b.body.Bytes())
+}
@@ -1,103 +1,61 @@
 package http
 
 import (
-	"encoding/json"
 	"net/http"
 
-	"github.com/rikut0904/bloomia/backend/internal/infrastructure/middleware/auth"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
 	"github.com/rikut0904/bloomia/backend/internal/usecase"
 )
 
 type DashboardHandler struct {
+	*BaseHandler
 	dashboardUsecase *usecase.DashboardUsecase
 }
 
-func NewDashboardHandler(dashboardUsecase *usecase.DashboardUsecase) *DashboardHandler {
+func NewDashboardHandler(dashboardUsecase *usecase.DashboardUsecase, authUsecase *usecase.AuthUsecase, cfg *config.Config) *DashboardHandler {
 	return &DashboardHandler{
+		BaseHandler:      NewBaseHandler(cfg, authUsecase),
 		dashboardUsecase: dashboardUsecase,
 	}
 }
 
 func (h *DashboardHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// JWT認証から ユーザー情報を取得
-	user, ok := auth.GetUserFromContext(r.Context())
-	if !ok {
-		writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
-		return
-	}
-
-	// ロールをクエリパラメータから取得（フォールバックとして）
-	role := r.URL.Query().Get("role")
-	if role == "" {
-		role = "student" // デフォルト
-	}
-
-	// ダッシュボードデータを取得
-	dashboardData, err := h.dashboardUsecase.GetDashboardData(r.Context(), user.Sub, role)
-	if err != nil {
-		writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// レスポンス
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(dashboardData)
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		// ロールはクエリパラメータではなくusersテーブルの値を使う
+		dashboardData, err := h.dashboardUsecase.GetDashboardData(r.Context(), authCtx.RequesterUID, authCtx.RequesterRole)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, dashboardData, http.StatusOK)
+		return nil
+	})
 }
 
 func (h *DashboardHandler) GetTasks(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	user, ok := auth.GetUserFromContext(r.Context())
-	if !ok {
-		writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
-		return
-	}
-
-	role := r.URL.Query().Get("role")
-	dashboardData, err := h.dashboardUsecase.GetDashboardData(r.Context(), user.Sub, role)
-	if err != nil {
-		writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"tasks": dashboardData.Tasks,
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		dashboardData, err := h.dashboardUsecase.GetDashboardData(r.Context(), authCtx.RequesterUID, authCtx.RequesterRole)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{
+			"tasks": dashboardData.Tasks,
+		}, http.StatusOK)
+		return nil
 	})
 }
 
 func (h *DashboardHandler) GetStats(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	user, ok := auth.GetUserFromContext(r.Context())
-	if !ok {
-		writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
-		return
-	}
-
-	role := r.URL.Query().Get("role")
-	dashboardData, err := h.dashboardUsecase.GetDashboardData(r.Context(), user.Sub, role)
-	if err != nil {
-		writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"stats": dashboardData.Stats,
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		dashboardData, err := h.dashboardUsecase.GetDashboardData(r.Context(), authCtx.RequesterUID, authCtx.RequesterRole)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{
+			"stats": dashboardData.Stats,
+		}, http.StatusOK)
+		return nil
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
+)
+
+// devTokenTTL 開発用トークンの有効期限
+const devTokenTTL = 12 * time.Hour
+
+// DevHandler 開発環境専用のエンドポイント（AUTH_PROVIDER=local の場合のみ登録）
+type DevHandler struct {
+	authUsecase    *usecase.AuthUsecase
+	sessionUsecase *usecase.SessionUsecase
+}
+
+func NewDevHandler(authUsecase *usecase.AuthUsecase, sessionUsecase *usecase.SessionUsecase) *DevHandler {
+	return &DevHandler{
+		authUsecase:    authUsecase,
+		sessionUsecase: sessionUsecase,
+	}
+}
+
+// IssueToken シード済みユーザーのトークンを発行
+// トークンはセッションに紐づき、/sessions でそのセッションを失効させると使えなくなる
+func (h *DevHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		FirebaseUID string `json:"firebase_uid"`
+		Email       string `json:"email"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		account *entities.UserManagement
+		err     error
+	)
+	switch {
+	case req.FirebaseUID != "":
+		account, err = h.authUsecase.GetAccountByFirebaseUID(r.Context(), req.FirebaseUID)
+	case req.Email != "":
+		account, err = h.authUsecase.GetAccountByEmail(r.Context(), req.Email)
+	default:
+		writeErrorResponse(w, "firebase_uid or email is required", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to find account for dev token: %v", err)
+		writeErrorResponse(w, "User account not found", http.StatusNotFound)
+		return
+	}
+
+	tokens, err := h.sessionUsecase.StartDevSession(r.Context(), account, sessionClient(r, "dev token"), devTokenTTL)
+	if err != nil {
+		log.Printf("Failed to issue dev token: %v", err)
+		writeErrorResponse(w, "Failed to issue token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":         tokens.AccessToken,
+		"token_type":    "Bearer",
+		"expires_at":    tokens.AccessTokenExpiresAt,
+		"refresh_token": tokens.RefreshToken,
+		"session_id":    tokens.Session.ID,
+		"user":          account,
+	})
+}
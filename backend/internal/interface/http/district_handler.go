@@ -0,0 +1,247 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
+)
+
+// DistrictHandler 教育委員会（複数校）の管理と集計のハンドラー
+type DistrictHandler struct {
+	*BaseHandler
+	districtUsecase *usecase.DistrictUsecase
+	adminUsecase    *usecase.AdminUsecase
+}
+
+func NewDistrictHandler(districtUsecase *usecase.DistrictUsecase, adminUsecase *usecase.AdminUsecase, authUsecase *usecase.AuthUsecase, cfg *config.Config) *DistrictHandler {
+	return &DistrictHandler{
+		BaseHandler:     NewBaseHandler(cfg, authUsecase),
+		districtUsecase: districtUsecase,
+		adminUsecase:    adminUsecase,
+	}
+}
+
+// CreateDistrictRequest 教育委員会作成リクエスト
+type CreateDistrictRequest struct {
+	Name       string  `json:"name"`
+	Code       string  `json:"code"`
+	Prefecture *string `json:"prefecture,omitempty"`
+}
+
+// AssignDistrictAdminRequest 教育委員会管理者の割り当てリクエスト
+type AssignDistrictAdminRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// ListDistricts 教育委員会の一覧（教育委員会管理者は管轄する教育委員会のみ）
+func (h *DistrictHandler) ListDistricts(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		districts, err := h.districtUsecase.ListDistricts(r.Context(), authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{"districts": districts}, http.StatusOK)
+		return nil
+	})
+}
+
+// CreateDistrict 教育委員会を作成
+func (h *DistrictHandler) CreateDistrict(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		var req CreateDistrictRequest
+		if !parseJSONRequest(w, r, &req) {
+			return nil
+		}
+
+		district, err := h.districtUsecase.CreateDistrict(r.Context(), &entities.District{
+			Name:       req.Name,
+			Code:       req.Code,
+			Prefecture: req.Prefecture,
+		}, authCtx.RequesterRole)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidDistrict) {
+				h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
+			return err
+		}
+
+		h.SendJSONResponse(w, district, http.StatusCreated)
+		return nil
+	})
+}
+
+// GetDistrict 教育委員会の詳細
+func (h *DistrictHandler) GetDistrict(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		districtID, ok := h.districtIDParam(w, r)
+		if !ok {
+			return nil
+		}
+
+		district, err := h.districtUsecase.GetDistrict(r.Context(), districtID, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return h.handleDistrictError(w, err)
+		}
+
+		h.SendJSONResponse(w, district, http.StatusOK)
+		return nil
+	})
+}
+
+// ListDistrictSchools 教育委員会の所属校の一覧
+func (h *DistrictHandler) ListDistrictSchools(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		districtID, ok := h.districtIDParam(w, r)
+		if !ok {
+			return nil
+		}
+
+		schools, err := h.districtUsecase.ListDistrictSchools(r.Context(), districtID, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return h.handleDistrictError(w, err)
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{"schools": schools}, http.StatusOK)
+		return nil
+	})
+}
+
+// GetDistrictStats 所属校ごとの統計と合計（from・toは学校の統計と同じ）
+func (h *DistrictHandler) GetDistrictStats(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		districtID, ok := h.districtIDParam(w, r)
+		if !ok {
+			return nil
+		}
+		from, ok := getDateQueryParam(w, r, "from")
+		if !ok {
+			return nil
+		}
+		to, ok := getDateQueryParam(w, r, "to")
+		if !ok {
+			return nil
+		}
+
+		stats, err := h.districtUsecase.GetDistrictStats(r.Context(), districtID, from, to, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return h.handleDistrictError(w, err)
+		}
+
+		h.SendJSONResponse(w, stats, http.StatusOK)
+		return nil
+	})
+}
+
+// AssignSchool 学校を教育委員会に所属させる（管轄が変わるため二要素認証の本人確認が必要）
+func (h *DistrictHandler) AssignSchool(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPut, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		districtID, schoolID, ok := h.districtSchoolParams(w, r)
+		if !ok {
+			return nil
+		}
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
+
+		if err := h.districtUsecase.AssignSchool(r.Context(), districtID, schoolID, authCtx.RequesterRole); err != nil {
+			return h.handleDistrictError(w, err)
+		}
+
+		h.SendSuccessResponse(w, "School assigned to district successfully", nil)
+		return nil
+	})
+}
+
+// RemoveSchool 学校を教育委員会の所属から外す
+func (h *DistrictHandler) RemoveSchool(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodDelete, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		districtID, schoolID, ok := h.districtSchoolParams(w, r)
+		if !ok {
+			return nil
+		}
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
+
+		if err := h.districtUsecase.RemoveSchool(r.Context(), districtID, schoolID, authCtx.RequesterRole); err != nil {
+			return h.handleDistrictError(w, err)
+		}
+
+		h.SendSuccessResponse(w, "School removed from district successfully", nil)
+		return nil
+	})
+}
+
+// AssignAdmin ユーザーを教育委員会管理者にする（ロールが変わるため二要素認証の本人確認が必要）
+func (h *DistrictHandler) AssignAdmin(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		districtID, ok := h.districtIDParam(w, r)
+		if !ok {
+			return nil
+		}
+
+		var req AssignDistrictAdminRequest
+		if !parseJSONRequest(w, r, &req) {
+			return nil
+		}
+		if req.UserID == "" {
+			h.SendErrorResponse(w, "user_id is required", http.StatusBadRequest)
+			return nil
+		}
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
+
+		user, err := h.adminUsecase.AssignDistrictAdmin(r.Context(), districtID, req.UserID, authCtx.RequesterRole)
+		if err != nil {
+			return h.handleDistrictError(w, err)
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{"success": true, "user": user}, http.StatusOK)
+		return nil
+	})
+}
+
+// districtIDParam URLパラメータの教育委員会IDを検証
+func (h *DistrictHandler) districtIDParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	districtID := chi.URLParam(r, "id")
+	if _, err := strconv.ParseInt(districtID, 10, 64); err != nil {
+		h.SendErrorResponse(w, "Invalid district ID", http.StatusBadRequest)
+		return "", false
+	}
+	return districtID, true
+}
+
+// districtSchoolParams URLパラメータの教育委員会IDと学校IDを検証
+func (h *DistrictHandler) districtSchoolParams(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	districtID, ok := h.districtIDParam(w, r)
+	if !ok {
+		return "", "", false
+	}
+	schoolID := chi.URLParam(r, "schoolId")
+	if _, err := convertSchoolIDToInt64(schoolID); err != nil {
+		h.SendErrorResponse(w, "Invalid school ID", http.StatusBadRequest)
+		return "", "", false
+	}
+	return districtID, schoolID, true
+}
+
+// handleDistrictError 教育委員会の操作のエラーをステータスコードに変換（それ以外はそのまま返す）
+func (h *DistrictHandler) handleDistrictError(w http.ResponseWriter, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidDistrict), errors.Is(err, usecase.ErrInvalidStatsPeriod):
+		h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return nil
+	case errors.Is(err, usecase.ErrDistrictNotFound):
+		h.SendErrorResponse(w, err.Error(), http.StatusNotFound)
+		return nil
+	}
+	return err
+}
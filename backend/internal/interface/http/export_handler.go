@@ -0,0 +1,83 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/export"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
+)
+
+// ExportHandler ユーザー・クラス・学校データのエクスポートのハンドラー
+type ExportHandler struct {
+	*BaseHandler
+	exportUsecase *usecase.ExportUsecase
+}
+
+func NewExportHandler(exportUsecase *usecase.ExportUsecase, authUsecase *usecase.AuthUsecase, cfg *config.Config) *ExportHandler {
+	return &ExportHandler{
+		BaseHandler:   NewBaseHandler(cfg, authUsecase),
+		exportUsecase: exportUsecase,
+	}
+}
+
+// Export データをファイルとして出力（dataset, format=csv|xlsx|json, columns=カンマ区切り, school_id）
+// 件数に上限はなく、取得した行から順に書き出す
+func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	h.StreamWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
+
+		req := entities.ExportRequest{
+			Dataset:  r.URL.Query().Get("dataset"),
+			Format:   entities.ExportFormatCSV,
+			SchoolID: getStringQueryParam(r, "school_id"),
+		}
+		if format := getStringQueryParam(r, "format"); format != nil {
+			req.Format = strings.ToLower(*format)
+		}
+		if columns := getStringQueryParam(r, "columns"); columns != nil {
+			for _, column := range strings.Split(*columns, ",") {
+				if column = strings.TrimSpace(column); column != "" {
+					req.Columns = append(req.Columns, column)
+				}
+			}
+		}
+
+		prepared, err := h.exportUsecase.PrepareExport(r.Context(), req, authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidExportRequest) {
+				h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
+			return err
+		}
+
+		filename := fmt.Sprintf("%s-%s.%s", prepared.Dataset, time.Now().Format("20060102"), prepared.Format)
+		w.Header().Set("Content-Type", export.ContentType(prepared.Format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+		writer, err := export.NewWriter(prepared.Format, w, prepared.Columns)
+		if err != nil {
+			return err
+		}
+
+		// 出力開始後はステータスを変更できないため、エラーはログのみ
+		count, err := h.exportUsecase.Export(r.Context(), prepared, writer)
+		if err != nil {
+			log.Printf("Export of %s aborted after %d rows: %v", prepared.Dataset, count, err)
+			return nil
+		}
+		if err := writer.Close(); err != nil {
+			log.Printf("Failed to finish export of %s: %v", prepared.Dataset, err)
+		}
+		return nil
+	})
+}
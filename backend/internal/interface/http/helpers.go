@@ -3,17 +3,47 @@ package http
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
 	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
 )
 
 // AuthContext 認証コンテキスト
 type AuthContext struct {
+	RequesterID       string // usersテーブルのID
+	RequesterUID      string // Firebase UID
 	RequesterRole     string
 	RequesterSchoolID string
+	SessionID         string // X-Session-IDで送られた検証済みのセッションID
+	ImpersonatorUID   string // 代理ログイン中の場合、操作している管理者のUID
+
+	DistrictID        string   // 教育委員会管理者の場合は管轄する教育委員会
+	DistrictSchoolIDs []string // 教育委員会管理者の場合は管轄する学校
+}
+
+// subject 権限判定用の操作者に変換
+func (a AuthContext) subject() policy.Subject {
+	return policy.Subject{
+		UserID:            a.RequesterID,
+		Role:              a.RequesterRole,
+		SchoolID:          a.RequesterSchoolID,
+		DistrictID:        a.DistrictID,
+		DistrictSchoolIDs: a.DistrictSchoolIDs,
+	}
+}
+
+// tenant RLS用のテナント情報に変換
+func (a AuthContext) tenant() database.Tenant {
+	return database.Tenant{
+		UserID:   a.RequesterID,
+		Role:     a.RequesterRole,
+		SchoolID: a.RequesterSchoolID,
+	}
 }
 
 // ErrorResponse エラーレスポンス
@@ -30,25 +60,11 @@ type SuccessResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// getAuthContext 認証コンテキストを取得
-func getAuthContext(cfg *config.Config) AuthContext {
-	var requesterRole string
-	var requesterSchoolID string
-	
-	if cfg.DisableAuth {
-		// 開発環境：認証を無効化
-		requesterRole = cfg.MockUserRole
-		requesterSchoolID = cfg.MockUserSchoolID
-	} else {
-		// 本番環境：実際の認証を実装
-		// TODO: JWTからroleとschool_idを取得
-		requesterRole = "admin"
-		requesterSchoolID = "1"
-	}
-
+// getMockAuthContext 開発環境用のモック認証コンテキストを取得
+func getMockAuthContext(cfg *config.Config) AuthContext {
 	return AuthContext{
-		RequesterRole:     requesterRole,
-		RequesterSchoolID: requesterSchoolID,
+		RequesterRole:     cfg.MockUserRole,
+		RequesterSchoolID: cfg.MockUserSchoolID,
 	}
 }
 
@@ -111,6 +127,60 @@ func getStringQueryParam(r *http.Request, key string) *string {
 	return &value
 }
 
+// getBoolQueryParam 真偽値クエリパラメータを取得（未指定・不正な値の場合はdefaultValue）
+func getBoolQueryParam(r *http.Request, key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(r.URL.Query().Get(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// parseBoolQueryParam 任意の真偽値クエリパラメータを取得（不正な値の場合はエラーレスポンスを送りfalse）
+func parseBoolQueryParam(w http.ResponseWriter, r *http.Request, key string) (*bool, bool) {
+	value := getStringQueryParam(r, key)
+	if value == nil {
+		return nil, true
+	}
+
+	parsed, err := strconv.ParseBool(*value)
+	if err != nil {
+		writeErrorResponse(w, "Invalid "+key+": must be true or false", http.StatusBadRequest)
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// parseIntQueryParam 任意の整数クエリパラメータを取得（不正な値の場合はエラーレスポンスを送りfalse）
+func parseIntQueryParam(w http.ResponseWriter, r *http.Request, key string) (*int, bool) {
+	value := getStringQueryParam(r, key)
+	if value == nil {
+		return nil, true
+	}
+
+	parsed, err := strconv.Atoi(*value)
+	if err != nil {
+		writeErrorResponse(w, "Invalid "+key+": must be an integer", http.StatusBadRequest)
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// parseTimeQueryParam RFC3339形式の日時クエリパラメータを取得（不正な形式の場合はエラーレスポンスを送りfalse）
+func parseTimeQueryParam(w http.ResponseWriter, r *http.Request, key string) (*time.Time, bool) {
+	value := getStringQueryParam(r, key)
+	if value == nil {
+		return nil, true
+	}
+
+	parsed, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		writeErrorResponse(w, "Invalid "+key+": must be RFC3339", http.StatusBadRequest)
+		return nil, false
+	}
+	return &parsed, true
+}
+
 // validateMethod HTTPメソッドを検証
 func validateMethod(w http.ResponseWriter, r *http.Request, allowedMethod string) bool {
 	if r.Method != allowedMethod {
@@ -129,6 +199,14 @@ func parseJSONRequest(w http.ResponseWriter, r *http.Request, dest interface{})
 	return true
 }
 
+// clientIP リクエスト元のIPアドレス（RealIPミドルウェアで設定されたRemoteAddrからポートを除く）
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
 // convertSchoolIDToInt64 学校IDを文字列からint64に変換
 func convertSchoolIDToInt64(schoolID string) (int64, error) {
 	if schoolID == "" {
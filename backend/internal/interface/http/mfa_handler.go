@@ -0,0 +1,189 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
+)
+
+// MFAHandler 二要素認証（TOTP）の登録・本人確認・リセットのハンドラー
+type MFAHandler struct {
+	*BaseHandler
+	mfaUsecase *usecase.MFAUsecase
+}
+
+func NewMFAHandler(mfaUsecase *usecase.MFAUsecase, authUsecase *usecase.AuthUsecase, cfg *config.Config) *MFAHandler {
+	return &MFAHandler{
+		BaseHandler: NewBaseHandler(cfg, authUsecase),
+		mfaUsecase:  mfaUsecase,
+	}
+}
+
+// MFACodeRequest 認証コード（またはリカバリーコード）の入力リクエスト
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// GetStatus 自分の二要素認証の登録状況を取得
+func (h *MFAHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		status, err := h.mfaUsecase.Status(r.Context(), authCtx.RequesterID, authCtx.RequesterRole)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, status, http.StatusOK)
+		return nil
+	})
+}
+
+// Enroll 認証アプリ登録用のシークレットを発行
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := forbidImpersonation(authCtx); err != nil {
+			return err
+		}
+
+		account, err := h.authUsecase.GetAccountByFirebaseUID(r.Context(), authCtx.RequesterUID)
+		if err != nil {
+			return err
+		}
+
+		enrollment, err := h.mfaUsecase.Enroll(r.Context(), authCtx.RequesterID, account.Email)
+		if err != nil {
+			if errors.Is(err, usecase.ErrMFAAlreadyEnabled) {
+				h.SendErrorResponse(w, err.Error(), http.StatusConflict)
+				return nil
+			}
+			return err
+		}
+
+		h.SendJSONResponse(w, enrollment, http.StatusOK)
+		return nil
+	})
+}
+
+// Confirm 最初の認証コードで登録を確定し、リカバリーコードを返す
+func (h *MFAHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := forbidImpersonation(authCtx); err != nil {
+			return err
+		}
+
+		var req MFACodeRequest
+		if !parseJSONRequest(w, r, &req) {
+			return nil
+		}
+		if req.Code == "" {
+			h.SendErrorResponse(w, "code is required", http.StatusBadRequest)
+			return nil
+		}
+
+		codes, err := h.mfaUsecase.Confirm(r.Context(), authCtx.RequesterID, authCtx.SessionID, req.Code)
+		if err != nil {
+			if writeMFAError(w, err) {
+				return nil
+			}
+			return err
+		}
+
+		h.SendSuccessResponse(w, "Two-factor authentication enabled", map[string]interface{}{
+			"recovery_codes": codes,
+		})
+		return nil
+	})
+}
+
+// Verify 重要な操作の前に認証コードで本人確認（X-Session-IDのセッションに記録）
+func (h *MFAHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := forbidImpersonation(authCtx); err != nil {
+			return err
+		}
+
+		var req MFACodeRequest
+		if !parseJSONRequest(w, r, &req) {
+			return nil
+		}
+		if req.Code == "" {
+			h.SendErrorResponse(w, "code is required", http.StatusBadRequest)
+			return nil
+		}
+
+		if err := h.mfaUsecase.Verify(r.Context(), authCtx.RequesterID, authCtx.SessionID, req.Code); err != nil {
+			if writeMFAError(w, err) {
+				return nil
+			}
+			return err
+		}
+
+		h.SendSuccessResponse(w, "Two-factor verification succeeded", nil)
+		return nil
+	})
+}
+
+// RegenerateRecoveryCodes リカバリーコードを再発行（本人確認済みのセッションが必要）
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := forbidImpersonation(authCtx); err != nil {
+			return err
+		}
+
+		codes, err := h.mfaUsecase.RegenerateRecoveryCodes(r.Context(), authCtx.RequesterID, authCtx.SessionID)
+		if err != nil {
+			if writeMFAError(w, err) {
+				return nil
+			}
+			return err
+		}
+
+		h.SendSuccessResponse(w, "Recovery codes regenerated", map[string]interface{}{
+			"recovery_codes": codes,
+		})
+		return nil
+	})
+}
+
+// ResetUserMFA 管理者用：認証アプリを紛失したユーザーの二要素認証を解除
+func (h *MFAHandler) ResetUserMFA(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodDelete, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
+
+		userID := chi.URLParam(r, "id")
+		if userID == "" {
+			h.SendErrorResponse(w, "user id required", http.StatusBadRequest)
+			return nil
+		}
+
+		if err := h.mfaUsecase.ResetUserMFA(r.Context(), userID, authCtx.RequesterRole, authCtx.RequesterSchoolID); err != nil {
+			return err
+		}
+
+		h.SendSuccessResponse(w, "Two-factor authentication reset successfully", nil)
+		return nil
+	})
+}
+
+// writeMFAError 入力内容に起因する二要素認証のエラーをレスポンスに変換（該当しない場合はfalse）
+func writeMFAError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidMFACode),
+		errors.Is(err, usecase.ErrMFANotEnrolled),
+		errors.Is(err, usecase.ErrMFASessionRequired):
+		writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrMFAAlreadyEnabled):
+		writeErrorResponse(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, usecase.ErrAccountLocked):
+		writeErrorResponse(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, usecase.ErrSessionInvalid):
+		writeErrorResponse(w, err.Error(), http.StatusUnauthorized)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
+)
+
+// RecordHandler 成績・出席・保護者紐付けのハンドラー
+type RecordHandler struct {
+	*BaseHandler
+	recordUsecase *usecase.RecordUsecase
+}
+
+func NewRecordHandler(recordUsecase *usecase.RecordUsecase, authUsecase *usecase.AuthUsecase, cfg *config.Config) *RecordHandler {
+	return &RecordHandler{
+		BaseHandler:   NewBaseHandler(cfg, authUsecase),
+		recordUsecase: recordUsecase,
+	}
+}
+
+// LinkGuardianStudentRequest 保護者と生徒の紐付けリクエスト
+type LinkGuardianStudentRequest struct {
+	StudentID    string  `json:"student_id"`
+	Relationship *string `json:"relationship,omitempty"`
+}
+
+// GetStudentGrades 生徒の成績
+func (h *RecordHandler) GetStudentGrades(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		grades, err := h.recordUsecase.GetStudentGrades(r.Context(), chi.URLParam(r, "id"), authCtx.subject())
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{
+			"grades": grades,
+		}, http.StatusOK)
+		return nil
+	})
+}
+
+// GetStudentAttendance 生徒の出席記録（from/toはYYYY-MM-DD）
+func (h *RecordHandler) GetStudentAttendance(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		from, ok := getDateQueryParam(w, r, "from")
+		if !ok {
+			return nil
+		}
+		to, ok := getDateQueryParam(w, r, "to")
+		if !ok {
+			return nil
+		}
+
+		records, err := h.recordUsecase.GetStudentAttendance(r.Context(), chi.URLParam(r, "id"), from, to, authCtx.subject())
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{
+			"attendance": records,
+		}, http.StatusOK)
+		return nil
+	})
+}
+
+// GetNotifications 成績・出欠の通知
+func (h *RecordHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		notifications, err := h.recordUsecase.GetNotifications(r.Context(), authCtx.subject())
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{
+			"notifications": notifications,
+		}, http.StatusOK)
+		return nil
+	})
+}
+
+// GetMyStudents 保護者本人に紐付けられた生徒一覧
+func (h *RecordHandler) GetMyStudents(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		students, err := h.recordUsecase.ListMyStudents(r.Context(), authCtx.subject())
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{
+			"students": students,
+		}, http.StatusOK)
+		return nil
+	})
+}
+
+// GetGuardianStudents 管理者用：保護者に紐付けられた生徒一覧
+func (h *RecordHandler) GetGuardianStudents(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		students, err := h.recordUsecase.ListGuardianStudents(r.Context(), chi.URLParam(r, "id"), authCtx.RequesterRole, authCtx.RequesterSchoolID)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{
+			"students": students,
+		}, http.StatusOK)
+		return nil
+	})
+}
+
+// LinkGuardianStudent 管理者用：保護者と生徒を紐付け
+func (h *RecordHandler) LinkGuardianStudent(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		var req LinkGuardianStudentRequest
+		if !parseJSONRequest(w, r, &req) {
+			return nil
+		}
+		if !validateRequiredString(w, req.StudentID, "student_id") {
+			return nil
+		}
+
+		if err := h.recordUsecase.LinkGuardianStudent(r.Context(), chi.URLParam(r, "id"), req.StudentID, req.Relationship, authCtx.RequesterRole, authCtx.RequesterSchoolID); err != nil {
+			return err
+		}
+
+		h.SendSuccessResponse(w, "Guardian linked successfully", nil)
+		return nil
+	})
+}
+
+// UnlinkGuardianStudent 管理者用：保護者と生徒の紐付けを解除
+func (h *RecordHandler) UnlinkGuardianStudent(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodDelete, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := h.recordUsecase.UnlinkGuardianStudent(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "studentId"), authCtx.RequesterRole, authCtx.RequesterSchoolID); err != nil {
+			return err
+		}
+
+		h.SendSuccessResponse(w, "Guardian unlinked successfully", nil)
+		return nil
+	})
+}
+
+// getDateQueryParam 日付（YYYY-MM-DD）のクエリパラメータを取得
+func getDateQueryParam(w http.ResponseWriter, r *http.Request, key string) (*time.Time, bool) {
+	value := getStringQueryParam(r, key)
+	if value == nil {
+		return nil, true
+	}
+
+	date, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		writeErrorResponse(w, "Invalid date for "+key+": "+*value, http.StatusBadRequest)
+		return nil, false
+	}
+	return &date, true
+}
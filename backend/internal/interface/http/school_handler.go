@@ -1,185 +1,376 @@
 package http
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
 	"github.com/rikut0904/bloomia/backend/internal/usecase"
 )
 
+// SchoolHandler 学校管理のハンドラー（学校の範囲はルートの権限チェックとRLSで制限する）
 type SchoolHandler struct {
+	*BaseHandler
 	schoolUsecase *usecase.SchoolUsecase
 }
 
-func NewSchoolHandler(schoolUsecase *usecase.SchoolUsecase) *SchoolHandler {
+func NewSchoolHandler(schoolUsecase *usecase.SchoolUsecase, authUsecase *usecase.AuthUsecase, cfg *config.Config) *SchoolHandler {
 	return &SchoolHandler{
+		BaseHandler:   NewBaseHandler(cfg, authUsecase),
 		schoolUsecase: schoolUsecase,
 	}
 }
 
+// CreateSchoolRequest school_id（学校コード）を省略した場合は都道府県ごとの連番で採番する
 type CreateSchoolRequest struct {
-	SchoolID    string  `json:"school_id"`
-	SchoolName  string  `json:"school_name"`
-	Prefecture  *string `json:"prefecture,omitempty"`
-	City        *string `json:"city,omitempty"`
-	Address     *string `json:"address,omitempty"`
-	Phone       *string `json:"phone,omitempty"`
-	Email       *string `json:"email,omitempty"`
+	SchoolID   string  `json:"school_id,omitempty"`
+	SchoolName string  `json:"school_name"`
+	Prefecture *string `json:"prefecture,omitempty"`
+	City       *string `json:"city,omitempty"`
+	Address    *string `json:"address,omitempty"`
+	Phone      *string `json:"phone,omitempty"`
+	Email      *string `json:"email,omitempty"`
 }
 
 func (h *SchoolHandler) CreateSchool(w http.ResponseWriter, r *http.Request) {
-	var req CreateSchoolRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
 
-	// Basic validation
-	if req.SchoolID == "" || req.SchoolName == "" {
-		http.Error(w, "School ID and name are required", http.StatusBadRequest)
-		return
-	}
+		var req CreateSchoolRequest
+		if !parseJSONRequest(w, r, &req) {
+			return nil
+		}
 
-	school := &entities.School{
-		SchoolID:    req.SchoolID,
-		SchoolName:  req.SchoolName,
-		Prefecture:  req.Prefecture,
-		City:        req.City,
-		Address:     req.Address,
-		Phone:       req.Phone,
-		Email:       req.Email,
-	}
+		// Basic validation
+		if req.SchoolName == "" {
+			h.SendErrorResponse(w, "School name is required", http.StatusBadRequest)
+			return nil
+		}
 
-	createdSchool, err := h.schoolUsecase.CreateSchool(r.Context(), school)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+		school := &entities.School{
+			SchoolID:   req.SchoolID,
+			SchoolName: req.SchoolName,
+			Prefecture: req.Prefecture,
+			City:       req.City,
+			Address:    req.Address,
+			Phone:      req.Phone,
+			Email:      req.Email,
+		}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(createdSchool)
+		createdSchool, err := h.schoolUsecase.CreateSchool(r.Context(), school)
+		if err != nil {
+			return h.writeSchoolCodeError(w, err)
+		}
+
+		h.SendJSONResponse(w, createdSchool, http.StatusOK)
+		return nil
+	})
 }
 
 func (h *SchoolHandler) GetSchools(w http.ResponseWriter, r *http.Request) {
-	// TODO: Parse filters from query parameters
-	filters := make(map[string]interface{})
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		// TODO: Parse filters from query parameters
+		filters := make(map[string]interface{})
 
-	schools, err := h.schoolUsecase.GetAllSchools(r.Context(), filters)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+		schools, err := h.schoolUsecase.GetAllSchools(r.Context(), filters)
+		if err != nil {
+			return err
+		}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(schools)
+		h.SendJSONResponse(w, schools, http.StatusOK)
+		return nil
+	})
 }
 
 func (h *SchoolHandler) GetSchoolByID(w http.ResponseWriter, r *http.Request) {
-	schoolIDStr := chi.URLParam(r, "id")
-	schoolID, err := strconv.ParseInt(schoolIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid school ID", http.StatusBadRequest)
-		return
-	}
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		schoolID, ok := h.schoolIDParam(w, r)
+		if !ok {
+			return nil
+		}
 
-	school, err := h.schoolUsecase.GetSchoolByID(r.Context(), schoolID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
-	}
+		school, err := h.schoolUsecase.GetSchoolByID(r.Context(), schoolID)
+		if err != nil {
+			h.SendErrorResponse(w, err.Error(), http.StatusNotFound)
+			return nil
+		}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(school)
+		h.SendJSONResponse(w, school, http.StatusOK)
+		return nil
+	})
 }
 
+// UpdateSchool 学校情報・設定を部分更新（PUT・PATCHとも指定した項目のみ変更する）
 func (h *SchoolHandler) UpdateSchool(w http.ResponseWriter, r *http.Request) {
-	schoolIDStr := chi.URLParam(r, "id")
-	schoolID, err := strconv.ParseInt(schoolIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid school ID", http.StatusBadRequest)
-		return
+	method := http.MethodPut
+	if r.Method == http.MethodPatch {
+		method = http.MethodPatch
 	}
 
-	var req CreateSchoolRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
+	h.HandleWithAuth(w, r, method, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		schoolID, ok := h.schoolIDParam(w, r)
+		if !ok {
+			return nil
+		}
 
-	updateData := entities.School{
-		SchoolID:    req.SchoolID,
-		SchoolName:  req.SchoolName,
-		Prefecture:  req.Prefecture,
-		City:        req.City,
-		Address:     req.Address,
-		Phone:       req.Phone,
-		Email:       req.Email,
-	}
+		var update entities.SchoolUpdate
+		if !parseJSONRequest(w, r, &update) {
+			return nil
+		}
 
-	updatedSchool, err := h.schoolUsecase.UpdateSchool(r.Context(), schoolID, updateData)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+		updatedSchool, err := h.schoolUsecase.UpdateSchool(r.Context(), schoolID, update)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidSchoolProfile) {
+				h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
+			return err
+		}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(updatedSchool)
+		h.SendJSONResponse(w, updatedSchool, http.StatusOK)
+		return nil
+	})
 }
 
 func (h *SchoolHandler) DeleteSchool(w http.ResponseWriter, r *http.Request) {
-	schoolIDStr := chi.URLParam(r, "id")
-	schoolID, err := strconv.ParseInt(schoolIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid school ID", http.StatusBadRequest)
-		return
-	}
+	h.HandleWithAuth(w, r, http.MethodDelete, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
 
-	err = h.schoolUsecase.DeleteSchool(r.Context(), schoolID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+		schoolID, ok := h.schoolIDParam(w, r)
+		if !ok {
+			return nil
+		}
+
+		if err := h.schoolUsecase.DeleteSchool(r.Context(), schoolID); err != nil {
+			return err
+		}
 
-	w.WriteHeader(http.StatusNoContent)
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
 }
 
-func (h *SchoolHandler) GetSchoolStats(w http.ResponseWriter, r *http.Request) {
-	schoolIDStr := chi.URLParam(r, "id")
-	schoolID, err := strconv.ParseInt(schoolIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid school ID", http.StatusBadRequest)
-		return
-	}
+// RestoreSchool 猶予期間内に削除された学校を復元
+func (h *SchoolHandler) RestoreSchool(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		schoolID, ok := h.schoolIDParam(w, r)
+		if !ok {
+			return nil
+		}
 
-	stats, err := h.schoolUsecase.GetSchoolStats(r.Context(), schoolID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		if err := h.schoolUsecase.RestoreSchool(r.Context(), schoolID); err != nil {
+			if errors.Is(err, usecase.ErrRestoreUnavailable) {
+				h.SendErrorResponse(w, err.Error(), http.StatusNotFound)
+				return nil
+			}
+			return err
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
+
+// ChangeSchoolCodeRequest 学校コードの変更リクエスト
+type ChangeSchoolCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// ValidateSchoolCode 入力中の学校コードの形式と使用状況を確認（school_idを指定するとその学校のコードは使用可能とする）
+func (h *SchoolHandler) ValidateSchoolCode(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		code := r.URL.Query().Get("code")
+		schoolID := r.URL.Query().Get("school_id")
+		if schoolID != "" {
+			if _, err := strconv.ParseInt(schoolID, 10, 64); err != nil {
+				h.SendErrorResponse(w, "Invalid school ID", http.StatusBadRequest)
+				return nil
+			}
+		}
+
+		check, err := h.schoolUsecase.ValidateSchoolCode(r.Context(), code, schoolID)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, check, http.StatusOK)
+		return nil
+	})
+}
+
+// ChangeSchoolCode 学校コードを変更（変更前のコードでも引き続き学校を参照できる）
+func (h *SchoolHandler) ChangeSchoolCode(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPut, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := h.requireStepUp(r, authCtx); err != nil {
+			return err
+		}
+
+		schoolID, ok := h.schoolIDParam(w, r)
+		if !ok {
+			return nil
+		}
+
+		var req ChangeSchoolCodeRequest
+		if !parseJSONRequest(w, r, &req) {
+			return nil
+		}
+
+		updatedSchool, err := h.schoolUsecase.ChangeSchoolCode(r.Context(), schoolID, req.Code)
+		if err != nil {
+			return h.writeSchoolCodeError(w, err)
+		}
+
+		h.SendJSONResponse(w, updatedSchool, http.StatusOK)
+		return nil
+	})
+}
+
+// ListSchoolCodeAliases 変更前の学校コードの一覧
+func (h *SchoolHandler) ListSchoolCodeAliases(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		schoolID, ok := h.schoolIDParam(w, r)
+		if !ok {
+			return nil
+		}
+
+		aliases, err := h.schoolUsecase.ListSchoolCodeAliases(r.Context(), schoolID)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{"aliases": aliases}, http.StatusOK)
+		return nil
+	})
+}
+
+// writeSchoolCodeError 学校コードのエラーをステータスコードに変換（それ以外のエラーはそのまま返す）
+func (h *SchoolHandler) writeSchoolCodeError(w http.ResponseWriter, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidSchoolCode):
+		h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return nil
+	case errors.Is(err, usecase.ErrSchoolCodeTaken):
+		h.SendErrorResponse(w, err.Error(), http.StatusConflict)
+		return nil
+	default:
+		return err
 	}
+}
+
+// GetSchoolStats 学校の統計レポート（from・toはYYYY-MM-DD、省略時は今日までの30日間）
+func (h *SchoolHandler) GetSchoolStats(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		schoolID, ok := h.schoolIDParam(w, r)
+		if !ok {
+			return nil
+		}
+
+		from, ok := getDateQueryParam(w, r, "from")
+		if !ok {
+			return nil
+		}
+		to, ok := getDateQueryParam(w, r, "to")
+		if !ok {
+			return nil
+		}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
+		stats, err := h.schoolUsecase.GetSchoolStats(r.Context(), schoolID, from, to)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidStatsPeriod) {
+				h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
+			return err
+		}
+
+		h.SendJSONResponse(w, stats, http.StatusOK)
+		return nil
+	})
 }
 
 func (h *SchoolHandler) GetSchoolUsers(w http.ResponseWriter, r *http.Request) {
-	schoolIDStr := chi.URLParam(r, "id")
-	schoolID, err := strconv.ParseInt(schoolIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid school ID", http.StatusBadRequest)
-		return
-	}
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		schoolID, ok := h.schoolIDParam(w, r)
+		if !ok {
+			return nil
+		}
+
+		role := r.URL.Query().Get("role")
+
+		users, err := h.schoolUsecase.GetSchoolUsers(r.Context(), schoolID, role)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, users, http.StatusOK)
+		return nil
+	})
+}
+
+type CreateStudentRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password,omitempty"`
+}
+
+// CreateStudent Googleアカウントを持たない生徒のアカウントを作成（初回ログイン時にパスワード変更が必要）
+func (h *SchoolHandler) CreateStudent(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		schoolID, ok := h.schoolIDParam(w, r)
+		if !ok {
+			return nil
+		}
 
-	role := r.URL.Query().Get("role")
+		var req CreateStudentRequest
+		if !parseJSONRequest(w, r, &req) {
+			return nil
+		}
 
-	users, err := h.schoolUsecase.GetSchoolUsers(r.Context(), schoolID, role)
+		if req.Name == "" || req.Email == "" {
+			h.SendErrorResponse(w, "Name and email are required", http.StatusBadRequest)
+			return nil
+		}
+
+		student := &entities.User{
+			DisplayName: req.Name,
+			Email:       req.Email,
+		}
+
+		created, temporaryPassword, err := h.schoolUsecase.CreateStudentForSchool(r.Context(), schoolID, student, req.Password)
+		if err != nil {
+			if errors.Is(err, usecase.ErrWeakPassword) {
+				h.SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
+			return err
+		}
+
+		response := map[string]interface{}{
+			"user": created,
+		}
+		// 仮パスワードは自動生成した場合のみ返す
+		if req.Password == "" {
+			response["temporary_password"] = temporaryPassword
+		}
+
+		h.SendJSONResponse(w, response, http.StatusCreated)
+		return nil
+	})
+}
+
+// schoolIDParam URLパラメータの学校IDを取得（不正な場合はエラーレスポンスを書き込みfalseを返す）
+func (h *SchoolHandler) schoolIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	schoolID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		h.SendErrorResponse(w, "Invalid school ID", http.StatusBadRequest)
+		return 0, false
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(users)
-}
\ No newline at end of file
+	return schoolID, true
+}
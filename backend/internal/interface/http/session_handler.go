@@ -0,0 +1,137 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/usecase"
+)
+
+// SessionHandler ログインセッション（端末一覧・失効・リフレッシュ）のハンドラー
+type SessionHandler struct {
+	*BaseHandler
+	sessionUsecase *usecase.SessionUsecase
+}
+
+func NewSessionHandler(sessionUsecase *usecase.SessionUsecase, authUsecase *usecase.AuthUsecase, cfg *config.Config) *SessionHandler {
+	return &SessionHandler{
+		BaseHandler:    NewBaseHandler(cfg, authUsecase),
+		sessionUsecase: sessionUsecase,
+	}
+}
+
+// CreateSessionRequest セッション作成リクエスト
+type CreateSessionRequest struct {
+	DeviceName string `json:"device_name"`
+}
+
+// RefreshSessionRequest リフレッシュトークンによるセッション更新リクエスト
+type RefreshSessionRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// CreateSession ログイン直後に端末のセッションを作成
+func (h *SessionHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := forbidImpersonation(authCtx); err != nil {
+			return err
+		}
+
+		var req CreateSessionRequest
+		if !parseJSONRequest(w, r, &req) {
+			return nil
+		}
+
+		tokens, err := h.sessionUsecase.CreateSession(
+			r.Context(),
+			authCtx.RequesterID,
+			authCtx.RequesterUID,
+			authCtx.RequesterSchoolID,
+			entities.SessionAuthToken,
+			sessionClient(r, req.DeviceName),
+		)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, tokens, http.StatusCreated)
+		return nil
+	})
+}
+
+// RefreshSession リフレッシュトークンをローテーション（アクセストークンが期限切れでも呼び出せる）
+func (h *SessionHandler) RefreshSession(w http.ResponseWriter, r *http.Request) {
+	if !validateMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req RefreshSessionRequest
+	if !parseJSONRequest(w, r, &req) {
+		return
+	}
+	if !validateRequiredString(w, req.RefreshToken, "refresh_token") {
+		return
+	}
+
+	tokens, err := h.sessionUsecase.RefreshSession(r.Context(), req.RefreshToken, clientIP(r))
+	if err != nil {
+		if errors.Is(err, usecase.ErrSessionInvalid) {
+			writeErrorResponse(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		log.Printf("Failed to refresh session: %v", err)
+		writeErrorResponse(w, "Failed to refresh session", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONResponse(w, tokens, http.StatusOK)
+}
+
+// ListSessions 自分のログイン中の端末一覧
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		sessions, err := h.sessionUsecase.ListSessions(r.Context(), authCtx.RequesterID, authCtx.SessionID)
+		if err != nil {
+			return err
+		}
+
+		h.SendJSONResponse(w, map[string]interface{}{
+			"sessions": sessions,
+		}, http.StatusOK)
+		return nil
+	})
+}
+
+// RevokeSession 自分のセッションを失効（他端末からのログアウト）
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	h.HandleWithAuth(w, r, http.MethodDelete, func(w http.ResponseWriter, r *http.Request, authCtx AuthContext) error {
+		if err := forbidImpersonation(authCtx); err != nil {
+			return err
+		}
+
+		err := h.sessionUsecase.RevokeSession(r.Context(), authCtx.RequesterID, chi.URLParam(r, "id"))
+		if errors.Is(err, usecase.ErrSessionInvalid) {
+			h.SendErrorResponse(w, "Session not found", http.StatusNotFound)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		h.SendSuccessResponse(w, "Session revoked successfully", nil)
+		return nil
+	})
+}
+
+// sessionClient リクエスト元の端末情報
+func sessionClient(r *http.Request, deviceName string) entities.SessionClient {
+	return entities.SessionClient{
+		DeviceName: deviceName,
+		IPAddress:  clientIP(r),
+		UserAgent:  r.UserAgent(),
+	}
+}
@@ -12,6 +12,15 @@ var ValidUserRoles = map[string]bool{
 	"school_admin": true,
 	"teacher":      true,
 	"student":      true,
+	"guardian":     true,
+}
+
+// ValidInvitationStatuses 有効な招待ステータス
+var ValidInvitationStatuses = map[string]bool{
+	"pending":  true,
+	"accepted": true,
+	"expired":  true,
+	"revoked":  true,
 }
 
 // validateUserRole ユーザー役割を検証
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/calendar"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+)
+
+var (
+	// ErrInvalidRollover 年度更新の対象年度の指定が正しくない
+	ErrInvalidRollover = errors.New("invalid academic year rollover")
+	// ErrAcademicYearArchived 指定した年度は年度更新済み（読み取り専用）
+	ErrAcademicYearArchived = errors.New("academic year has already been rolled over")
+)
+
+// AcademicYearUsecase 学校ごとの年度・学期の判定と年度更新
+type AcademicYearUsecase struct {
+	academicYearRepo repositories.AcademicYearRepository
+	schoolRepo       repositories.SchoolRepository
+	auditRecorder    AuditRecorder
+}
+
+func NewAcademicYearUsecase(academicYearRepo repositories.AcademicYearRepository, schoolRepo repositories.SchoolRepository) *AcademicYearUsecase {
+	return &AcademicYearUsecase{
+		academicYearRepo: academicYearRepo,
+		schoolRepo:       schoolRepo,
+	}
+}
+
+// SetAuditRecorder allows injecting AuditRecorder
+func (u *AcademicYearUsecase) SetAuditRecorder(recorder AuditRecorder) {
+	u.auditRecorder = recorder
+}
+
+// CurrentPeriod 学校の現在の年度と学期を取得
+func (u *AcademicYearUsecase) CurrentPeriod(ctx context.Context, schoolID int64, requesterRole, requesterSchoolID string) (*entities.AcademicPeriod, error) {
+	school, err := u.authorizedSchool(ctx, schoolID, policy.SchoolsRead, requesterRole, requesterSchoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	period := calendar.Current(school, time.Now())
+	return &period, nil
+}
+
+// Rollover 年度更新（fromYearが0の場合は年度更新の済んでいない最新の年度）
+// 最高学年の生徒を卒業させて他の生徒を進級し、クラスと授業を翌年度に複製したうえで、
+// 前年度のクラス・授業・成績・ポイントを読み取り専用にする。dryRunの場合は件数のみ返す
+func (u *AcademicYearUsecase) Rollover(ctx context.Context, schoolID int64, fromYear int, dryRun bool, requesterID, requesterRole, requesterSchoolID string) (*entities.AcademicYearRollover, error) {
+	school, err := u.authorizedSchool(ctx, schoolID, policy.AcademicYearRollover, requesterRole, requesterSchoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := calendar.Current(school, time.Now())
+	if fromYear == 0 {
+		fromYear, err = u.academicYearRepo.LatestOpenClassYear(ctx, schoolID)
+		if err != nil {
+			return nil, err
+		}
+		if fromYear == 0 {
+			fromYear = current.AcademicYear
+		}
+	}
+	// まだ始まっていない年度は更新できない（新年度の準備のため現在の年度は可）
+	if fromYear > current.AcademicYear {
+		return nil, fmt.Errorf("%w: academic year %d has not started yet", ErrInvalidRollover, fromYear)
+	}
+
+	archived, err := u.academicYearRepo.IsArchived(ctx, schoolID, fromYear)
+	if err != nil {
+		return nil, err
+	}
+	if archived {
+		return nil, fmt.Errorf("%w: %d", ErrAcademicYearArchived, fromYear)
+	}
+
+	if dryRun {
+		return u.academicYearRepo.PreviewRollover(ctx, schoolID, fromYear)
+	}
+
+	result, err := u.academicYearRepo.Rollover(ctx, schoolID, fromYear, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("%w: %d", ErrAcademicYearArchived, fromYear)
+	}
+
+	id := strconv.FormatInt(schoolID, 10)
+	if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionSchoolRollover,
+		TargetType: audit.TargetSchool,
+		TargetID:   id,
+		SchoolID:   id,
+		Before:     map[string]interface{}{"academic_year": result.FromYear},
+		After: map[string]interface{}{
+			"academic_year":      result.ToYear,
+			"promoted_students":  result.PromotedStudents,
+			"graduated_students": result.GraduatedStudents,
+			"cloned_classes":     result.ClonedClasses,
+			"cloned_courses":     result.ClonedCourses,
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// authorizedSchool 権限を確認して学校を取得
+func (u *AcademicYearUsecase) authorizedSchool(ctx context.Context, schoolID int64, permission policy.Permission, requesterRole, requesterSchoolID string) (*entities.School, error) {
+	id := strconv.FormatInt(schoolID, 10)
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), permission, policy.Resource{SchoolID: id}); err != nil {
+		return nil, err
+	}
+	return u.schoolRepo.GetSchoolByID(ctx, schoolID)
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/firebase"
+)
+
+// SetFirebaseClient allows injecting FirebaseClient
+func (u *AdminUsecase) SetFirebaseClient(client *firebase.FirebaseClient) {
+	u.firebaseClient = client
+}
+
+// syncUserClaims DBのロール・学校IDをFirebaseカスタムクレームに反映
+// ロールバックした変更を反映しないよう、テナントトランザクションのコミット後に実行する
+// 失敗してもDB更新は完了しているためログのみ出力し、ReconcileSchoolClaimsで修復する
+func (u *AdminUsecase) syncUserClaims(ctx context.Context, firebaseUID, role, schoolID string) {
+	if u.firebaseClient == nil || firebaseUID == "" {
+		return
+	}
+
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		if err := u.pushUserClaims(ctx, firebaseUID, role, schoolID); err != nil {
+			log.Printf("Failed to sync custom claims for uid %s (run reconcileclaims to repair): %v", firebaseUID, err)
+		}
+	})
+}
+
+// pushUserClaims 既存のカスタムクレームを保持したままrole・school_idを上書き
+func (u *AdminUsecase) pushUserClaims(ctx context.Context, firebaseUID, role, schoolID string) error {
+	claims, err := u.firebaseClient.GetCustomUserClaims(ctx, firebaseUID)
+	if err != nil {
+		return err
+	}
+
+	updated := make(map[string]interface{}, len(claims)+2)
+	for key, value := range claims {
+		updated[key] = value
+	}
+	updated["role"] = role
+	if id, err := strconv.ParseInt(schoolID, 10, 64); err == nil {
+		updated["school_id"] = id
+	} else {
+		delete(updated, "school_id")
+	}
+
+	return u.firebaseClient.SetCustomUserClaims(ctx, firebaseUID, updated)
+}
+
+// revokeUserSessions リフレッシュトークンを失効させ、既存セッションを無効化
+func (u *AdminUsecase) revokeUserSessions(ctx context.Context, firebaseUID string) error {
+	if u.firebaseClient == nil || firebaseUID == "" {
+		return nil
+	}
+
+	if err := u.firebaseClient.RevokeRefreshTokens(ctx, firebaseUID); err != nil {
+		if firebase.IsUserNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// ReconcileSchoolClaims 学校単位でDBのロールとFirebaseカスタムクレームの差分を検出し修正
+func (u *AdminUsecase) ReconcileSchoolClaims(ctx context.Context, schoolID int64, dryRun bool) (*entities.ClaimsReconcileReport, error) {
+	if u.firebaseClient == nil {
+		return nil, fmt.Errorf("firebase client not configured")
+	}
+	if u.schoolRepo == nil {
+		return nil, fmt.Errorf("school repository not configured")
+	}
+
+	users, err := u.schoolRepo.GetSchoolUsers(ctx, schoolID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get school users: %w", err)
+	}
+
+	report := &entities.ClaimsReconcileReport{
+		SchoolID: strconv.FormatInt(schoolID, 10),
+		DryRun:   dryRun,
+		Drifted:  []entities.ClaimsDrift{},
+		NotFound: []string{},
+	}
+
+	for _, user := range users {
+		report.Checked++
+
+		claims, err := u.firebaseClient.GetCustomUserClaims(ctx, user.FirebaseUID)
+		if err != nil {
+			if firebase.IsUserNotFound(err) {
+				report.NotFound = append(report.NotFound, user.FirebaseUID)
+				continue
+			}
+			return nil, fmt.Errorf("failed to get claims for uid %s: %w", user.FirebaseUID, err)
+		}
+
+		claimRole, _ := claims["role"].(string)
+		claimSchoolID := ""
+		if id, ok := claims["school_id"].(float64); ok {
+			claimSchoolID = strconv.FormatInt(int64(id), 10)
+		}
+
+		if claimRole == user.Role && claimSchoolID == user.SchoolID {
+			report.InSync++
+			continue
+		}
+
+		drift := entities.ClaimsDrift{
+			UserID:        user.ID,
+			FirebaseUID:   user.FirebaseUID,
+			Email:         user.Email,
+			DBRole:        user.Role,
+			DBSchoolID:    user.SchoolID,
+			ClaimRole:     claimRole,
+			ClaimSchoolID: claimSchoolID,
+		}
+		if !dryRun {
+			if err := u.pushUserClaims(ctx, user.FirebaseUID, user.Role, user.SchoolID); err != nil {
+				drift.Error = err.Error()
+			} else {
+				drift.Fixed = true
+			}
+		}
+		report.Drifted = append(report.Drifted, drift)
+	}
+
+	return report, nil
+}
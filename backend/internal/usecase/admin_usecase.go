@@ -3,17 +3,33 @@ package usecase
 import (
     "context"
     "fmt"
+    "log"
+    "strconv"
     "time"
 
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
 	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
 	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
 	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/firebase"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/mail"
 )
 
 type AdminUsecase struct {
     adminRepo repositories.AdminRepository
     userRepo  repositories.UserRepository
     schoolRepo repositories.SchoolRepository
+    sessionRepo repositories.SessionRepository
+    mailer    mail.Mailer
+    firebaseClient *firebase.FirebaseClient
+    userImportRepo repositories.UserImportRepository
+    deletionRepo repositories.UserDeletionRepository
+    tokenIssuer TokenIssuer
+    studentProfileRepo repositories.StudentProfileRepository
+    districtRepo repositories.DistrictRepository
+    auditRecorder AuditRecorder
     config    *config.Config
 }
 
@@ -30,34 +46,26 @@ func (u *AdminUsecase) SetSchoolRepository(repo repositories.SchoolRepository) {
     u.schoolRepo = repo
 }
 
-func (u *AdminUsecase) GetAllUsers(ctx context.Context, page, perPage int, schoolID *string, requesterRole string, requesterSchoolID string) (*entities.UserListResponse, error) {
-	// 権限チェック
-	if !u.canManageUsers(requesterRole, schoolID, requesterSchoolID) {
-		return nil, fmt.Errorf("insufficient permissions to manage users")
-	}
-
-	// school_adminの場合は自分の学校のユーザーのみ
-	if requesterRole == "school_admin" {
-		schoolID = &requesterSchoolID
-	}
+// SetSessionRepository allows injecting SessionRepository
+func (u *AdminUsecase) SetSessionRepository(repo repositories.SessionRepository) {
+    u.sessionRepo = repo
+}
 
-	users, totalCount, err := u.adminRepo.GetAllUsers(ctx, page, perPage, schoolID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get users: %w", err)
-	}
+// SetMailer allows injecting Mailer
+func (u *AdminUsecase) SetMailer(mailer mail.Mailer) {
+    u.mailer = mailer
+}
 
-	return &entities.UserListResponse{
-		Users:      users,
-		TotalCount: totalCount,
-		Page:       page,
-		PerPage:    perPage,
-	}, nil
+// SetAuditRecorder allows injecting AuditRecorder
+func (u *AdminUsecase) SetAuditRecorder(recorder AuditRecorder) {
+    u.auditRecorder = recorder
 }
 
 func (u *AdminUsecase) UpdateUserRole(ctx context.Context, req *entities.UpdateUserRoleRequest, requesterRole string, requesterSchoolID string) error {
-	// 権限チェック
-	if !u.canManageUsers(requesterRole, req.SchoolID, requesterSchoolID) {
-		return fmt.Errorf("insufficient permissions to update user role")
+	// 権限チェック（学校スコープの場合は自分の学校に固定）
+	schoolIDToSet, err := scopedSchoolID(ctx, policy.UsersUpdateRole, req.SchoolID, requesterRole, requesterSchoolID)
+	if err != nil {
+		return err
 	}
 
 	// 対象ユーザーを取得
@@ -66,29 +74,49 @@ func (u *AdminUsecase) UpdateUserRole(ctx context.Context, req *entities.UpdateU
 		return fmt.Errorf("failed to get target user: %w", err)
 	}
 
-	// school_adminは自分の学校のユーザーのみ変更可能
-	if requesterRole == "school_admin" && (targetUser.SchoolID == nil || *targetUser.SchoolID != requesterSchoolID) {
-		return fmt.Errorf("cannot modify users from other schools")
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersUpdateRole, userResource(targetUser)); err != nil {
+		return err
+	}
+
+	// 現在のロールを自分が付与できないユーザーは変更不可（school_adminによる同じ学校のschool_adminの降格など）
+	if !policy.CanAssignRole(requesterRole, targetUser.Role) {
+		return fmt.Errorf("%w: cannot modify %s users", policy.ErrForbidden, targetUser.Role)
 	}
 
-	// adminロールは管理者のみが設定可能
-	if req.Role == "admin" && requesterRole != "admin" {
-		return fmt.Errorf("only system administrators can assign admin role")
+	// 付与できるロールの制限
+	if !policy.CanAssignRole(requesterRole, req.Role) {
+		return fmt.Errorf("%w: cannot assign %s role", policy.ErrForbidden, req.Role)
 	}
 
-	// school_adminロールの制限
-	if req.Role == "school_admin" && requesterRole == "school_admin" {
-		return fmt.Errorf("school administrators cannot assign school_admin role")
+	if err := u.adminRepo.UpdateUserRole(ctx, req.UserID, req.Role, schoolIDToSet); err != nil {
+		return err
 	}
 
-	// 学校IDの設定
-	schoolIDToSet := req.SchoolID
-	if requesterRole == "school_admin" {
-		// school_adminの場合は自分の学校に固定
-		schoolIDToSet = &requesterSchoolID
+	// 更新後の値をカスタムクレームへ反映
+	updatedUser, err := u.adminRepo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to reload updated user: %w", err)
+	}
+	updatedSchoolID := stringValue(updatedUser.SchoolID)
+
+	auditSchoolID := updatedSchoolID
+	if auditSchoolID == "" {
+		auditSchoolID = stringValue(targetUser.SchoolID)
+	}
+	if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionUserUpdateRole,
+		TargetType: audit.TargetUser,
+		TargetID:   targetUser.ID,
+		SchoolID:   auditSchoolID,
+		Before:     map[string]interface{}{"role": targetUser.Role, "school_id": stringValue(targetUser.SchoolID)},
+		After:      map[string]interface{}{"role": updatedUser.Role, "school_id": updatedSchoolID},
+	}); err != nil {
+		return err
 	}
 
-	return u.adminRepo.UpdateUserRole(ctx, req.UserID, req.Role, schoolIDToSet)
+	u.syncUserClaims(ctx, updatedUser.FirebaseUID, updatedUser.Role, updatedSchoolID)
+
+	return nil
 }
 
 func (u *AdminUsecase) UpdateUserStatus(ctx context.Context, userID string, isActive, isApproved bool, requesterRole string, requesterSchoolID string) error {
@@ -98,34 +126,159 @@ func (u *AdminUsecase) UpdateUserStatus(ctx context.Context, userID string, isAc
 		return fmt.Errorf("failed to get target user: %w", err)
 	}
 
-	// school_adminは自分の学校のユーザーのみ変更可能
-	if requesterRole == "school_admin" && (targetUser.SchoolID == nil || *targetUser.SchoolID != requesterSchoolID) {
-		return fmt.Errorf("cannot modify users from other schools")
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersUpdateStatus, userResource(targetUser)); err != nil {
+		return err
+	}
+
+	// 自分が付与できないロールのユーザーは変更不可（school_adminによるadminの無効化など）
+	if !policy.CanAssignRole(requesterRole, targetUser.Role) {
+		return fmt.Errorf("%w: cannot modify %s users", policy.ErrForbidden, targetUser.Role)
+	}
+
+	if err := u.adminRepo.UpdateUserStatus(ctx, userID, isActive, isApproved); err != nil {
+		return err
+	}
+
+	if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionUserUpdateStatus,
+		TargetType: audit.TargetUser,
+		TargetID:   targetUser.ID,
+		SchoolID:   stringValue(targetUser.SchoolID),
+		Before:     map[string]interface{}{"is_active": targetUser.IsActive, "is_approved": targetUser.IsApproved},
+		After:      map[string]interface{}{"is_active": isActive, "is_approved": isApproved},
+	}); err != nil {
+		return err
+	}
+
+	// セッションの失効と通知はコミット後に行う（無効化済みのアカウントは認証時に拒否されるため、失効の失敗はログのみ）
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		if !isActive {
+			if _, err := u.logoutUser(ctx, targetUser); err != nil {
+				log.Printf("User %s deactivated but failed to revoke sessions: %v", targetUser.ID, err)
+			}
+		}
+
+		// 新たに承認されたユーザーへ通知
+		if isApproved && !targetUser.IsApproved {
+			sendTemplateMail(ctx, u.mailer, u.config.MailLocale, mail.TemplateApproval, targetUser.Email, mail.ApprovalData{
+				Name: targetUser.Name,
+				URL:  u.config.FrontendURL + "/login",
+			})
+		}
+	})
+
+	return nil
+}
+
+// ForceLogoutUser ユーザーの全セッションを強制ログアウト
+func (u *AdminUsecase) ForceLogoutUser(ctx context.Context, userID string, requesterRole string, requesterSchoolID string) (int, error) {
+	targetUser, err := u.adminRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get target user: %w", err)
+	}
+
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersUpdateStatus, userResource(targetUser)); err != nil {
+		return 0, err
+	}
+	if !policy.CanAssignRole(requesterRole, targetUser.Role) {
+		return 0, fmt.Errorf("%w: cannot modify %s users", policy.ErrForbidden, targetUser.Role)
+	}
+
+	return u.logoutUser(ctx, targetUser)
+}
+
+// ForceLogoutSchool 学校の全ユーザーを強制ログアウト
+func (u *AdminUsecase) ForceLogoutSchool(ctx context.Context, schoolID string, requesterRole string, requesterSchoolID string) (int, error) {
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersUpdateStatus, policy.Resource{SchoolID: schoolID}); err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	if u.sessionRepo != nil {
+		count, err := u.sessionRepo.DeleteBySchool(ctx, schoolID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to revoke school sessions: %w", err)
+		}
+		revoked = count
 	}
 
-	// adminユーザーはschool_adminが無効化できない
-	if targetUser.Role == "admin" && requesterRole != "admin" {
-		return fmt.Errorf("only system administrators can modify admin users")
+	// Firebaseのリフレッシュトークンも失効させ、セッションを使わないクライアントも再ログインさせる
+	if u.firebaseClient != nil && u.schoolRepo != nil {
+		id, err := strconv.ParseInt(schoolID, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid school ID: %s", schoolID)
+		}
+		users, err := u.schoolRepo.GetSchoolUsers(ctx, id, "")
+		if err != nil {
+			return 0, fmt.Errorf("failed to get school users: %w", err)
+		}
+		for _, user := range users {
+			if err := u.revokeUserSessions(ctx, user.FirebaseUID); err != nil {
+				log.Printf("Failed to revoke refresh tokens for uid %s: %v", user.FirebaseUID, err)
+			}
+		}
 	}
 
-	return u.adminRepo.UpdateUserStatus(ctx, userID, isActive, isApproved)
+	return revoked, nil
 }
 
-func (u *AdminUsecase) GetAllSchools(ctx context.Context, requesterRole string) ([]entities.SchoolOption, error) {
-	// 管理者権限が必要
-	if requesterRole != "admin" && requesterRole != "school_admin" {
-		return nil, fmt.Errorf("insufficient permissions to view schools")
+// logoutUser Redisのセッションを削除し、Firebaseのリフレッシュトークンを失効
+func (u *AdminUsecase) logoutUser(ctx context.Context, user *entities.UserManagement) (int, error) {
+	revoked := 0
+	if u.sessionRepo != nil {
+		count, err := u.sessionRepo.DeleteByUser(ctx, user.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to revoke user sessions: %w", err)
+		}
+		revoked = count
 	}
 
-	return u.adminRepo.GetAllSchools(ctx)
+	if err := u.revokeUserSessions(ctx, user.FirebaseUID); err != nil {
+		return revoked, err
+	}
+	return revoked, nil
+}
+
+func (u *AdminUsecase) GetAllSchools(ctx context.Context, requesterRole string, requesterSchoolID string) ([]entities.SchoolOption, error) {
+	// 選択肢の一覧はユーザー管理権限を持つ場合のみ
+	if err := policy.Require(requesterRole, policy.UsersRead); err != nil {
+		return nil, err
+	}
+
+	schools, err := u.adminRepo.GetAllSchools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if policy.ScopeOf(requesterRole, policy.SchoolsRead) == policy.ScopeGlobal {
+		return schools, nil
+	}
+
+	// 学校スコープの場合は自分の学校のみ
+	subject := subjectOf(ctx, requesterRole, requesterSchoolID)
+	visible := make([]entities.SchoolOption, 0, 1)
+	for _, school := range schools {
+		if policy.Can(subject, policy.SchoolsRead, policy.Resource{SchoolID: school.ID}) {
+			visible = append(visible, school)
+		}
+	}
+	return visible, nil
 }
 
 func (u *AdminUsecase) GetUserStatsByRole(ctx context.Context, schoolID *string, requesterRole string, requesterSchoolID string) (map[string]int, error) {
-	// school_adminの場合は自分の学校の統計のみ
-	if requesterRole == "school_admin" {
+	// 学校スコープの場合は自分の学校の統計のみ、教育委員会スコープの場合は管轄校のみ
+	switch policy.ScopeOf(requesterRole, policy.UsersStats) {
+	case policy.ScopeSchool:
 		schoolID = &requesterSchoolID
-	} else if requesterRole != "admin" {
-		return nil, fmt.Errorf("insufficient permissions to view user statistics")
+	case policy.ScopeDistrict:
+		scoped, err := scopedSchoolID(ctx, policy.UsersStats, schoolID, requesterRole, requesterSchoolID)
+		if err != nil {
+			return nil, err
+		}
+		schoolID = scoped
+	default:
+		if err := policy.Require(requesterRole, policy.UsersStats); err != nil {
+			return nil, err
+		}
 	}
 
 	return u.adminRepo.GetUserStatsByRole(ctx, schoolID)
@@ -137,12 +290,8 @@ func (u *AdminUsecase) GetUserByID(ctx context.Context, userID string, requester
     if err != nil {
         return nil, err
     }
-    if requesterRole == "school_admin" {
-        if user.SchoolID == nil || *user.SchoolID != requesterSchoolID {
-            return nil, fmt.Errorf("insufficient permissions to view this user")
-        }
-    } else if requesterRole != "admin" {
-        return nil, fmt.Errorf("insufficient permissions")
+    if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersRead, userResource(user)); err != nil {
+        return nil, err
     }
     return user, nil
 }
@@ -150,21 +299,37 @@ func (u *AdminUsecase) GetUserByID(ctx context.Context, userID string, requester
 // UpdateUser 管理者用：ユーザー情報更新
 func (u *AdminUsecase) UpdateUser(ctx context.Context, userID string, updateData entities.User, requesterRole string, requesterSchoolID string) (*entities.User, error) {
     // 役割や学校IDの制約
-    if requesterRole == "school_admin" {
-        // school_admin は自校ユーザーのみ更新可、school_id は自校に限定
-        target, err := u.adminRepo.GetUserByID(ctx, userID)
-        if err != nil {
-            return nil, err
-        }
-        if target.SchoolID == nil || *target.SchoolID != requesterSchoolID {
-            return nil, fmt.Errorf("cannot modify users from other schools")
-        }
+    target, err := u.adminRepo.GetUserByID(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersUpdate, userResource(target)); err != nil {
+        return nil, err
+    }
+    // 現在のロールを自分が付与できないユーザーは変更不可（同じ学校の管理者の降格など）
+    if !policy.CanAssignRole(requesterRole, target.Role) {
+        return nil, fmt.Errorf("%w: cannot modify %s users", policy.ErrForbidden, target.Role)
+    }
+    switch policy.ScopeOf(requesterRole, policy.UsersUpdate) {
+    case policy.ScopeSchool:
+        // 学校スコープの場合は school_id を自校に限定
         updateData.SchoolID = requesterSchoolID
-        if updateData.Role == "admin" {
-            return nil, fmt.Errorf("school administrators cannot assign admin role")
+    case policy.ScopeDistrict:
+        // 教育委員会スコープの場合は管轄校の間でのみ移動できる
+        if updateData.SchoolID != "" {
+            if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersUpdate, policy.Resource{SchoolID: updateData.SchoolID}); err != nil {
+                return nil, err
+            }
         }
-    } else if requesterRole != "admin" {
-        return nil, fmt.Errorf("insufficient permissions")
+    }
+    // ロールが変わる場合は付与可能なロールのみ
+    if updateData.Role != "" && updateData.Role != target.Role && !policy.CanAssignRole(requesterRole, updateData.Role) {
+        return nil, fmt.Errorf("%w: cannot assign %s role", policy.ErrForbidden, updateData.Role)
+    }
+    // クラスは在籍人数を合わせるためTransferClassで変更する
+    updateData.ClassID = nil
+    if err := u.validateProfileUpdate(ctx, target, &updateData); err != nil {
+        return nil, err
     }
 
     // 実更新
@@ -172,52 +337,203 @@ func (u *AdminUsecase) UpdateUser(ctx context.Context, userID string, updateData
     if err != nil {
         return nil, err
     }
+
+    before := userAuditFields(target)
+    delete(before, "is_active")
+    delete(before, "is_approved")
+    before["furigana"] = stringValue(target.Furigana)
+    before["avatar_url"] = stringValue(target.AvatarURL)
+    before["student_number"] = stringValue(target.StudentNumber)
+    before["grade"] = intValue(target.Grade)
+    if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+        Action:     audit.ActionUserUpdate,
+        TargetType: audit.TargetUser,
+        TargetID:   target.ID,
+        SchoolID:   updated.SchoolID,
+        Before:     before,
+        After: map[string]interface{}{
+            "name":      updated.DisplayName,
+            "email":     updated.Email,
+            "role":      updated.Role,
+            "school_id": updated.SchoolID,
+            "furigana":       stringValue(updated.Furigana),
+            "avatar_url":     stringValue(updated.AvatarURL),
+            "student_number": stringValue(updated.StudentNumber),
+            "grade":          intValue(updated.Grade),
+        },
+    }); err != nil {
+        return nil, err
+    }
+
+    // 更新後の値をカスタムクレームへ反映
+    u.syncUserClaims(ctx, updated.FirebaseUID, updated.Role, updated.SchoolID)
     return updated, nil
 }
 
-// 権限チェックヘルパー
-func (u *AdminUsecase) canManageUsers(requesterRole string, targetSchoolID *string, requesterSchoolID string) bool {
-	switch requesterRole {
-	case "admin":
-		return true // adminは全てのユーザーを管理可能
-	case "school_admin":
-		// school_adminは自分の学校のユーザーのみ管理可能
-		return targetSchoolID == nil || *targetSchoolID == requesterSchoolID
+// subjectOf リクエスト元のロールと学校IDから権限判定用の操作者を生成
+// 教育委員会管理者の管轄はミドルウェアがコンテキストに設定したものを使う
+func subjectOf(ctx context.Context, requesterRole string, requesterSchoolID string) policy.Subject {
+	return policy.WithDistrict(ctx, policy.Subject{Role: requesterRole, SchoolID: requesterSchoolID})
+}
+
+// userResource ユーザーを権限判定用のリソースに変換
+// 教育委員会管理者は所属校ではなく教育委員会の単位で扱うため、全体スコープでのみ操作できる
+func userResource(user *entities.UserManagement) policy.Resource {
+	resource := policy.Resource{OwnerID: user.ID}
+	if user.Role == policy.RoleDistrictAdmin {
+		return resource
+	}
+	if user.SchoolID != nil {
+		resource.SchoolID = *user.SchoolID
+	}
+	return resource
+}
+
+// scopedSchoolID 権限のスコープに応じて絞り込み対象の学校IDを決定
+// 学校スコープの場合は自分の学校に固定し、他校が指定された場合はエラー
+// 教育委員会スコープで学校を指定しない場合は管轄校全体（RLSにより管轄外の学校は含まれない）
+func scopedSchoolID(ctx context.Context, perm policy.Permission, schoolID *string, requesterRole string, requesterSchoolID string) (*string, error) {
+	switch policy.ScopeOf(requesterRole, perm) {
+	case policy.ScopeGlobal:
+		return schoolID, nil
+	case policy.ScopeDistrict:
+		if schoolID != nil {
+			if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), perm, policy.Resource{SchoolID: *schoolID}); err != nil {
+				return nil, err
+			}
+		}
+		return schoolID, nil
+	case policy.ScopeSchool:
+		if schoolID != nil && *schoolID != requesterSchoolID {
+			return nil, fmt.Errorf("%w: %s", policy.ErrForbidden, perm)
+		}
+		return &requesterSchoolID, nil
 	default:
-		return false
+		return nil, fmt.Errorf("%w: %s", policy.ErrForbidden, perm)
 	}
 }
 
 // InviteUser ユーザー招待
-func (u *AdminUsecase) InviteUser(ctx context.Context, name, email, role string, schoolID string, message, requesterRole string, requesterSchoolID string) error {
+func (u *AdminUsecase) InviteUser(ctx context.Context, name, email, role string, schoolID string, message, requesterRole string, requesterSchoolID string) (*entities.UserInvitation, error) {
 	// 権限チェック
-	if !u.canManageUsers(requesterRole, &schoolID, requesterSchoolID) {
-		return fmt.Errorf("insufficient permissions to invite users")
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersInvite, policy.Resource{SchoolID: schoolID}); err != nil {
+		return nil, err
 	}
 
-	// 役割のバリデーション
-	validRoles := []string{"school_admin", "teacher", "student"}
-	validRole := false
-	for _, validRoleName := range validRoles {
-		if role == validRoleName {
-			validRole = true
-			break
-		}
-	}
-	if !validRole {
-		return fmt.Errorf("invalid role: %s", role)
+	// 役割のバリデーション（adminは招待不可）
+	if role == policy.RoleAdmin || !policy.CanAssignRole(requesterRole, role) {
+		return nil, fmt.Errorf("invalid role: %s", role)
 	}
 
+    // 期限切れの招待をexpiredにしてから作成（同じメールアドレスのpendingの招待は1件のみ）
+    if _, err := u.adminRepo.ExpireInvitations(ctx); err != nil {
+        return nil, fmt.Errorf("failed to expire invitations: %w", err)
+    }
+
     // 招待レコードをDBに保存
-    token := fmt.Sprintf("inv_%d", time.Now().UnixNano())
-    expiresAt := time.Now().Add(7 * 24 * time.Hour)
+    token, err := generateSecureToken(32)
+    if err != nil {
+        return nil, fmt.Errorf("failed to generate token: %w", err)
+    }
+    expiresAt := time.Now().Add(invitationTTL)
 
-    if err := u.adminRepo.CreateUserInvitation(ctx, name, email, role, schoolID, message, token, expiresAt); err != nil {
-        return fmt.Errorf("failed to create user invitation: %w", err)
+    invitation, err := u.adminRepo.CreateUserInvitation(ctx, name, email, role, schoolID, message, token, expiresAt)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create user invitation: %w", err)
     }
 
-    // TODO: 招待メール送信
-    return nil
+    // トークンは記録しない
+    if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+        Action:     audit.ActionUserInvite,
+        TargetType: audit.TargetInvitation,
+        TargetID:   invitation.ID,
+        SchoolID:   invitation.SchoolID,
+        After: map[string]interface{}{
+            "name":  invitation.Name,
+            "email": invitation.Email,
+            "role":  invitation.Role,
+        },
+    }); err != nil {
+        return nil, err
+    }
+
+    u.sendInvitationMail(ctx, invitation)
+    return invitation, nil
+}
+
+// ListInvitations 招待一覧を取得
+func (u *AdminUsecase) ListInvitations(ctx context.Context, page, perPage int, schoolID, status *string, requesterRole string, requesterSchoolID string) (*entities.InvitationListResponse, error) {
+	// 権限チェック（学校スコープの場合は自分の学校の招待のみ）
+	schoolID, err := scopedSchoolID(ctx, policy.InvitationsManage, schoolID, requesterRole, requesterSchoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 期限切れの招待を先にexpiredへ更新
+	if _, err := u.adminRepo.ExpireInvitations(ctx); err != nil {
+		return nil, err
+	}
+
+	invitations, totalCount, err := u.adminRepo.ListInvitations(ctx, page, perPage, schoolID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+
+	return &entities.InvitationListResponse{
+		Invitations: invitations,
+		TotalCount:  totalCount,
+		Page:        page,
+		PerPage:     perPage,
+	}, nil
+}
+
+// RevokeInvitation 招待を取り消す
+func (u *AdminUsecase) RevokeInvitation(ctx context.Context, invitationID string, requesterRole string, requesterSchoolID string) error {
+	if _, err := u.getManageableInvitation(ctx, invitationID, requesterRole, requesterSchoolID); err != nil {
+		return err
+	}
+
+	return u.adminRepo.UpdateInvitationStatus(ctx, invitationID, "revoked")
+}
+
+// ResendInvitation 招待トークンを再発行して有効期限を延長
+func (u *AdminUsecase) ResendInvitation(ctx context.Context, invitationID string, requesterRole string, requesterSchoolID string) (*entities.UserInvitation, error) {
+	if _, err := u.getManageableInvitation(ctx, invitationID, requesterRole, requesterSchoolID); err != nil {
+		return nil, err
+	}
+
+	token, err := generateSecureToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	invitation, err := u.adminRepo.RegenerateInvitationToken(ctx, invitationID, token, time.Now().Add(invitationTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	u.sendInvitationMail(ctx, invitation)
+	return invitation, nil
+}
+
+// sendInvitationMail 招待メールを送信
+func (u *AdminUsecase) sendInvitationMail(ctx context.Context, invitation *entities.UserInvitation) {
+	sendTemplateMail(ctx, u.mailer, u.config.MailLocale, mail.TemplateInvitation, invitation.Email,
+		newInvitationMailData(ctx, u.schoolRepo, u.config, invitation))
+}
+
+// getManageableInvitation 操作権限のある招待を取得
+func (u *AdminUsecase) getManageableInvitation(ctx context.Context, invitationID string, requesterRole string, requesterSchoolID string) (*entities.UserInvitation, error) {
+	invitation, err := u.adminRepo.GetInvitationByID(ctx, invitationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.InvitationsManage, policy.Resource{SchoolID: invitation.SchoolID}); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
 }
 
 // CreateSchool 管理者用 学校作成ユースケース
@@ -225,11 +541,23 @@ func (u *AdminUsecase) CreateSchool(ctx context.Context, school *entities.School
     if school.SchoolName == "" {
         return nil, fmt.Errorf("school name is required")
     }
-    if school.SchoolID == "" {
-        return nil, fmt.Errorf("school code is required")
-    }
     if u.schoolRepo == nil {
         return nil, fmt.Errorf("school repository not configured")
     }
-    return u.schoolRepo.CreateSchool(ctx, school)
+
+    // コードが未指定の場合は設定の形式で採番する
+    created, err := createSchool(ctx, u.schoolRepo, u.config, school)
+    if err != nil {
+        return nil, err
+    }
+    if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+        Action:     audit.ActionSchoolCreate,
+        TargetType: audit.TargetSchool,
+        TargetID:   created.ID,
+        SchoolID:   created.ID,
+        After:      schoolAuditFields(created),
+    }); err != nil {
+        return nil, err
+    }
+    return created, nil
 }
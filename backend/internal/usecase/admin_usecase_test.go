@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+)
+
+// stubAdminRepository 対象ユーザーの取得と更新の呼び出しのみを扱うAdminRepository
+type stubAdminRepository struct {
+	repositories.AdminRepository
+	user        *entities.UserManagement
+	roleUpdated bool
+}
+
+func (r *stubAdminRepository) GetUserByID(ctx context.Context, userID string) (*entities.UserManagement, error) {
+	user := *r.user
+	return &user, nil
+}
+
+func (r *stubAdminRepository) UpdateUserRole(ctx context.Context, userID string, role string, schoolID *string) error {
+	r.roleUpdated = true
+	r.user.Role = role
+	return nil
+}
+
+// stubUserRepository ユーザー情報の更新の呼び出しのみを扱うUserRepository
+type stubUserRepository struct {
+	repositories.UserRepository
+	updated bool
+}
+
+func (r *stubUserRepository) UpdateUser(ctx context.Context, userID string, user entities.User) (*entities.User, error) {
+	r.updated = true
+	return &user, nil
+}
+
+func schoolUser(id, role, schoolID string) *entities.UserManagement {
+	return &entities.UserManagement{ID: id, Role: role, SchoolID: &schoolID, IsActive: true, IsApproved: true}
+}
+
+func TestUpdateUserRoleRejectsPeerAdmin(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  *entities.UserManagement
+		wantErr bool
+	}{
+		{"demote school admin of the same school", schoolUser("2", policy.RoleSchoolAdmin, "1"), true},
+		{"demote district admin", schoolUser("3", policy.RoleDistrictAdmin, "1"), true},
+		{"change teacher to student", schoolUser("4", policy.RoleTeacher, "1"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubAdminRepository{user: tt.target}
+			u := NewAdminUsecase(repo, nil, nil)
+
+			err := u.UpdateUserRole(context.Background(), &entities.UpdateUserRoleRequest{UserID: tt.target.ID, Role: policy.RoleStudent}, policy.RoleSchoolAdmin, "1")
+			if tt.wantErr {
+				if !errors.Is(err, policy.ErrForbidden) {
+					t.Fatalf("UpdateUserRole() error = %v, want ErrForbidden", err)
+				}
+				if repo.roleUpdated {
+					t.Error("UpdateUserRole() changed the role of a user the requester cannot manage")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateUserRole() error = %v", err)
+			}
+			if !repo.roleUpdated {
+				t.Error("UpdateUserRole() did not change the role")
+			}
+		})
+	}
+}
+
+func TestUpdateUserRejectsPeerAdmin(t *testing.T) {
+	repo := &stubAdminRepository{user: schoolUser("2", policy.RoleSchoolAdmin, "1")}
+	userRepo := &stubUserRepository{}
+	u := NewAdminUsecase(repo, userRepo, nil)
+
+	_, err := u.UpdateUser(context.Background(), "2", entities.User{Role: policy.RoleTeacher}, policy.RoleSchoolAdmin, "1")
+	if !errors.Is(err, policy.ErrForbidden) {
+		t.Fatalf("UpdateUser() error = %v, want ErrForbidden", err)
+	}
+	if userRepo.updated {
+		t.Error("UpdateUser() updated a school admin of the same school")
+	}
+}
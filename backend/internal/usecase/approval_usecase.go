@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/mail"
+)
+
+// ErrInvalidApprovalRequest 一括承認・却下の指定が正しくない
+var ErrInvalidApprovalRequest = errors.New("invalid approval request")
+
+// ApprovalUsecase 自己登録ユーザーの承認ワークフロー
+type ApprovalUsecase struct {
+	approvalRepo  repositories.ApprovalRepository
+	mailer        mail.Mailer
+	auditRecorder AuditRecorder
+	config        *config.Config
+}
+
+func NewApprovalUsecase(approvalRepo repositories.ApprovalRepository, cfg *config.Config) *ApprovalUsecase {
+	return &ApprovalUsecase{
+		approvalRepo: approvalRepo,
+		config:       cfg,
+	}
+}
+
+// SetMailer allows injecting Mailer
+func (u *ApprovalUsecase) SetMailer(mailer mail.Mailer) {
+	u.mailer = mailer
+}
+
+// SetAuditRecorder allows injecting AuditRecorder
+func (u *ApprovalUsecase) SetAuditRecorder(recorder AuditRecorder) {
+	u.auditRecorder = recorder
+}
+
+// GetAccountStatus 自分のアカウントの承認状況を取得（未承認のユーザーも参照可能）
+func (u *ApprovalUsecase) GetAccountStatus(ctx context.Context, firebaseUID string) (*entities.AccountStatus, error) {
+	return u.approvalRepo.GetAccountStatus(ctx, firebaseUID)
+}
+
+// ListPending 学校の承認待ちユーザーを申請の古い順に取得
+func (u *ApprovalUsecase) ListPending(ctx context.Context, schoolID string, page, perPage int, requesterRole string, requesterSchoolID string) (*entities.PendingApprovalListResponse, error) {
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersUpdateStatus, policy.Resource{SchoolID: schoolID}); err != nil {
+		return nil, err
+	}
+
+	users, totalCount, err := u.approvalRepo.ListPending(ctx, schoolID, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.PendingApprovalListResponse{
+		Users:      users,
+		TotalCount: totalCount,
+		Page:       page,
+		PerPage:    perPage,
+	}, nil
+}
+
+// Approve 承認待ちのユーザーを一括で承認し、本人へ通知する
+func (u *ApprovalUsecase) Approve(ctx context.Context, schoolID string, req entities.ApprovalReviewRequest, requesterID string, requesterRole string, requesterSchoolID string) (*entities.ApprovalReviewResult, error) {
+	return u.review(ctx, schoolID, req, true, requesterID, requesterRole, requesterSchoolID)
+}
+
+// Reject 承認待ちのユーザーを理由を付けて一括で却下し、本人へ通知する
+func (u *ApprovalUsecase) Reject(ctx context.Context, schoolID string, req entities.ApprovalReviewRequest, requesterID string, requesterRole string, requesterSchoolID string) (*entities.ApprovalReviewResult, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, fmt.Errorf("%w: reason is required to reject users", ErrInvalidApprovalRequest)
+	}
+	return u.review(ctx, schoolID, req, false, requesterID, requesterRole, requesterSchoolID)
+}
+
+// review 承認・却下の共通処理（承認待ちでない・付与できないロールのユーザーはスキップ）
+func (u *ApprovalUsecase) review(ctx context.Context, schoolID string, req entities.ApprovalReviewRequest, approve bool, requesterID string, requesterRole string, requesterSchoolID string) (*entities.ApprovalReviewResult, error) {
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersUpdateStatus, policy.Resource{SchoolID: schoolID}); err != nil {
+		return nil, err
+	}
+
+	if len(req.UserIDs) == 0 {
+		return nil, fmt.Errorf("%w: user_ids is required", ErrInvalidApprovalRequest)
+	}
+	if len(req.UserIDs) > entities.MaxApprovalBatchSize {
+		return nil, fmt.Errorf("%w: at most %d users can be reviewed at once", ErrInvalidApprovalRequest, entities.MaxApprovalBatchSize)
+	}
+	for _, id := range req.UserIDs {
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: invalid user id %q", ErrInvalidApprovalRequest, id)
+		}
+	}
+
+	// 自分が付与できないロール（school_adminから見たadminなど）は審査できない
+	var roles []string
+	for _, role := range policy.Roles() {
+		if policy.CanAssignRole(requesterRole, role) {
+			roles = append(roles, role)
+		}
+	}
+
+	var reason *string
+	if trimmed := strings.TrimSpace(req.Reason); trimmed != "" && !approve {
+		reason = &trimmed
+	}
+
+	reviewed, err := u.approvalRepo.ReviewUsers(ctx, schoolID, req.UserIDs, roles, approve, reason, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	action := audit.ActionUserApprove
+	if !approve {
+		action = audit.ActionUserReject
+	}
+
+	result := &entities.ApprovalReviewResult{Reviewed: []string{}, Skipped: []string{}}
+	reviewedIDs := make(map[string]bool, len(reviewed))
+	for _, user := range reviewed {
+		after := map[string]interface{}{"is_approved": approve}
+		if strings.TrimSpace(req.Reason) != "" {
+			after["reason"] = strings.TrimSpace(req.Reason)
+		}
+		if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+			Action:     action,
+			TargetType: audit.TargetUser,
+			TargetID:   user.ID,
+			SchoolID:   stringValue(user.SchoolID),
+			Before:     map[string]interface{}{"is_approved": false},
+			After:      after,
+		}); err != nil {
+			return nil, err
+		}
+
+		reviewedIDs[user.ID] = true
+		result.Reviewed = append(result.Reviewed, user.ID)
+	}
+	for _, id := range req.UserIDs {
+		if !reviewedIDs[id] {
+			result.Skipped = append(result.Skipped, id)
+		}
+	}
+
+	// 通知はすべての審査結果と監査ログがコミットされてから行う（途中で失敗した場合に誰にも通知しない）
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		for _, user := range reviewed {
+			u.notifyReviewed(ctx, user, approve, stringValue(reason))
+		}
+	})
+
+	return result, nil
+}
+
+// notifyReviewed 審査結果を本人へメールで通知
+func (u *ApprovalUsecase) notifyReviewed(ctx context.Context, user entities.UserManagement, approved bool, reason string) {
+	if approved {
+		sendTemplateMail(ctx, u.mailer, u.config.MailLocale, mail.TemplateApproval, user.Email, mail.ApprovalData{
+			Name: user.Name,
+			URL:  u.config.FrontendURL + "/login",
+		})
+		return
+	}
+
+	sendTemplateMail(ctx, u.mailer, u.config.MailLocale, mail.TemplateRejection, user.Email, mail.RejectionData{
+		Name:       user.Name,
+		SchoolName: stringValue(user.SchoolName),
+		Reason:     reason,
+	})
+}
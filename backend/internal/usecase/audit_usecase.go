@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+)
+
+// auditVerifyBatchSize ハッシュチェーン検証時に一度に読み込むイベント数
+const auditVerifyBatchSize = 500
+
+// AuditRecorder 管理操作を監査ログに記録する
+// 記録に失敗した場合は操作自体もエラーとし、テナントトランザクションごとロールバックさせる
+type AuditRecorder interface {
+	Record(ctx context.Context, entry audit.Entry) error
+}
+
+// AuditUsecase 監査ログの記録・閲覧・改ざん検証
+type AuditUsecase struct {
+	auditRepo repositories.AuditRepository
+	adminRepo repositories.AdminRepository
+	config    *config.Config
+}
+
+func NewAuditUsecase(auditRepo repositories.AuditRepository, adminRepo repositories.AdminRepository, cfg *config.Config) *AuditUsecase {
+	return &AuditUsecase{
+		auditRepo: auditRepo,
+		adminRepo: adminRepo,
+		config:    cfg,
+	}
+}
+
+// Record コンテキストの操作者情報とともに操作内容を記録
+// ユーザー・招待の氏名・メールアドレスなどは値を記録せず、変更があったことのみ記録する
+func (u *AuditUsecase) Record(ctx context.Context, entry audit.Entry) error {
+	changes := audit.Diff(entry.Before, entry.After)
+	audit.RedactPersonalData(entry.TargetType, changes)
+
+	event := &entities.AuditEvent{
+		ActorRole:  audit.ActorSystem,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Changes:    changes,
+	}
+	if entry.SchoolID != "" {
+		schoolID := entry.SchoolID
+		event.SchoolID = &schoolID
+	}
+
+	if actor := audit.ActorFromContext(ctx); actor != nil {
+		event.ActorUID = actor.UID
+		event.RequestID = actor.RequestID
+		event.IPAddress = actor.IPAddress
+		if actor.Role != "" {
+			event.ActorRole = actor.Role
+		}
+
+		actorID := actor.UserID
+		if actorID == "" && actor.UID != "" {
+			// トークン認証のみのハンドラーではusersテーブルのIDが未解決のため補完する
+			if account, err := u.adminRepo.GetUserByFirebaseUID(ctx, actor.UID); err == nil {
+				actorID = account.ID
+			} else {
+				log.Printf("Failed to resolve audit actor for uid %s: %v", actor.UID, err)
+			}
+		}
+		if actorID != "" {
+			event.ActorID = &actorID
+		}
+
+		// 代理ログイン中の操作は実際に操作した管理者も記録する
+		if actor.ImpersonatorUID != "" {
+			event.ImpersonatorUID = actor.ImpersonatorUID
+			if account, err := u.adminRepo.GetUserByFirebaseUID(ctx, actor.ImpersonatorUID); err == nil {
+				event.ImpersonatorID = &account.ID
+			} else {
+				log.Printf("Failed to resolve impersonator for uid %s: %v", actor.ImpersonatorUID, err)
+			}
+		}
+	}
+
+	if err := u.auditRepo.Append(ctx, event); err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// ListEvents 監査ログを新しい順に取得（学校スコープの場合は自分の学校のみ）
+func (u *AuditUsecase) ListEvents(ctx context.Context, filter entities.AuditEventFilter, page, perPage int, requesterRole string, requesterSchoolID string) (*entities.AuditEventListResponse, error) {
+	schoolID, err := scopedSchoolID(ctx, policy.AuditRead, filter.SchoolID, requesterRole, requesterSchoolID)
+	if err != nil {
+		return nil, err
+	}
+	filter.SchoolID = schoolID
+
+	events, totalCount, err := u.auditRepo.List(ctx, filter, page, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit events: %w", err)
+	}
+
+	return &entities.AuditEventListResponse{
+		Events:     events,
+		TotalCount: totalCount,
+		Page:       page,
+		PerPage:    perPage,
+	}, nil
+}
+
+// VerifyChain 学校のハッシュチェーンを先頭から再計算し、改ざん・欠落がないか検証
+// schoolIDがnilの場合は学校に属さない操作のチェーンを対象とする（全体スコープのみ）
+func (u *AuditUsecase) VerifyChain(ctx context.Context, schoolID *string, requesterRole string, requesterSchoolID string) (*entities.AuditChainVerification, error) {
+	schoolID, err := scopedSchoolID(ctx, policy.AuditRead, schoolID, requesterRole, requesterSchoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entities.AuditChainVerification{SchoolID: schoolID, Valid: true}
+	prevHash := ""
+	afterID := "0"
+	for {
+		events, err := u.auditRepo.ListChain(ctx, schoolID, afterID, auditVerifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range events {
+			event := &events[i]
+			hash, err := audit.ComputeHash(prevHash, event)
+			if err != nil {
+				return nil, err
+			}
+			if event.PrevHash != prevHash || event.Hash != hash {
+				result.Valid = false
+				result.BrokenEventID = event.ID
+				return result, nil
+			}
+
+			result.CheckedEvents++
+			prevHash = event.Hash
+			afterID = event.ID
+		}
+
+		if len(events) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// userAuditFields ユーザーの監査対象項目
+func userAuditFields(user *entities.UserManagement) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        user.Name,
+		"email":       user.Email,
+		"role":        user.Role,
+		"school_id":   stringValue(user.SchoolID),
+		"is_active":   user.IsActive,
+		"is_approved": user.IsApproved,
+	}
+}
+
+// schoolAuditFields 学校の監査対象項目
+func schoolAuditFields(school *entities.School) map[string]interface{} {
+	var settings interface{}
+	if school.Settings != nil {
+		settings = *school.Settings
+	}
+	return map[string]interface{}{
+		"name":                school.SchoolName,
+		"code":                school.SchoolID,
+		"address":             stringValue(school.Address),
+		"phone":               stringValue(school.Phone),
+		"email_domain":        stringValue(school.EmailDomain),
+		"theme_color":         school.ThemeColor,
+		"background_color":    school.BackgroundColor,
+		"logo_url":            stringValue(school.LogoURL),
+		"principal_name":      stringValue(school.PrincipalName),
+		"vice_principal_name": stringValue(school.VicePrincipalName),
+		"student_capacity":    school.StudentCapacity,
+		"academic_year_start": school.AcademicYearStart,
+		"settings":            settings,
+	}
+}
+
+// stringValue nilの場合は空文字を返す
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// intValue 監査ログ用に整数のポインターを値に変換（nilの場合はnil）
+func intValue(value *int) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// recordAudit 監査ログを記録（記録先が未設定の場合は何もしない）
+func recordAudit(ctx context.Context, recorder AuditRecorder, entry audit.Entry) error {
+	if recorder == nil {
+		return nil
+	}
+	return recorder.Record(ctx, entry)
+}
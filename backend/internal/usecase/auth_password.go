@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/mail"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// minPasswordLength パスワードの最小文字数
+	minPasswordLength = 8
+	// maxLoginFailures この回数連続で失敗するとロックする
+	maxLoginFailures = 5
+	// loginLockoutWindow 失敗回数を数える期間（ロック中はこの期間が過ぎるまでログイン不可）
+	loginLockoutWindow = 15 * time.Minute
+	// passwordResetTTL パスワード再設定リンクの有効期限
+	passwordResetTTL = time.Hour
+)
+
+var (
+	// ErrInvalidCredentials メールアドレスまたはパスワードが正しくない
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrAccountLocked ログイン失敗が続いたため一時的にロック中
+	ErrAccountLocked = errors.New("too many failed login attempts, please try again later")
+	// ErrAccountDisabled 無効化・未承認のアカウント
+	ErrAccountDisabled = errors.New("user account is inactive or not approved")
+	// ErrInvalidResetToken パスワード再設定トークンが無効・期限切れ・使用済み
+	ErrInvalidResetToken = errors.New("password reset token is invalid or has expired")
+	// ErrWeakPassword パスワードが要件を満たさない
+	ErrWeakPassword = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	// ErrPasswordReused 新しいパスワードが現在のパスワードと同じ
+	ErrPasswordReused = errors.New("new password must be different from the current password")
+)
+
+// dummyPasswordHash アカウントが存在しない場合の比較に使うハッシュ
+// 存在する場合と同じだけbcryptの計算を行い、応答時間からアカウントの有無を推測されないようにする
+var (
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
+)
+
+// compareDummyPassword アカウントが存在しない場合もbcryptの比較を1回行う（結果は常に不一致として扱う）
+func compareDummyPassword(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		hash, err := bcrypt.GenerateFromPassword([]byte("bloomia-dummy-password"), bcrypt.DefaultCost)
+		if err != nil {
+			log.Printf("Failed to generate dummy password hash: %v", err)
+			return
+		}
+		dummyPasswordHash = hash
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+}
+
+// SetCredentialRepository allows injecting CredentialRepository
+func (u *AuthUsecase) SetCredentialRepository(repo repositories.CredentialRepository) {
+	u.credentialRepo = repo
+}
+
+// SetLoginAttemptRepository allows injecting LoginAttemptRepository
+func (u *AuthUsecase) SetLoginAttemptRepository(repo repositories.LoginAttemptRepository) {
+	u.loginAttempts = repo
+}
+
+// SetSessionUsecase allows injecting SessionUsecase
+func (u *AuthUsecase) SetSessionUsecase(sessionUsecase *SessionUsecase) {
+	u.sessionUsecase = sessionUsecase
+}
+
+// LoginUser メールアドレスとパスワードでログインし、セッションとアクセストークンを発行
+// 初回ログイン時のパスワード変更が必要な場合はトークンを発行せずMustChangePasswordを返す
+func (u *AuthUsecase) LoginUser(ctx context.Context, email, password string, client entities.SessionClient) (*entities.LoginResult, error) {
+	credentials, err := u.verifyPassword(ctx, email, password)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := u.adminRepo.GetUserByFirebaseUID(ctx, credentials.FirebaseUID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+
+	if credentials.MustChangePassword {
+		return &entities.LoginResult{User: account, MustChangePassword: true}, nil
+	}
+
+	return u.startPasswordSession(ctx, account, client)
+}
+
+// ChangePassword 現在のパスワードを確認して変更し、新しいセッションでログイン
+// 初回ログイン時の強制変更にも使用するため、アクセストークンではなくパスワードで本人確認する
+func (u *AuthUsecase) ChangePassword(ctx context.Context, email, currentPassword, newPassword string, client entities.SessionClient) (*entities.LoginResult, error) {
+	if err := validatePassword(newPassword); err != nil {
+		return nil, err
+	}
+	if newPassword == currentPassword {
+		return nil, ErrPasswordReused
+	}
+
+	credentials, err := u.verifyPassword(ctx, email, currentPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.setPassword(ctx, credentials.UserID, newPassword); err != nil {
+		return nil, err
+	}
+
+	account, err := u.adminRepo.GetUserByFirebaseUID(ctx, credentials.FirebaseUID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+
+	return u.startPasswordSession(ctx, account, client)
+}
+
+// RequestPasswordReset パスワード再設定メールを送信
+// アカウントの有無を推測されないよう、対象外のメールアドレスでもエラーにしない
+func (u *AuthUsecase) RequestPasswordReset(ctx context.Context, email string) error {
+	if u.credentialRepo == nil {
+		return fmt.Errorf("password login is not configured")
+	}
+
+	credentials, err := u.credentialRepo.GetCredentialsByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return err
+	}
+	if credentials == nil || credentials.PasswordHash == "" || !credentials.IsActive {
+		return nil
+	}
+
+	token, err := generateSecureToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(passwordResetTTL)
+	if err := u.credentialRepo.CreatePasswordResetToken(ctx, credentials.UserID, hashToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	sendTemplateMail(ctx, u.mailer, u.config.MailLocale, mail.TemplatePasswordReset, credentials.Email, mail.PasswordResetData{
+		Name:      credentials.Name,
+		URL:       u.config.FrontendURL + "/reset-password?token=" + url.QueryEscape(token),
+		ExpiresAt: expiresAt,
+	})
+
+	return nil
+}
+
+// ResetPassword 再設定トークンでパスワードを変更（トークンは一度のみ有効）
+func (u *AuthUsecase) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if u.credentialRepo == nil {
+		return fmt.Errorf("password login is not configured")
+	}
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	userID, err := u.credentialRepo.ConsumePasswordResetToken(ctx, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if userID == "" {
+		return ErrInvalidResetToken
+	}
+
+	if err := u.setPassword(ctx, userID, newPassword); err != nil {
+		return err
+	}
+
+	// 再設定後はロックを解除する
+	if credentials, err := u.credentialRepo.GetCredentialsByUserID(ctx, userID); err == nil {
+		u.resetLoginFailures(ctx, credentials.Email)
+	}
+
+	return nil
+}
+
+// verifyPassword ロック状態を確認したうえでパスワードを照合し、失敗回数を記録
+func (u *AuthUsecase) verifyPassword(ctx context.Context, email, password string) (*entities.UserCredentials, error) {
+	if u.credentialRepo == nil {
+		return nil, fmt.Errorf("password login is not configured")
+	}
+
+	lockKey := strings.ToLower(strings.TrimSpace(email))
+	if u.loginAttempts != nil {
+		failures, err := u.loginAttempts.GetFailures(ctx, lockKey)
+		if err != nil {
+			return nil, err
+		}
+		if failures >= maxLoginFailures {
+			return nil, ErrAccountLocked
+		}
+	}
+
+	credentials, err := u.credentialRepo.GetCredentialsByEmail(ctx, lockKey)
+	if err != nil {
+		return nil, err
+	}
+	if credentials == nil || credentials.PasswordHash == "" {
+		compareDummyPassword(password)
+		u.recordLoginFailure(ctx, lockKey)
+		return nil, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(credentials.PasswordHash), []byte(password)) != nil {
+		u.recordLoginFailure(ctx, lockKey)
+		return nil, ErrInvalidCredentials
+	}
+
+	if !credentials.IsActive || !credentials.IsApproved {
+		return nil, ErrAccountDisabled
+	}
+
+	u.resetLoginFailures(ctx, lockKey)
+	return credentials, nil
+}
+
+// setPassword パスワードを更新し、既存のセッションを全て失効させる
+func (u *AuthUsecase) setPassword(ctx context.Context, userID, password string) error {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := u.credentialRepo.UpdatePassword(ctx, userID, string(passwordHash), false); err != nil {
+		return err
+	}
+
+	if u.sessionUsecase != nil {
+		if _, err := u.sessionUsecase.RevokeUserSessions(ctx, userID); err != nil {
+			log.Printf("Failed to revoke sessions after password change for user %s: %v", userID, err)
+		}
+	}
+	return nil
+}
+
+// startPasswordSession ログイン結果にセッションとアクセストークンを設定
+func (u *AuthUsecase) startPasswordSession(ctx context.Context, account *entities.UserManagement, client entities.SessionClient) (*entities.LoginResult, error) {
+	if u.sessionUsecase == nil {
+		return nil, fmt.Errorf("password login is not configured")
+	}
+
+	tokens, err := u.sessionUsecase.StartPasswordSession(ctx, account, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.LoginResult{User: account, Tokens: tokens}, nil
+}
+
+// recordLoginFailure ログイン失敗を記録（Redis障害時もログイン判定自体は継続する）
+func (u *AuthUsecase) recordLoginFailure(ctx context.Context, key string) {
+	if u.loginAttempts == nil {
+		return
+	}
+	if _, err := u.loginAttempts.RecordFailure(ctx, key, loginLockoutWindow); err != nil {
+		log.Printf("Failed to record login failure: %v", err)
+	}
+}
+
+// resetLoginFailures ログイン失敗回数をリセット
+func (u *AuthUsecase) resetLoginFailures(ctx context.Context, email string) {
+	if u.loginAttempts == nil {
+		return
+	}
+	if err := u.loginAttempts.ResetFailures(ctx, strings.ToLower(strings.TrimSpace(email))); err != nil {
+		log.Printf("Failed to reset login failures: %v", err)
+	}
+}
+
+// validatePassword パスワードの要件を確認
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+// hashToken ワンタイムトークンをDB保存用にハッシュ化
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -4,27 +4,53 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
 	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/mail"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthUsecase struct {
 	userRepo repositories.UserRepository
 	adminRepo repositories.AdminRepository
+	credentialRepo repositories.CredentialRepository
+	loginAttempts  repositories.LoginAttemptRepository
+	sessionUsecase *SessionUsecase
+	mailer   mail.Mailer
+	config   *config.Config
 }
 
-func NewAuthUsecase(userRepo repositories.UserRepository, adminRepo repositories.AdminRepository) *AuthUsecase {
+func NewAuthUsecase(userRepo repositories.UserRepository, adminRepo repositories.AdminRepository, cfg *config.Config) *AuthUsecase {
 	return &AuthUsecase{
 		userRepo: userRepo,
 		adminRepo: adminRepo,
+		config:   cfg,
 	}
 }
 
+// SetMailer はDIのためのセッター
+func (u *AuthUsecase) SetMailer(mailer mail.Mailer) {
+	u.mailer = mailer
+}
+
+// invitationTTL 招待の有効期間
+const invitationTTL = 7 * 24 * time.Hour
+
+var (
+	// ErrSignupNotAllowed 招待もメールドメインの一致する学校もないため登録できない
+	ErrSignupNotAllowed = errors.New("no invitation or school matches this email address")
+	// ErrUnverifiedSignIn 招待の承諾・メールドメインでの登録には確認済みのメールアドレスが必要
+	ErrUnverifiedSignIn = errors.New("a verified email address is required to join a school")
+)
+
 // CreateInvitation ユーザー招待を作成
 func (u *AuthUsecase) CreateInvitation(ctx context.Context, name, email, role string, schoolID string, message string) (*entities.UserInvitation, error) {
 	// 招待トークンを生成
@@ -34,57 +60,25 @@ func (u *AuthUsecase) CreateInvitation(ctx context.Context, name, email, role st
 	}
 
 	// 有効期限を設定（7日間）
-	expiresAt := time.Now().Add(7 * 24 * time.Hour)
-
-	invitation := &entities.UserInvitation{
-		Name:      name,
-		Email:     email,
-		Role:      role,
-		SchoolID:  schoolID,
-		Message:   message,
-		Status:    "pending",
-		Token:     token,
-		ExpiresAt: expiresAt,
-	}
-
-	// TODO: データベースに保存
-	// invitation, err = u.adminRepo.CreateInvitation(ctx, invitation)
-	// if err != nil {
-	//     return nil, fmt.Errorf("failed to create invitation: %w", err)
-	// }
-
-	// TODO: 招待メールを送信
-	// err = u.sendInvitationEmail(invitation)
-	// if err != nil {
-	//     return nil, fmt.Errorf("failed to send invitation email: %w", err)
-	// }
+	expiresAt := time.Now().Add(invitationTTL)
+
+	invitation, err := u.adminRepo.CreateUserInvitation(ctx, name, email, role, schoolID, message, token, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	// 招待メールを送信（失敗しても招待作成は成功とする）
+	sendTemplateMail(ctx, u.mailer, u.config.MailLocale, mail.TemplateInvitation, invitation.Email,
+		newInvitationMailData(ctx, nil, u.config, invitation))
 
 	return invitation, nil
 }
 
 // ValidateInvitation 招待トークンを検証
 func (u *AuthUsecase) ValidateInvitation(ctx context.Context, token string) (*entities.UserInvitation, error) {
-	// TODO: データベースから招待情報を取得
-	// invitation, err := u.adminRepo.GetInvitationByToken(ctx, token)
-	// if err != nil {
-	//     return nil, fmt.Errorf("invitation not found: %w", err)
-	// }
-
-	// モックデータ
-	invitation := &entities.UserInvitation{
-		ID:        "1",
-		Name:      "テストユーザー",
-		Email:     "test@example.com",
-		Role:      "student",
-		SchoolID:  "1",
-		Status:    "pending",
-		Token:     token,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
-	}
-
-	// 有効期限チェック
-	if time.Now().After(invitation.ExpiresAt) {
-		return nil, fmt.Errorf("invitation has expired")
+	invitation, err := u.adminRepo.GetInvitationByToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("invitation not found: %w", err)
 	}
 
 	// ステータスチェック
@@ -92,19 +86,35 @@ func (u *AuthUsecase) ValidateInvitation(ctx context.Context, token string) (*en
 		return nil, fmt.Errorf("invitation is no longer valid")
 	}
 
+	// 有効期限チェック（期限切れの場合はステータスも更新）
+	if time.Now().After(invitation.ExpiresAt) {
+		if err := u.adminRepo.UpdateInvitationStatus(ctx, invitation.ID, "expired"); err != nil {
+			return nil, fmt.Errorf("failed to expire invitation: %w", err)
+		}
+		return nil, fmt.Errorf("invitation has expired")
+	}
+
 	return invitation, nil
 }
 
 // RegisterUser 招待に基づいてユーザー登録
-func (u *AuthUsecase) RegisterUser(ctx context.Context, token, name, email, password string) (*entities.User, error) {
+func (u *AuthUsecase) RegisterUser(ctx context.Context, token, name, email, password, firebaseUID string) (*entities.User, error) {
 	// 招待の検証
 	invitation, err := u.ValidateInvitation(ctx, token)
 	if err != nil {
 		return nil, fmt.Errorf("invalid invitation: %w", err)
 	}
 
-	// パスワードのハッシュ化（Firebaseでは不要だが、将来の拡張用）
-	_, err = bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	// 招待されたメールアドレス以外での登録は不可
+	if !strings.EqualFold(strings.TrimSpace(email), invitation.Email) {
+		return nil, fmt.Errorf("email does not match the invitation")
+	}
+
+	// パスワードのハッシュ化（Firebaseを使えない学校ではパスワードログインに使用）
+	if err := validatePassword(password); err != nil {
+		return nil, err
+	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -115,117 +125,118 @@ func (u *AuthUsecase) RegisterUser(ctx context.Context, token, name, email, pass
 		return nil, fmt.Errorf("invalid school ID: %w", err)
 	}
 
+	// Firebase UIDが未指定の場合はローカルアカウント用のUIDを発行
+	if firebaseUID == "" {
+		localID, err := generateSecureToken(12)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate uid: %w", err)
+		}
+		firebaseUID = "local_" + localID
+	}
+
 	// ユーザー作成
 	user := &entities.User{
+		FirebaseUID:  firebaseUID,
 		DisplayName:  name,
-		Email:        email,
+		Email:        invitation.Email,
 		Role:         invitation.Role,
 		SchoolID:     fmt.Sprintf("%d", schoolIDInt), // Convert to string
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		PasswordHash: string(passwordHash),
 	}
 
-	// TODO: データベースに保存
-	// user, err = u.userRepo.CreateUser(ctx, user, string(hashedPassword))
-	// if err != nil {
-	//     return nil, fmt.Errorf("failed to create user: %w", err)
-	// }
-
-	// TODO: 招待ステータスを更新
-	// err = u.adminRepo.UpdateInvitationStatus(ctx, invitation.ID, "accepted")
-	// if err != nil {
-	//     return nil, fmt.Errorf("failed to update invitation status: %w", err)
-	// }
-
-	return user, nil
-}
-
-// LoginUser ユーザーログイン
-func (u *AuthUsecase) LoginUser(ctx context.Context, email, password string) (*entities.User, error) {
-	// TODO: データベースからユーザーを取得
-	// user, err := u.userRepo.GetUserByEmail(ctx, email)
-	// if err != nil {
-	//     return nil, fmt.Errorf("user not found: %w", err)
-	// }
-
-	// モックデータ
-	user := &entities.User{
-		ID:          "test-uuid-1",
-		DisplayName: "テストユーザー",
-		Email:       email,
-		Role:        "student",
-		SchoolID:    "1",
+	// ユーザー作成と招待ステータス更新を同一トランザクションで実行
+	user, err = u.adminRepo.AcceptInvitation(ctx, invitation.ID, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register user: %w", err)
 	}
 
-	// アクティブ状態チェック（現在のスキーマでは省略）
-	// TODO: アクティブ状態チェックの実装
-
-	// TODO: パスワード検証
-	// err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
-	// if err != nil {
-	//     return nil, fmt.Errorf("invalid password: %w", err)
-	// }
-
 	return user, nil
 }
 
 // SyncUser Firebase認証とデータベースの同期
-func (u *AuthUsecase) SyncUser(ctx context.Context, firebaseUID, email string, displayName, schoolID, role *string) (*entities.User, error) {
+// 初回サインイン時は招待またはメールドメインから所属校を決定し、どちらにも該当しない場合は拒否する
+// UID・メールアドレスは検証済みトークンの値を渡し、emailVerifiedはIdPがメールアドレスの所有を確認済みかどうか
+func (u *AuthUsecase) SyncUser(ctx context.Context, firebaseUID, email string, emailVerified bool, displayName *string) (*entities.User, error) {
 	// 既存ユーザーをチェック
 	existingUser, err := u.userRepo.GetUserByFirebaseUID(ctx, firebaseUID)
 	if err == nil && existingUser != nil {
-		// 既存ユーザーの場合は更新
-		updateData := entities.User{
-			Email: email,
-		}
-		
-		if displayName != nil {
+		// 既存ユーザーの場合は表示名・メールアドレスのみ更新（ロール・所属校は管理者が変更する）
+		updateData := *existingUser
+		if displayName != nil && *displayName != "" {
 			updateData.DisplayName = *displayName
 		}
-		if role != nil {
-			updateData.Role = *role
-		}
-		if schoolID != nil {
-			updateData.SchoolID = *schoolID // Use string directly
+		if emailVerified && email != "" {
+			updateData.Email = email
 		}
-		
+
 		updatedUser, err := u.userRepo.UpdateUser(ctx, existingUser.ID, updateData)
 		if err != nil {
 			return nil, fmt.Errorf("failed to update user: %w", err)
 		}
-		
+
 		return updatedUser, nil
 	}
-	
+
 	// 新規ユーザーの場合は作成
+	// 招待・メールドメインはメールアドレスで照合するため、所有を確認できないアドレスでは所属校を決めない
+	if !emailVerified {
+		return nil, ErrUnverifiedSignIn
+	}
+	email = strings.TrimSpace(email)
 	newUser := &entities.User{
 		FirebaseUID: firebaseUID,
 		Email:       email,
 		DisplayName: email, // デフォルトはメールアドレス
-		Role:        "student", // デフォルトロール
-		SchoolID:    "1",  // デフォルト学校ID
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
 	}
-	
 	if displayName != nil && *displayName != "" {
 		newUser.DisplayName = *displayName
 	}
-	if role != nil {
-		newUser.Role = *role
+
+	// 招待済みのメールアドレスの場合は招待を承諾して承認済みユーザーとして作成
+	invitation, err := u.adminRepo.GetPendingInvitationByEmail(ctx, email)
+	if err != nil {
+		return nil, err
 	}
-	if schoolID != nil {
-		newUser.SchoolID = *schoolID // Use string directly
+	if invitation != nil {
+		newUser.Role = invitation.Role
+		newUser.SchoolID = invitation.SchoolID
+		createdUser, err := u.adminRepo.AcceptInvitation(ctx, invitation.ID, newUser)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+		return createdUser, nil
 	}
-	
+
+	// メールドメインから所属校を決定し、学校管理者の承認待ちとして作成
+	school, err := u.userRepo.FindSchoolByEmailDomain(ctx, emailDomain(email))
+	if err != nil {
+		return nil, err
+	}
+	if school == nil {
+		return nil, ErrSignupNotAllowed
+	}
+
+	newUser.Role = policy.RoleStudent
+	newUser.SchoolID = school.ID
+	newUser.IsApproved = false
+
 	createdUser, err := u.userRepo.CreateUser(ctx, newUser)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
-	
+
 	return createdUser, nil
 }
 
+// emailDomain メールアドレスのドメイン部分を取得
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
 // GetUserByFirebaseUID Firebase UIDでユーザーを取得
 func (u *AuthUsecase) GetUserByFirebaseUID(ctx context.Context, firebaseUID string) (*entities.User, error) {
 	user, err := u.userRepo.GetUserByFirebaseUID(ctx, firebaseUID)
@@ -236,6 +247,26 @@ func (u *AuthUsecase) GetUserByFirebaseUID(ctx context.Context, firebaseUID stri
 	return user, nil
 }
 
+// GetAccountByFirebaseUID 認証用にロール・所属校・アカウント状態を含むユーザー情報を取得
+func (u *AuthUsecase) GetAccountByFirebaseUID(ctx context.Context, firebaseUID string) (*entities.UserManagement, error) {
+	account, err := u.adminRepo.GetUserByFirebaseUID(ctx, firebaseUID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+
+	return account, nil
+}
+
+// GetAccountByEmail メールアドレスから認証用のユーザー情報を取得
+func (u *AuthUsecase) GetAccountByEmail(ctx context.Context, email string) (*entities.UserManagement, error) {
+	user, err := u.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+
+	return u.GetAccountByFirebaseUID(ctx, user.FirebaseUID)
+}
+
 // generateSecureToken セキュアなトークンを生成
 func generateSecureToken(length int) (string, error) {
 	bytes := make([]byte, length)
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+)
+
+// SetDistrictRepository allows injecting DistrictRepository
+func (u *AdminUsecase) SetDistrictRepository(repo repositories.DistrictRepository) {
+	u.districtRepo = repo
+}
+
+// AssignDistrictAdmin ユーザーを教育委員会管理者にする（所属校はそのまま残す）
+// 管轄の変更はロールの変更と同時に行う必要があるため、UpdateUserRoleでは付与できない
+func (u *AdminUsecase) AssignDistrictAdmin(ctx context.Context, districtID, userID string, requesterRole string) (*entities.UserManagement, error) {
+	if err := policy.Require(requesterRole, policy.DistrictsManage); err != nil {
+		return nil, err
+	}
+	if u.districtRepo == nil {
+		return nil, fmt.Errorf("district repository not configured")
+	}
+
+	district, err := u.districtRepo.GetDistrictByID(ctx, districtID)
+	if err != nil {
+		return nil, err
+	}
+	if district == nil {
+		return nil, ErrDistrictNotFound
+	}
+
+	targetUser, err := u.adminRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target user: %w", err)
+	}
+	if targetUser.Role == policy.RoleAdmin {
+		return nil, fmt.Errorf("%w: cannot change the role of admin users", ErrInvalidDistrict)
+	}
+
+	assigned, err := u.districtRepo.AssignAdmin(ctx, districtID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !assigned {
+		return nil, fmt.Errorf("%w: user %s not found", ErrInvalidDistrict, userID)
+	}
+
+	updatedUser, err := u.adminRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload updated user: %w", err)
+	}
+
+	if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionDistrictAdmin,
+		TargetType: audit.TargetUser,
+		TargetID:   targetUser.ID,
+		SchoolID:   stringValue(targetUser.SchoolID),
+		Before:     map[string]interface{}{"role": targetUser.Role},
+		After:      map[string]interface{}{"role": updatedUser.Role, "district_id": districtID},
+	}); err != nil {
+		return nil, err
+	}
+
+	u.syncUserClaims(ctx, updatedUser.FirebaseUID, updatedUser.Role, stringValue(updatedUser.SchoolID))
+	return updatedUser, nil
+}
@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+)
+
+var (
+	// ErrInvalidDistrict 教育委員会の入力内容や割り当て先が正しくない
+	ErrInvalidDistrict = errors.New("invalid district")
+	// ErrDistrictNotFound 指定した教育委員会が存在しない
+	ErrDistrictNotFound = errors.New("district not found")
+)
+
+// DistrictUsecase 教育委員会（複数校）の管理と所属校をまとめた集計
+type DistrictUsecase struct {
+	districtRepo  repositories.DistrictRepository
+	schoolRepo    repositories.SchoolRepository
+	adminRepo     repositories.AdminRepository
+	auditRecorder AuditRecorder
+	statsCache    repositories.SchoolStatsCache
+}
+
+func NewDistrictUsecase(districtRepo repositories.DistrictRepository, schoolRepo repositories.SchoolRepository, adminRepo repositories.AdminRepository) *DistrictUsecase {
+	return &DistrictUsecase{
+		districtRepo: districtRepo,
+		schoolRepo:   schoolRepo,
+		adminRepo:    adminRepo,
+	}
+}
+
+// SetAuditRecorder allows injecting AuditRecorder
+func (u *DistrictUsecase) SetAuditRecorder(recorder AuditRecorder) {
+	u.auditRecorder = recorder
+}
+
+// SetSchoolStatsCache allows injecting SchoolStatsCache
+func (u *DistrictUsecase) SetSchoolStatsCache(cache repositories.SchoolStatsCache) {
+	u.statsCache = cache
+}
+
+// GetDistrictScope 教育委員会管理者が管轄する教育委員会と学校（ミドルウェアから参照）
+func (u *DistrictUsecase) GetDistrictScope(ctx context.Context, firebaseUID string) (*policy.DistrictScope, error) {
+	return u.districtRepo.GetScopeByFirebaseUID(ctx, firebaseUID)
+}
+
+// CreateDistrict 教育委員会を作成
+func (u *DistrictUsecase) CreateDistrict(ctx context.Context, district *entities.District, requesterRole string) (*entities.District, error) {
+	if err := policy.Require(requesterRole, policy.DistrictsManage); err != nil {
+		return nil, err
+	}
+
+	district.Name = strings.TrimSpace(district.Name)
+	district.Code = strings.TrimSpace(district.Code)
+	if district.Name == "" || district.Code == "" {
+		return nil, fmt.Errorf("%w: name and code are required", ErrInvalidDistrict)
+	}
+
+	created, err := u.districtRepo.CreateDistrict(ctx, district)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionDistrictCreate,
+		TargetType: audit.TargetDistrict,
+		TargetID:   created.ID,
+		After: map[string]interface{}{
+			"name":       created.Name,
+			"code":       created.Code,
+			"prefecture": stringValue(created.Prefecture),
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// ListDistricts 教育委員会の一覧（教育委員会管理者は管轄する教育委員会のみ）
+func (u *DistrictUsecase) ListDistricts(ctx context.Context, requesterRole, requesterSchoolID string) ([]*entities.District, error) {
+	if err := policy.Require(requesterRole, policy.DistrictsRead); err != nil {
+		return nil, err
+	}
+
+	districts, err := u.districtRepo.ListDistricts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if policy.ScopeOf(requesterRole, policy.DistrictsRead) == policy.ScopeGlobal {
+		return districts, nil
+	}
+
+	subject := subjectOf(ctx, requesterRole, requesterSchoolID)
+	visible := make([]*entities.District, 0, 1)
+	for _, district := range districts {
+		if policy.Can(subject, policy.DistrictsRead, policy.Resource{DistrictID: district.ID}) {
+			visible = append(visible, district)
+		}
+	}
+	return visible, nil
+}
+
+// GetDistrict 教育委員会の詳細
+func (u *DistrictUsecase) GetDistrict(ctx context.Context, districtID, requesterRole, requesterSchoolID string) (*entities.District, error) {
+	return u.authorizedDistrict(ctx, districtID, requesterRole, requesterSchoolID)
+}
+
+// ListDistrictSchools 教育委員会の所属校の一覧
+func (u *DistrictUsecase) ListDistrictSchools(ctx context.Context, districtID, requesterRole, requesterSchoolID string) ([]entities.SchoolOption, error) {
+	if _, err := u.authorizedDistrict(ctx, districtID, requesterRole, requesterSchoolID); err != nil {
+		return nil, err
+	}
+	return u.districtRepo.ListDistrictSchools(ctx, districtID)
+}
+
+// AssignSchool 学校を教育委員会に所属させる（他の教育委員会に所属している場合は移す）
+func (u *DistrictUsecase) AssignSchool(ctx context.Context, districtID, schoolID, requesterRole string) error {
+	return u.setSchoolDistrict(ctx, districtID, schoolID, true, requesterRole)
+}
+
+// RemoveSchool 学校を教育委員会の所属から外す
+func (u *DistrictUsecase) RemoveSchool(ctx context.Context, districtID, schoolID, requesterRole string) error {
+	return u.setSchoolDistrict(ctx, districtID, schoolID, false, requesterRole)
+}
+
+func (u *DistrictUsecase) setSchoolDistrict(ctx context.Context, districtID, schoolID string, assign bool, requesterRole string) error {
+	if err := policy.Require(requesterRole, policy.DistrictsManage); err != nil {
+		return err
+	}
+
+	district, err := u.districtRepo.GetDistrictByID(ctx, districtID)
+	if err != nil {
+		return err
+	}
+	if district == nil {
+		return ErrDistrictNotFound
+	}
+
+	id, err := strconv.ParseInt(schoolID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid school id %s", ErrInvalidDistrict, schoolID)
+	}
+	school, err := u.schoolRepo.GetSchoolByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%w: school %s not found", ErrInvalidDistrict, schoolID)
+	}
+	previous := stringValue(school.DistrictID)
+
+	action, next := audit.ActionDistrictAssign, districtID
+	if !assign {
+		if previous != districtID {
+			return fmt.Errorf("%w: school %s does not belong to district %s", ErrInvalidDistrict, schoolID, districtID)
+		}
+		action, next = audit.ActionDistrictUnassign, ""
+	}
+
+	updated, err := u.districtRepo.SetSchoolDistrict(ctx, schoolID, next)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return fmt.Errorf("%w: school %s not found", ErrInvalidDistrict, schoolID)
+	}
+
+	return recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     action,
+		TargetType: audit.TargetSchool,
+		TargetID:   schoolID,
+		SchoolID:   schoolID,
+		Before:     map[string]interface{}{"district_id": previous},
+		After:      map[string]interface{}{"district_id": next},
+	})
+}
+
+// GetDistrictStats 所属校ごとの統計と合計（集計期間は学校ごとのタイムゾーンで解釈する）
+func (u *DistrictUsecase) GetDistrictStats(ctx context.Context, districtID string, from, to *time.Time, requesterRole, requesterSchoolID string) (*entities.DistrictStats, error) {
+	if _, err := u.authorizedDistrict(ctx, districtID, requesterRole, requesterSchoolID); err != nil {
+		return nil, err
+	}
+	if err := policy.Require(requesterRole, policy.UsersStats); err != nil {
+		return nil, err
+	}
+
+	schools, err := u.districtRepo.ListDistrictSchools(ctx, districtID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entities.DistrictStats{
+		DistrictID: districtID,
+		Totals:     map[string]int{},
+		Schools:    make([]entities.DistrictSchoolStats, 0, len(schools)),
+	}
+	for _, school := range schools {
+		id, err := strconv.ParseInt(school.ID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse school id %s: %w", school.ID, err)
+		}
+		detail, err := u.schoolRepo.GetSchoolByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		period, err := schoolStatsPeriod(detail, from, to, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		schoolStats, err := loadSchoolStats(ctx, u.schoolRepo, u.statsCache, id, period)
+		if err != nil {
+			return nil, err
+		}
+		schoolID := school.ID
+		byRole, err := u.adminRepo.GetUserStatsByRole(ctx, &schoolID)
+		if err != nil {
+			return nil, err
+		}
+		// 学校数は学校ごとの値ではないため合計時に数え直す
+		delete(byRole, "total_schools")
+		delete(byRole, "active_schools")
+
+		for key, count := range byRole {
+			result.Totals[key] += count
+		}
+		result.Totals["active_users"] += schoolStats.Users.Active
+
+		result.Schools = append(result.Schools, entities.DistrictSchoolStats{
+			SchoolID:    school.ID,
+			SchoolName:  school.Name,
+			Stats:       schoolStats,
+			UsersByRole: byRole,
+		})
+	}
+	result.Totals["total_schools"] = len(schools)
+	return result, nil
+}
+
+// authorizedDistrict 権限を確認して教育委員会を取得
+func (u *DistrictUsecase) authorizedDistrict(ctx context.Context, districtID, requesterRole, requesterSchoolID string) (*entities.District, error) {
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.DistrictsRead, policy.Resource{DistrictID: districtID}); err != nil {
+		return nil, err
+	}
+
+	district, err := u.districtRepo.GetDistrictByID(ctx, districtID)
+	if err != nil {
+		return nil, err
+	}
+	if district == nil {
+		return nil, ErrDistrictNotFound
+	}
+	return district, nil
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/security"
+)
+
+// ErrInvalidExportRequest 対象データ・形式・列の指定が正しくない
+var ErrInvalidExportRequest = errors.New("invalid export request")
+
+// ExportRowWriter エクスポートの出力先
+type ExportRowWriter interface {
+	WriteRow(values []interface{}) error
+}
+
+// ExportUsecase ユーザー・クラス・学校データの一括エクスポート
+type ExportUsecase struct {
+	exportRepo    repositories.ExportRepository
+	cipher        *security.Cipher
+	auditRecorder AuditRecorder
+	config        *config.Config
+}
+
+func NewExportUsecase(exportRepo repositories.ExportRepository, cfg *config.Config) *ExportUsecase {
+	return &ExportUsecase{
+		exportRepo: exportRepo,
+		config:     cfg,
+	}
+}
+
+// SetCipher allows injecting Cipher
+func (u *ExportUsecase) SetCipher(cipher *security.Cipher) {
+	u.cipher = cipher
+}
+
+// SetAuditRecorder allows injecting AuditRecorder
+func (u *ExportUsecase) SetAuditRecorder(recorder AuditRecorder) {
+	u.auditRecorder = recorder
+}
+
+// PrepareExport 出力前に条件を検証し、既定の列と権限に応じた学校の絞り込みを補完して監査ログに記録する
+// 出力を開始してからはエラーを返せないため、権限・入力の誤りはここで検出する
+func (u *ExportUsecase) PrepareExport(ctx context.Context, req entities.ExportRequest, requesterRole string, requesterSchoolID string) (*entities.ExportRequest, error) {
+	available, ok := entities.ExportColumns[req.Dataset]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown dataset %q", ErrInvalidExportRequest, req.Dataset)
+	}
+	switch req.Format {
+	case entities.ExportFormatCSV, entities.ExportFormatXLSX, entities.ExportFormatJSON:
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidExportRequest, req.Format)
+	}
+
+	schoolID, err := scopedSchoolID(ctx, policy.DataExport, req.SchoolID, requesterRole, requesterSchoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := req.Columns
+	if len(columns) == 0 {
+		for _, column := range available {
+			if !entities.EncryptedExportColumns[column] {
+				columns = append(columns, column)
+			}
+		}
+	}
+
+	valid := map[string]bool{}
+	for _, column := range available {
+		valid[column] = true
+	}
+	seen := map[string]bool{}
+	encrypted := false
+	for _, column := range columns {
+		if !valid[column] {
+			return nil, fmt.Errorf("%w: unknown column %q for %s", ErrInvalidExportRequest, column, req.Dataset)
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidExportRequest, column)
+		}
+		seen[column] = true
+		encrypted = encrypted || entities.EncryptedExportColumns[column]
+	}
+
+	// 保護者情報は閲覧権限の範囲がエクスポート範囲を含む場合のみ復号する
+	if encrypted {
+		guardianSchoolID, err := scopedSchoolID(ctx, policy.GuardianContactsRead, schoolID, requesterRole, requesterSchoolID)
+		if err != nil {
+			return nil, err
+		}
+		if (guardianSchoolID == nil) != (schoolID == nil) || (schoolID != nil && *guardianSchoolID != *schoolID) {
+			return nil, fmt.Errorf("%w: %s", policy.ErrForbidden, policy.GuardianContactsRead)
+		}
+		if u.cipher == nil {
+			return nil, fmt.Errorf("guardian contact decryption is not configured")
+		}
+	}
+
+	entry := audit.Entry{
+		Action:     audit.ActionDataExport,
+		TargetType: audit.TargetDataset,
+		TargetID:   req.Dataset,
+		After: map[string]interface{}{
+			"format":  req.Format,
+			"columns": strings.Join(columns, ","),
+		},
+	}
+	if schoolID != nil {
+		entry.SchoolID = *schoolID
+	}
+	if err := recordAudit(ctx, u.auditRecorder, entry); err != nil {
+		return nil, err
+	}
+
+	return &entities.ExportRequest{
+		Dataset:  req.Dataset,
+		Format:   req.Format,
+		Columns:  columns,
+		SchoolID: schoolID,
+	}, nil
+}
+
+// Export PrepareExportで検証済みの条件でデータを1行ずつ書き出し、出力件数を返す
+func (u *ExportUsecase) Export(ctx context.Context, req *entities.ExportRequest, writer ExportRowWriter) (int, error) {
+	encrypted := make([]bool, len(req.Columns))
+	for i, column := range req.Columns {
+		encrypted[i] = entities.EncryptedExportColumns[column]
+	}
+
+	count := 0
+	err := u.exportRepo.StreamRows(ctx, req.Dataset, req.Columns, req.SchoolID, func(values []interface{}) error {
+		for i, value := range values {
+			if encrypted[i] && value != nil {
+				values[i] = u.decrypt(req.Columns[i], value)
+			}
+		}
+		count++
+		return writer.WriteRow(values)
+	})
+	return count, err
+}
+
+// decrypt 暗号化列を復号（復号できない値は空欄にして出力を続ける）
+func (u *ExportUsecase) decrypt(column string, value interface{}) interface{} {
+	var ciphertext string
+	switch v := value.(type) {
+	case []byte:
+		ciphertext = string(v)
+	case string:
+		ciphertext = v
+	default:
+		return nil
+	}
+	if ciphertext == "" {
+		return nil
+	}
+
+	plaintext, err := u.cipher.Decrypt(ciphertext)
+	if err != nil {
+		log.Printf("Failed to decrypt %s for export: %v", column, err)
+		return nil
+	}
+	return plaintext
+}
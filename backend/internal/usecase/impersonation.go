@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/middleware/auth"
+)
+
+// ErrImpersonationUnavailable 無効・未承認・削除済みのユーザーには代理ログインできない
+var ErrImpersonationUnavailable = errors.New("user account is inactive, not approved or deleted")
+
+// SetTokenIssuer allows injecting TokenIssuer
+func (u *AdminUsecase) SetTokenIssuer(issuer TokenIssuer) {
+	u.tokenIssuer = issuer
+}
+
+// Impersonate サポート対応のため、対象ユーザーとして操作できる短時間のトークンを発行
+// トークンには発行を受けた管理者のUIDを含め、代理ログイン中の操作は監査ログに管理者も記録される
+// トークンは対象ユーザーのセッションに紐づけ、強制ログアウトで有効期限前でも使えなくなる
+func (u *AdminUsecase) Impersonate(ctx context.Context, userID string, requesterUID string, requesterRole string, requesterSchoolID string) (*entities.ImpersonationToken, error) {
+	if u.tokenIssuer == nil || u.sessionRepo == nil {
+		return nil, fmt.Errorf("impersonation is not configured")
+	}
+
+	targetUser, err := u.adminRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target user: %w", err)
+	}
+
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersImpersonate, userResource(targetUser)); err != nil {
+		return nil, err
+	}
+	// 管理者の権限を他の管理者の名義で使えないようにする（学校管理者を含む）
+	if policy.IsAdminRole(targetUser.Role) {
+		return nil, fmt.Errorf("%w: cannot impersonate admin users", policy.ErrForbidden)
+	}
+	if !targetUser.IsActive || !targetUser.IsApproved || targetUser.DeletedAt != nil {
+		return nil, ErrImpersonationUnavailable
+	}
+
+	identity := auth.VerifiedToken{
+		UID:             targetUser.FirebaseUID,
+		Email:           targetUser.Email,
+		Name:            targetUser.Name,
+		Role:            targetUser.Role,
+		ImpersonatorUID: requesterUID,
+	}
+	if targetUser.SchoolID != nil {
+		schoolID, err := strconv.ParseInt(*targetUser.SchoolID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid school ID: %s", *targetUser.SchoolID)
+		}
+		identity.SchoolID = schoolID
+	}
+
+	ttl := time.Duration(u.config.ImpersonationTTLMinutes) * time.Minute
+	session, err := u.startImpersonationSession(ctx, targetUser, ttl)
+	if err != nil {
+		return nil, err
+	}
+	identity.SessionID = session.ID
+
+	accessToken, expiresAt, err := u.tokenIssuer.IssueToken(identity, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionUserImpersonate,
+		TargetType: audit.TargetUser,
+		TargetID:   targetUser.ID,
+		SchoolID:   stringValue(targetUser.SchoolID),
+		After:      map[string]interface{}{"expires_at": expiresAt.UTC().Format(time.RFC3339)},
+	}); err != nil {
+		return nil, err
+	}
+
+	return &entities.ImpersonationToken{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresAt:   expiresAt,
+		User:        targetUser,
+	}, nil
+}
+
+// startImpersonationSession 代理ログイン用トークンを紐づける対象ユーザーのセッションを作成
+// リフレッシュトークンは発行せず、トークンの有効期限を過ぎて延長できないようにする
+func (u *AdminUsecase) startImpersonationSession(ctx context.Context, targetUser *entities.UserManagement, ttl time.Duration) (*entities.Session, error) {
+	sessionID, err := generateSecureToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session id: %w", err)
+	}
+
+	now := time.Now()
+	session := &entities.Session{
+		ID:          sessionID,
+		UserID:      targetUser.ID,
+		FirebaseUID: targetUser.FirebaseUID,
+		SchoolID:    stringValue(targetUser.SchoolID),
+		DeviceName:  "代理ログイン",
+		AuthMethod:  entities.SessionAuthImpersonation,
+		CreatedAt:   now,
+		LastSeenAt:  now,
+		ExpiresAt:   now.Add(ttl),
+	}
+	if err := u.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
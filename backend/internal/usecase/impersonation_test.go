@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/middleware/auth"
+)
+
+// stubSessionRepository セッションの作成と削除の呼び出しのみを扱うSessionRepository
+type stubSessionRepository struct {
+	repositories.SessionRepository
+	sessions map[string]*entities.Session
+}
+
+func (r *stubSessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *stubSessionRepository) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	count := 0
+	for id, session := range r.sessions {
+		if session.UserID == userID {
+			delete(r.sessions, id)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// stubTokenIssuer 発行を依頼されたトークンの内容を記録するTokenIssuer
+type stubTokenIssuer struct {
+	identity auth.VerifiedToken
+}
+
+func (i *stubTokenIssuer) IssueToken(identity auth.VerifiedToken, ttl time.Duration) (string, time.Time, error) {
+	i.identity = identity
+	return "token", time.Now().Add(ttl), nil
+}
+
+func TestImpersonateBindsTokenToSession(t *testing.T) {
+	target := schoolUser("2", policy.RoleTeacher, "1")
+	target.FirebaseUID = "teacher-uid"
+	sessions := &stubSessionRepository{sessions: map[string]*entities.Session{}}
+	issuer := &stubTokenIssuer{}
+
+	u := NewAdminUsecase(&stubAdminRepository{user: target}, nil, &config.Config{ImpersonationTTLMinutes: 15})
+	u.SetSessionRepository(sessions)
+	u.SetTokenIssuer(issuer)
+
+	if _, err := u.Impersonate(context.Background(), "2", "admin-uid", policy.RoleAdmin, ""); err != nil {
+		t.Fatalf("Impersonate() error = %v", err)
+	}
+
+	session, ok := sessions.sessions[issuer.identity.SessionID]
+	if !ok {
+		t.Fatalf("Impersonate() issued a token with sid %q that has no session", issuer.identity.SessionID)
+	}
+	if session.UserID != "2" || session.FirebaseUID != "teacher-uid" || session.AuthMethod != entities.SessionAuthImpersonation {
+		t.Errorf("Impersonate() created session %+v, want an impersonation session of the target user", session)
+	}
+	if issuer.identity.ImpersonatorUID != "admin-uid" {
+		t.Errorf("Impersonate() ImpersonatorUID = %q, want admin-uid", issuer.identity.ImpersonatorUID)
+	}
+
+	if _, err := u.ForceLogoutUser(context.Background(), "2", policy.RoleAdmin, ""); err != nil {
+		t.Fatalf("ForceLogoutUser() error = %v", err)
+	}
+	if _, ok := sessions.sessions[issuer.identity.SessionID]; ok {
+		t.Error("ForceLogoutUser() did not revoke the impersonation session")
+	}
+}
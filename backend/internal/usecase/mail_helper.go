@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strconv"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/mail"
+)
+
+// sendTemplateMail テンプレートメールを送信
+// メール送信の失敗で呼び出し元の処理を失敗させないよう、エラーはログ出力のみ行う
+// 送信はテナントトランザクションのコミット後に行う（ロールバックした招待・再設定トークン・審査結果を通知しない）
+func sendTemplateMail(ctx context.Context, mailer mail.Mailer, locale string, tmpl mail.Template, to string, data interface{}) {
+	if mailer == nil {
+		return
+	}
+
+	msg, err := mail.Render(tmpl, locale, to, data)
+	if err != nil {
+		log.Printf("Failed to render %s mail: %v", tmpl, err)
+		return
+	}
+
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		if err := mailer.Send(ctx, msg); err != nil {
+			log.Printf("Failed to send %s mail to %q: %v", tmpl, to, err)
+		}
+	})
+}
+
+// newInvitationMailData 招待メールのテンプレートデータを生成
+func newInvitationMailData(ctx context.Context, schoolRepo repositories.SchoolRepository, cfg *config.Config, invitation *entities.UserInvitation) mail.InvitationData {
+	data := mail.InvitationData{
+		Name:      invitation.Name,
+		Role:      invitation.Role,
+		Message:   invitation.Message,
+		URL:       cfg.FrontendURL + "/api/auth/register?token=" + url.QueryEscape(invitation.Token),
+		ExpiresAt: invitation.ExpiresAt,
+	}
+
+	// 学校名は取得できた場合のみ差し込む
+	if schoolRepo != nil {
+		if schoolID, err := strconv.ParseInt(invitation.SchoolID, 10, 64); err == nil {
+			if school, err := schoolRepo.GetSchoolByID(ctx, schoolID); err == nil {
+				data.SchoolName = school.SchoolName
+			}
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,388 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/security"
+)
+
+const (
+	// mfaIssuer 認証アプリに表示するサービス名
+	mfaIssuer = "Bloomia"
+	// recoveryCodeCount 発行するリカバリーコードの数
+	recoveryCodeCount = 10
+	// recoveryCodeBytes リカバリーコード1つあたりのランダムバイト数（16進数で10文字）
+	recoveryCodeBytes = 5
+	// mfaAttemptKeyPrefix コード入力の失敗回数を数えるキー（ログイン失敗と同じ上限でロック）
+	mfaAttemptKeyPrefix = "mfa:"
+)
+
+var (
+	// ErrMFANotEnrolled 二要素認証が未登録
+	ErrMFANotEnrolled = errors.New("two-factor authentication is not enrolled")
+	// ErrMFAAlreadyEnabled 二要素認証は登録済み（再登録には管理者によるリセットが必要）
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrInvalidMFACode 認証コードまたはリカバリーコードが正しくない
+	ErrInvalidMFACode = errors.New("invalid authentication code")
+	// ErrMFAEnrollmentRequired 管理者ロールは二要素認証の登録が必要
+	ErrMFAEnrollmentRequired = errors.New("two-factor authentication must be enabled for this account")
+	// ErrStepUpRequired 重要な操作の前に二要素認証での本人確認が必要
+	ErrStepUpRequired = errors.New("two-factor verification is required for this operation")
+	// ErrMFASessionRequired 本人確認の記録にはログインセッション（X-Session-ID）が必要
+	ErrMFASessionRequired = errors.New("a login session is required for two-factor verification")
+)
+
+// MFAUsecase 管理者アカウントの二要素認証（TOTP）と重要操作前の本人確認
+type MFAUsecase struct {
+	mfaRepo        repositories.MFARepository
+	adminRepo      repositories.AdminRepository
+	loginAttempts  repositories.LoginAttemptRepository
+	sessionUsecase *SessionUsecase
+	cipher         *security.Cipher
+	config         *config.Config
+}
+
+func NewMFAUsecase(mfaRepo repositories.MFARepository, adminRepo repositories.AdminRepository, sessionUsecase *SessionUsecase, cfg *config.Config) *MFAUsecase {
+	return &MFAUsecase{
+		mfaRepo:        mfaRepo,
+		adminRepo:      adminRepo,
+		sessionUsecase: sessionUsecase,
+		config:         cfg,
+	}
+}
+
+// SetCipher allows injecting Cipher
+func (u *MFAUsecase) SetCipher(cipher *security.Cipher) {
+	u.cipher = cipher
+}
+
+// SetLoginAttemptRepository allows injecting LoginAttemptRepository
+func (u *MFAUsecase) SetLoginAttemptRepository(repo repositories.LoginAttemptRepository) {
+	u.loginAttempts = repo
+}
+
+// Enabled 暗号化キーが設定され、二要素認証を利用できるかどうか
+func (u *MFAUsecase) Enabled() bool {
+	return u.cipher != nil
+}
+
+// Status 二要素認証の登録状況を取得
+func (u *MFAUsecase) Status(ctx context.Context, userID, role string) (*entities.MFAStatus, error) {
+	status := &entities.MFAStatus{Required: requiresMFA(role)}
+
+	settings, err := u.mfaRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil || settings.ConfirmedAt == nil {
+		return status, nil
+	}
+
+	remaining, err := u.mfaRepo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status.Enabled = true
+	status.ConfirmedAt = settings.ConfirmedAt
+	status.RecoveryCodesRemaining = remaining
+	return status, nil
+}
+
+// Enroll 新しいシークレットを発行（Confirmで最初のコードを確認するまで有効にならない）
+func (u *MFAUsecase) Enroll(ctx context.Context, userID, accountName string) (*entities.MFAEnrollment, error) {
+	if !u.Enabled() {
+		return nil, fmt.Errorf("two-factor authentication is not configured")
+	}
+
+	settings, err := u.mfaRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil && settings.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := u.cipher.Encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.mfaRepo.SaveSecret(ctx, userID, encrypted); err != nil {
+		return nil, err
+	}
+
+	return &entities.MFAEnrollment{
+		Secret: secret,
+		URI:    security.TOTPURI(mfaIssuer, accountName, secret),
+	}, nil
+}
+
+// Confirm 認証アプリのコードで登録を確定し、リカバリーコードを発行（平文を返すのはこの時のみ）
+func (u *MFAUsecase) Confirm(ctx context.Context, userID, sessionID, code string) ([]string, error) {
+	settings, err := u.mfaRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if settings.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if err := u.checkAttempts(ctx, userID); err != nil {
+		return nil, err
+	}
+	step, ok, err := u.validateTOTP(settings, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		u.recordFailure(ctx, userID)
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.mfaRepo.Confirm(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+	u.resetFailures(ctx, userID)
+
+	// 登録直後の操作で再入力を求めないよう、セッションを本人確認済みにする
+	if sessionID != "" {
+		if err := u.sessionUsecase.MarkMFAVerified(ctx, sessionID, userID); err != nil {
+			log.Printf("Failed to mark session %s as verified: %v", sessionID, err)
+		}
+	}
+
+	return codes, nil
+}
+
+// Verify 認証コードまたはリカバリーコードで本人確認し、セッションに記録（ステップアップ認証）
+func (u *MFAUsecase) Verify(ctx context.Context, userID, sessionID, code string) error {
+	if sessionID == "" {
+		return ErrMFASessionRequired
+	}
+
+	settings, err := u.mfaRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if settings == nil || settings.ConfirmedAt == nil {
+		return ErrMFANotEnrolled
+	}
+
+	if err := u.checkAttempts(ctx, userID); err != nil {
+		return err
+	}
+	ok, err := u.verifyCode(ctx, settings, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		u.recordFailure(ctx, userID)
+		return ErrInvalidMFACode
+	}
+	u.resetFailures(ctx, userID)
+
+	return u.sessionUsecase.MarkMFAVerified(ctx, sessionID, userID)
+}
+
+// RegenerateRecoveryCodes リカバリーコードを再発行（既存のコードは無効になる）
+func (u *MFAUsecase) RegenerateRecoveryCodes(ctx context.Context, userID, sessionID string) ([]string, error) {
+	settings, err := u.mfaRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil || settings.ConfirmedAt == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if err := u.requireRecentVerification(ctx, userID, sessionID); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RequireStepUp 管理者ロールの場合、直近MFA_STEP_UP_MINUTES分以内にこのセッションで本人確認済みか確認
+func (u *MFAUsecase) RequireStepUp(ctx context.Context, userID, role, sessionID string) error {
+	if !u.Enabled() || !requiresMFA(role) {
+		return nil
+	}
+
+	settings, err := u.mfaRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if settings == nil || settings.ConfirmedAt == nil {
+		return ErrMFAEnrollmentRequired
+	}
+
+	return u.requireRecentVerification(ctx, userID, sessionID)
+}
+
+// ResetUserMFA 管理者用：認証アプリを紛失したユーザーの二要素認証を解除（再登録が必要になる）
+func (u *MFAUsecase) ResetUserMFA(ctx context.Context, userID string, requesterRole string, requesterSchoolID string) error {
+	targetUser, err := u.adminRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get target user: %w", err)
+	}
+
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersResetMFA, userResource(targetUser)); err != nil {
+		return err
+	}
+	if !policy.CanAssignRole(requesterRole, targetUser.Role) {
+		return fmt.Errorf("%w: cannot modify %s users", policy.ErrForbidden, targetUser.Role)
+	}
+
+	if err := u.mfaRepo.Delete(ctx, targetUser.ID); err != nil {
+		return err
+	}
+	u.resetFailures(ctx, targetUser.ID)
+	return nil
+}
+
+// requireRecentVerification セッションでの本人確認がステップアップの有効期間内か確認
+func (u *MFAUsecase) requireRecentVerification(ctx context.Context, userID, sessionID string) error {
+	if sessionID == "" {
+		return ErrStepUpRequired
+	}
+
+	verifiedAt, err := u.sessionUsecase.MFAVerifiedAt(ctx, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	window := time.Duration(u.config.MFAStepUpMinutes) * time.Minute
+	if verifiedAt == nil || time.Since(*verifiedAt) > window {
+		return ErrStepUpRequired
+	}
+	return nil
+}
+
+// verifyCode 6桁の数字はTOTP、それ以外はリカバリーコードとして検証
+func (u *MFAUsecase) verifyCode(ctx context.Context, settings *entities.MFASettings, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		step, ok, err := u.validateTOTP(settings, code)
+		if err != nil || !ok {
+			return false, err
+		}
+		// 同じコード（またはそれ以前のコード）の再利用を拒否
+		return u.mfaRepo.UseStep(ctx, settings.UserID, step)
+	}
+
+	return u.mfaRepo.ConsumeRecoveryCode(ctx, settings.UserID, hashToken(normalizeRecoveryCode(code)))
+}
+
+// validateTOTP 暗号化されたシークレットを復号してTOTPを検証
+func (u *MFAUsecase) validateTOTP(settings *entities.MFASettings, code string) (int64, bool, error) {
+	if !u.Enabled() {
+		return 0, false, fmt.Errorf("two-factor authentication is not configured")
+	}
+
+	secret, err := u.cipher.Decrypt(settings.SecretEncrypted)
+	if err != nil {
+		return 0, false, err
+	}
+
+	step, ok := security.ValidateTOTP(secret, strings.TrimSpace(code), time.Now())
+	return step, ok, nil
+}
+
+// checkAttempts コード入力の失敗が上限に達していないか確認
+func (u *MFAUsecase) checkAttempts(ctx context.Context, userID string) error {
+	if u.loginAttempts == nil {
+		return nil
+	}
+	failures, err := u.loginAttempts.GetFailures(ctx, mfaAttemptKeyPrefix+userID)
+	if err != nil {
+		return err
+	}
+	if failures >= maxLoginFailures {
+		return ErrAccountLocked
+	}
+	return nil
+}
+
+// recordFailure コード入力の失敗を記録
+func (u *MFAUsecase) recordFailure(ctx context.Context, userID string) {
+	if u.loginAttempts == nil {
+		return
+	}
+	if _, err := u.loginAttempts.RecordFailure(ctx, mfaAttemptKeyPrefix+userID, loginLockoutWindow); err != nil {
+		log.Printf("Failed to record mfa failure: %v", err)
+	}
+}
+
+// resetFailures コード入力の失敗回数をリセット
+func (u *MFAUsecase) resetFailures(ctx context.Context, userID string) {
+	if u.loginAttempts == nil {
+		return
+	}
+	if err := u.loginAttempts.ResetFailures(ctx, mfaAttemptKeyPrefix+userID); err != nil {
+		log.Printf("Failed to reset mfa failures: %v", err)
+	}
+}
+
+// requiresMFA 二要素認証が必須のロールかどうか
+func requiresMFA(role string) bool {
+	return policy.IsAdminRole(role)
+}
+
+// generateRecoveryCodes リカバリーコードと保存用のハッシュを生成
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := generateSecureToken(recoveryCodeBytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		// 読みやすいよう5文字ずつ区切る（検証時は区切りを無視する）
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode 区切り文字・空白・大文字小文字の違いを吸収
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// isTOTPCode 6桁の数字かどうか
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
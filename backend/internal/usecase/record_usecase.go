@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+)
+
+// defaultNotificationLimit 通知の取得件数
+const defaultNotificationLimit = 50
+
+// RecordUsecase 成績・出席など生徒記録の閲覧と保護者の紐付け
+// 閲覧範囲（担当・担任クラス、保護者の子など）はリポジトリのクエリで絞り込む
+type RecordUsecase struct {
+	recordRepo repositories.StudentRecordRepository
+	adminRepo  repositories.AdminRepository
+	config     *config.Config
+}
+
+func NewRecordUsecase(recordRepo repositories.StudentRecordRepository, adminRepo repositories.AdminRepository, cfg *config.Config) *RecordUsecase {
+	return &RecordUsecase{
+		recordRepo: recordRepo,
+		adminRepo:  adminRepo,
+		config:     cfg,
+	}
+}
+
+// GetStudentGrades 生徒の成績を取得
+func (u *RecordUsecase) GetStudentGrades(ctx context.Context, studentID string, requester policy.Subject) ([]entities.Grade, error) {
+	if err := policy.Require(requester.Role, policy.GradesRead); err != nil {
+		return nil, err
+	}
+
+	return u.recordRepo.ListGrades(ctx, requester, studentID)
+}
+
+// GetStudentAttendance 生徒の出席記録を取得
+func (u *RecordUsecase) GetStudentAttendance(ctx context.Context, studentID string, from, to *time.Time, requester policy.Subject) ([]entities.AttendanceRecord, error) {
+	if err := policy.Require(requester.Role, policy.AttendanceRead); err != nil {
+		return nil, err
+	}
+
+	return u.recordRepo.ListAttendance(ctx, requester, studentID, from, to)
+}
+
+// GetNotifications 自分（保護者の場合は子）の成績・出欠の通知を取得
+func (u *RecordUsecase) GetNotifications(ctx context.Context, requester policy.Subject) ([]entities.Notification, error) {
+	if err := policy.Require(requester.Role, policy.NotificationsRead); err != nil {
+		return nil, err
+	}
+
+	return u.recordRepo.ListNotifications(ctx, requester, defaultNotificationLimit)
+}
+
+// ListMyStudents 保護者に紐付けられた生徒一覧
+func (u *RecordUsecase) ListMyStudents(ctx context.Context, requester policy.Subject) ([]entities.GuardianStudent, error) {
+	if requester.Role != policy.RoleGuardian {
+		return nil, fmt.Errorf("%w: only guardians have linked students", policy.ErrForbidden)
+	}
+
+	return u.recordRepo.ListGuardianStudents(ctx, requester.UserID)
+}
+
+// ListGuardianStudents 管理者用：保護者に紐付けられた生徒一覧
+func (u *RecordUsecase) ListGuardianStudents(ctx context.Context, guardianID string, requesterRole string, requesterSchoolID string) ([]entities.GuardianStudent, error) {
+	guardian, err := u.adminRepo.GetUserByID(ctx, guardianID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guardian: %w", err)
+	}
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersRead, userResource(guardian)); err != nil {
+		return nil, err
+	}
+
+	return u.recordRepo.ListGuardianStudents(ctx, guardianID)
+}
+
+// LinkGuardianStudent 保護者と生徒を紐付け
+func (u *RecordUsecase) LinkGuardianStudent(ctx context.Context, guardianID, studentID string, relationship *string, requesterRole string, requesterSchoolID string) error {
+	if err := u.checkGuardianLink(ctx, guardianID, studentID, requesterRole, requesterSchoolID); err != nil {
+		return err
+	}
+
+	return u.recordRepo.LinkGuardianStudent(ctx, guardianID, studentID, relationship)
+}
+
+// UnlinkGuardianStudent 保護者と生徒の紐付けを解除
+func (u *RecordUsecase) UnlinkGuardianStudent(ctx context.Context, guardianID, studentID string, requesterRole string, requesterSchoolID string) error {
+	if err := u.checkGuardianLink(ctx, guardianID, studentID, requesterRole, requesterSchoolID); err != nil {
+		return err
+	}
+
+	return u.recordRepo.UnlinkGuardianStudent(ctx, guardianID, studentID)
+}
+
+// checkGuardianLink 紐付けの対象ロールと操作権限を確認
+func (u *RecordUsecase) checkGuardianLink(ctx context.Context, guardianID, studentID string, requesterRole string, requesterSchoolID string) error {
+	guardian, err := u.adminRepo.GetUserByID(ctx, guardianID)
+	if err != nil {
+		return fmt.Errorf("failed to get guardian: %w", err)
+	}
+	student, err := u.adminRepo.GetUserByID(ctx, studentID)
+	if err != nil {
+		return fmt.Errorf("failed to get student: %w", err)
+	}
+
+	if guardian.Role != policy.RoleGuardian {
+		return fmt.Errorf("user %s is not a guardian", guardianID)
+	}
+	if student.Role != policy.RoleStudent {
+		return fmt.Errorf("user %s is not a student", studentID)
+	}
+
+	subject := subjectOf(ctx, requesterRole, requesterSchoolID)
+	if err := policy.Authorize(subject, policy.UsersUpdate, userResource(guardian)); err != nil {
+		return err
+	}
+	if err := policy.Authorize(subject, policy.UsersUpdate, userResource(student)); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/domain/schoolcode"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+)
+
+var (
+	// ErrInvalidSchoolCode 学校コードの形式や都道府県が正しくない
+	ErrInvalidSchoolCode = errors.New("invalid school code")
+	// ErrSchoolCodeTaken 学校コードが他校で使用されている（変更前のコードを含む）
+	ErrSchoolCodeTaken = errors.New("school code is already in use")
+)
+
+// SchoolCodeFormat 設定の学校コードの形式（未設定の場合は既定の形式）
+func SchoolCodeFormat(cfg *config.Config) (schoolcode.Format, error) {
+	template := schoolcode.DefaultFormat
+	if cfg != nil && cfg.SchoolCodeFormat != "" {
+		template = cfg.SchoolCodeFormat
+	}
+	return schoolcode.ParseFormat(template)
+}
+
+// createSchool 指定されたコード（SchoolID）で学校を作成し、未指定の場合は都道府県ごとの連番で採番する
+func createSchool(ctx context.Context, schoolRepo repositories.SchoolRepository, cfg *config.Config, school *entities.School) (*entities.School, error) {
+	if school.SchoolID != "" {
+		code := schoolcode.Normalize(school.SchoolID)
+		if err := schoolcode.Validate(code); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSchoolCode, err)
+		}
+		school.SchoolID = code
+
+		created, err := schoolRepo.CreateSchool(ctx, school)
+		if err != nil {
+			return nil, err
+		}
+		if created == nil {
+			return nil, fmt.Errorf("%w: %s", ErrSchoolCodeTaken, code)
+		}
+		return created, nil
+	}
+
+	format, err := SchoolCodeFormat(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load school code format: %w", err)
+	}
+	prefecture, ok := schoolcode.PrefectureCode(stringValue(school.Prefecture))
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown prefecture %q", ErrInvalidSchoolCode, stringValue(school.Prefecture))
+	}
+	return schoolRepo.CreateSchoolWithGeneratedCode(ctx, school, format, prefecture)
+}
+
+// ValidateSchoolCode 入力中の学校コードを検証（schoolIDを指定した場合はその学校の現在・変更前のコードを使用可能とする）
+func (u *SchoolUsecase) ValidateSchoolCode(ctx context.Context, code, schoolID string) (*entities.SchoolCodeCheck, error) {
+	check := &entities.SchoolCodeCheck{Code: schoolcode.Normalize(code)}
+	if err := schoolcode.Validate(check.Code); err != nil {
+		check.Reason = err.Error()
+		return check, nil
+	}
+	check.Valid = true
+
+	owner, err := u.schoolRepo.SchoolCodeOwner(ctx, check.Code)
+	if err != nil {
+		return nil, err
+	}
+	if owner != "" && owner != schoolID {
+		check.Reason = "code is already in use"
+		return check, nil
+	}
+	check.Available = true
+	return check, nil
+}
+
+// ChangeSchoolCode 学校コードを変更（変更前のコードは別名として残り、引き続きこの学校として解決する）
+func (u *SchoolUsecase) ChangeSchoolCode(ctx context.Context, schoolID int64, code string) (*entities.School, error) {
+	code = schoolcode.Normalize(code)
+	if err := schoolcode.Validate(code); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchoolCode, err)
+	}
+
+	before, err := u.schoolRepo.GetSchoolByID(ctx, schoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := u.schoolRepo.ChangeSchoolCode(ctx, schoolID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, fmt.Errorf("%w: %s", ErrSchoolCodeTaken, code)
+	}
+
+	updated, err := u.schoolRepo.GetSchoolByID(ctx, schoolID)
+	if err != nil {
+		return nil, err
+	}
+	if updated.SchoolID == before.SchoolID {
+		return updated, nil
+	}
+
+	id := strconv.FormatInt(schoolID, 10)
+	if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionSchoolChangeCode,
+		TargetType: audit.TargetSchool,
+		TargetID:   id,
+		SchoolID:   id,
+		Before:     map[string]interface{}{"code": before.SchoolID},
+		After:      map[string]interface{}{"code": updated.SchoolID},
+	}); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ListSchoolCodeAliases 学校の変更前のコードの一覧（新しい順）
+func (u *SchoolUsecase) ListSchoolCodeAliases(ctx context.Context, schoolID int64) ([]entities.SchoolCodeAlias, error) {
+	return u.schoolRepo.ListSchoolCodeAliases(ctx, schoolID)
+}
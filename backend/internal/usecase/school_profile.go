@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	// 本番イメージ（scratch）にはタイムゾーンのデータがないため埋め込む
+	_ "time/tzdata"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/calendar"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+)
+
+var (
+	// ErrInvalidSchoolProfile 学校情報・設定の指定が正しくない
+	ErrInvalidSchoolProfile = errors.New("invalid school profile")
+
+	hexColorPattern    = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+	emailDomainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+	themeNamePattern   = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+	supportedLocales   = map[string]bool{"ja": true, "en": true}
+)
+
+// UpdateSchool 学校情報を部分更新（設定は指定したキーのみ現在の設定に上書きして検証する）
+func (u *SchoolUsecase) UpdateSchool(ctx context.Context, schoolID int64, update entities.SchoolUpdate) (*entities.School, error) {
+	current, err := u.schoolRepo.GetSchoolByID(ctx, schoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.validateSchoolUpdate(ctx, current, &update); err != nil {
+		return nil, err
+	}
+
+	updated, err := u.schoolRepo.UpdateSchool(ctx, schoolID, update)
+	if err != nil {
+		return nil, err
+	}
+
+	id := strconv.FormatInt(schoolID, 10)
+	if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionSchoolUpdate,
+		TargetType: audit.TargetSchool,
+		TargetID:   id,
+		SchoolID:   id,
+		Before:     schoolAuditFields(current),
+		After:      schoolAuditFields(updated),
+	}); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// validateSchoolUpdate 更新内容を検証し、前後の空白を除く
+// 設定はマージ後の全体を検証し、保存する形式に置き換える
+func (u *SchoolUsecase) validateSchoolUpdate(ctx context.Context, current *entities.School, update *entities.SchoolUpdate) error {
+	trimSpace(update.Address)
+	trimSpace(update.Phone)
+	trimSpace(update.PrincipalName)
+	trimSpace(update.VicePrincipalName)
+	trimSpace(update.LogoURL)
+
+	if update.SchoolName != nil {
+		trimSpace(update.SchoolName)
+		if *update.SchoolName == "" {
+			return fmt.Errorf("%w: school_name must not be empty", ErrInvalidSchoolProfile)
+		}
+	}
+
+	if update.EmailDomain != nil {
+		domain := strings.ToLower(strings.TrimSpace(*update.EmailDomain))
+		update.EmailDomain = &domain
+		if domain != "" {
+			if !emailDomainPattern.MatchString(domain) {
+				return fmt.Errorf("%w: email_domain must be a domain name such as example.ed.jp", ErrInvalidSchoolProfile)
+			}
+			ownerID, err := u.schoolRepo.FindSchoolIDByEmailDomain(ctx, domain)
+			if err != nil {
+				return err
+			}
+			if ownerID != "" && ownerID != current.ID {
+				return fmt.Errorf("%w: email_domain %s is already in use", ErrInvalidSchoolProfile, domain)
+			}
+		}
+	}
+
+	if update.ThemeColor != nil && !hexColorPattern.MatchString(*update.ThemeColor) {
+		return fmt.Errorf("%w: theme_color must be a color such as #FF7F50", ErrInvalidSchoolProfile)
+	}
+	if update.BackgroundColor != nil && !hexColorPattern.MatchString(*update.BackgroundColor) {
+		return fmt.Errorf("%w: background_color must be a color such as #fdf8f0", ErrInvalidSchoolProfile)
+	}
+
+	if update.LogoURL != nil && *update.LogoURL != "" {
+		parsed, err := url.Parse(*update.LogoURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%w: logo_url must be an http(s) URL", ErrInvalidSchoolProfile)
+		}
+	}
+
+	if update.StudentCapacity != nil && *update.StudentCapacity < 1 {
+		return fmt.Errorf("%w: student_capacity must be at least 1", ErrInvalidSchoolProfile)
+	}
+
+	yearStart := current.AcademicYearStart
+	if update.AcademicYearStart != nil {
+		yearStart = *update.AcademicYearStart
+		if yearStart < 1 || yearStart > 12 {
+			return fmt.Errorf("%w: academic_year_start must be a month between 1 and 12", ErrInvalidSchoolProfile)
+		}
+	}
+
+	// 年度の開始月を変えた場合も学期の並びを確認する
+	if update.Settings == nil && update.AcademicYearStart == nil {
+		return nil
+	}
+
+	settings, err := mergeSchoolSettings(current.Settings, update.Settings)
+	if err != nil {
+		return err
+	}
+	if err := validateSchoolSettings(settings, yearStart); err != nil {
+		return err
+	}
+
+	if update.Settings != nil {
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return fmt.Errorf("failed to encode school settings: %w", err)
+		}
+		update.Settings = data
+	}
+	return nil
+}
+
+// mergeSchoolSettings 現在の設定に指定されたキーを上書きする（未知のキーはエラー）
+func mergeSchoolSettings(current *entities.SchoolSettings, patch json.RawMessage) (*entities.SchoolSettings, error) {
+	merged := entities.SchoolSettings{Version: entities.SchoolSettingsVersion}
+	if current != nil {
+		merged = *current
+	}
+	if patch == nil {
+		return &merged, nil
+	}
+
+	// 学期は配列の要素ごとにマージされないよう、指定された場合は全体を置き換える
+	var terms struct {
+		Calendar *struct {
+			Terms json.RawMessage `json:"terms"`
+		} `json:"calendar"`
+	}
+	if err := json.Unmarshal(patch, &terms); err == nil && terms.Calendar != nil && terms.Calendar.Terms != nil {
+		merged.Calendar.Terms = nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patch))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&merged); err != nil {
+		return nil, fmt.Errorf("%w: settings: %v", ErrInvalidSchoolProfile, err)
+	}
+	if merged.Calendar.Terms == nil {
+		merged.Calendar.Terms = []entities.AcademicTerm{}
+	}
+	return &merged, nil
+}
+
+// validateSchoolSettings 設定の内容を検証（学期は年度の開始月から順に重ならないこと）
+func validateSchoolSettings(settings *entities.SchoolSettings, yearStart int) error {
+	if settings.Version != entities.SchoolSettingsVersion {
+		return fmt.Errorf("%w: settings version %d is not supported", ErrInvalidSchoolProfile, settings.Version)
+	}
+	if !themeNamePattern.MatchString(settings.Theme) {
+		return fmt.Errorf("%w: settings.theme must be a lowercase theme name", ErrInvalidSchoolProfile)
+	}
+	if !supportedLocales[settings.Locale] {
+		return fmt.Errorf("%w: settings.locale must be ja or en", ErrInvalidSchoolProfile)
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil || settings.Timezone == "" {
+		return fmt.Errorf("%w: settings.timezone %q is not a valid time zone", ErrInvalidSchoolProfile, settings.Timezone)
+	}
+
+	terms := settings.Calendar.Terms
+	if len(terms) > calendar.MaxTerms {
+		return fmt.Errorf("%w: settings.calendar.terms must have at most %d terms", ErrInvalidSchoolProfile, calendar.MaxTerms)
+	}
+
+	previousEnd := -1
+	for i, term := range terms {
+		if strings.TrimSpace(term.Name) == "" {
+			return fmt.Errorf("%w: settings.calendar.terms[%d].name is required", ErrInvalidSchoolProfile, i)
+		}
+		start, err := calendar.DayOfAcademicYear(term.Start, yearStart)
+		if err != nil {
+			return fmt.Errorf("%w: settings.calendar.terms[%d].start must be MM-DD", ErrInvalidSchoolProfile, i)
+		}
+		end, err := calendar.DayOfAcademicYear(term.End, yearStart)
+		if err != nil {
+			return fmt.Errorf("%w: settings.calendar.terms[%d].end must be MM-DD", ErrInvalidSchoolProfile, i)
+		}
+		if start > end || start <= previousEnd {
+			return fmt.Errorf("%w: settings.calendar.terms must be in order within the academic year without overlapping", ErrInvalidSchoolProfile)
+		}
+		previousEnd = end
+	}
+	return nil
+}
+
+// trimSpace 指定された文字列の前後の空白を除く
+func trimSpace(value *string) {
+	if value != nil {
+		*value = strings.TrimSpace(*value)
+	}
+}
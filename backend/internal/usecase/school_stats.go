@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+)
+
+const (
+	// schoolStatsCacheTTL 統計のキャッシュの有効期間（DBの変更通知を取りこぼした場合もこの期間で反映される）
+	schoolStatsCacheTTL = 5 * time.Minute
+	// defaultStatsDays 集計期間を指定しない場合の日数（今日を含む）
+	defaultStatsDays = 30
+	// maxStatsDays 指定できる集計期間の上限
+	maxStatsDays = 366
+
+	statsDateLayout = "2006-01-02"
+)
+
+// ErrInvalidStatsPeriod 統計の集計期間が正しくない
+var ErrInvalidStatsPeriod = errors.New("invalid stats period")
+
+// SetSchoolStatsCache allows injecting SchoolStatsCache
+func (u *SchoolUsecase) SetSchoolStatsCache(cache repositories.SchoolStatsCache) {
+	u.statsCache = cache
+}
+
+// GetSchoolStats 学校の統計レポート（from・toを省略した場合は今日までの30日間）
+func (u *SchoolUsecase) GetSchoolStats(ctx context.Context, schoolID int64, from, to *time.Time) (*entities.SchoolStats, error) {
+	school, err := u.schoolRepo.GetSchoolByID(ctx, schoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := schoolStatsPeriod(school, from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return loadSchoolStats(ctx, u.schoolRepo, u.statsCache, schoolID, period)
+}
+
+// schoolStatsPeriod 集計期間を学校のタイムゾーンの日付として解釈する
+func schoolStatsPeriod(school *entities.School, from, to *time.Time, now time.Time) (entities.SchoolStatsPeriod, error) {
+	location := time.UTC
+	if school.Settings != nil {
+		if loaded, err := time.LoadLocation(school.Settings.Timezone); err == nil {
+			location = loaded
+		}
+	}
+
+	local := now.In(location)
+	end := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	if to != nil {
+		end = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, location)
+	}
+	start := end.AddDate(0, 0, 1-defaultStatsDays)
+	if from != nil {
+		start = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+	}
+
+	if start.After(end) {
+		return entities.SchoolStatsPeriod{}, fmt.Errorf("%w: from must not be after to", ErrInvalidStatsPeriod)
+	}
+	if start.AddDate(0, 0, maxStatsDays).Before(end.AddDate(0, 0, 1)) {
+		return entities.SchoolStatsPeriod{}, fmt.Errorf("%w: period must be %d days or less", ErrInvalidStatsPeriod, maxStatsDays)
+	}
+
+	return entities.SchoolStatsPeriod{
+		From:  start.Format(statsDateLayout),
+		To:    end.Format(statsDateLayout),
+		Start: start,
+		End:   end.AddDate(0, 0, 1),
+	}, nil
+}
+
+// loadSchoolStats キャッシュがあれば返し、なければ集計してキャッシュする（キャッシュの障害では集計を止めない）
+func loadSchoolStats(ctx context.Context, schoolRepo repositories.SchoolRepository, cache repositories.SchoolStatsCache, schoolID int64, period entities.SchoolStatsPeriod) (*entities.SchoolStats, error) {
+	key := fmt.Sprintf("%d", schoolID)
+	if cache != nil {
+		cached, err := cache.Get(ctx, key, period.From, period.To)
+		if err != nil {
+			log.Printf("Failed to read stats cache for school %s: %v", key, err)
+		} else if cached != nil {
+			return cached, nil
+		}
+	}
+
+	stats, err := schoolRepo.GetSchoolStats(ctx, schoolID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache != nil {
+		if err := cache.Set(ctx, stats, schoolStatsCacheTTL); err != nil {
+			log.Printf("Failed to write stats cache for school %s: %v", key, err)
+		}
+	}
+	return stats, nil
+}
+
+// invalidateSchoolStats 学校の統計のキャッシュを削除（失敗してもTTLで反映されるため記録のみ）
+func invalidateSchoolStats(ctx context.Context, cache repositories.SchoolStatsCache, schoolID string) {
+	if cache == nil || schoolID == "" {
+		return
+	}
+	if err := cache.Invalidate(ctx, schoolID); err != nil {
+		log.Printf("Failed to invalidate stats cache for school %s: %v", schoolID, err)
+	}
+}
+
+// InvalidateSchoolStats 学校の統計のキャッシュを削除する（DBの変更通知から呼ぶ）
+func (u *SchoolUsecase) InvalidateSchoolStats(ctx context.Context, schoolID string) {
+	invalidateSchoolStats(ctx, u.statsCache, schoolID)
+}
+
+// statsInvalidatingRecorder 監査ログの記録とともに対象校の統計のキャッシュを無効化する
+// 学校のデータを変更する操作は監査ログを記録するため、書き込みごとの無効化をここにまとめる
+// 成績・出欠・提出物・ログインなど監査ログを残さない変更は、DBの変更通知（InvalidateSchoolStats）で無効化する
+type statsInvalidatingRecorder struct {
+	recorder AuditRecorder
+	cache    repositories.SchoolStatsCache
+}
+
+// NewStatsInvalidatingRecorder 書き込みを行うユースケースに渡すAuditRecorder
+func NewStatsInvalidatingRecorder(recorder AuditRecorder, cache repositories.SchoolStatsCache) AuditRecorder {
+	return &statsInvalidatingRecorder{recorder: recorder, cache: cache}
+}
+
+func (r *statsInvalidatingRecorder) Record(ctx context.Context, entry audit.Entry) error {
+	if err := r.recorder.Record(ctx, entry); err != nil {
+		return err
+	}
+	// コミット前に削除すると、コミットまでの間の集計で古い値が再びキャッシュされるため
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		invalidateSchoolStats(ctx, r.cache, entry.SchoolID)
+	})
+	return nil
+}
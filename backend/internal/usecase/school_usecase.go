@@ -3,15 +3,23 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
 	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
 	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
 	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/database"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type SchoolUsecase struct {
 	schoolRepo repositories.SchoolRepository
 	userRepo   repositories.UserRepository
+	auditRecorder AuditRecorder
+	statsCache repositories.SchoolStatsCache
 	config     *config.Config
 }
 
@@ -23,9 +31,27 @@ func NewSchoolUsecase(schoolRepo repositories.SchoolRepository, userRepo reposit
 	}
 }
 
+// SetAuditRecorder allows injecting AuditRecorder
+func (u *SchoolUsecase) SetAuditRecorder(recorder AuditRecorder) {
+	u.auditRecorder = recorder
+}
+
 func (u *SchoolUsecase) CreateSchool(ctx context.Context, school *entities.School) (*entities.School, error) {
-	// TODO: Implement school creation with current schema
-	return u.schoolRepo.CreateSchool(ctx, school)
+	created, err := createSchool(ctx, u.schoolRepo, u.config, school)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionSchoolCreate,
+		TargetType: audit.TargetSchool,
+		TargetID:   created.ID,
+		SchoolID:   created.ID,
+		After:      schoolAuditFields(created),
+	}); err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
 func (u *SchoolUsecase) GetSchoolByID(ctx context.Context, schoolID int64) (*entities.School, error) {
@@ -40,31 +66,92 @@ func (u *SchoolUsecase) GetAllSchools(ctx context.Context, filters map[string]in
 	return u.schoolRepo.GetAllSchools(ctx, filters)
 }
 
-func (u *SchoolUsecase) UpdateSchool(ctx context.Context, schoolID int64, updateData entities.School) (*entities.School, error) {
-	return u.schoolRepo.UpdateSchool(ctx, schoolID, updateData)
-}
-
 func (u *SchoolUsecase) DeleteSchool(ctx context.Context, schoolID int64) error {
-	return u.schoolRepo.DeleteSchool(ctx, schoolID)
+	// 削除前の内容を監査ログに残す
+	school, err := u.schoolRepo.GetSchoolByID(ctx, schoolID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.schoolRepo.DeleteSchool(ctx, schoolID); err != nil {
+		return err
+	}
+
+	id := strconv.FormatInt(schoolID, 10)
+	return recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionSchoolDelete,
+		TargetType: audit.TargetSchool,
+		TargetID:   id,
+		SchoolID:   id,
+		Before:     schoolAuditFields(school),
+	})
 }
 
-func (u *SchoolUsecase) GetSchoolStats(ctx context.Context, schoolID int64) (map[string]interface{}, error) {
-	return u.schoolRepo.GetSchoolStats(ctx, schoolID)
+// RestoreSchool 猶予期間内に削除された学校を復元
+func (u *SchoolUsecase) RestoreSchool(ctx context.Context, schoolID int64) error {
+	deletedAfter := time.Now().Add(-deletionGracePeriod(u.config.DeletionGraceDays))
+	restored, err := u.schoolRepo.RestoreSchool(ctx, schoolID, deletedAfter)
+	if err != nil {
+		return err
+	}
+	if !restored {
+		return ErrRestoreUnavailable
+	}
+
+	id := strconv.FormatInt(schoolID, 10)
+	return recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionSchoolRestore,
+		TargetType: audit.TargetSchool,
+		TargetID:   id,
+		SchoolID:   id,
+		Before:     map[string]interface{}{"deleted": true},
+		After:      map[string]interface{}{"deleted": false},
+	})
 }
 
 func (u *SchoolUsecase) GetSchoolUsers(ctx context.Context, schoolID int64, role string) ([]*entities.User, error) {
 	return u.schoolRepo.GetSchoolUsers(ctx, schoolID, role)
 }
 
-func (u *SchoolUsecase) ValidateSchoolCode(ctx context.Context, code string) (bool, error) {
-	school, err := u.schoolRepo.GetSchoolByCode(ctx, code)
+// CreateStudentForSchool Googleアカウントを持たない生徒のローカルアカウントを作成
+// 初期パスワードが未指定の場合は仮パスワードを発行し、初回ログイン時に変更を必須とする
+func (u *SchoolUsecase) CreateStudentForSchool(ctx context.Context, schoolID int64, student *entities.User, initialPassword string) (*entities.User, string, error) {
+	if initialPassword == "" {
+		generated, err := generateSecureToken(6)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate temporary password: %w", err)
+		}
+		initialPassword = generated
+	}
+	if err := validatePassword(initialPassword); err != nil {
+		return nil, "", err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(initialPassword), bcrypt.DefaultCost)
 	if err != nil {
-		return true, nil // School not found means code is available
+		return nil, "", fmt.Errorf("failed to hash password: %w", err)
 	}
-	return school == nil, nil
-}
 
-func (u *SchoolUsecase) CreateStudentForSchool(ctx context.Context, schoolID int64, student *entities.User) (*entities.User, error) {
-	// TODO: Implement with current schema - for now return not implemented
-	return nil, fmt.Errorf("student creation not implemented for current schema")
+	localID, err := generateSecureToken(12)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate uid: %w", err)
+	}
+
+	student.FirebaseUID = "local_" + localID
+	student.Role = policy.RoleStudent
+	student.SchoolID = strconv.FormatInt(schoolID, 10)
+	student.PasswordHash = string(passwordHash)
+	student.MustChangePassword = true
+	// 管理者が作成したアカウントは承認済みとする
+	student.IsApproved = true
+
+	created, err := u.userRepo.CreateUser(ctx, student)
+	if err != nil {
+		return nil, "", err
+	}
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		invalidateSchoolStats(ctx, u.statsCache, student.SchoolID)
+	})
+
+	return created, initialPassword, nil
 }
\ No newline at end of file
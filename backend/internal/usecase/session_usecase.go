@@ -0,0 +1,361 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/config"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/middleware/auth"
+)
+
+const (
+	// sessionTTL セッション（リフレッシュトークン）の有効期限。リフレッシュのたびに延長する
+	sessionTTL = 30 * 24 * time.Hour
+	// sessionTouchInterval 最終アクセス日時を更新する間隔（毎リクエストの書き込みを避ける）
+	sessionTouchInterval = time.Minute
+	// sessionAccessTokenTTL リフレッシュ時に発行するアクセストークンの有効期限
+	sessionAccessTokenTTL = time.Hour
+	// maxDeviceNameLength 端末名の最大長
+	maxDeviceNameLength = 100
+)
+
+// ErrSessionInvalid セッションが存在しない・失効済み・他ユーザーのもの
+var ErrSessionInvalid = errors.New("session is invalid or has been revoked")
+
+// TokenIssuer アクセストークンを発行する（AUTH_PROVIDER=local の場合のみ）
+type TokenIssuer interface {
+	IssueToken(identity auth.VerifiedToken, ttl time.Duration) (string, time.Time, error)
+}
+
+// SessionUsecase 端末ごとのログインセッションとリフレッシュトークンの管理
+type SessionUsecase struct {
+	sessionRepo repositories.SessionRepository
+	userRepo    repositories.UserRepository
+	adminRepo   repositories.AdminRepository
+	tokenIssuer TokenIssuer
+	config      *config.Config
+}
+
+func NewSessionUsecase(sessionRepo repositories.SessionRepository, userRepo repositories.UserRepository, adminRepo repositories.AdminRepository, cfg *config.Config) *SessionUsecase {
+	return &SessionUsecase{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		adminRepo:   adminRepo,
+		config:      cfg,
+	}
+}
+
+// SetTokenIssuer allows injecting TokenIssuer
+func (u *SessionUsecase) SetTokenIssuer(issuer TokenIssuer) {
+	u.tokenIssuer = issuer
+}
+
+// CreateSession ログイン時にセッションを作成し、最終ログイン日時を更新
+func (u *SessionUsecase) CreateSession(ctx context.Context, userID, firebaseUID, schoolID, authMethod string, client entities.SessionClient) (*entities.SessionTokens, error) {
+	sessionID, err := generateSecureToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session id: %w", err)
+	}
+	secret, err := generateSecureToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	deviceName := client.DeviceName
+	if deviceName == "" {
+		deviceName = client.UserAgent
+	}
+	if len(deviceName) > maxDeviceNameLength {
+		deviceName = deviceName[:maxDeviceNameLength]
+	}
+
+	now := time.Now()
+	session := &entities.Session{
+		ID:               sessionID,
+		UserID:           userID,
+		FirebaseUID:      firebaseUID,
+		SchoolID:         schoolID,
+		DeviceName:       deviceName,
+		IPAddress:        client.IPAddress,
+		UserAgent:        client.UserAgent,
+		AuthMethod:       authMethod,
+		RefreshTokenHash: u.hashRefreshToken(secret),
+		CreatedAt:        now,
+		LastSeenAt:       now,
+		ExpiresAt:        now.Add(sessionTTL),
+	}
+	if err := u.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.UpdateLastLogin(ctx, firebaseUID); err != nil {
+		log.Printf("Failed to update last login for uid %s: %v", firebaseUID, err)
+	}
+
+	session.Current = true
+	return &entities.SessionTokens{
+		Session:      session,
+		RefreshToken: formatRefreshToken(sessionID, secret),
+	}, nil
+}
+
+// StartPasswordSession パスワードログイン成功時にセッションとアクセストークンを発行
+func (u *SessionUsecase) StartPasswordSession(ctx context.Context, account *entities.UserManagement, client entities.SessionClient) (*entities.SessionTokens, error) {
+	if u.tokenIssuer == nil {
+		return nil, fmt.Errorf("password login is not configured")
+	}
+	return u.startIssuedSession(ctx, account, entities.SessionAuthPassword, client, sessionAccessTokenTTL)
+}
+
+// StartDevSession 開発用トークンのセッションを作成し、ttlのアクセストークンを発行（セッションの失効でトークンも無効になる）
+func (u *SessionUsecase) StartDevSession(ctx context.Context, account *entities.UserManagement, client entities.SessionClient, ttl time.Duration) (*entities.SessionTokens, error) {
+	if u.tokenIssuer == nil {
+		return nil, fmt.Errorf("dev token is not configured")
+	}
+	return u.startIssuedSession(ctx, account, entities.SessionAuthDev, client, ttl)
+}
+
+// startIssuedSession セッションを作成し、そのセッションに紐づくアクセストークンを発行
+func (u *SessionUsecase) startIssuedSession(ctx context.Context, account *entities.UserManagement, authMethod string, client entities.SessionClient, ttl time.Duration) (*entities.SessionTokens, error) {
+	schoolID := ""
+	if account.SchoolID != nil {
+		schoolID = *account.SchoolID
+	}
+
+	tokens, err := u.CreateSession(ctx, account.ID, account.FirebaseUID, schoolID, authMethod, client)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.issueAccessToken(account, tokens, ttl); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// ValidateSession リクエストのセッションが有効か確認し、最終アクセス日時とIPを更新
+func (u *SessionUsecase) ValidateSession(ctx context.Context, sessionID, userID, ipAddress string) error {
+	session, err := u.sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID {
+		return ErrSessionInvalid
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) < sessionTouchInterval && session.IPAddress == ipAddress {
+		return nil
+	}
+
+	session.LastSeenAt = now
+	session.IPAddress = ipAddress
+	updated, err := u.sessionRepo.Update(ctx, session)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrSessionInvalid
+	}
+	return nil
+}
+
+// CheckTokenSession アクセストークンのsidクレームのセッションが有効で、トークンのユーザーのものか確認
+func (u *SessionUsecase) CheckTokenSession(ctx context.Context, sessionID, firebaseUID string) error {
+	session, err := u.sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.FirebaseUID != firebaseUID {
+		return ErrSessionInvalid
+	}
+	return nil
+}
+
+// RefreshSession リフレッシュトークンをローテーションしてセッションを延長
+// 一度使用したリフレッシュトークンは無効になる
+func (u *SessionUsecase) RefreshSession(ctx context.Context, refreshToken, ipAddress string) (*entities.SessionTokens, error) {
+	sessionID, secret, ok := parseRefreshToken(refreshToken)
+	if !ok {
+		return nil, ErrSessionInvalid
+	}
+
+	session, err := u.sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || !hmac.Equal([]byte(session.RefreshTokenHash), []byte(u.hashRefreshToken(secret))) {
+		return nil, ErrSessionInvalid
+	}
+
+	// 無効化されたアカウントのセッションは更新しない
+	account, err := u.adminRepo.GetUserByFirebaseUID(ctx, session.FirebaseUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if !account.IsActive || !account.IsApproved {
+		if err := u.sessionRepo.Delete(ctx, session.ID); err != nil {
+			log.Printf("Failed to delete session of inactive user %s: %v", account.ID, err)
+		}
+		return nil, ErrSessionInvalid
+	}
+
+	newSecret, err := generateSecureToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	now := time.Now()
+	session.RefreshTokenHash = u.hashRefreshToken(newSecret)
+	session.LastSeenAt = now
+	session.IPAddress = ipAddress
+	session.ExpiresAt = now.Add(sessionTTL)
+	updated, err := u.sessionRepo.Update(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrSessionInvalid
+	}
+
+	session.Current = true
+	tokens := &entities.SessionTokens{
+		Session:      session,
+		RefreshToken: formatRefreshToken(session.ID, newSecret),
+	}
+
+	// 外部IdPのトークンを使うセッションはIdP側で更新するため、アクセストークンは発行しない
+	if u.tokenIssuer != nil && (session.AuthMethod == entities.SessionAuthPassword || u.config.AuthProvider == "local") {
+		if err := u.issueAccessToken(account, tokens, sessionAccessTokenTTL); err != nil {
+			return nil, err
+		}
+	}
+
+	return tokens, nil
+}
+
+// issueAccessToken アカウント情報からアクセストークンを発行してtokensに設定
+// トークンにはsidクレームとしてセッションIDを含め、セッションを失効させると有効期限前でも使えなくなる
+func (u *SessionUsecase) issueAccessToken(account *entities.UserManagement, tokens *entities.SessionTokens, ttl time.Duration) error {
+	identity := auth.VerifiedToken{
+		UID:       account.FirebaseUID,
+		Email:     account.Email,
+		Name:      account.Name,
+		Role:      account.Role,
+		SessionID: tokens.Session.ID,
+	}
+	if account.SchoolID != nil {
+		if schoolID, err := strconv.ParseInt(*account.SchoolID, 10, 64); err == nil {
+			identity.SchoolID = schoolID
+		}
+	}
+
+	accessToken, expiresAt, err := u.tokenIssuer.IssueToken(identity, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to issue access token: %w", err)
+	}
+	tokens.AccessToken = accessToken
+	tokens.AccessTokenExpiresAt = &expiresAt
+	return nil
+}
+
+// ListSessions 自分の有効なセッション一覧（最終アクセスの新しい順）
+func (u *SessionUsecase) ListSessions(ctx context.Context, userID, currentSessionID string) ([]entities.Session, error) {
+	sessions, err := u.sessionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	return sessions, nil
+}
+
+// RevokeSession 自分のセッションを失効させる
+func (u *SessionUsecase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	session, err := u.sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID {
+		return ErrSessionInvalid
+	}
+
+	return u.sessionRepo.Delete(ctx, sessionID)
+}
+
+// RevokeUserSessions ユーザーの全セッションを失効させる（パスワード変更時など）
+func (u *SessionUsecase) RevokeUserSessions(ctx context.Context, userID string) (int, error) {
+	return u.sessionRepo.DeleteByUser(ctx, userID)
+}
+
+// MarkMFAVerified セッションに二要素認証での本人確認日時を記録
+func (u *SessionUsecase) MarkMFAVerified(ctx context.Context, sessionID, userID string) error {
+	session, err := u.sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID {
+		return ErrSessionInvalid
+	}
+
+	now := time.Now()
+	session.MFAVerifiedAt = &now
+	updated, err := u.sessionRepo.Update(ctx, session)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrSessionInvalid
+	}
+	return nil
+}
+
+// MFAVerifiedAt セッションで二要素認証を行った日時を取得（未実施の場合はnil）
+func (u *SessionUsecase) MFAVerifiedAt(ctx context.Context, sessionID, userID string) (*time.Time, error) {
+	session, err := u.sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.UserID != userID {
+		return nil, ErrSessionInvalid
+	}
+	return session.MFAVerifiedAt, nil
+}
+
+// hashRefreshToken リフレッシュトークンをSESSION_SECRETでHMAC化（Redisには平文を保存しない）
+func (u *SessionUsecase) hashRefreshToken(secret string) string {
+	mac := hmac.New(sha256.New, []byte(u.config.SessionSecret))
+	mac.Write([]byte(secret))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// formatRefreshToken クライアントに返すリフレッシュトークン（セッションID.秘密値）
+func formatRefreshToken(sessionID, secret string) string {
+	return sessionID + "." + secret
+}
+
+// parseRefreshToken リフレッシュトークンをセッションIDと秘密値に分割
+func parseRefreshToken(token string) (string, string, bool) {
+	sessionID, secret, ok := strings.Cut(token, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return "", "", false
+	}
+	return sessionID, secret, true
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+)
+
+// ErrInvalidStudentProfile 学年・学籍番号・クラスなどのプロフィールの指定が正しくない
+var ErrInvalidStudentProfile = errors.New("invalid student profile")
+
+// SetStudentProfileRepository allows injecting StudentProfileRepository
+func (u *AdminUsecase) SetStudentProfileRepository(repo repositories.StudentProfileRepository) {
+	u.studentProfileRepo = repo
+}
+
+// validateProfileUpdate プロフィール項目を検証し、前後の空白を除く（学年・学籍番号は生徒のみ）
+func (u *AdminUsecase) validateProfileUpdate(ctx context.Context, target *entities.UserManagement, updateData *entities.User) error {
+	role := target.Role
+	if updateData.Role != "" {
+		role = updateData.Role
+	}
+
+	if updateData.Furigana != nil {
+		furigana := strings.TrimSpace(*updateData.Furigana)
+		updateData.Furigana = &furigana
+	}
+
+	if updateData.AvatarURL != nil && *updateData.AvatarURL != "" {
+		parsed, err := url.Parse(*updateData.AvatarURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%w: avatar_url must be an http(s) URL", ErrInvalidStudentProfile)
+		}
+	}
+
+	if updateData.Grade != nil {
+		if role != policy.RoleStudent {
+			return fmt.Errorf("%w: grade can only be set for students", ErrInvalidStudentProfile)
+		}
+		if *updateData.Grade < 1 || *updateData.Grade > 3 {
+			return fmt.Errorf("%w: grade must be between 1 and 3", ErrInvalidStudentProfile)
+		}
+	}
+
+	if updateData.StudentNumber != nil {
+		studentNumber := strings.TrimSpace(*updateData.StudentNumber)
+		updateData.StudentNumber = &studentNumber
+		if studentNumber == "" {
+			return nil
+		}
+		if role != policy.RoleStudent {
+			return fmt.Errorf("%w: student_number can only be set for students", ErrInvalidStudentProfile)
+		}
+
+		// 学籍番号は学校内で一意
+		schoolID := updateData.SchoolID
+		if schoolID == "" {
+			schoolID = stringValue(target.SchoolID)
+		}
+		if schoolID == "" {
+			return fmt.Errorf("%w: student_number requires a school", ErrInvalidStudentProfile)
+		}
+		if u.studentProfileRepo != nil {
+			ownerID, err := u.studentProfileRepo.FindUserIDByStudentNumber(ctx, schoolID, studentNumber)
+			if err != nil {
+				return err
+			}
+			if ownerID != "" && ownerID != target.ID {
+				return fmt.Errorf("%w: student_number %s is already in use", ErrInvalidStudentProfile, studentNumber)
+			}
+		}
+	}
+	return nil
+}
+
+// TransferClass 生徒を同じ学校のクラスへ移動（classIDがnilの場合はクラスから外す）
+// 移動前後のクラスの在籍人数（current_students）も更新する
+func (u *AdminUsecase) TransferClass(ctx context.Context, userID string, classID *string, requesterRole string, requesterSchoolID string) (*entities.UserManagement, error) {
+	if u.studentProfileRepo == nil {
+		return nil, fmt.Errorf("class transfer is not configured")
+	}
+
+	target, err := u.adminRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target user: %w", err)
+	}
+
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersUpdate, userResource(target)); err != nil {
+		return nil, err
+	}
+	if target.Role != policy.RoleStudent {
+		return nil, fmt.Errorf("%w: only students can be assigned to a class", ErrInvalidStudentProfile)
+	}
+
+	var newClassID *int64
+	if classID != nil && *classID != "" {
+		id, err := strconv.ParseInt(*classID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid class_id %q", ErrInvalidStudentProfile, *classID)
+		}
+
+		class, err := u.userRepo.FindClassByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if class == nil || strconv.FormatInt(class.SchoolID, 10) != stringValue(target.SchoolID) {
+			return nil, fmt.Errorf("%w: class %d not found in the student's school", ErrInvalidStudentProfile, id)
+		}
+		if !class.IsActive {
+			return nil, fmt.Errorf("%w: class %d is not active", ErrInvalidStudentProfile, id)
+		}
+		newClassID = &id
+	}
+
+	// 定員はリポジトリがクラスをロックしたトランザクション内で確認する
+	if err := u.studentProfileRepo.TransferClass(ctx, userID, newClassID); err != nil {
+		if errors.Is(err, repositories.ErrClassFull) {
+			return nil, fmt.Errorf("%w: class %d is full", ErrInvalidStudentProfile, *newClassID)
+		}
+		return nil, err
+	}
+
+	updated, err := u.adminRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transferred user: %w", err)
+	}
+
+	if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionUserTransferClass,
+		TargetType: audit.TargetUser,
+		TargetID:   target.ID,
+		SchoolID:   stringValue(target.SchoolID),
+		Before:     map[string]interface{}{"class_id": stringValue(target.ClassID), "grade": intValue(target.Grade)},
+		After:      map[string]interface{}{"class_id": stringValue(updated.ClassID), "grade": intValue(updated.Grade)},
+	}); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+	"github.com/rikut0904/bloomia/backend/internal/infrastructure/firebase"
+)
+
+// erasureBatchSize 消去ジョブが1回の実行で処理する最大件数
+const erasureBatchSize = 100
+
+var (
+	// ErrRestoreUnavailable 削除されていない、猶予期間を過ぎた、または消去済みのため復元できない
+	ErrRestoreUnavailable = errors.New("not deleted or the restore period has expired")
+	// ErrCannotDeleteSelf 自分自身のアカウントは削除できない
+	ErrCannotDeleteSelf = errors.New("cannot delete your own account")
+)
+
+// SetUserDeletionRepository allows injecting UserDeletionRepository
+func (u *AdminUsecase) SetUserDeletionRepository(repo repositories.UserDeletionRepository) {
+	u.deletionRepo = repo
+}
+
+// deletionGracePeriod 削除から復元できるまでの期間（経過後は個人情報を消去）
+func deletionGracePeriod(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// DeleteUser ユーザーを論理削除し、全セッションを失効させる（猶予期間内は復元可能）
+func (u *AdminUsecase) DeleteUser(ctx context.Context, userID string, requesterID string, requesterRole string, requesterSchoolID string) error {
+	if u.deletionRepo == nil {
+		return fmt.Errorf("user deletion is not configured")
+	}
+
+	targetUser, err := u.adminRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get target user: %w", err)
+	}
+
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersDelete, userResource(targetUser)); err != nil {
+		return err
+	}
+	if !policy.CanAssignRole(requesterRole, targetUser.Role) {
+		return fmt.Errorf("%w: cannot delete %s users", policy.ErrForbidden, targetUser.Role)
+	}
+	if targetUser.ID == requesterID {
+		return ErrCannotDeleteSelf
+	}
+
+	if err := u.deletionRepo.SoftDeleteUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionUserDelete,
+		TargetType: audit.TargetUser,
+		TargetID:   targetUser.ID,
+		SchoolID:   stringValue(targetUser.SchoolID),
+		Before:     map[string]interface{}{"deleted": false},
+		After:      map[string]interface{}{"deleted": true},
+	}); err != nil {
+		return err
+	}
+
+	if _, err := u.logoutUser(ctx, targetUser); err != nil {
+		return fmt.Errorf("user deleted but failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// RestoreUser 猶予期間内に論理削除されたユーザーを復元
+func (u *AdminUsecase) RestoreUser(ctx context.Context, userID string, requesterRole string, requesterSchoolID string) error {
+	if u.deletionRepo == nil {
+		return fmt.Errorf("user deletion is not configured")
+	}
+
+	targetUser, err := u.adminRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get target user: %w", err)
+	}
+
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersDelete, userResource(targetUser)); err != nil {
+		return err
+	}
+	if !policy.CanAssignRole(requesterRole, targetUser.Role) {
+		return fmt.Errorf("%w: cannot restore %s users", policy.ErrForbidden, targetUser.Role)
+	}
+
+	deletedAfter := time.Now().Add(-deletionGracePeriod(u.config.DeletionGraceDays))
+	restored, err := u.deletionRepo.RestoreUser(ctx, userID, deletedAfter)
+	if err != nil {
+		return err
+	}
+	if !restored {
+		return ErrRestoreUnavailable
+	}
+
+	return recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionUserRestore,
+		TargetType: audit.TargetUser,
+		TargetID:   targetUser.ID,
+		SchoolID:   stringValue(targetUser.SchoolID),
+		Before:     map[string]interface{}{"deleted": true},
+		After:      map[string]interface{}{"deleted": false},
+	})
+}
+
+// EraseUser 本人からの削除請求に応じて、猶予期間を待たずに個人情報を消去（成績は匿名のまま残す）
+func (u *AdminUsecase) EraseUser(ctx context.Context, userID string, requesterID string, requesterRole string, requesterSchoolID string) error {
+	if u.deletionRepo == nil {
+		return fmt.Errorf("user deletion is not configured")
+	}
+
+	targetUser, err := u.adminRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get target user: %w", err)
+	}
+
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersErase, userResource(targetUser)); err != nil {
+		return err
+	}
+	if targetUser.ID == requesterID {
+		return ErrCannotDeleteSelf
+	}
+
+	return u.eraseUser(ctx, targetUser)
+}
+
+// PurgeExpiredDeletions 猶予期間を過ぎた削除済みユーザーの個人情報を消去し、処理件数を返す
+func (u *AdminUsecase) PurgeExpiredDeletions(ctx context.Context) (int, error) {
+	if u.deletionRepo == nil {
+		return 0, fmt.Errorf("user deletion is not configured")
+	}
+
+	deletedBefore := time.Now().Add(-deletionGracePeriod(u.config.DeletionGraceDays))
+	userIDs, err := u.deletionRepo.ListExpiredDeletions(ctx, deletedBefore, erasureBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	erased := 0
+	for _, userID := range userIDs {
+		targetUser, err := u.adminRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			log.Printf("Failed to get user %s for erasure: %v", userID, err)
+			continue
+		}
+		// 1件の失敗で他のユーザーの消去を止めない（次回の実行で再試行される）
+		if err := u.eraseUser(ctx, targetUser); err != nil {
+			log.Printf("Failed to erase user %s: %v", userID, err)
+			continue
+		}
+		erased++
+	}
+	return erased, nil
+}
+
+// RunErasureJob 猶予期間を過ぎたユーザーの消去を定期的に実行（ctxが終了するまで継続）
+func (u *AdminUsecase) RunErasureJob(ctx context.Context, interval time.Duration) {
+	log.Println("User erasure job started")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if erased, err := u.PurgeExpiredDeletions(ctx); err != nil {
+			log.Printf("User erasure job failed: %v", err)
+		} else if erased > 0 {
+			log.Printf("Erased personal data of %d deleted users", erased)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("User erasure job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// eraseUser セッションとFirebaseアカウントを削除してから、DBの個人情報を匿名化
+// Firebaseの削除後にDB更新が失敗しても、再実行すれば同じ結果になる
+func (u *AdminUsecase) eraseUser(ctx context.Context, targetUser *entities.UserManagement) error {
+	if u.sessionRepo != nil {
+		if _, err := u.sessionRepo.DeleteByUser(ctx, targetUser.ID); err != nil {
+			return fmt.Errorf("failed to revoke user sessions: %w", err)
+		}
+	}
+
+	if u.firebaseClient != nil && hasFirebaseAccount(targetUser.FirebaseUID) {
+		if err := u.firebaseClient.DeleteUser(ctx, targetUser.FirebaseUID); err != nil && !firebase.IsUserNotFound(err) {
+			return fmt.Errorf("failed to delete firebase account: %w", err)
+		}
+	}
+
+	erased, err := u.deletionRepo.EraseUser(ctx, targetUser.ID)
+	if err != nil {
+		return err
+	}
+	if !erased {
+		// 既に消去済み
+		return nil
+	}
+
+	// 監査ログには消去した個人情報を含めない
+	return recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionUserErase,
+		TargetType: audit.TargetUser,
+		TargetID:   targetUser.ID,
+		SchoolID:   stringValue(targetUser.SchoolID),
+		After:      map[string]interface{}{"erased": true},
+	})
+}
+
+// hasFirebaseAccount Firebaseにアカウントが存在しうるUIDか（一括登録の仮UIDや消去済みのUIDは除く）
+func hasFirebaseAccount(uid string) bool {
+	return uid != "" &&
+		!strings.HasPrefix(uid, entities.ImportedUIDPrefix) &&
+		!strings.HasPrefix(uid, entities.ErasedUIDPrefix)
+}
@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/audit"
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+)
+
+// 名簿取り込みの行ごとの処理
+const (
+	importActionCreate = "create"
+	importActionUpdate = "update"
+)
+
+// ErrImportConflict 検証後に他の操作でメールアドレス・クラスの定員が埋まり、名簿を取り込めなかった（取り込み全体を取り消す）
+var ErrImportConflict = errors.New("roster conflicts with changes made during the import")
+
+// SetUserImportRepository allows injecting UserImportRepository
+func (u *AdminUsecase) SetUserImportRepository(repo repositories.UserImportRepository) {
+	u.userImportRepo = repo
+}
+
+// ImportStudents 名簿の生徒を学籍番号をキーに一括登録・更新し、新規の生徒へ招待を送る
+// 検証エラーが1件でもある場合、またはドライランの場合は何も登録せずに結果のみ返す
+// 同じ名簿を再度取り込んでも重複登録されない
+func (u *AdminUsecase) ImportStudents(ctx context.Context, schoolID string, rows []entities.RosterRow, dryRun, sendInvitations bool, requesterRole string, requesterSchoolID string) (*entities.UserImportResult, error) {
+	if err := policy.Authorize(subjectOf(ctx, requesterRole, requesterSchoolID), policy.UsersInvite, policy.Resource{SchoolID: schoolID}); err != nil {
+		return nil, err
+	}
+	if !policy.CanAssignRole(requesterRole, policy.RoleStudent) {
+		return nil, fmt.Errorf("%w: cannot assign %s role", policy.ErrForbidden, policy.RoleStudent)
+	}
+	if u.userImportRepo == nil {
+		return nil, fmt.Errorf("user import is not configured")
+	}
+
+	students, result, err := u.validateRoster(ctx, schoolID, rows)
+	if err != nil {
+		return nil, err
+	}
+	result.DryRun = dryRun
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	result.Created, result.Updated = 0, 0
+	for i := range result.Rows {
+		row := &result.Rows[i]
+		student := students[i]
+
+		localID, err := generateSecureToken(12)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate uid: %w", err)
+		}
+		student.FirebaseUID = entities.ImportedUIDPrefix + localID
+
+		userID, created, err := u.userImportRepo.UpsertStudent(ctx, schoolID, student)
+		if errors.Is(err, repositories.ErrEmailTaken) || errors.Is(err, repositories.ErrClassFull) {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrImportConflict, row.Line, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		row.UserID = userID
+
+		// 検証後に他の取り込みで作成された場合に備え、実際の結果で数え直す
+		if created {
+			row.Action = importActionCreate
+			result.Created++
+		} else {
+			row.Action = importActionUpdate
+			result.Updated++
+		}
+
+		if created && sendInvitations {
+			if _, err := u.InviteUser(ctx, student.Name, student.Email, policy.RoleStudent, schoolID, "", requesterRole, requesterSchoolID); err != nil {
+				return nil, fmt.Errorf("failed to invite %s: %w", student.Email, err)
+			}
+			row.Invited = true
+			result.Invited++
+		}
+	}
+
+	if err := recordAudit(ctx, u.auditRecorder, audit.Entry{
+		Action:     audit.ActionUserImport,
+		TargetType: audit.TargetSchool,
+		TargetID:   schoolID,
+		SchoolID:   schoolID,
+		After: map[string]interface{}{
+			"total_rows": result.TotalRows,
+			"created":    result.Created,
+			"updated":    result.Updated,
+			"invited":    result.Invited,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// validateRoster 名簿の各行を検証し、登録内容と予定される処理（作成・更新）を求める
+func (u *AdminUsecase) validateRoster(ctx context.Context, schoolID string, rows []entities.RosterRow) ([]*entities.StudentImport, *entities.UserImportResult, error) {
+	result := &entities.UserImportResult{
+		TotalRows: len(rows),
+		Rows:      []entities.UserImportRowResult{},
+		Errors:    []entities.UserImportRowError{},
+	}
+	addError := func(line int, field, message string) {
+		result.Errors = append(result.Errors, entities.UserImportRowError{Line: line, Field: field, Message: message})
+	}
+
+	classes, err := u.userImportRepo.ListCurrentClasses(ctx, schoolID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	studentNumbers := make([]string, 0, len(rows))
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		studentNumbers = append(studentNumbers, row.StudentNumber)
+		emails = append(emails, strings.ToLower(row.Email))
+	}
+	byNumber, err := u.userImportRepo.FindByStudentNumbers(ctx, schoolID, studentNumbers)
+	if err != nil {
+		return nil, nil, err
+	}
+	byEmail, err := u.userImportRepo.FindByEmails(ctx, emails)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seenNumbers := map[string]int{}
+	seenEmails := map[string]int{}
+	students := []*entities.StudentImport{}
+	for _, row := range rows {
+		errorCount := len(result.Errors)
+		student := &entities.StudentImport{
+			Name:          row.Name,
+			Furigana:      row.Furigana,
+			Email:         row.Email,
+			StudentNumber: row.StudentNumber,
+		}
+
+		if row.Name == "" {
+			addError(row.Line, "name", "name is required")
+		}
+		if row.StudentNumber == "" {
+			addError(row.Line, "student_number", "student number is required")
+		} else if line, ok := seenNumbers[row.StudentNumber]; ok {
+			addError(row.Line, "student_number", fmt.Sprintf("duplicate student number (same as line %d)", line))
+		} else {
+			seenNumbers[row.StudentNumber] = row.Line
+		}
+
+		email := strings.ToLower(row.Email)
+		if row.Email == "" {
+			addError(row.Line, "email", "email is required")
+		} else if address, err := mail.ParseAddress(row.Email); err != nil || address.Address != row.Email {
+			addError(row.Line, "email", "invalid email format")
+		} else if line, ok := seenEmails[email]; ok {
+			addError(row.Line, "email", fmt.Sprintf("duplicate email (same as line %d)", line))
+		} else {
+			seenEmails[email] = row.Line
+		}
+
+		if row.Grade != "" {
+			grade, err := strconv.Atoi(row.Grade)
+			if err != nil || grade < 1 || grade > 3 {
+				addError(row.Line, "grade", "grade must be between 1 and 3")
+			} else {
+				student.Grade = &grade
+			}
+		}
+		if row.ClassName != "" {
+			classID, ok := classes[row.ClassName]
+			if !ok {
+				addError(row.Line, "class", fmt.Sprintf("class not found for the current academic year: %s", row.ClassName))
+			} else {
+				student.ClassID = &classID
+			}
+		}
+
+		// 既存ユーザーとの整合性
+		action := importActionCreate
+		existing, exists := byNumber[row.StudentNumber]
+		if exists {
+			action = importActionUpdate
+			if existing.Role != policy.RoleStudent {
+				addError(row.Line, "student_number", "student number belongs to a non-student account")
+			}
+		}
+		if owner, ok := byEmail[email]; ok && row.Email != "" && (!exists || owner.ID != existing.ID) {
+			addError(row.Line, "email", "email is already used by another account")
+		}
+
+		if len(result.Errors) > errorCount {
+			continue
+		}
+		students = append(students, student)
+		result.Rows = append(result.Rows, entities.UserImportRowResult{
+			Line:          row.Line,
+			StudentNumber: row.StudentNumber,
+			Email:         row.Email,
+			Action:        action,
+		})
+	}
+
+	full, err := u.rosterOverCapacity(ctx, students, byNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	valid := students[:0]
+	validRows := result.Rows[:0]
+	for i, row := range result.Rows {
+		if maxStudents, ok := full[i]; ok {
+			addError(row.Line, "class", fmt.Sprintf("class is full (max %d students)", maxStudents))
+			continue
+		}
+		valid = append(valid, students[i])
+		validRows = append(validRows, row)
+		if row.Action == importActionCreate {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+	result.Rows = validRows
+	// 定員のエラーは最後に追加するため行番号順に並べ直す
+	sort.SliceStable(result.Errors, func(i, j int) bool { return result.Errors[i].Line < result.Errors[j].Line })
+
+	return valid, result, nil
+}
+
+// rosterOverCapacity 定員を超えるクラスへ移る行（studentsの添字とクラスの定員）を求める
+// クラスの行をロックして数えるため、同じトランザクションで取り込む間は他の移動で定員を超えない
+// 名簿でクラスを出る生徒の分は空きとして数えない（取り込みの途中で定員を超えないように）
+func (u *AdminUsecase) rosterOverCapacity(ctx context.Context, students []*entities.StudentImport, byNumber map[string]entities.RosterUser) (map[int]int, error) {
+	arrivals := map[int64][]int{}
+	classIDs := []int64{}
+	for i, student := range students {
+		if student.ClassID == nil {
+			continue
+		}
+		if existing, ok := byNumber[student.StudentNumber]; ok && existing.ClassID != nil && *existing.ClassID == *student.ClassID {
+			continue
+		}
+		if _, ok := arrivals[*student.ClassID]; !ok {
+			classIDs = append(classIDs, *student.ClassID)
+		}
+		arrivals[*student.ClassID] = append(arrivals[*student.ClassID], i)
+	}
+
+	capacities, err := u.userImportRepo.LockClassCapacities(ctx, classIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	full := map[int]int{}
+	for classID, indexes := range arrivals {
+		capacity := capacities[classID]
+		if capacity.MaxStudents <= 0 {
+			continue
+		}
+		available := capacity.MaxStudents - capacity.Enrolled
+		for n, i := range indexes {
+			if n >= available {
+				full[i] = capacity.MaxStudents
+			}
+		}
+	}
+	return full, nil
+}
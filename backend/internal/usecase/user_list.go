@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/rikut0904/bloomia/backend/internal/domain/entities"
+	"github.com/rikut0904/bloomia/backend/internal/domain/policy"
+	"github.com/rikut0904/bloomia/backend/internal/domain/repositories"
+)
+
+// ErrInvalidUserListQuery 並び替え項目・絞り込み条件・カーソルの指定が正しくない
+var ErrInvalidUserListQuery = errors.New("invalid user list query")
+
+// GetAllUsers ユーザー一覧を検索・絞り込み・並び替えして取得
+// cursorを指定した場合はページ番号を使わず、前回の応答のnext_cursorの続きから取得する
+func (u *AdminUsecase) GetAllUsers(ctx context.Context, filter entities.UserListFilter, cursor string, requesterRole string, requesterSchoolID string) (*entities.UserListResponse, error) {
+	// 権限チェック（学校スコープの場合は自分の学校のユーザーのみ）
+	schoolID, err := scopedSchoolID(ctx, policy.UsersRead, filter.SchoolID, requesterRole, requesterSchoolID)
+	if err != nil {
+		return nil, err
+	}
+	filter.SchoolID = schoolID
+
+	if filter.SortBy == "" {
+		filter.SortBy = entities.UserSortCreatedAt
+	}
+	if !entities.IsUserSortColumn(filter.SortBy) {
+		return nil, fmt.Errorf("%w: unknown sort column %q", ErrInvalidUserListQuery, filter.SortBy)
+	}
+	if filter.Role != nil && !isRole(*filter.Role) {
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidUserListQuery, *filter.Role)
+	}
+	if filter.LastLoginFrom != nil && filter.LastLoginTo != nil && !filter.LastLoginFrom.Before(*filter.LastLoginTo) {
+		return nil, fmt.Errorf("%w: last_login_from must be before last_login_to", ErrInvalidUserListQuery)
+	}
+
+	if cursor != "" {
+		after, err := decodeUserListCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		// 並び順を変えた場合は前回のカーソルを使えない
+		if after.SortBy != filter.SortBy || after.Descending != filter.Descending {
+			return nil, fmt.Errorf("%w: cursor does not match the sort order", ErrInvalidUserListQuery)
+		}
+		filter.After = after
+	}
+
+	users, totalCount, next, err := u.adminRepo.GetAllUsers(ctx, filter)
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidUserListCursor) {
+			return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidUserListQuery)
+		}
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	response := &entities.UserListResponse{
+		Users:      users,
+		TotalCount: totalCount,
+		Page:       filter.Page,
+		PerPage:    filter.PerPage,
+	}
+	if next != nil {
+		encoded, err := encodeUserListCursor(next)
+		if err != nil {
+			return nil, err
+		}
+		response.NextCursor = &encoded
+	}
+	return response, nil
+}
+
+// isRole 定義済みのロールかを判定
+func isRole(role string) bool {
+	for _, r := range policy.Roles() {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeUserListCursor カーソルをクエリパラメータ用の文字列に変換
+func encodeUserListCursor(cursor *entities.UserListCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeUserListCursor クエリパラメータのカーソルを復元
+func decodeUserListCursor(value string) (*entities.UserListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidUserListQuery)
+	}
+	var cursor entities.UserListCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidUserListQuery)
+	}
+	if _, err := strconv.ParseInt(cursor.ID, 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidUserListQuery)
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,31 @@
+-- +migrate Up
+-- 保護者ロールの追加と保護者・生徒の紐付け
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'school_admin', 'teacher', 'student', 'guardian'));
+
+CREATE TABLE IF NOT EXISTS guardian_students (
+    guardian_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    relationship TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (guardian_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_guardian_students_student_id ON guardian_students(student_id);
+CREATE INDEX IF NOT EXISTS idx_courses_teacher_id ON courses(teacher_id);
+CREATE INDEX IF NOT EXISTS idx_attendance_course_id ON attendance(course_id);
+
+-- +migrate Down
+-- 保護者ロールの削除
+
+DROP INDEX IF EXISTS idx_attendance_course_id;
+DROP INDEX IF EXISTS idx_courses_teacher_id;
+DROP TABLE IF EXISTS guardian_students;
+
+DELETE FROM users WHERE role = 'guardian';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'school_admin', 'teacher', 'student'));
@@ -0,0 +1,207 @@
+-- +migrate Up
+-- 学校単位のテナント分離をRow Level Securityで強制する
+-- アプリケーションは認証済みリクエストごとにトランザクションを開始し、
+-- SET LOCAL ROLE authenticated と app.current_user_* の設定を行ってからクエリを実行する
+
+-- RLSの対象となるロール（テーブル所有者はRLSをバイパスするため切り替えて使用する）
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        CREATE ROLE authenticated NOLOGIN;
+    END IF;
+END
+$$;
+
+GRANT authenticated TO CURRENT_USER;
+GRANT USAGE ON SCHEMA public TO authenticated;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO authenticated;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO authenticated;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO authenticated;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO authenticated;
+
+-- リクエスト元がその学校のデータにアクセスできるか（adminは全校）
+CREATE OR REPLACE FUNCTION app_can_access_school(target_school_id BIGINT) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT CASE
+        WHEN current_setting('app.current_user_role', true) = 'admin' THEN true
+        ELSE target_school_id = NULLIF(current_setting('app.current_user_school_id', true), '')::bigint
+    END
+$$;
+
+-- 既存ポリシーを関数ベースに置き換え
+-- init.sqlで作成済みのデータベースにも適用できるよう、各ポリシーは削除してから作成する
+
+-- school_id を直接持つテーブル
+ALTER TABLE schools ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_schools ON schools;
+CREATE POLICY school_isolation_schools ON schools
+    FOR ALL TO authenticated
+    USING (app_can_access_school(id));
+
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_users ON users;
+CREATE POLICY school_isolation_users ON users
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+ALTER TABLE classes ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_classes ON classes;
+CREATE POLICY school_isolation_classes ON classes
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+ALTER TABLE chat_rooms ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_chat_rooms ON chat_rooms;
+CREATE POLICY school_isolation_chat_rooms ON chat_rooms
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+ALTER TABLE whiteboard_sessions ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_whiteboard_sessions ON whiteboard_sessions;
+CREATE POLICY school_isolation_whiteboard_sessions ON whiteboard_sessions
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+ALTER TABLE administrative_tasks ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_administrative_tasks ON administrative_tasks;
+CREATE POLICY school_isolation_administrative_tasks ON administrative_tasks
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+ALTER TABLE meetings ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_meetings ON meetings;
+CREATE POLICY school_isolation_meetings ON meetings
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+ALTER TABLE user_invitations ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_user_invitations ON user_invitations;
+CREATE POLICY school_isolation_user_invitations ON user_invitations
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+-- 親テーブル経由で学校に属するテーブル（親テーブルのRLSにより他校の行は参照できない）
+ALTER TABLE teachers ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_teachers ON teachers;
+CREATE POLICY school_isolation_teachers ON teachers
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = teachers.user_id));
+
+ALTER TABLE courses ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_courses ON courses;
+CREATE POLICY school_isolation_courses ON courses
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM classes cl WHERE cl.id = courses.class_id));
+
+ALTER TABLE materials ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_materials ON materials;
+CREATE POLICY school_isolation_materials ON materials
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM courses c WHERE c.id = materials.course_id));
+
+ALTER TABLE assignments ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_assignments ON assignments;
+CREATE POLICY school_isolation_assignments ON assignments
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM courses c WHERE c.id = assignments.course_id));
+
+ALTER TABLE submissions ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_submissions ON submissions;
+CREATE POLICY school_isolation_submissions ON submissions
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = submissions.student_id));
+
+ALTER TABLE grades ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_grades ON grades;
+CREATE POLICY school_isolation_grades ON grades
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = grades.student_id));
+
+ALTER TABLE attendance ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_attendance ON attendance;
+CREATE POLICY school_isolation_attendance ON attendance
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = attendance.student_id));
+
+ALTER TABLE messages ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_messages ON messages;
+CREATE POLICY school_isolation_messages ON messages
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM chat_rooms cr WHERE cr.id = messages.room_id));
+
+ALTER TABLE learning_notes ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_learning_notes ON learning_notes;
+CREATE POLICY school_isolation_learning_notes ON learning_notes
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = learning_notes.student_id));
+
+ALTER TABLE user_points ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_user_points ON user_points;
+CREATE POLICY school_isolation_user_points ON user_points
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = user_points.user_id));
+
+ALTER TABLE guardian_students ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_guardian_students ON guardian_students;
+CREATE POLICY school_isolation_guardian_students ON guardian_students
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = guardian_students.student_id));
+
+-- +migrate Down
+-- 学校単位のRLSポリシーを初期状態（users / classes のみ）に戻す
+
+DROP POLICY IF EXISTS school_isolation_guardian_students ON guardian_students;
+DROP POLICY IF EXISTS school_isolation_user_points ON user_points;
+DROP POLICY IF EXISTS school_isolation_learning_notes ON learning_notes;
+DROP POLICY IF EXISTS school_isolation_messages ON messages;
+DROP POLICY IF EXISTS school_isolation_attendance ON attendance;
+DROP POLICY IF EXISTS school_isolation_grades ON grades;
+DROP POLICY IF EXISTS school_isolation_submissions ON submissions;
+DROP POLICY IF EXISTS school_isolation_assignments ON assignments;
+DROP POLICY IF EXISTS school_isolation_materials ON materials;
+DROP POLICY IF EXISTS school_isolation_courses ON courses;
+DROP POLICY IF EXISTS school_isolation_teachers ON teachers;
+DROP POLICY IF EXISTS school_isolation_user_invitations ON user_invitations;
+DROP POLICY IF EXISTS school_isolation_meetings ON meetings;
+DROP POLICY IF EXISTS school_isolation_administrative_tasks ON administrative_tasks;
+DROP POLICY IF EXISTS school_isolation_whiteboard_sessions ON whiteboard_sessions;
+DROP POLICY IF EXISTS school_isolation_chat_rooms ON chat_rooms;
+DROP POLICY IF EXISTS school_isolation_classes ON classes;
+DROP POLICY IF EXISTS school_isolation_users ON users;
+DROP POLICY IF EXISTS school_isolation_schools ON schools;
+
+ALTER TABLE guardian_students DISABLE ROW LEVEL SECURITY;
+ALTER TABLE user_points DISABLE ROW LEVEL SECURITY;
+ALTER TABLE learning_notes DISABLE ROW LEVEL SECURITY;
+ALTER TABLE messages DISABLE ROW LEVEL SECURITY;
+ALTER TABLE attendance DISABLE ROW LEVEL SECURITY;
+ALTER TABLE grades DISABLE ROW LEVEL SECURITY;
+ALTER TABLE submissions DISABLE ROW LEVEL SECURITY;
+ALTER TABLE assignments DISABLE ROW LEVEL SECURITY;
+ALTER TABLE materials DISABLE ROW LEVEL SECURITY;
+ALTER TABLE courses DISABLE ROW LEVEL SECURITY;
+ALTER TABLE user_invitations DISABLE ROW LEVEL SECURITY;
+ALTER TABLE meetings DISABLE ROW LEVEL SECURITY;
+ALTER TABLE administrative_tasks DISABLE ROW LEVEL SECURITY;
+ALTER TABLE whiteboard_sessions DISABLE ROW LEVEL SECURITY;
+ALTER TABLE chat_rooms DISABLE ROW LEVEL SECURITY;
+
+CREATE POLICY school_isolation_users ON users
+    FOR ALL TO authenticated
+    USING (
+        CASE
+            WHEN current_setting('app.current_user_role', true) = 'admin' THEN true
+            ELSE school_id = current_setting('app.current_user_school_id', true)::bigint
+        END
+    );
+
+CREATE POLICY school_isolation_classes ON classes
+    FOR ALL TO authenticated
+    USING (
+        CASE
+            WHEN current_setting('app.current_user_role', true) = 'admin' THEN true
+            ELSE school_id = current_setting('app.current_user_school_id', true)::bigint
+        END
+    );
+
+DROP FUNCTION IF EXISTS app_can_access_school(BIGINT);
@@ -0,0 +1,33 @@
+-- +migrate Up
+-- Googleアカウントを使えない学校向けのローカル認証（パスワード）
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_hash TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ;
+
+-- パスワード再設定用のワンタイムトークン（平文は保存せずSHA-256ハッシュのみ保持）
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+
+ALTER TABLE password_reset_tokens ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_password_reset_tokens ON password_reset_tokens;
+CREATE POLICY school_isolation_password_reset_tokens ON password_reset_tokens
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = password_reset_tokens.user_id));
+
+-- +migrate Down
+-- ローカル認証の削除
+
+DROP TABLE IF EXISTS password_reset_tokens;
+
+ALTER TABLE users DROP COLUMN IF EXISTS password_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS must_change_password;
+ALTER TABLE users DROP COLUMN IF EXISTS password_hash;
@@ -0,0 +1,41 @@
+-- +migrate Up
+-- 管理者アカウントの二要素認証（TOTP）
+
+-- TOTPシークレット（ENCRYPTION_KEYで暗号化して保存）
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret_encrypted TEXT NOT NULL,
+    confirmed_at TIMESTAMPTZ,        -- 登録確認前はNULL
+    last_used_step BIGINT NOT NULL DEFAULT 0,  -- 同じコードの再利用防止
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- リカバリーコード（平文は保存せずSHA-256ハッシュのみ保持）
+CREATE TABLE IF NOT EXISTS user_mfa_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE(user_id, code_hash)
+);
+
+ALTER TABLE user_mfa ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_user_mfa ON user_mfa;
+CREATE POLICY school_isolation_user_mfa ON user_mfa
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = user_mfa.user_id));
+
+ALTER TABLE user_mfa_recovery_codes ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_user_mfa_recovery_codes ON user_mfa_recovery_codes;
+CREATE POLICY school_isolation_user_mfa_recovery_codes ON user_mfa_recovery_codes
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = user_mfa_recovery_codes.user_id));
+
+-- +migrate Down
+-- 二要素認証の削除
+
+DROP TABLE IF EXISTS user_mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
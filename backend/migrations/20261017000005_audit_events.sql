@@ -0,0 +1,42 @@
+-- +migrate Up
+-- 管理操作の監査ログ
+-- 学校ごと（school_idがNULLの操作は全体で1本）にハッシュチェーンを構成し、行の改ざん・削除を検知できるようにする
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    school_id BIGINT,                -- 学校削除後も履歴を残すため外部キーは設定しない
+    actor_id BIGINT,                 -- 操作者のusers.id（ユーザー削除後も残す）
+    actor_uid TEXT NOT NULL DEFAULT '',
+    actor_role TEXT NOT NULL,
+    action TEXT NOT NULL,            -- user.update_role, school.create など
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    changes JSONB,                   -- {"項目": {"before": ..., "after": ...}}
+    request_id TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    prev_hash TEXT NOT NULL DEFAULT '',
+    hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_school_id ON audit_events(school_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target ON audit_events(target_type, target_id);
+
+-- 追記のみ許可する
+REVOKE UPDATE, DELETE ON audit_events FROM authenticated;
+
+ALTER TABLE audit_events ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_audit_events_select ON audit_events;
+CREATE POLICY school_isolation_audit_events_select ON audit_events
+    FOR SELECT TO authenticated
+    USING (app_can_access_school(school_id));
+DROP POLICY IF EXISTS school_isolation_audit_events_insert ON audit_events;
+CREATE POLICY school_isolation_audit_events_insert ON audit_events
+    FOR INSERT TO authenticated
+    WITH CHECK (app_can_access_school(school_id));
+
+-- +migrate Down
+-- 監査ログの削除
+
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,25 @@
+-- +migrate Up
+-- 管理者用ユーザー一覧の検索・並び替え用インデックス
+
+-- 氏名・ふりがな・メールアドレスの部分一致検索（ILIKE '%...%'）用のトライグラム索引
+-- 日本語を索引するにはLC_CTYPEがC以外（例: ja_JP.UTF-8, en_US.UTF-8）のデータベースが必要
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_users_name_trgm ON users USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_furigana_trgm ON users USING gin (furigana gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING gin (email gin_trgm_ops);
+
+-- 学校内の既定の並び順（登録日時の降順）と最終ログインの範囲指定・並び替え用
+CREATE INDEX IF NOT EXISTS idx_users_school_created_at ON users (school_id, (COALESCE(created_at, '-infinity'::timestamptz)), id);
+CREATE INDEX IF NOT EXISTS idx_users_school_last_login_at ON users (school_id, last_login_at);
+CREATE INDEX IF NOT EXISTS idx_users_class_id ON users (class_id);
+
+-- +migrate Down
+-- 検索用インデックスの削除（pg_trgm拡張は他で使用している可能性があるため残す）
+
+DROP INDEX IF EXISTS idx_users_class_id;
+DROP INDEX IF EXISTS idx_users_school_last_login_at;
+DROP INDEX IF EXISTS idx_users_school_created_at;
+DROP INDEX IF EXISTS idx_users_email_trgm;
+DROP INDEX IF EXISTS idx_users_furigana_trgm;
+DROP INDEX IF EXISTS idx_users_name_trgm;
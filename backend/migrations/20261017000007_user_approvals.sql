@@ -0,0 +1,19 @@
+-- +migrate Up
+-- 自己登録ユーザーの承認ワークフロー
+
+-- 承認・却下の記録（未審査: is_approved=false かつ approval_reviewed_at IS NULL）
+ALTER TABLE users ADD COLUMN IF NOT EXISTS approval_reviewed_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS approval_reviewed_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS rejection_reason TEXT;
+
+-- 学校ごとの承認待ち一覧（申請の古い順）
+CREATE INDEX IF NOT EXISTS idx_users_pending_approval ON users (school_id, created_at, id)
+    WHERE is_approved = false AND approval_reviewed_at IS NULL;
+
+-- +migrate Down
+-- 承認ワークフローの列を削除
+
+DROP INDEX IF EXISTS idx_users_pending_approval;
+ALTER TABLE users DROP COLUMN IF EXISTS rejection_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS approval_reviewed_by;
+ALTER TABLE users DROP COLUMN IF EXISTS approval_reviewed_at;
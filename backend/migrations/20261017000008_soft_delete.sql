@@ -0,0 +1,19 @@
+-- +migrate Up
+-- ユーザー・学校の論理削除と、ユーザーの個人情報の消去
+
+-- 削除日時（猶予期間内は復元可能。期間を過ぎたユーザーは個人情報を消去する）
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS erased_at TIMESTAMPTZ;
+ALTER TABLE schools ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- 消去ジョブの対象（削除済みかつ未消去）の検索用
+CREATE INDEX IF NOT EXISTS idx_users_pending_erasure ON users (deleted_at)
+    WHERE deleted_at IS NOT NULL AND erased_at IS NULL;
+
+-- +migrate Down
+-- 論理削除の列を削除
+
+DROP INDEX IF EXISTS idx_users_pending_erasure;
+ALTER TABLE schools DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS erased_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
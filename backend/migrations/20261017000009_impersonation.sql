@@ -0,0 +1,16 @@
+-- +migrate Up
+-- 代理ログイン中の操作を監査ログに記録
+
+-- 実際に操作した管理者（actor_idは代理ログイン先のユーザー）
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS impersonator_id BIGINT;
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS impersonator_uid TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_impersonator ON audit_events (impersonator_id, id)
+    WHERE impersonator_id IS NOT NULL;
+
+-- +migrate Down
+-- 代理ログインの列を削除
+
+DROP INDEX IF EXISTS idx_audit_events_impersonator;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS impersonator_uid;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS impersonator_id;
@@ -0,0 +1,13 @@
+-- +migrate Up
+-- 学校設定をバージョン付きの形式にする（背景色はbackground_color列で管理）
+
+ALTER TABLE schools ALTER COLUMN settings SET DEFAULT '{"version": 1, "theme": "coral", "locale": "ja", "timezone": "Asia/Tokyo"}';
+
+UPDATE schools
+SET settings = jsonb_build_object('version', 1) || (COALESCE(settings, '{}'::jsonb) - 'background')
+WHERE settings IS NULL OR NOT settings ? 'version';
+
+-- +migrate Down
+-- 学校設定の初期値を元に戻す
+
+ALTER TABLE schools ALTER COLUMN settings SET DEFAULT '{"theme": "coral", "background": "#fdf8f0"}';
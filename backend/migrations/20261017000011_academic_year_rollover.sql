@@ -0,0 +1,104 @@
+-- +migrate Up
+-- 年度更新（進級・卒業・クラスと授業の複製）と、前年度のデータの読み取り専用化
+
+-- 卒業日時（卒業した生徒は無効化し、学年は卒業時のまま残す）
+ALTER TABLE users ADD COLUMN IF NOT EXISTS graduated_at TIMESTAMPTZ;
+
+-- 年度更新の済んだ年度（この年度のクラス・授業・成績・ポイントは変更できない）
+CREATE TABLE IF NOT EXISTS academic_year_archives (
+    school_id BIGINT NOT NULL REFERENCES schools(id),
+    academic_year INTEGER NOT NULL,
+    archived_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    summary JSONB,                   -- 進級・卒業・複製した件数
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (school_id, academic_year)
+);
+
+REVOKE UPDATE, DELETE ON academic_year_archives FROM authenticated;
+
+ALTER TABLE academic_year_archives ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_academic_year_archives ON academic_year_archives;
+CREATE POLICY school_isolation_academic_year_archives ON academic_year_archives
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+-- 学校のその年度が年度更新済みか
+CREATE OR REPLACE FUNCTION app_is_archived_year(target_school_id BIGINT, target_year INTEGER) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM academic_year_archives
+        WHERE school_id = target_school_id AND academic_year = target_year
+    )
+$$;
+
+-- 年度を持つ行の学校を求め、更新の済んだ年度の変更を拒否する
+CREATE OR REPLACE FUNCTION app_reject_archived_year_write() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+DECLARE
+    target JSONB;
+    target_school_id BIGINT;
+BEGIN
+    -- 更新の場合は変更前・変更後のどちらの年度も確認する
+    FOREACH target IN ARRAY (
+        CASE TG_OP
+            WHEN 'INSERT' THEN ARRAY[to_jsonb(NEW)]
+            WHEN 'DELETE' THEN ARRAY[to_jsonb(OLD)]
+            ELSE ARRAY[to_jsonb(OLD), to_jsonb(NEW)]
+        END
+    ) LOOP
+        IF TG_TABLE_NAME = 'classes' THEN
+            target_school_id := (target->>'school_id')::bigint;
+        ELSIF TG_TABLE_NAME = 'courses' THEN
+            SELECT cl.school_id INTO target_school_id
+            FROM classes cl WHERE cl.id = (target->>'class_id')::bigint;
+        ELSIF TG_TABLE_NAME = 'grades' THEN
+            SELECT cl.school_id INTO target_school_id
+            FROM courses c JOIN classes cl ON cl.id = c.class_id
+            WHERE c.id = (target->>'course_id')::bigint;
+        ELSE
+            SELECT u.school_id INTO target_school_id
+            FROM users u WHERE u.id = (target->>'user_id')::bigint;
+        END IF;
+
+        IF app_is_archived_year(target_school_id, (target->>'academic_year')::integer) THEN
+            RAISE EXCEPTION 'academic year % is archived and read-only', target->>'academic_year'
+                USING ERRCODE = 'read_only_sql_transaction';
+        END IF;
+    END LOOP;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END
+$$;
+
+CREATE OR REPLACE TRIGGER classes_archived_year_read_only
+    BEFORE INSERT OR UPDATE OR DELETE ON classes
+    FOR EACH ROW EXECUTE FUNCTION app_reject_archived_year_write();
+CREATE OR REPLACE TRIGGER courses_archived_year_read_only
+    BEFORE INSERT OR UPDATE OR DELETE ON courses
+    FOR EACH ROW EXECUTE FUNCTION app_reject_archived_year_write();
+CREATE OR REPLACE TRIGGER grades_archived_year_read_only
+    BEFORE INSERT OR UPDATE OR DELETE ON grades
+    FOR EACH ROW EXECUTE FUNCTION app_reject_archived_year_write();
+CREATE OR REPLACE TRIGGER user_points_archived_year_read_only
+    BEFORE INSERT OR UPDATE OR DELETE ON user_points
+    FOR EACH ROW EXECUTE FUNCTION app_reject_archived_year_write();
+
+-- 年度更新の対象（学校・年度ごとのクラス）の検索用
+CREATE INDEX IF NOT EXISTS idx_classes_school_year ON classes (school_id, academic_year);
+
+-- +migrate Down
+-- 年度更新のテーブル・トリガーを削除
+
+DROP TRIGGER IF EXISTS user_points_archived_year_read_only ON user_points;
+DROP TRIGGER IF EXISTS grades_archived_year_read_only ON grades;
+DROP TRIGGER IF EXISTS courses_archived_year_read_only ON courses;
+DROP TRIGGER IF EXISTS classes_archived_year_read_only ON classes;
+DROP FUNCTION IF EXISTS app_reject_archived_year_write();
+DROP FUNCTION IF EXISTS app_is_archived_year(BIGINT, INTEGER);
+DROP INDEX IF EXISTS idx_classes_school_year;
+DROP TABLE IF EXISTS academic_year_archives;
+ALTER TABLE users DROP COLUMN IF EXISTS graduated_at;
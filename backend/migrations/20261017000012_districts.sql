@@ -0,0 +1,83 @@
+-- +migrate Up
+-- 教育委員会（学区）による複数校の管理と教育委員会管理者ロールの追加
+
+CREATE TABLE IF NOT EXISTS districts (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    code TEXT UNIQUE NOT NULL,
+    prefecture TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+ALTER TABLE schools ADD COLUMN IF NOT EXISTS district_id BIGINT REFERENCES districts(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_schools_district_id ON schools(district_id);
+
+-- 教育委員会管理者が管轄する教育委員会（他のロールでは使用しない）
+ALTER TABLE users ADD COLUMN IF NOT EXISTS district_id BIGINT REFERENCES districts(id);
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'district_admin', 'school_admin', 'teacher', 'student', 'guardian'));
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_district_admin_check;
+ALTER TABLE users ADD CONSTRAINT users_district_admin_check
+    CHECK (role <> 'district_admin' OR district_id IS NOT NULL);
+
+-- 操作者が教育委員会管理者の場合に管轄する教育委員会
+-- schools・usersのRLSから呼ばれるため、所有者権限で参照して再帰を避ける
+CREATE OR REPLACE FUNCTION app_current_district_id() RETURNS BIGINT
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT u.district_id FROM users u
+    WHERE u.id = NULLIF(current_setting('app.current_user_id', true), '')::bigint
+      AND u.role = 'district_admin' AND u.deleted_at IS NULL
+$$;
+
+-- 学校が操作者の管轄する教育委員会に属するか
+CREATE OR REPLACE FUNCTION app_is_district_school(target_school_id BIGINT) RETURNS BOOLEAN
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM schools s
+        WHERE s.id = target_school_id AND s.district_id = app_current_district_id()
+    )
+$$;
+
+CREATE OR REPLACE FUNCTION app_can_access_school(target_school_id BIGINT) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT CASE
+        WHEN current_setting('app.current_user_role', true) = 'admin' THEN true
+        WHEN current_setting('app.current_user_role', true) = 'district_admin' THEN app_is_district_school(target_school_id)
+        ELSE target_school_id = NULLIF(current_setting('app.current_user_school_id', true), '')::bigint
+    END
+$$;
+
+ALTER TABLE districts ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS district_isolation_districts ON districts;
+CREATE POLICY district_isolation_districts ON districts
+    FOR ALL TO authenticated
+    USING (current_setting('app.current_user_role', true) = 'admin' OR id = app_current_district_id());
+
+-- +migrate Down
+-- 教育委員会と教育委員会管理者ロールの削除
+
+CREATE OR REPLACE FUNCTION app_can_access_school(target_school_id BIGINT) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT CASE
+        WHEN current_setting('app.current_user_role', true) = 'admin' THEN true
+        ELSE target_school_id = NULLIF(current_setting('app.current_user_school_id', true), '')::bigint
+    END
+$$;
+
+DROP FUNCTION IF EXISTS app_is_district_school(BIGINT);
+DROP FUNCTION IF EXISTS app_current_district_id();
+
+-- 教育委員会管理者は削除せず学校管理者に戻し、管理者が所属校を確認して承認し直すまで承認待ちにする
+UPDATE users SET role = 'school_admin', is_approved = false, updated_at = NOW()
+WHERE role = 'district_admin';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'school_admin', 'teacher', 'student', 'guardian'));
+ALTER TABLE users DROP COLUMN IF EXISTS district_id;
+
+DROP INDEX IF EXISTS idx_schools_district_id;
+ALTER TABLE schools DROP COLUMN IF EXISTS district_id;
+DROP TABLE IF EXISTS districts;
@@ -0,0 +1,42 @@
+-- +migrate Up
+-- 学校コードの採番と、変更前のコード（別名）の履歴
+
+-- 学校コードの連番（連番以外の部分が同じコードごとに最後に採番した値）
+CREATE TABLE IF NOT EXISTS school_code_sequences (
+    scope TEXT PRIMARY KEY,          -- 例: 13-{seq}
+    last_value INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 変更前の学校コード（引き続きその学校として解決する）
+CREATE TABLE IF NOT EXISTS school_code_aliases (
+    code TEXT PRIMARY KEY,
+    school_id BIGINT NOT NULL REFERENCES schools(id) ON DELETE CASCADE,
+    replaced_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    replaced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_school_code_aliases_school_id ON school_code_aliases(school_id);
+
+ALTER TABLE school_code_aliases ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS school_isolation_school_code_aliases ON school_code_aliases;
+CREATE POLICY school_isolation_school_code_aliases ON school_code_aliases
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+-- 学校コード（別名を含む）を使用している学校
+-- 他校のコードとの重複も判定するため、所有者権限でRLSを越えて参照する
+CREATE OR REPLACE FUNCTION app_school_code_owner(target_code TEXT) RETURNS BIGINT
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT id FROM schools WHERE code = target_code
+    UNION ALL
+    SELECT school_id FROM school_code_aliases WHERE code = target_code
+    LIMIT 1
+$$;
+
+-- +migrate Down
+-- 学校コードの採番と別名の履歴の削除
+
+DROP FUNCTION IF EXISTS app_school_code_owner(TEXT);
+DROP TABLE IF EXISTS school_code_aliases;
+DROP TABLE IF EXISTS school_code_sequences;
@@ -0,0 +1,12 @@
+-- +migrate Up
+-- 招待のメールアドレスの重複は承諾待ちの招待のみ禁止（取り消し・期限切れの後に再招待できるようにする）
+
+ALTER TABLE user_invitations DROP CONSTRAINT IF EXISTS user_invitations_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_invitations_pending_email ON user_invitations (email)
+    WHERE status = 'pending';
+
+-- +migrate Down
+-- メールアドレスの一意制約を戻す（再招待で同じメールアドレスの招待が複数ある場合は失敗する）
+
+DROP INDEX IF EXISTS idx_user_invitations_pending_email;
+ALTER TABLE user_invitations ADD CONSTRAINT user_invitations_email_key UNIQUE (email);
@@ -0,0 +1,25 @@
+-- +migrate Up
+-- 監査ログは追記のみ許可する（テーブル所有者・スーパーユーザーを含む全ロールで更新・削除を拒否）
+-- authenticatedロールの権限の取り消しだけでは、所有者権限で接続した処理や運用作業での書き換えを防げない
+
+CREATE OR REPLACE FUNCTION app_reject_audit_event_change() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only (% is not allowed)', TG_OP
+        USING ERRCODE = 'insufficient_privilege';
+END
+$$;
+
+CREATE OR REPLACE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION app_reject_audit_event_change();
+CREATE OR REPLACE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION app_reject_audit_event_change();
+
+-- +migrate Down
+-- 監査ログの更新・削除を拒否するトリガーを削除
+
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events;
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+DROP FUNCTION IF EXISTS app_reject_audit_event_change();
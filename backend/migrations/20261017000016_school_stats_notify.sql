@@ -0,0 +1,68 @@
+-- +migrate Up
+-- 学校の統計に関わる行の変更を通知し、APIサーバーが統計のキャッシュを無効化する
+-- 成績・出欠・提出物はAPIを経由せずに書き込まれることもあるため、DB側で検知する
+-- NOTIFYはコミット時に配信され（ロールバックした変更は通知しない）、同じトランザクション内の同じ学校の通知は1件にまとめられる
+
+CREATE OR REPLACE FUNCTION app_notify_school_stats_change() RETURNS TRIGGER
+LANGUAGE plpgsql SECURITY DEFINER SET search_path = public AS $$
+DECLARE
+    target JSONB;
+    target_school_id BIGINT;
+BEGIN
+    -- 更新の場合は変更前・変更後のどちらの学校にも通知する
+    FOREACH target IN ARRAY (
+        CASE TG_OP
+            WHEN 'INSERT' THEN ARRAY[to_jsonb(NEW)]
+            WHEN 'DELETE' THEN ARRAY[to_jsonb(OLD)]
+            ELSE ARRAY[to_jsonb(OLD), to_jsonb(NEW)]
+        END
+    ) LOOP
+        IF TG_TABLE_NAME IN ('users', 'classes') THEN
+            target_school_id := (target->>'school_id')::bigint;
+        ELSIF TG_TABLE_NAME = 'assignments' THEN
+            SELECT cl.school_id INTO target_school_id
+            FROM courses c JOIN classes cl ON cl.id = c.class_id
+            WHERE c.id = (target->>'course_id')::bigint;
+        ELSE
+            SELECT u.school_id INTO target_school_id
+            FROM users u WHERE u.id = (target->>'student_id')::bigint;
+        END IF;
+
+        IF target_school_id IS NOT NULL THEN
+            PERFORM pg_notify('school_stats_changed', target_school_id::text);
+        END IF;
+    END LOOP;
+    RETURN NULL;
+END
+$$;
+
+-- usersはログイン日時の更新も含む（直近のログイン人数）
+CREATE OR REPLACE TRIGGER users_school_stats_notify
+    AFTER INSERT OR UPDATE OR DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION app_notify_school_stats_change();
+CREATE OR REPLACE TRIGGER classes_school_stats_notify
+    AFTER INSERT OR UPDATE OR DELETE ON classes
+    FOR EACH ROW EXECUTE FUNCTION app_notify_school_stats_change();
+CREATE OR REPLACE TRIGGER assignments_school_stats_notify
+    AFTER INSERT OR UPDATE OR DELETE ON assignments
+    FOR EACH ROW EXECUTE FUNCTION app_notify_school_stats_change();
+CREATE OR REPLACE TRIGGER submissions_school_stats_notify
+    AFTER INSERT OR UPDATE OR DELETE ON submissions
+    FOR EACH ROW EXECUTE FUNCTION app_notify_school_stats_change();
+CREATE OR REPLACE TRIGGER attendance_school_stats_notify
+    AFTER INSERT OR UPDATE OR DELETE ON attendance
+    FOR EACH ROW EXECUTE FUNCTION app_notify_school_stats_change();
+CREATE OR REPLACE TRIGGER grades_school_stats_notify
+    AFTER INSERT OR UPDATE OR DELETE ON grades
+    FOR EACH ROW EXECUTE FUNCTION app_notify_school_stats_change();
+
+-- +migrate Down
+-- 学校の統計の変更通知を削除
+
+DROP TRIGGER IF EXISTS grades_school_stats_notify ON grades;
+DROP TRIGGER IF EXISTS attendance_school_stats_notify ON attendance;
+DROP TRIGGER IF EXISTS submissions_school_stats_notify ON submissions;
+DROP TRIGGER IF EXISTS assignments_school_stats_notify ON assignments;
+DROP TRIGGER IF EXISTS classes_school_stats_notify ON classes;
+DROP TRIGGER IF EXISTS users_school_stats_notify ON users;
+DROP FUNCTION IF EXISTS app_notify_school_stats_change();
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- メールアドレスを使用しているユーザー（名簿取り込みの重複チェック用）
+-- usersのメールアドレスは全校で一意のため、他校や削除済みのユーザーも所有者権限でRLSを越えて参照する
+-- 他校のユーザーの情報は返さず、IDのみ返す
+
+CREATE OR REPLACE FUNCTION app_email_owners(target_emails TEXT[]) RETURNS TABLE (owner_email TEXT, owner_id BIGINT)
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT LOWER(u.email), u.id FROM users u WHERE LOWER(u.email) = ANY(target_emails)
+$$;
+
+-- +migrate Down
+-- メールアドレスの使用者の参照関数を削除
+
+DROP FUNCTION IF EXISTS app_email_owners(TEXT[]);
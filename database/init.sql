@@ -3,6 +3,19 @@
 -- Enable UUID extension
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
+-- 氏名・メールアドレスの部分一致検索用（日本語の索引にはLC_CTYPEがC以外のデータベースが必要）
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- 教育委員会（学区）テーブル
+CREATE TABLE IF NOT EXISTS districts (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    code TEXT UNIQUE NOT NULL,
+    prefecture TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
 -- 学校情報テーブル
 CREATE TABLE IF NOT EXISTS schools (
     id BIGSERIAL PRIMARY KEY,
@@ -17,9 +30,11 @@ CREATE TABLE IF NOT EXISTS schools (
     principal_name TEXT,
     vice_principal_name TEXT,
     student_capacity INTEGER DEFAULT 500,
-    settings JSONB DEFAULT '{"theme": "coral", "background": "#fdf8f0"}',
+    settings JSONB DEFAULT '{"version": 1, "theme": "coral", "locale": "ja", "timezone": "Asia/Tokyo"}', -- 学校設定（バージョン付き）
     academic_year_start INTEGER DEFAULT 4,
+    district_id BIGINT REFERENCES districts(id) ON DELETE SET NULL, -- 所属する教育委員会
     is_active BOOLEAN DEFAULT true,
+    deleted_at TIMESTAMPTZ,              -- 論理削除（猶予期間内は復元可能）
     created_at TIMESTAMPTZ DEFAULT NOW(),
     updated_at TIMESTAMPTZ DEFAULT NOW()
 );
@@ -34,6 +49,7 @@ CREATE TABLE IF NOT EXISTS users (
     avatar_url TEXT,
     role TEXT NOT NULL DEFAULT 'student',
     school_id BIGINT NOT NULL REFERENCES schools(id),
+    district_id BIGINT REFERENCES districts(id),  -- 教育委員会管理者が管轄する教育委員会
     class_id BIGINT REFERENCES classes(id),
     student_number TEXT,
     grade INTEGER CHECK (grade BETWEEN 1 AND 3),
@@ -42,13 +58,23 @@ CREATE TABLE IF NOT EXISTS users (
     guardian_email_encrypted TEXT,
     is_active BOOLEAN DEFAULT true,
     is_approved BOOLEAN DEFAULT false,
+    approval_reviewed_at TIMESTAMPTZ,    -- 承認・却下した日時（未審査はNULL）
+    approval_reviewed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    rejection_reason TEXT,
     ui_preferences JSONB DEFAULT '{"theme": "coral", "background": "#fdf8f0"}',
+    password_hash TEXT,  -- ローカル認証（bcrypt）
+    must_change_password BOOLEAN NOT NULL DEFAULT false,
+    password_changed_at TIMESTAMPTZ,
     last_login_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,              -- 論理削除（猶予期間内は復元可能）
+    erased_at TIMESTAMPTZ,               -- 個人情報を消去した日時
+    graduated_at TIMESTAMPTZ,            -- 卒業日時（卒業した生徒は無効化する）
     created_at TIMESTAMPTZ DEFAULT NOW(),
     updated_at TIMESTAMPTZ DEFAULT NOW(),
     
     UNIQUE(school_id, student_number),
-    CHECK (role IN ('admin', 'school_admin', 'teacher', 'student'))
+    CHECK (role IN ('admin', 'district_admin', 'school_admin', 'teacher', 'student', 'guardian')),
+    CONSTRAINT users_district_admin_check CHECK (role <> 'district_admin' OR district_id IS NOT NULL)
 );
 
 -- クラス情報テーブル
@@ -101,6 +127,67 @@ ALTER TABLE users
 ADD CONSTRAINT fk_class_id 
 FOREIGN KEY (class_id) REFERENCES classes(id);
 
+-- 保護者・生徒の紐付けテーブル
+CREATE TABLE IF NOT EXISTS guardian_students (
+    guardian_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    student_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    relationship TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    
+    PRIMARY KEY (guardian_id, student_id)
+);
+
+-- パスワード再設定用のワンタイムトークン（SHA-256ハッシュのみ保持）
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 二要素認証（TOTPシークレットはENCRYPTION_KEYで暗号化）
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret_encrypted TEXT NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 二要素認証のリカバリーコード（SHA-256ハッシュのみ保持）
+CREATE TABLE IF NOT EXISTS user_mfa_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE(user_id, code_hash)
+);
+
+-- 管理操作の監査ログ（学校ごとのハッシュチェーンで改ざんを検知）
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    school_id BIGINT,
+    actor_id BIGINT,
+    actor_uid TEXT NOT NULL DEFAULT '',
+    actor_role TEXT NOT NULL,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    changes JSONB,
+    request_id TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    prev_hash TEXT NOT NULL DEFAULT '',
+    hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    impersonator_id BIGINT, -- 代理ログイン中の操作の場合、実際に操作した管理者
+    impersonator_uid TEXT NOT NULL DEFAULT ''
+);
+
 -- 教科テーブル
 CREATE TABLE IF NOT EXISTS subjects (
     id BIGSERIAL PRIMARY KEY,
@@ -386,7 +473,7 @@ CREATE TABLE IF NOT EXISTS achievements (
 CREATE TABLE IF NOT EXISTS user_invitations (
     id BIGSERIAL PRIMARY KEY,
     name TEXT NOT NULL,
-    email TEXT UNIQUE NOT NULL,
+    email TEXT NOT NULL,
     role TEXT NOT NULL,
     school_id BIGINT NOT NULL REFERENCES schools(id),
     message TEXT,
@@ -401,45 +488,407 @@ CREATE TABLE IF NOT EXISTS user_invitations (
 CREATE INDEX IF NOT EXISTS idx_users_firebase_uid ON users(firebase_uid);
 CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
 CREATE INDEX IF NOT EXISTS idx_users_school_id ON users(school_id);
+CREATE INDEX IF NOT EXISTS idx_schools_district_id ON schools(district_id);
 CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+CREATE INDEX IF NOT EXISTS idx_users_name_trgm ON users USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_furigana_trgm ON users USING gin (furigana gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING gin (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_school_created_at ON users (school_id, (COALESCE(created_at, '-infinity'::timestamptz)), id);
+CREATE INDEX IF NOT EXISTS idx_users_school_last_login_at ON users (school_id, last_login_at);
+CREATE INDEX IF NOT EXISTS idx_users_class_id ON users (class_id);
+CREATE INDEX IF NOT EXISTS idx_users_pending_erasure ON users (deleted_at) WHERE deleted_at IS NOT NULL AND erased_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_pending_approval ON users (school_id, created_at, id) WHERE is_approved = false AND approval_reviewed_at IS NULL;
 CREATE INDEX IF NOT EXISTS idx_classes_school_id ON classes(school_id);
 CREATE INDEX IF NOT EXISTS idx_teachers_user_id ON teachers(user_id);
 CREATE INDEX IF NOT EXISTS idx_courses_class_id ON courses(class_id);
+CREATE INDEX IF NOT EXISTS idx_courses_teacher_id ON courses(teacher_id);
 CREATE INDEX IF NOT EXISTS idx_materials_course_id ON materials(course_id);
 CREATE INDEX IF NOT EXISTS idx_assignments_course_id ON assignments(course_id);
 CREATE INDEX IF NOT EXISTS idx_submissions_assignment_id ON submissions(assignment_id);
 CREATE INDEX IF NOT EXISTS idx_grades_student_id ON grades(student_id);
 CREATE INDEX IF NOT EXISTS idx_attendance_student_id ON attendance(student_id);
+CREATE INDEX IF NOT EXISTS idx_attendance_course_id ON attendance(course_id);
+CREATE INDEX IF NOT EXISTS idx_guardian_students_student_id ON guardian_students(student_id);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
 CREATE INDEX IF NOT EXISTS idx_chat_rooms_school_id ON chat_rooms(school_id);
 CREATE INDEX IF NOT EXISTS idx_messages_room_id ON messages(room_id);
 CREATE INDEX IF NOT EXISTS idx_learning_notes_student_id ON learning_notes(student_id);
 CREATE INDEX IF NOT EXISTS idx_administrative_tasks_school_id ON administrative_tasks(school_id);
 CREATE INDEX IF NOT EXISTS idx_meetings_school_id ON meetings(school_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_school_id ON audit_events(school_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target ON audit_events(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_impersonator ON audit_events(impersonator_id, id) WHERE impersonator_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_classes_school_year ON classes(school_id, academic_year);
+-- 招待のメールアドレスの重複は承諾待ちの招待のみ禁止（取り消し・期限切れの後に再招待できるようにする）
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_invitations_pending_email ON user_invitations (email)
+    WHERE status = 'pending';
 
--- Row Level Security (RLS) 有効化
-ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+-- Row Level Security (RLS)
+-- 認証済みリクエストは SET LOCAL ROLE authenticated と app.current_user_* を設定したトランザクション内で実行される
+
+-- RLSの対象となるロール（テーブル所有者はRLSをバイパスするため切り替えて使用する）
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        CREATE ROLE authenticated NOLOGIN;
+    END IF;
+END
+$$;
+
+GRANT authenticated TO CURRENT_USER;
+GRANT USAGE ON SCHEMA public TO authenticated;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO authenticated;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO authenticated;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO authenticated;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO authenticated;
+
+-- 操作者が教育委員会管理者の場合に管轄する教育委員会
+-- schools・usersのRLSから呼ばれるため、所有者権限で参照して再帰を避ける
+CREATE OR REPLACE FUNCTION app_current_district_id() RETURNS BIGINT
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT u.district_id FROM users u
+    WHERE u.id = NULLIF(current_setting('app.current_user_id', true), '')::bigint
+      AND u.role = 'district_admin' AND u.deleted_at IS NULL
+$$;
+
+-- 学校が操作者の管轄する教育委員会に属するか
+CREATE OR REPLACE FUNCTION app_is_district_school(target_school_id BIGINT) RETURNS BOOLEAN
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM schools s
+        WHERE s.id = target_school_id AND s.district_id = app_current_district_id()
+    )
+$$;
+
+-- リクエスト元がその学校のデータにアクセスできるか（adminは全校、district_adminは管轄校）
+CREATE OR REPLACE FUNCTION app_can_access_school(target_school_id BIGINT) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT CASE
+        WHEN current_setting('app.current_user_role', true) = 'admin' THEN true
+        WHEN current_setting('app.current_user_role', true) = 'district_admin' THEN app_is_district_school(target_school_id)
+        ELSE target_school_id = NULLIF(current_setting('app.current_user_school_id', true), '')::bigint
+    END
+$$;
+
+ALTER TABLE districts ENABLE ROW LEVEL SECURITY;
+CREATE POLICY district_isolation_districts ON districts
+    FOR ALL TO authenticated
+    USING (current_setting('app.current_user_role', true) = 'admin' OR id = app_current_district_id());
+
+-- school_id を直接持つテーブル
 ALTER TABLE schools ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_schools ON schools
+    FOR ALL TO authenticated
+    USING (app_can_access_school(id));
+
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_users ON users
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
 ALTER TABLE classes ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_classes ON classes
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+ALTER TABLE chat_rooms ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_chat_rooms ON chat_rooms
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+ALTER TABLE whiteboard_sessions ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_whiteboard_sessions ON whiteboard_sessions
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+ALTER TABLE administrative_tasks ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_administrative_tasks ON administrative_tasks
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+ALTER TABLE meetings ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_meetings ON meetings
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+ALTER TABLE user_invitations ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_user_invitations ON user_invitations
+    FOR ALL TO authenticated
+    USING (app_can_access_school(school_id));
+
+-- 親テーブル経由で学校に属するテーブル（親テーブルのRLSにより他校の行は参照できない）
 ALTER TABLE teachers ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_teachers ON teachers
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = teachers.user_id));
 
--- RLSポリシー作成（基本的な学校別分離）
-CREATE POLICY school_isolation_users ON users
+ALTER TABLE courses ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_courses ON courses
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM classes cl WHERE cl.id = courses.class_id));
+
+ALTER TABLE materials ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_materials ON materials
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM courses c WHERE c.id = materials.course_id));
+
+ALTER TABLE assignments ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_assignments ON assignments
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM courses c WHERE c.id = assignments.course_id));
+
+ALTER TABLE submissions ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_submissions ON submissions
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = submissions.student_id));
+
+ALTER TABLE grades ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_grades ON grades
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = grades.student_id));
+
+ALTER TABLE attendance ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_attendance ON attendance
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = attendance.student_id));
+
+ALTER TABLE messages ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_messages ON messages
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM chat_rooms cr WHERE cr.id = messages.room_id));
+
+ALTER TABLE learning_notes ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_learning_notes ON learning_notes
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = learning_notes.student_id));
+
+ALTER TABLE user_points ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_user_points ON user_points
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = user_points.user_id));
+
+ALTER TABLE guardian_students ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_guardian_students ON guardian_students
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = guardian_students.student_id));
+
+ALTER TABLE password_reset_tokens ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_password_reset_tokens ON password_reset_tokens
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = password_reset_tokens.user_id));
+
+ALTER TABLE user_mfa ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_user_mfa ON user_mfa
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = user_mfa.user_id));
+
+ALTER TABLE user_mfa_recovery_codes ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_user_mfa_recovery_codes ON user_mfa_recovery_codes
+    FOR ALL TO authenticated
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = user_mfa_recovery_codes.user_id));
+
+-- 監査ログは追記のみ許可する
+REVOKE UPDATE, DELETE ON audit_events FROM authenticated;
+
+ALTER TABLE audit_events ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_audit_events_select ON audit_events
+    FOR SELECT TO authenticated
+    USING (app_can_access_school(school_id));
+CREATE POLICY school_isolation_audit_events_insert ON audit_events
+    FOR INSERT TO authenticated
+    WITH CHECK (app_can_access_school(school_id));
+
+-- 所有者権限の接続を含め、監査ログの更新・削除を拒否する
+CREATE OR REPLACE FUNCTION app_reject_audit_event_change() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only (% is not allowed)', TG_OP
+        USING ERRCODE = 'insufficient_privilege';
+END
+$$;
+
+CREATE OR REPLACE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION app_reject_audit_event_change();
+CREATE OR REPLACE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION app_reject_audit_event_change();
+
+-- 年度更新の済んだ年度（この年度のクラス・授業・成績・ポイントは変更できない）
+CREATE TABLE IF NOT EXISTS academic_year_archives (
+    school_id BIGINT NOT NULL REFERENCES schools(id),
+    academic_year INTEGER NOT NULL,
+    archived_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    summary JSONB,                   -- 進級・卒業・複製した件数
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (school_id, academic_year)
+);
+
+REVOKE UPDATE, DELETE ON academic_year_archives FROM authenticated;
+
+ALTER TABLE academic_year_archives ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_academic_year_archives ON academic_year_archives
     FOR ALL TO authenticated
-    USING (
-        CASE 
-            WHEN current_setting('app.current_user_role', true) = 'admin' THEN true
-            ELSE school_id = current_setting('app.current_user_school_id', true)::bigint
+    USING (app_can_access_school(school_id));
+
+-- 学校のその年度が年度更新済みか
+CREATE OR REPLACE FUNCTION app_is_archived_year(target_school_id BIGINT, target_year INTEGER) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM academic_year_archives
+        WHERE school_id = target_school_id AND academic_year = target_year
+    )
+$$;
+
+-- 年度を持つ行の学校を求め、更新の済んだ年度の変更を拒否する
+CREATE OR REPLACE FUNCTION app_reject_archived_year_write() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+DECLARE
+    target JSONB;
+    target_school_id BIGINT;
+BEGIN
+    -- 更新の場合は変更前・変更後のどちらの年度も確認する
+    FOREACH target IN ARRAY (
+        CASE TG_OP
+            WHEN 'INSERT' THEN ARRAY[to_jsonb(NEW)]
+            WHEN 'DELETE' THEN ARRAY[to_jsonb(OLD)]
+            ELSE ARRAY[to_jsonb(OLD), to_jsonb(NEW)]
         END
-    );
+    ) LOOP
+        IF TG_TABLE_NAME = 'classes' THEN
+            target_school_id := (target->>'school_id')::bigint;
+        ELSIF TG_TABLE_NAME = 'courses' THEN
+            SELECT cl.school_id INTO target_school_id
+            FROM classes cl WHERE cl.id = (target->>'class_id')::bigint;
+        ELSIF TG_TABLE_NAME = 'grades' THEN
+            SELECT cl.school_id INTO target_school_id
+            FROM courses c JOIN classes cl ON cl.id = c.class_id
+            WHERE c.id = (target->>'course_id')::bigint;
+        ELSE
+            SELECT u.school_id INTO target_school_id
+            FROM users u WHERE u.id = (target->>'user_id')::bigint;
+        END IF;
 
-CREATE POLICY school_isolation_classes ON classes
+        IF app_is_archived_year(target_school_id, (target->>'academic_year')::integer) THEN
+            RAISE EXCEPTION 'academic year % is archived and read-only', target->>'academic_year'
+                USING ERRCODE = 'read_only_sql_transaction';
+        END IF;
+    END LOOP;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END
+$$;
+
+CREATE OR REPLACE TRIGGER classes_archived_year_read_only
+    BEFORE INSERT OR UPDATE OR DELETE ON classes
+    FOR EACH ROW EXECUTE FUNCTION app_reject_archived_year_write();
+CREATE OR REPLACE TRIGGER courses_archived_year_read_only
+    BEFORE INSERT OR UPDATE OR DELETE ON courses
+    FOR EACH ROW EXECUTE FUNCTION app_reject_archived_year_write();
+CREATE OR REPLACE TRIGGER grades_archived_year_read_only
+    BEFORE INSERT OR UPDATE OR DELETE ON grades
+    FOR EACH ROW EXECUTE FUNCTION app_reject_archived_year_write();
+CREATE OR REPLACE TRIGGER user_points_archived_year_read_only
+    BEFORE INSERT OR UPDATE OR DELETE ON user_points
+    FOR EACH ROW EXECUTE FUNCTION app_reject_archived_year_write();
+
+-- 学校コードの連番（連番以外の部分が同じコードごとに最後に採番した値）
+CREATE TABLE IF NOT EXISTS school_code_sequences (
+    scope TEXT PRIMARY KEY,          -- 例: 13-{seq}
+    last_value INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 変更前の学校コード（引き続きその学校として解決する）
+CREATE TABLE IF NOT EXISTS school_code_aliases (
+    code TEXT PRIMARY KEY,
+    school_id BIGINT NOT NULL REFERENCES schools(id) ON DELETE CASCADE,
+    replaced_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    replaced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_school_code_aliases_school_id ON school_code_aliases(school_id);
+
+ALTER TABLE school_code_aliases ENABLE ROW LEVEL SECURITY;
+CREATE POLICY school_isolation_school_code_aliases ON school_code_aliases
     FOR ALL TO authenticated
-    USING (
-        CASE 
-            WHEN current_setting('app.current_user_role', true) = 'admin' THEN true
-            ELSE school_id = current_setting('app.current_user_school_id', true)::bigint
+    USING (app_can_access_school(school_id));
+
+-- 学校コード（別名を含む）を使用している学校
+-- 他校のコードとの重複も判定するため、所有者権限でRLSを越えて参照する
+CREATE OR REPLACE FUNCTION app_school_code_owner(target_code TEXT) RETURNS BIGINT
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT id FROM schools WHERE code = target_code
+    UNION ALL
+    SELECT school_id FROM school_code_aliases WHERE code = target_code
+    LIMIT 1
+$$;
+
+-- メールアドレスを使用しているユーザー（名簿取り込みの重複チェック用）
+-- 他校や削除済みのユーザーも所有者権限でRLSを越えて参照し、IDのみ返す
+CREATE OR REPLACE FUNCTION app_email_owners(target_emails TEXT[]) RETURNS TABLE (owner_email TEXT, owner_id BIGINT)
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT LOWER(u.email), u.id FROM users u WHERE LOWER(u.email) = ANY(target_emails)
+$$;
+
+-- 学校の統計に関わる行の変更を通知する（APIサーバーが統計のキャッシュを無効化する）
+CREATE OR REPLACE FUNCTION app_notify_school_stats_change() RETURNS TRIGGER
+LANGUAGE plpgsql SECURITY DEFINER SET search_path = public AS $$
+DECLARE
+    target JSONB;
+    target_school_id BIGINT;
+BEGIN
+    -- 更新の場合は変更前・変更後のどちらの学校にも通知する
+    FOREACH target IN ARRAY (
+        CASE TG_OP
+            WHEN 'INSERT' THEN ARRAY[to_jsonb(NEW)]
+            WHEN 'DELETE' THEN ARRAY[to_jsonb(OLD)]
+            ELSE ARRAY[to_jsonb(OLD), to_jsonb(NEW)]
         END
-    );
+    ) LOOP
+        IF TG_TABLE_NAME IN ('users', 'classes') THEN
+            target_school_id := (target->>'school_id')::bigint;
+        ELSIF TG_TABLE_NAME = 'assignments' THEN
+            SELECT cl.school_id INTO target_school_id
+            FROM courses c JOIN classes cl ON cl.id = c.class_id
+            WHERE c.id = (target->>'course_id')::bigint;
+        ELSE
+            SELECT u.school_id INTO target_school_id
+            FROM users u WHERE u.id = (target->>'student_id')::bigint;
+        END IF;
+
+        IF target_school_id IS NOT NULL THEN
+            PERFORM pg_notify('school_stats_changed', target_school_id::text);
+        END IF;
+    END LOOP;
+    RETURN NULL;
+END
+$$;
+
+-- usersはログイン日時の更新も含む（直近のログイン人数）
+CREATE OR REPLACE TRIGGER users_school_stats_notify
+    AFTER INSERT OR UPDATE OR DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION app_notify_school_stats_change();
+CREATE OR REPLACE TRIGGER classes_school_stats_notify
+    AFTER INSERT OR UPDATE OR DELETE ON classes
+    FOR EACH ROW EXECUTE FUNCTION app_notify_school_stats_change();
+CREATE OR REPLACE TRIGGER assignments_school_stats_notify
+    AFTER INSERT OR UPDATE OR DELETE ON assignments
+    FOR EACH ROW EXECUTE FUNCTION app_notify_school_stats_change();
+CREATE OR REPLACE TRIGGER submissions_school_stats_notify
+    AFTER INSERT OR UPDATE OR DELETE ON submissions
+    FOR EACH ROW EXECUTE FUNCTION app_notify_school_stats_change();
+CREATE OR REPLACE TRIGGER attendance_school_stats_notify
+    AFTER INSERT OR UPDATE OR DELETE ON attendance
+    FOR EACH ROW EXECUTE FUNCTION app_notify_school_stats_change();
+CREATE OR REPLACE TRIGGER grades_school_stats_notify
+    AFTER INSERT OR UPDATE OR DELETE ON grades
+    FOR EACH ROW EXECUTE FUNCTION app_notify_school_stats_change();
 
 -- データ保持期限管理テーブル
 CREATE TABLE IF NOT EXISTS data_retention_policies (
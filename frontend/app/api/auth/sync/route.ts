@@ -1,28 +1,28 @@
 import { NextRequest, NextResponse } from 'next/server';
 
 // ユーザー情報をバックエンドAPIに送信して同期
+// UID・メールアドレスはバックエンドがFirebase IDトークンから取得する
 export async function POST(request: NextRequest) {
   try {
-    const body = await request.json();
-    const { firebaseUid, email, displayName, schoolId, role } = body;
-
-    if (!firebaseUid || !email) {
-      return NextResponse.json({ error: 'Firebase UID and email are required' }, { status: 400 });
+    const authHeader = request.headers.get('authorization');
+    if (!authHeader || !authHeader.startsWith('Bearer ')) {
+      return NextResponse.json({ error: 'Not authenticated' }, { status: 401 });
     }
 
+    const body = await request.json();
+    const { firebaseUid, displayName } = body;
+
     // バックエンドの認証同期APIを呼び出し
     const backendUrl = process.env.API_BASE_URL || process.env.NEXT_PUBLIC_API_BASE_URL || 'http://localhost:8080';
     const response = await fetch(`${backendUrl}/api/v1/auth/sync`, {
       method: 'POST',
       headers: {
+        'Authorization': authHeader,
         'Content-Type': 'application/json',
       },
       body: JSON.stringify({
         firebase_uid: firebaseUid,
-        email,
-        display_name: displayName,
-        school_id: schoolId,
-        role
+        display_name: displayName
       })
     });
 
@@ -46,18 +46,17 @@ export async function POST(request: NextRequest) {
 // ユーザー情報を取得
 export async function GET(request: NextRequest) {
   try {
-    const url = new URL(request.url);
-    const firebaseUid = url.searchParams.get('uid');
-
-    if (!firebaseUid) {
-      return NextResponse.json({ error: 'Firebase UID is required' }, { status: 400 });
+    const authHeader = request.headers.get('authorization');
+    if (!authHeader || !authHeader.startsWith('Bearer ')) {
+      return NextResponse.json({ error: 'Not authenticated' }, { status: 401 });
     }
 
-    // バックエンドAPIからユーザー情報を取得
+    // バックエンドAPIからユーザー情報を取得（トークンのユーザー本人のみ）
     const backendUrl = process.env.API_BASE_URL || process.env.NEXT_PUBLIC_API_BASE_URL || 'http://localhost:8080';
-    const response = await fetch(`${backendUrl}/api/v1/auth/sync?uid=${firebaseUid}`, {
+    const response = await fetch(`${backendUrl}/api/v1/auth/sync`, {
       method: 'GET',
       headers: {
+        'Authorization': authHeader,
         'Content-Type': 'application/json',
       },
     });
@@ -5,6 +5,7 @@ import { useRouter } from 'next/navigation';
 import Link from 'next/link';
 import { Button } from '@/components/ui/button';
 import { useAuth } from '@/contexts/AuthContext';
+import { getIdToken } from '@/lib/auth';
 import '@/styles/app.css';
 
 // Force dynamic rendering
@@ -47,17 +48,17 @@ export default function HomePage() {
   // ユーザー同期を実行
   const syncUser = async () => {
     try {
+      const idToken = await getIdToken();
+      if (!firebaseUser || !idToken) return;
       const response = await fetch('/api/auth/sync', { 
         method: 'POST',
         headers: {
+          'Authorization': `Bearer ${idToken}`,
           'Content-Type': 'application/json',
         },
         body: JSON.stringify({
-          firebaseUid: firebaseUser?.uid,
-          email: firebaseUser?.email,
-          displayName: firebaseUser?.displayName,
-          schoolId: 'default-school', // デフォルトスクールID
-          role: 'user' // デフォルトロール
+          firebaseUid: firebaseUser.uid,
+          displayName: firebaseUser.displayName
         })
       });
       if (response.ok) {
@@ -0,0 +1,76 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Usage: DATABASE_URL=postgres://... scripts/check-rls.sh
+#
+# 学校Aのschool_adminとして、WHERE句で絞り込まない（バグのある）クエリでも
+# 学校Bの行を参照・更新・作成できないことを確認する。
+# テストデータは1トランザクション内で作成し、最後にロールバックする。
+# リポジトリのクエリをRunAsTenant経由で確認するGoのテストは
+#   TEST_DATABASE_URL=postgres://... go test ./internal/infrastructure/repository/school/
+# で実行する（backendディレクトリで実行。マイグレーションを適用するためテスト用のDBを指定する）。
+
+if [ -z "${DATABASE_URL:-}" ]; then
+  echo "DATABASE_URL is required" >&2
+  exit 1
+fi
+
+psql "$DATABASE_URL" -X -q -v ON_ERROR_STOP=1 <<'SQL'
+\o /dev/null
+BEGIN;
+
+CREATE FUNCTION pg_temp.assert(ok BOOLEAN, message TEXT) RETURNS VOID
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF ok IS NOT TRUE THEN
+        RAISE EXCEPTION 'RLS check failed: %', message;
+    END IF;
+END
+$$;
+
+-- テストデータ（学校A・Bとそれぞれの生徒・クラス）
+INSERT INTO schools (name, code) VALUES ('RLS Check A', 'rls-check-a') RETURNING id AS school_a \gset
+INSERT INTO schools (name, code) VALUES ('RLS Check B', 'rls-check-b') RETURNING id AS school_b \gset
+INSERT INTO users (firebase_uid, name, email, role, school_id)
+    VALUES ('rls-check-a', 'Student A', 'rls-check-a@example.com', 'student', :school_a) RETURNING id AS student_a \gset
+INSERT INTO users (firebase_uid, name, email, role, school_id)
+    VALUES ('rls-check-b', 'Student B', 'rls-check-b@example.com', 'student', :school_b) RETURNING id AS student_b \gset
+INSERT INTO classes (school_id, name, grade, academic_year) VALUES (:school_b, 'RLS Check B-1', 1, 2000);
+
+-- アプリケーションと同じ手順で学校Aのschool_adminとしてテナント設定
+SET LOCAL ROLE authenticated;
+SET LOCAL app.current_user_role = 'school_admin';
+SET LOCAL app.current_user_school_id = :'school_a';
+SET LOCAL rls_check.school_b = :'school_b';
+
+-- 絞り込みのないクエリ
+SELECT count(*) FILTER (WHERE id = :student_a) AS visible_own,
+       count(*) FILTER (WHERE id = :student_b) AS visible_other
+FROM users \gset
+SELECT count(*) AS visible_schools FROM schools WHERE id = :school_b \gset
+SELECT count(*) AS visible_classes FROM classes WHERE school_id = :school_b \gset
+UPDATE users SET name = 'tampered' WHERE id = :student_b;
+
+-- 他校へのINSERTはポリシー違反になる
+DO $$
+BEGIN
+    INSERT INTO users (firebase_uid, name, email, role, school_id)
+        VALUES ('rls-check-c', 'Intruder', 'rls-check-c@example.com', 'student', current_setting('rls_check.school_b')::bigint);
+    RAISE EXCEPTION 'RLS check failed: inserted a user into another school';
+EXCEPTION WHEN insufficient_privilege THEN
+    NULL;
+END
+$$;
+
+RESET ROLE;
+
+SELECT pg_temp.assert(:visible_own = 1, 'school_admin cannot read users of own school');
+SELECT pg_temp.assert(:visible_other = 0, 'school_admin can read users of another school');
+SELECT pg_temp.assert(:visible_schools = 0, 'school_admin can read another school');
+SELECT pg_temp.assert(:visible_classes = 0, 'school_admin can read classes of another school');
+SELECT pg_temp.assert((SELECT name FROM users WHERE id = :student_b) = 'Student B', 'school_admin updated a user of another school');
+
+ROLLBACK;
+\o
+\echo 'RLS check passed'
+SQL